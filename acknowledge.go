@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// destructiveStatusContext is the commit status that blocks merging until
+// destructive changes are acknowledged
+const destructiveStatusContext = "tfplan-commenter/destructive"
+
+// destructiveMarkerPattern reads the destructive change count recorded in a
+// plan comment
+var destructiveMarkerPattern = regexp.MustCompile(`<!-- tfplan-commenter:destructive (\d+) -->`)
+
+// destructiveMarker records the number of deletes and replacements of a
+// posted plan so acknowledgements can be evaluated without the plan
+func destructiveMarker(count int) string {
+	return fmt.Sprintf("%sdestructive %d -->", commentMarkerPrefix, count)
+}
+
+// headMarkerPattern reads the commit recorded in a plan comment
+var headMarkerPattern = regexp.MustCompile(`<!-- tfplan-commenter:head ([0-9a-fA-F]+) -->`)
+
+// headMarker records the commit a posted plan was made for, so approvals and
+// acknowledgements of it only count while that commit is the head
+func headMarker(sha string) string {
+	return fmt.Sprintf("%shead %s -->", commentMarkerPrefix, sha)
+}
+
+// planCommit returns the commit recorded in a plan comment, or "" when the
+// comment has none
+func planCommit(body string) string {
+	if match := headMarkerPattern.FindStringSubmatch(body); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// outdatedPlanDescription explains why a plan's sign-off doesn't count for
+// the pull request's head commit
+func outdatedPlanDescription(planned, head string) string {
+	if planned == "" {
+		return fmt.Sprintf("Plan comment records no commit; waiting for a plan of %s", shortCommit(head))
+	}
+	return fmt.Sprintf("Plan is for %s, not the head commit %s; waiting for a new plan", shortCommit(planned), shortCommit(head))
+}
+
+// AcknowledgementConfig requires an explicit sign-off comment from one of
+// the listed users before deletes and replacements can be merged
+type AcknowledgementConfig struct {
+	// Users are the GitHub logins allowed to acknowledge destruction
+	Users []string `json:"users"`
+	// Phrase is the comment that acknowledges (default "/acknowledge-destroy")
+	Phrase string `json:"phrase"`
+}
+
+func (a *AcknowledgementConfig) validate() error {
+	if len(a.Users) == 0 {
+		return fmt.Errorf("destructive_ack.users must list at least one user")
+	}
+	return nil
+}
+
+func (a *AcknowledgementConfig) phrase() string {
+	if a.Phrase == "" {
+		return "/acknowledge-destroy"
+	}
+	return a.Phrase
+}
+
+// destructiveChanges counts deletes and replacements across plans
+func destructiveChanges(plans []PlanInfo, opts ReportOptions) int {
+	count := 0
+	for _, env := range environmentResults(plans, opts) {
+		count += env.Delete + env.Replace
+	}
+	return count
+}
+
+// Acknowledgement is the destructive change sign-off state of a pull request's
+// latest plan comment
+type Acknowledgement struct {
+	PlanComment    int64
+	Commit         string // Commit the plan was made for
+	Destructive    int
+	AcknowledgedBy string
+	Outdated       bool // The plan isn't for the pull request's head commit
+}
+
+// evaluateAcknowledgement finds the latest plan comment and the first
+// authorized user who commented the acknowledgement phrase after it
+func evaluateAcknowledgement(client *githubClient, repository string, number int, config *AcknowledgementConfig) (Acknowledgement, error) {
+	var ack Acknowledgement
+	login, err := client.authenticatedLogin()
+	if err != nil {
+		return ack, err
+	}
+	comments, err := client.listComments(repository, number)
+	if err != nil {
+		return ack, err
+	}
+	plan := latestPlanComment(comments, login)
+	if plan == nil {
+		return ack, nil
+	}
+	ack.PlanComment = plan.ID
+	ack.Commit = planCommit(plan.Body)
+	if match := destructiveMarkerPattern.FindStringSubmatch(plan.Body); match != nil {
+		ack.Destructive, _ = strconv.Atoi(match[1])
+	}
+	if ack.Destructive == 0 {
+		return ack, nil
+	}
+
+	approvers := ApprovalConfig{Users: config.Users}
+	for _, comment := range comments {
+		if comment.ID > plan.ID && startsWithCommand(comment.Body, config.phrase()) && approvers.authorized(comment.User.Login) {
+			ack.AcknowledgedBy = comment.User.Login
+			break
+		}
+	}
+	return ack, nil
+}
+
+// refreshAcknowledgement evaluates a pull request's destructive change
+// sign-off and publishes it as a failing or successful commit status. A plan
+// made for another commit than the head fails the status until a new plan
+// is posted.
+func (s *server) refreshAcknowledgement(repository string, number int) (Acknowledgement, error) {
+	config := s.opts.Acknowledgement
+	ack, err := evaluateAcknowledgement(s.github, repository, number, config)
+	if err != nil || ack.PlanComment == 0 {
+		return ack, err
+	}
+
+	sha, err := s.github.pullRequestHead(repository, number)
+	if err != nil {
+		return ack, err
+	}
+	ack.Outdated = ack.Commit != sha
+	state, description := "success", "No destructive changes"
+	switch {
+	case ack.Outdated:
+		state = "failure"
+		description = outdatedPlanDescription(ack.Commit, sha)
+	case ack.AcknowledgedBy != "":
+		description = fmt.Sprintf("%d destructive change(s) acknowledged by %s", ack.Destructive, ack.AcknowledgedBy)
+	case ack.Destructive > 0:
+		state = "failure"
+		description = fmt.Sprintf("%d destructive change(s) need sign-off: comment %s", ack.Destructive, config.phrase())
+	}
+	return ack, s.github.setCommitStatus(repository, sha, state, destructiveStatusContext, truncateText(description, 140))
+}
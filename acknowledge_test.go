@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDestructiveAcknowledgement(t *testing.T) {
+	var comments []issueComment
+	var status map[string]string
+	head := "abc123"
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+		case r.URL.Path == "/repos/org/infra/issues/7/comments" && r.Method == http.MethodPost:
+			var comment map[string]string
+			json.NewDecoder(r.Body).Decode(&comment)
+			posted := issueComment{ID: int64(len(comments) + 1), Body: comment["body"]}
+			posted.User.Login = "tfplan-bot"
+			comments = append(comments, posted)
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7"}`)
+		case r.URL.Path == "/repos/org/infra/issues/7/comments":
+			if r.URL.Query().Get("page") != "1" {
+				io.WriteString(w, `[]`)
+				return
+			}
+			json.NewEncoder(w).Encode(comments)
+		case r.URL.Path == "/repos/org/infra/pulls/7":
+			io.WriteString(w, `{"head": {"sha": "`+head+`"}}`)
+		case r.URL.Path == "/repos/org/infra/statuses/"+head:
+			json.NewDecoder(r.Body).Decode(&status)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+
+	config := &AcknowledgementConfig{Users: []string{"alice"}}
+	s := &server{opts: ReportOptions{Acknowledgement: config}, github: newGitHubClient("secret")}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	publish := func(action string) {
+		plan := strings.Replace(serverTestPlan, `"create"`, action, 1)
+		request := `{"repository": "org/infra", "pull_request": 7, "plan": ` + plan + `}`
+		response, err := http.Post(ts.URL+"/publish", "application/json", strings.NewReader(request))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("Expected 200, got %d", response.StatusCode)
+		}
+	}
+	commentEvent := func(login, body string) {
+		comment := issueComment{ID: int64(len(comments) + 1), Body: body}
+		comment.User.Login = login
+		comments = append(comments, comment)
+		request, _ := http.NewRequest(http.MethodPost, ts.URL+"/webhook/github", strings.NewReader(
+			`{"action": "created", "issue": {"number": 7, "pull_request": {}}, "repository": {"full_name": "org/infra"}}`))
+		request.Header.Set("X-GitHub-Event", "issue_comment")
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+	}
+
+	publish(`"delete"`)
+	if !strings.Contains(comments[0].Body, destructiveMarker(1)) || !strings.Contains(comments[0].Body, headMarker("abc123")) {
+		t.Errorf("Expected the destructive marker in the plan comment, got:\n%s", comments[0].Body)
+	}
+	if status["state"] != "failure" || status["context"] != destructiveStatusContext {
+		t.Fatalf("Expected a failing status, got %v", status)
+	}
+
+	commentEvent("mallory", "/acknowledge-destroy")
+	if status["state"] != "failure" {
+		t.Errorf("Expected unauthorized acknowledgements to be ignored, got %v", status)
+	}
+	commentEvent("mallory", planCommentMarker+"\nno destructive changes here")
+	if status["state"] != "failure" {
+		t.Errorf("Expected a plan marker from another user not to lift the block, got %v", status)
+	}
+	commentEvent("Alice", "/acknowledge-destroy reviewed the bucket deletion")
+	if status["state"] != "success" || status["description"] != "1 destructive change(s) acknowledged by Alice" {
+		t.Errorf("Expected an acknowledged status, got %v", status)
+	}
+
+	// A new destructive plan needs a fresh acknowledgement
+	publish(`"delete", "create"`)
+	if status["state"] != "failure" {
+		t.Errorf("Expected a new plan to reset the acknowledgement, got %v", status)
+	}
+
+	publish(`"update"`)
+	if status["state"] != "success" || status["description"] != "No destructive changes" {
+		t.Errorf("Expected success without destructive changes, got %v", status)
+	}
+
+	publish(`"delete"`)
+	commentEvent("alice", "/acknowledge-destroy-later")
+	if status["state"] != "failure" {
+		t.Errorf("Expected the phrase to match whole words only, got %v", status)
+	}
+	commentEvent("alice", "/acknowledge-destroy")
+	if status["state"] != "success" {
+		t.Fatalf("Expected an acknowledged status, got %v", status)
+	}
+
+	// An acknowledgement of a plan for an earlier commit doesn't count
+	head = "def456"
+	commentEvent("bob", "pushed a fix")
+	if status["state"] != "failure" || !strings.Contains(status["description"], "Plan is for abc123") {
+		t.Errorf("Expected a failing status for an outdated plan, got %v", status)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WorkflowAnnotation is a GitHub Actions warning or error about a planned
+// change, placed on the resource's configuration block when it can be located
+type WorkflowAnnotation struct {
+	Level   string // "warning" or "error"
+	Title   string
+	Message string
+	File    string
+	Line    int
+}
+
+// workflowAnnotations lists annotations for destructive changes, -fail-on
+// matches and naming and tag policy violations. Gating violations are errors,
+// the rest warnings.
+func workflowAnnotations(plans []PlanInfo, failRules []FailRule, opts ReportOptions) []WorkflowAnnotation {
+	var annotations []WorkflowAnnotation
+	for _, planInfo := range plans {
+		plan := planInfo.Plan
+		locator := newSourceLocator(sourceRootDir(plan, opts), plan.Configuration)
+		prefix := ""
+		if planInfo.RelativePath != "" {
+			prefix = fmt.Sprintf("[%s] ", environmentName(planInfo.RelativePath, opts.EnvironmentNames))
+		}
+
+		add := func(level, title, address, message string) {
+			annotation := WorkflowAnnotation{Level: level, Title: title, Message: prefix + message}
+			if location, ok := locator.locate(address); ok && location.File != "" {
+				annotation.File = relativeSourcePath(location.File)
+				annotation.Line = location.Line
+			}
+			annotations = append(annotations, annotation)
+		}
+
+		for _, rc := range plan.ResourceChanges {
+			action := planAction(rc.Change.Actions)
+			matched := false
+			for _, rule := range failRules {
+				if rule.matches(rc) {
+					add("error", "Terraform plan gate", rc.Address,
+						fmt.Sprintf("%s %s matches -fail-on rule %s", action, rc.Address, rule))
+					matched = true
+					break
+				}
+			}
+			if matched {
+				continue
+			}
+			switch action {
+			case "delete":
+				add("warning", "Terraform delete", rc.Address, fmt.Sprintf("%s will be destroyed", rc.Address))
+			case "replace":
+				add("warning", "Terraform replace", rc.Address, fmt.Sprintf("%s will be destroyed and recreated", rc.Address))
+			}
+		}
+
+		if opts.Naming != nil {
+			level := "warning"
+			if opts.Naming.Fail {
+				level = "error"
+			}
+			for _, violation := range checkNaming(plan, opts.Naming) {
+				add(level, "Naming convention", violation.Address, strings.ReplaceAll(formatNamingViolation(violation), "`", ""))
+			}
+		}
+		if opts.TagPolicy != nil {
+			level := "warning"
+			if opts.TagPolicy.Fail {
+				level = "error"
+			}
+			for _, violation := range checkTagPolicy(plan, opts.TagPolicy) {
+				add(level, "Required tags", violation.Address, strings.ReplaceAll(formatTagViolation(violation), "`", ""))
+			}
+		}
+	}
+	return annotations
+}
+
+// String renders the annotation as a GitHub Actions workflow command
+func (a WorkflowAnnotation) String() string {
+	properties := []string{"title=" + escapeWorkflowProperty(a.Title)}
+	if a.File != "" {
+		properties = append([]string{
+			"file=" + escapeWorkflowProperty(a.File),
+			fmt.Sprintf("line=%d", a.Line),
+		}, properties...)
+	}
+	return fmt.Sprintf("::%s %s::%s", a.Level, strings.Join(properties, ","), escapeWorkflowData(a.Message))
+}
+
+// escapeWorkflowData escapes a workflow command message
+func escapeWorkflowData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeWorkflowProperty escapes a workflow command property value
+func escapeWorkflowProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWorkflowAnnotations(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.tf"), []byte("resource \"aws_db_instance\" \"main\" {\n}\n\nresource \"aws_s3_bucket\" \"logs\" {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &TerraformPlan{
+		Configuration: &Configuration{},
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"delete", "create"}}},
+			{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete"}}},
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+	plans := []PlanInfo{{Plan: plan, RelativePath: "prod"}}
+	rules := []FailRule{{Action: "delete", TypePattern: "aws_s3_*"}}
+
+	annotations := workflowAnnotations(plans, rules, ReportOptions{SourceRoot: root})
+	if len(annotations) != 2 {
+		t.Fatalf("Expected 2 annotations, got %+v", annotations)
+	}
+
+	replace := annotations[0]
+	if replace.Level != "warning" || replace.Message != "[prod] aws_db_instance.main will be destroyed and recreated" || replace.Line != 1 {
+		t.Errorf("Unexpected replace annotation: %+v", replace)
+	}
+	gate := annotations[1]
+	if gate.Level != "error" || gate.Line != 4 || filepath.Base(gate.File) != "main.tf" {
+		t.Errorf("Unexpected gate annotation: %+v", gate)
+	}
+
+	unlocated := WorkflowAnnotation{Level: "warning", Title: "Terraform delete", Message: "50% done\nnext"}
+	if got := unlocated.String(); got != "::warning title=Terraform delete::50%25 done%0Anext" {
+		t.Errorf("Unexpected workflow command: %s", got)
+	}
+	located := WorkflowAnnotation{Level: "error", Title: "Gate: plan", Message: "m", File: "stacks/main.tf", Line: 4}
+	if got := located.String(); got != "::error file=stacks/main.tf,line=4,title=Gate%3A plan::m" {
+		t.Errorf("Unexpected workflow command: %s", got)
+	}
+}
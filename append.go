@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// appendReport adds a report to the end of outputFile under a header naming
+// the plan input, separated from any earlier report, and returns the file's
+// new content
+func appendReport(outputFile, input, markdown string) ([]byte, error) {
+	existing, err := os.ReadFile(outputFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read output file: %w", err)
+	}
+
+	section := fmt.Sprintf("# 📎 `%s`\n\n%s", input, markdown)
+	if len(existing) > 0 {
+		section = "\n---\n\n" + section
+	}
+
+	file, err := os.OpenFile(outputFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(section); err != nil {
+		return nil, fmt.Errorf("failed to append to output file: %w", err)
+	}
+	return append(existing, section...), nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAppendReport(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "comment.md")
+
+	if _, err := appendReport(outputFile, "plan/tfplan.json", "## first\n"); err != nil {
+		t.Fatal(err)
+	}
+	content, err := appendReport(outputFile, "apply/tfplan.json", "## second\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "# 📎 `plan/tfplan.json`\n\n## first\n" +
+		"\n---\n\n# 📎 `apply/tfplan.json`\n\n## second\n"
+	if string(content) != expected {
+		t.Errorf("Expected accumulated reports, got:\n%s", content)
+	}
+	written, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(written) != expected {
+		t.Errorf("Expected the returned content to match the file, got:\n%s", written)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// planCommentMarker identifies plan comments posted by the server, whose
+// reactions and follow-up comments count as approvals
+const planCommentMarker = commentMarkerPrefix + "plan -->"
+
+// approvalStatusContext is the commit status carrying the approval state
+const approvalStatusContext = "tfplan-commenter/approval"
+
+// ApprovalConfig configures the lightweight apply gate of server mode: users
+// approve the posted plan by reacting to it or commenting the command
+type ApprovalConfig struct {
+	// Users are the GitHub logins allowed to approve
+	Users []string `json:"users"`
+	// Reactions that approve, as GitHub reaction names (default ["+1"])
+	Reactions []string `json:"reactions"`
+	// Command is the comment that approves (default "/approve-plan")
+	Command string `json:"command"`
+	// Required is the number of distinct approvers needed (default 1)
+	Required int `json:"required"`
+}
+
+func (a *ApprovalConfig) validate() error {
+	if len(a.Users) == 0 {
+		return fmt.Errorf("approval.users must list at least one user")
+	}
+	// An omitted required is 0 and means the default of 1
+	if a.Required < 0 || a.Required > len(a.Users) {
+		return fmt.Errorf("approval.required must be between 1 and the number of users (%d), or omitted for 1", len(a.Users))
+	}
+	return nil
+}
+
+func (a *ApprovalConfig) reactions() []string {
+	if len(a.Reactions) == 0 {
+		return []string{"+1"}
+	}
+	return a.Reactions
+}
+
+func (a *ApprovalConfig) command() string {
+	if a.Command == "" {
+		return "/approve-plan"
+	}
+	return a.Command
+}
+
+func (a *ApprovalConfig) required() int {
+	if a.Required == 0 {
+		return 1
+	}
+	return a.Required
+}
+
+// authorized reports whether login may approve
+func (a *ApprovalConfig) authorized(login string) bool {
+	for _, user := range a.Users {
+		if strings.EqualFold(user, login) {
+			return true
+		}
+	}
+	return false
+}
+
+// Approval is the approval state of a pull request's latest plan comment
+type Approval struct {
+	PlanComment int64
+	Commit      string // Commit the plan was made for
+	Approvers   []string
+	Approved    bool
+	Outdated    bool // The plan isn't for the pull request's head commit
+}
+
+// evaluateApproval finds the latest plan comment and collects the authorized
+// users who approved it by reaction or by command comment after it. The
+// returned approval has no PlanComment when no plan was posted.
+func evaluateApproval(client *githubClient, repository string, number int, config *ApprovalConfig) (Approval, error) {
+	var approval Approval
+	login, err := client.authenticatedLogin()
+	if err != nil {
+		return approval, err
+	}
+	comments, err := client.listComments(repository, number)
+	if err != nil {
+		return approval, err
+	}
+	plan := latestPlanComment(comments, login)
+	if plan == nil {
+		return approval, nil
+	}
+	approval.PlanComment = plan.ID
+	approval.Commit = planCommit(plan.Body)
+
+	seen := make(map[string]bool)
+	approve := func(login string) {
+		if config.authorized(login) && !seen[strings.ToLower(login)] {
+			seen[strings.ToLower(login)] = true
+			approval.Approvers = append(approval.Approvers, login)
+		}
+	}
+
+	reactions, err := client.commentReactions(repository, approval.PlanComment)
+	if err != nil {
+		return approval, err
+	}
+	for _, reaction := range reactions {
+		for _, content := range config.reactions() {
+			if reaction.Content == content {
+				approve(reaction.User.Login)
+			}
+		}
+	}
+	for _, comment := range comments {
+		if comment.ID > approval.PlanComment && startsWithCommand(comment.Body, config.command()) {
+			approve(comment.User.Login)
+		}
+	}
+
+	approval.Approved = len(approval.Approvers) >= config.required()
+	return approval, nil
+}
+
+// startsWithCommand reports whether a comment starts with command as whole
+// words, so "/approve-plan" doesn't match "/approve-plan-later". Case is
+// ignored.
+func startsWithCommand(body, command string) bool {
+	words, commandWords := strings.Fields(body), strings.Fields(command)
+	if len(commandWords) == 0 || len(words) < len(commandWords) {
+		return false
+	}
+	for i, word := range commandWords {
+		if !strings.EqualFold(words[i], word) {
+			return false
+		}
+	}
+	return true
+}
+
+// latestPlanComment returns the most recent plan comment posted by the
+// server's login, or nil when there is none
+func latestPlanComment(comments []issueComment, login string) *issueComment {
+	var latest *issueComment
+	for i := range comments {
+		if postedBy(comments[i], login) && strings.HasPrefix(comments[i].Body, planCommentMarker) {
+			latest = &comments[i]
+		}
+	}
+	return latest
+}
+
+// refreshApproval evaluates a pull request's approval and publishes it as a
+// commit status on the head commit. Approvals of a plan made for another
+// commit leave the status pending until a new plan is posted.
+func (s *server) refreshApproval(repository string, number int) (Approval, error) {
+	config := s.opts.Approval
+	approval, err := evaluateApproval(s.github, repository, number, config)
+	if err != nil || approval.PlanComment == 0 {
+		return approval, err
+	}
+
+	sha, err := s.github.pullRequestHead(repository, number)
+	if err != nil {
+		return approval, err
+	}
+	state := "pending"
+	description := fmt.Sprintf("%d of %d approval(s); react %s or comment %s",
+		len(approval.Approvers), config.required(), strings.Join(config.reactions(), " "), config.command())
+	if approval.Outdated = approval.Commit != sha; approval.Outdated {
+		approval.Approved = false
+		description = outdatedPlanDescription(approval.Commit, sha)
+	} else if approval.Approved {
+		state = "success"
+		description = "Plan approved by " + strings.Join(approval.Approvers, ", ")
+	}
+	return approval, s.github.setCommitStatus(repository, sha, state, approvalStatusContext, truncateText(description, 140))
+}
+
+// ApprovalRequest is the body of POST /approval
+type ApprovalRequest struct {
+	Repository  string `json:"repository"`
+	PullRequest int    `json:"pull_request"`
+}
+
+// handleApproval re-evaluates a pull request's approval on demand. GitHub
+// sends no webhooks for reactions, so this is called on a schedule or from CI.
+func (s *server) handleApproval(w http.ResponseWriter, r *http.Request) {
+	if s.github == nil || s.opts.Approval == nil {
+		http.Error(w, "approvals are not configured (set GITHUB_TOKEN and an approval section in -config)", http.StatusServiceUnavailable)
+		return
+	}
+
+	var request ApprovalRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid approval request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if request.Repository == "" || request.PullRequest <= 0 {
+		http.Error(w, "repository and pull_request are required", http.StatusBadRequest)
+		return
+	}
+
+	approval, err := s.refreshApproval(request.Repository, request.PullRequest)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"plan_comment": approval.PlanComment,
+		"approvers":    approval.Approvers,
+		"approved":     approval.Approved,
+		"outdated":     approval.Outdated,
+	})
+}
+
+// GitHubIssueCommentEvent is the subset of the issue_comment webhook payload used
+type GitHubIssueCommentEvent struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number      int       `json:"number"`
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// handleGitHubIssueComment re-evaluates approval and destructive change
+// sign-off when a pull request comment is added, edited or removed
+func (s *server) handleGitHubIssueComment(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Approval == nil && s.opts.Acknowledgement == nil {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "approvals are not configured"})
+		return
+	}
+
+	var event GitHubIssueCommentEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&event); err != nil {
+		http.Error(w, fmt.Sprintf("invalid issue_comment payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if event.Issue.PullRequest == nil {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "comment is not on a pull request"})
+		return
+	}
+
+	result := map[string]string{"status": "evaluated"}
+	var planComment int64
+	if s.opts.Approval != nil {
+		approval, err := s.refreshApproval(event.Repository.FullName, event.Issue.Number)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		planComment = approval.PlanComment
+		result["approved"] = fmt.Sprintf("%t", approval.Approved)
+	}
+	if s.opts.Acknowledgement != nil {
+		ack, err := s.refreshAcknowledgement(event.Repository.FullName, event.Issue.Number)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		planComment = ack.PlanComment
+		result["destruction_acknowledged"] = fmt.Sprintf("%t", !ack.Outdated && (ack.Destructive == 0 || ack.AcknowledgedBy != ""))
+	}
+	if planComment == 0 {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "no plan comment on the pull request"})
+		return
+	}
+	writeWebhookResult(w, http.StatusOK, result)
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApprovalConfigValidate(t *testing.T) {
+	if err := (&ApprovalConfig{}).validate(); err == nil {
+		t.Error("Expected an error without users")
+	}
+	if err := (&ApprovalConfig{Users: []string{"alice"}, Required: 2}).validate(); err == nil {
+		t.Error("Expected an error when more approvals are required than users exist")
+	}
+	if err := (&ApprovalConfig{Users: []string{"alice"}, Required: -1}).validate(); err == nil {
+		t.Error("Expected an error for a negative number of approvals")
+	}
+	if err := (&ApprovalConfig{Users: []string{"alice"}}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestStartsWithCommand(t *testing.T) {
+	for body, expected := range map[string]bool{
+		"/approve-plan":              true,
+		"  /Approve-Plan looks good": true,
+		"/approve-plan\nthanks":      true,
+		"/approve-plan-later":        false,
+		"please /approve-plan":       false,
+		"":                           false,
+	} {
+		if got := startsWithCommand(body, "/approve-plan"); got != expected {
+			t.Errorf("startsWithCommand(%q) = %v, expected %v", body, got, expected)
+		}
+	}
+	if !startsWithCommand("I acknowledge the deletes", "i acknowledge") || startsWithCommand("I acknowledged", "I acknowledge") {
+		t.Error("Expected multi-word commands to match whole words")
+	}
+}
+
+func TestApprovalStatus(t *testing.T) {
+	var status map[string]string
+	planned := "abc123"
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+		case "/repos/org/infra/issues/7/comments":
+			if r.URL.Query().Get("page") != "1" {
+				io.WriteString(w, `[]`)
+				return
+			}
+			io.WriteString(w, `[
+				{"id": 1, "body": "unrelated", "user": {"login": "bob"}},
+				{"id": 2, "body": "`+planCommentMarker+`\n`+headMarker(planned)+`\nplan", "user": {"login": "tfplan-bot"}},
+				{"id": 3, "body": "/approve-plan", "user": {"login": "Carol"}},
+				{"id": 4, "body": "/approve-plan", "user": {"login": "mallory"}},
+				{"id": 6, "body": "/approve-plan-later", "user": {"login": "dave"}},
+				{"id": 5, "body": "`+planCommentMarker+`\nfake plan", "user": {"login": "mallory"}}
+			]`)
+		case "/repos/org/infra/issues/comments/2/reactions":
+			io.WriteString(w, `[
+				{"content": "+1", "user": {"login": "alice"}},
+				{"content": "heart", "user": {"login": "dave"}},
+				{"content": "+1", "user": {"login": "mallory"}}
+			]`)
+		case "/repos/org/infra/pulls/7":
+			io.WriteString(w, `{"head": {"sha": "abc123"}}`)
+		case "/repos/org/infra/statuses/abc123":
+			json.NewDecoder(r.Body).Decode(&status)
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+
+	config := &ApprovalConfig{Users: []string{"alice", "carol", "dave"}, Required: 2}
+	s := &server{opts: ReportOptions{Approval: config}, github: newGitHubClient("secret")}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	response, err := http.Post(ts.URL+"/approval", "application/json", strings.NewReader(`{"repository": "org/infra", "pull_request": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		PlanComment int64    `json:"plan_comment"`
+		Approvers   []string `json:"approvers"`
+		Approved    bool     `json:"approved"`
+	}
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.StatusCode)
+	}
+	// The marker comment from mallory must not take over as the plan comment
+	if result.PlanComment != 2 || !result.Approved || strings.Join(result.Approvers, ",") != "alice,Carol" {
+		t.Errorf("Unexpected approval %+v", result)
+	}
+	if status["state"] != "success" || status["context"] != approvalStatusContext || !strings.Contains(status["description"], "alice, Carol") {
+		t.Errorf("Unexpected commit status %v", status)
+	}
+
+	config.Required = 3
+	request, _ := http.NewRequest(http.MethodPost, ts.URL+"/webhook/github", strings.NewReader(
+		`{"action": "created", "issue": {"number": 7, "pull_request": {}}, "repository": {"full_name": "org/infra"}}`))
+	request.Header.Set("X-GitHub-Event", "issue_comment")
+	response, err = http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for an issue_comment event, got %d", response.StatusCode)
+	}
+	if status["state"] != "pending" || !strings.Contains(status["description"], "2 of 3") {
+		t.Errorf("Expected a pending status, got %v", status)
+	}
+
+	// Approvals of a plan for another commit don't count
+	config.Required = 2
+	planned = "0123abc"
+	response, err = http.Post(ts.URL+"/approval", "application/json", strings.NewReader(`{"repository": "org/infra", "pull_request": 7}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if result.Approved || status["state"] != "pending" || !strings.Contains(status["description"], "not the head commit") {
+		t.Errorf("Expected an outdated plan to stay pending, got %+v and %v", result, status)
+	}
+}
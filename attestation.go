@@ -0,0 +1,327 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// In-toto and DSSE identifiers used by attestations
+const (
+	inTotoStatementType  = "https://in-toto.io/Statement/v1"
+	inTotoPayloadType    = "application/vnd.in-toto+json"
+	reportPredicateType  = "https://github.com/akomic/go-tfplan-commenter/report/v1"
+	attestationExtension = ".intoto.jsonl"
+)
+
+// commitEnvVars name the commit being built in common CI systems, in order of preference
+var commitEnvVars = []string{"GITHUB_SHA", "CI_COMMIT_SHA", "BITBUCKET_COMMIT", "BUILDKITE_COMMIT", "GIT_COMMIT", "DRONE_COMMIT_SHA"}
+
+// ciCommit returns the commit the run belongs to, when running in CI
+func ciCommit() string {
+	for _, name := range commitEnvVars {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// InTotoStatement binds the rendered report (the subject) to its inputs
+type InTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []InTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     ReportPredicate `json:"predicate"`
+}
+
+// InTotoSubject is an artifact identified by its digests
+type InTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ReportPredicate records what a report was generated from
+type ReportPredicate struct {
+	Commit     string          `json:"commit,omitempty"`
+	Repository string          `json:"repository,omitempty"`
+	Plans      []InTotoSubject `json:"plans"`
+	Generator  string          `json:"generator"`
+}
+
+// DSSEEnvelope is a Dead Simple Signing Envelope, the format cosign and
+// in-toto use for signed attestations
+type DSSEEnvelope struct {
+	PayloadType string          `json:"payloadType"`
+	Payload     string          `json:"payload"` // Base64-encoded statement
+	Signatures  []DSSESignature `json:"signatures"`
+}
+
+// DSSESignature is a signature over the envelope's pre-authentication encoding
+type DSSESignature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"` // Base64-encoded
+}
+
+// reportStatement builds the attestation statement for a rendered report
+func reportStatement(reportName string, report []byte, plans []PlanInfo, commit, repository string) InTotoStatement {
+	reportDigest := sha256.Sum256(report)
+	predicate := ReportPredicate{
+		Commit:     commit,
+		Repository: repository,
+		Plans:      []InTotoSubject{},
+		Generator:  "tfplan-commenter " + Version,
+	}
+	for _, planInfo := range plans {
+		name := planInfo.Plan.PlanPath
+		if planInfo.RelativePath != "" {
+			name = planInfo.RelativePath
+		}
+		predicate.Plans = append(predicate.Plans, InTotoSubject{
+			Name:   name,
+			Digest: map[string]string{"sha256": planInfo.Plan.Digest},
+		})
+	}
+	return InTotoStatement{
+		Type:          inTotoStatementType,
+		Subject:       []InTotoSubject{{Name: filepath.Base(reportName), Digest: map[string]string{"sha256": hex.EncodeToString(reportDigest[:])}}},
+		PredicateType: reportPredicateType,
+		Predicate:     predicate,
+	}
+}
+
+// preAuthEncoding is the DSSE PAE of a payload, which is what gets signed
+func preAuthEncoding(payloadType string, payload []byte) []byte {
+	encoded := "DSSEv1 " + strconv.Itoa(len(payloadType)) + " " + payloadType + " " + strconv.Itoa(len(payload)) + " "
+	return append([]byte(encoded), payload...)
+}
+
+// loadSigningKey reads an unencrypted PEM private key (PKCS#8, SEC 1 or PKCS#1)
+func loadSigningKey(path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+
+	var key interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q (encrypted keys must be decrypted first)", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid signing key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing key %T", key)
+	}
+	return signer, nil
+}
+
+// signStatement wraps a statement in a signed DSSE envelope
+func signStatement(statement InTotoStatement, signer crypto.Signer) (*DSSEEnvelope, error) {
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+
+	message := preAuthEncoding(inTotoPayloadType, payload)
+	var sig []byte
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign statement: %w", err)
+	}
+
+	return &DSSEEnvelope{
+		PayloadType: inTotoPayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []DSSESignature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+	}, nil
+}
+
+// writeAttestation signs a report's statement and writes the envelope next to the report
+func writeAttestation(reportFile string, report []byte, plans []PlanInfo, keyFile, commit string) (string, error) {
+	signer, err := loadSigningKey(keyFile)
+	if err != nil {
+		return "", err
+	}
+	envelope, err := signStatement(reportStatement(reportFile, report, plans, commit, ciRepository()), signer)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode attestation: %w", err)
+	}
+
+	attestationFile := reportFile + attestationExtension
+	if err := os.WriteFile(attestationFile, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return attestationFile, nil
+}
+
+// loadVerificationKey reads a PEM public key (PKIX)
+func loadVerificationKey(path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, fmt.Errorf("no PEM public key found in %s", path)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return key, nil
+}
+
+// verifyMessage checks a signature made by signStatement's scheme: Ed25519
+// over the message itself, ECDSA or RSA PKCS#1 v1.5 over its SHA-256 digest
+func verifyMessage(key crypto.PublicKey, message, sig []byte) (bool, error) {
+	digest := sha256.Sum256(message)
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, message, sig), nil
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest[:], sig), nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil, nil
+	default:
+		return false, fmt.Errorf("unsupported public key %T", key)
+	}
+}
+
+// verifyEnvelope checks an envelope's signature and returns its statement
+func verifyEnvelope(envelope DSSEEnvelope, key crypto.PublicKey) (*InTotoStatement, error) {
+	if envelope.PayloadType != inTotoPayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload encoding: %w", err)
+	}
+
+	message := preAuthEncoding(envelope.PayloadType, payload)
+	verified := false
+	for _, signature := range envelope.Signatures {
+		sig, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil {
+			continue
+		}
+		if verified, err = verifyMessage(key, message, sig); err != nil {
+			return nil, err
+		}
+		if verified {
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("no valid signature for the public key")
+	}
+
+	var statement InTotoStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("invalid statement: %w", err)
+	}
+	if statement.Type != inTotoStatementType || statement.PredicateType != reportPredicateType {
+		return nil, fmt.Errorf("unexpected statement type %q / %q", statement.Type, statement.PredicateType)
+	}
+	return &statement, nil
+}
+
+// verifyReport checks that a report matches the subject of a verified statement
+func verifyReport(statement *InTotoStatement, report []byte) error {
+	digest := sha256.Sum256(report)
+	for _, subject := range statement.Subject {
+		if subject.Digest["sha256"] == hex.EncodeToString(digest[:]) {
+			return nil
+		}
+	}
+	return errors.New("report does not match the attested digest (edited after generation?)")
+}
+
+// runVerifyCommand implements `tfplan-commenter verify -key <public-key> <report>`
+func runVerifyCommand(args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	keyFile := flags.String("key", "", "PEM public key the attestation was signed with")
+	attestationFile := flags.String("attestation", "", "Attestation file (default: <report>"+attestationExtension+")")
+	flags.Parse(args)
+	if flags.NArg() != 1 || *keyFile == "" {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter verify -key <public-key> [-attestation file] <report>")
+		os.Exit(1)
+	}
+	reportFile := flags.Arg(0)
+	if *attestationFile == "" {
+		*attestationFile = reportFile + attestationExtension
+	}
+
+	key, err := loadVerificationKey(*keyFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data, err := os.ReadFile(*attestationFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading attestation: %v\n", err)
+		os.Exit(1)
+	}
+	var envelope DSSEEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		fmt.Fprintf(os.Stderr, "Error parsing attestation: %v\n", err)
+		os.Exit(1)
+	}
+	report, err := os.ReadFile(reportFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading report: %v\n", err)
+		os.Exit(1)
+	}
+
+	statement, err := verifyEnvelope(envelope, key)
+	if err == nil {
+		err = verifyReport(statement, report)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ %s: %v\n", reportFile, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ %s matches its attestation\n", reportFile)
+	if statement.Predicate.Commit != "" {
+		fmt.Printf("  commit: %s\n", statement.Predicate.Commit)
+	}
+	for _, plan := range statement.Predicate.Plans {
+		fmt.Printf("  plan %s: sha256:%s\n", plan.Name, plan.Digest["sha256"])
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestKeys(t *testing.T, key crypto.Signer) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	private, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateFile, publicFile := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+	os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: private}), 0600)
+	os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public}), 0644)
+	return privateFile, publicFile
+}
+
+func TestPreAuthEncoding(t *testing.T) {
+	// Test vector from the DSSE specification
+	if got := string(preAuthEncoding("http://example.com/HelloWorld", []byte("hello world"))); got != "DSSEv1 29 http://example.com/HelloWorld 11 hello world" {
+		t.Errorf("Unexpected PAE %q", got)
+	}
+}
+
+func TestAttestation(t *testing.T) {
+	plan, err := parseTerraformPlan([]byte(serverTestPlan), "tfplan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	plans := []PlanInfo{{Plan: plan, RelativePath: "env/dev"}}
+
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	for name, key := range map[string]crypto.Signer{"ed25519": edKey, "ecdsa": ecKey} {
+		t.Run(name, func(t *testing.T) {
+			privateFile, publicFile := writeTestKeys(t, key)
+			reportFile := filepath.Join(t.TempDir(), "comment.md")
+			report := []byte("## Plan\n")
+
+			attestationFile, err := writeAttestation(reportFile, report, plans, privateFile, "abc123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(attestationFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var envelope DSSEEnvelope
+			if err := json.Unmarshal(data, &envelope); err != nil {
+				t.Fatal(err)
+			}
+
+			publicKey, err := loadVerificationKey(publicFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			statement, err := verifyEnvelope(envelope, publicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if statement.Predicate.Commit != "abc123" || len(statement.Predicate.Plans) != 1 ||
+				statement.Predicate.Plans[0].Name != "env/dev" || statement.Predicate.Plans[0].Digest["sha256"] != plan.Digest {
+				t.Errorf("Unexpected predicate %+v", statement.Predicate)
+			}
+			if err := verifyReport(statement, report); err != nil {
+				t.Error(err)
+			}
+			if err := verifyReport(statement, []byte("## Edited\n")); err == nil {
+				t.Error("Expected an edited report to fail verification")
+			}
+
+			otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			if _, err := verifyEnvelope(envelope, otherKey.Public()); err == nil {
+				t.Error("Expected verification with another key to fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// actorEnvVars name the user who triggered the run in common CI systems, in
+// order of preference, falling back to the local user
+var actorEnvVars = []string{"GITHUB_ACTOR", "GITLAB_USER_LOGIN", "BITBUCKET_STEP_TRIGGERER_UUID", "BUILDKITE_BUILD_CREATOR", "BUILD_USER_ID", "DRONE_COMMIT_AUTHOR", "USER"}
+
+// runActor returns who triggered the run
+func runActor() string {
+	for _, name := range actorEnvVars {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// AuditRecord is one run's evidence: what went in, what came out, and who ran it
+type AuditRecord struct {
+	Time         time.Time       `json:"time"`
+	Actor        string          `json:"actor,omitempty"`
+	Host         string          `json:"host,omitempty"`
+	Repository   string          `json:"repository,omitempty"`
+	Commit       string          `json:"commit,omitempty"`
+	Version      string          `json:"version"`
+	Inputs       []InTotoSubject `json:"inputs"`
+	Changes      map[string]int  `json:"changes"` // Resource counts by action across all plans
+	ReportSHA256 string          `json:"report_sha256"`
+	Outputs      []string        `json:"outputs"` // Files and endpoints the report and its by-products went to
+}
+
+// auditRecord builds the audit record of a run
+func auditRecord(plans []PlanInfo, report []byte, outputs []string, commit string, now time.Time) AuditRecord {
+	reportDigest := sha256.Sum256(report)
+	host, _ := os.Hostname()
+	record := AuditRecord{
+		Time:         now.UTC(),
+		Actor:        runActor(),
+		Host:         host,
+		Repository:   ciRepository(),
+		Commit:       commit,
+		Version:      Version,
+		Inputs:       []InTotoSubject{},
+		Changes:      map[string]int{"create": 0, "update": 0, "replace": 0, "delete": 0},
+		ReportSHA256: hex.EncodeToString(reportDigest[:]),
+		Outputs:      outputs,
+	}
+	for _, planInfo := range plans {
+		name := planInfo.Plan.PlanPath
+		if planInfo.RelativePath != "" {
+			name = planInfo.RelativePath
+		}
+		record.Inputs = append(record.Inputs, InTotoSubject{Name: name, Digest: map[string]string{"sha256": planInfo.Plan.Digest}})
+
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		record.Changes["create"] += len(summary.Create)
+		record.Changes["update"] += len(summary.Update)
+		record.Changes["replace"] += len(summary.Replace)
+		record.Changes["delete"] += len(summary.Delete)
+	}
+	return record
+}
+
+// writeAuditRecord appends a record to a JSON Lines file, or POSTs it when the
+// destination is an HTTP(S) URL. Posted records are signed like webhooks when
+// the shared secret is set.
+func writeAuditRecord(destination string, record AuditRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+
+	if !strings.HasPrefix(destination, "http://") && !strings.HasPrefix(destination, "https://") {
+		file, err := os.OpenFile(destination, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open audit log: %w", err)
+		}
+		defer file.Close()
+		if _, err := file.Write(append(data, '\n')); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		return nil
+	}
+
+	request, err := http.NewRequest(http.MethodPost, destination, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid audit endpoint: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if secret := os.Getenv(webhookSecretEnv); secret != "" {
+		request.Header.Set(signatureHeader, signPayload(secret, data))
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to send audit record: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("audit endpoint returned %s", response.Status)
+	}
+	return nil
+}
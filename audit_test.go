@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAuditRecord(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "octocat")
+	plan, err := parseTerraformPlan([]byte(serverTestPlan), "tfplan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	record := auditRecord([]PlanInfo{{Plan: plan, RelativePath: "env/dev"}}, []byte("report"), []string{"comment.md"}, "abc123", now)
+
+	if record.Actor != "octocat" || record.Commit != "abc123" || !record.Time.Equal(now) {
+		t.Errorf("Unexpected record %+v", record)
+	}
+	if record.Changes["create"] != 1 || record.Changes["delete"] != 0 {
+		t.Errorf("Unexpected change counts %v", record.Changes)
+	}
+	if len(record.Inputs) != 1 || record.Inputs[0].Name != "env/dev" || record.Inputs[0].Digest["sha256"] != plan.Digest {
+		t.Errorf("Unexpected inputs %+v", record.Inputs)
+	}
+	if digest := sha256.Sum256([]byte("report")); record.ReportSHA256 != hex.EncodeToString(digest[:]) {
+		t.Errorf("Unexpected report digest %q", record.ReportSHA256)
+	}
+
+	// Records are appended, one per line
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	for i := 0; i < 2; i++ {
+		if err := writeAuditRecord(file, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		var decoded AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &decoded); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("Expected 2 audit lines, got %d", lines)
+	}
+}
+
+func TestWriteAuditRecordEndpoint(t *testing.T) {
+	t.Setenv(webhookSecretEnv, "secret")
+	var verified bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verified = verifySignature("secret", body, r.Header.Get(signatureHeader))
+	}))
+	defer ts.Close()
+
+	if err := writeAuditRecord(ts.URL, AuditRecord{Version: "dev"}); err != nil {
+		t.Fatal(err)
+	}
+	if !verified {
+		t.Error("Expected the posted record to be signed")
+	}
+}
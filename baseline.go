@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// defaultBaselineFile is written by the baseline subcommand when no output is given
+const defaultBaselineFile = "tfplan-baseline.json"
+
+// Baseline lists expected changes that are left out of the report and of
+// gating, such as an attribute a provider bug perpetually shows a diff on
+type Baseline struct {
+	Entries []BaselineEntry `json:"entries"`
+}
+
+// BaselineEntry is one expected change. Without an attribute the whole
+// resource change is expected; with one, an update is expected only while
+// every changed attribute is listed in the baseline.
+type BaselineEntry struct {
+	Environment string `json:"environment,omitempty"` // Relative plan path in multi-plan mode
+	Address     string `json:"address"`
+	Action      string `json:"action,omitempty"` // Expected action; any when empty
+	Attribute   string `json:"attribute,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+func readBaseline(filename string) (*Baseline, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline: %w", err)
+	}
+	for i, entry := range baseline.Entries {
+		if entry.Address == "" {
+			return nil, fmt.Errorf("baseline entry %d has no address", i+1)
+		}
+	}
+	return &baseline, nil
+}
+
+// generateBaseline records every change in the plans as expected
+func generateBaseline(plans []PlanInfo) Baseline {
+	baseline := Baseline{Entries: []BaselineEntry{}}
+	for _, planInfo := range plans {
+		for _, rc := range planInfo.Plan.ResourceChanges {
+			action := planAction(rc.Change.Actions)
+			if action == "no-op" || action == "read" {
+				continue
+			}
+
+			entry := BaselineEntry{Environment: planInfo.RelativePath, Address: rc.Address, Action: action}
+			var changes []AttributeChange
+			if action == "update" {
+				changes = analyzeAttributeChanges(rc.Change)
+			}
+			if len(changes) == 0 {
+				baseline.Entries = append(baseline.Entries, entry)
+				continue
+			}
+			for _, change := range changes {
+				entry.Attribute = change.Attribute
+				baseline.Entries = append(baseline.Entries, entry)
+			}
+		}
+	}
+	return baseline
+}
+
+// entriesFor returns the baseline entries for a resource in an environment
+func (b *Baseline) entriesFor(env, address string) []BaselineEntry {
+	if b == nil {
+		return nil
+	}
+
+	var entries []BaselineEntry
+	for _, entry := range b.Entries {
+		if entry.Address == address && (entry.Environment == "" || entry.Environment == env) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// expects reports whether an attribute change of a resource is in the
+// baseline. An attribute entry also covers everything nested below it.
+func (b *Baseline) expects(env, address, attribute string) bool {
+	for _, entry := range b.entriesFor(env, address) {
+		if entry.Attribute == "" || (entry.Action != "" && entry.Action != "update") {
+			continue
+		}
+		if attribute == entry.Attribute ||
+			strings.HasPrefix(attribute, entry.Attribute+".") ||
+			strings.HasPrefix(attribute, entry.Attribute+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// applyBaseline turns expected resource changes into no-ops, so they are left
+// out of the report and of gating decisions alike
+func applyBaseline(plan *TerraformPlan, env string, baseline *Baseline) {
+	if baseline == nil {
+		return
+	}
+
+	for i := range plan.ResourceChanges {
+		rc := &plan.ResourceChanges[i]
+		action := planAction(rc.Change.Actions)
+		if action == "no-op" || action == "read" {
+			continue
+		}
+
+		expected := false
+		for _, entry := range baseline.entriesFor(env, rc.Address) {
+			if entry.Attribute == "" && (entry.Action == "" || entry.Action == action) {
+				expected = true
+				break
+			}
+		}
+		if !expected && action == "update" {
+			changes := analyzeAttributeChanges(rc.Change)
+			expected = len(changes) > 0
+			for _, change := range changes {
+				if !baseline.expects(env, rc.Address, change.Attribute) {
+					expected = false
+					break
+				}
+			}
+		}
+		if expected {
+			rc.Change.Actions = []string{"no-op"}
+		}
+	}
+}
+
+// applyBaselineAttributes hides expected attribute changes of resources that
+// also have unexpected changes
+func applyBaselineAttributes(summary *ResourceSummary, env string, baseline *Baseline) {
+	if baseline == nil {
+		return
+	}
+
+	for i := range summary.Update {
+		var kept []AttributeChange
+		for _, change := range summary.Update[i].Changes {
+			if !baseline.expects(env, summary.Update[i].Address, change.Attribute) {
+				kept = append(kept, change)
+			}
+		}
+		summary.Update[i].Changes = kept
+	}
+}
+
+// runBaselineCommand implements `tfplan-commenter baseline <input> [baseline.json]`
+func runBaselineCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter baseline <input> [baseline.json]")
+		os.Exit(1)
+	}
+
+	inputPath := args[0]
+	outputFile := defaultBaselineFile
+	if len(args) > 1 {
+		outputFile = args[1]
+	}
+
+	plans, err := readPlans(inputPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	data, err := json.MarshalIndent(generateBaseline(plans), "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding baseline: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputFile, append(data, '\n'), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing baseline file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Baseline generated: %s\n", outputFile)
+}
+
+// readPlans reads a single plan file, or every tfplan.json under a directory
+func readPlans(inputPath string) ([]PlanInfo, error) {
+	fileInfo, err := os.Stat(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access input path: %w", err)
+	}
+	if fileInfo.IsDir() {
+		return findAndReadPlanFiles(inputPath)
+	}
+
+	plan, err := readTerraformPlan(inputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan file: %w", err)
+	}
+	return []PlanInfo{{Plan: plan}}, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func baselineTestPlan() *TerraformPlan {
+	return &TerraformPlan{ResourceChanges: []ResourceChange{
+		{
+			Address: "aws_instance.web",
+			Type:    "aws_instance",
+			Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"metadata_options": []interface{}{map[string]interface{}{"http_tokens": "optional"}}},
+				After:   map[string]interface{}{"metadata_options": []interface{}{map[string]interface{}{"http_tokens": "required"}}},
+			},
+		},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete", "create"}}},
+	}}
+}
+
+func TestGenerateBaseline(t *testing.T) {
+	baseline := generateBaseline([]PlanInfo{{Plan: baselineTestPlan(), RelativePath: "prod"}})
+	expected := []BaselineEntry{
+		{Environment: "prod", Address: "aws_instance.web", Action: "update", Attribute: "metadata_options[0].http_tokens"},
+		{Environment: "prod", Address: "aws_s3_bucket.logs", Action: "replace"},
+	}
+	if len(baseline.Entries) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, baseline.Entries)
+	}
+	for i := range expected {
+		if baseline.Entries[i] != expected[i] {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, expected[i], baseline.Entries[i])
+		}
+	}
+}
+
+func TestApplyBaseline(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	data, err := json.Marshal(Baseline{Entries: []BaselineEntry{
+		{Address: "aws_instance.web", Action: "update", Attribute: "metadata_options", Reason: "provider bug"},
+		{Address: "aws_s3_bucket.logs", Action: "delete"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := readBaseline(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := baselineTestPlan()
+	applyBaseline(plan, "", baseline)
+	if action := planAction(plan.ResourceChanges[0].Change.Actions); action != "no-op" {
+		t.Errorf("Expected baselined update to become a no-op, got %s", action)
+	}
+	// The baseline expects a delete, not a replacement
+	if action := planAction(plan.ResourceChanges[1].Change.Actions); action != "replace" {
+		t.Errorf("Expected unexpected replacement to be kept, got %s", action)
+	}
+
+	// An update with other changes is kept, without the expected attribute
+	plan = baselineTestPlan()
+	after := plan.ResourceChanges[0].Change.After.(map[string]interface{})
+	after["instance_type"] = "m5.large"
+	plan.ResourceChanges[0].Change.Before.(map[string]interface{})["instance_type"] = "t3.micro"
+	applyBaseline(plan, "", baseline)
+	result := generateMarkdownComment(plan, ReportOptions{Baseline: baseline})
+	if !strings.Contains(result, "instance_type") || strings.Contains(result, "http_tokens") {
+		t.Errorf("Expected only the unexpected attribute change, got:\n%s", result)
+	}
+}
+
+func TestReadBaselineRequiresAddress(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(file, []byte(`{"entries": [{"attribute": "tags"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readBaseline(file); err == nil {
+		t.Error("Expected error for entry without address")
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultBitbucketAPIURL is used unless BITBUCKET_API_URL is set
+const defaultBitbucketAPIURL = "https://api.bitbucket.org/2.0"
+
+// bitbucketPipelinesProxy authenticates Code Insights requests made from a
+// Bitbucket Pipelines step, so no token is needed there
+const bitbucketPipelinesProxy = "http://localhost:29418"
+
+// Code Insights limits
+const (
+	bitbucketReportID          = "tfplan-commenter"
+	bitbucketDetailsLimit      = 2000
+	bitbucketSummaryLimit      = 450
+	bitbucketAnnotationsBatch  = 100
+	bitbucketAnnotationsPerRun = 1000
+)
+
+// bitbucketClient calls the Bitbucket Cloud REST API
+type bitbucketClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newBitbucketClient returns a client authenticating with token, or through
+// the Pipelines proxy when there is no token and the run is a Pipelines step.
+// It returns nil when neither is available.
+func newBitbucketClient(token string) *bitbucketClient {
+	client := &bitbucketClient{
+		baseURL: defaultBitbucketAPIURL,
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+	if baseURL := os.Getenv("BITBUCKET_API_URL"); baseURL != "" {
+		client.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+	if token == "" {
+		if os.Getenv("BITBUCKET_BUILD_NUMBER") == "" {
+			return nil
+		}
+		proxy, _ := url.Parse(bitbucketPipelinesProxy)
+		client.http.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+		// The proxy only intercepts plain HTTP requests
+		client.baseURL = strings.Replace(client.baseURL, "https://", "http://", 1)
+	}
+	return client
+}
+
+// do sends a JSON request and returns an error for non-2xx responses
+func (c *bitbucketClient) do(method, path string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	request, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid Bitbucket API request: %w", err)
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("Bitbucket API request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("Bitbucket API %s %s returned %s: %s", method, path, response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// BitbucketReport is a Code Insights report
+type BitbucketReport struct {
+	Title      string                `json:"title"`
+	Details    string                `json:"details"`
+	ReportType string                `json:"report_type"`
+	Reporter   string                `json:"reporter"`
+	Result     string                `json:"result"`
+	Data       []BitbucketReportData `json:"data"`
+}
+
+// BitbucketReportData is one value shown in the report panel
+type BitbucketReportData struct {
+	Title string      `json:"title"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// BitbucketAnnotation is a Code Insights annotation
+type BitbucketAnnotation struct {
+	ExternalID     string `json:"external_id"`
+	AnnotationType string `json:"annotation_type"`
+	Summary        string `json:"summary"`
+	Severity       string `json:"severity"`
+	Path           string `json:"path,omitempty"`
+	Line           int    `json:"line,omitempty"`
+}
+
+// bitbucketReport builds the Code Insights report of a run: change counts,
+// and FAILED when a gate failed
+func bitbucketReport(plans []PlanInfo, result RunResult) BitbucketReport {
+	var create, update, replace, remove int
+	for _, env := range result.Environments {
+		create += env.Create
+		update += env.Update
+		replace += env.Replace
+		remove += env.Delete
+	}
+
+	report := BitbucketReport{
+		Title:      "Terraform plan",
+		ReportType: "TEST",
+		Reporter:   "tfplan-commenter",
+		Result:     "PASSED",
+		Data: []BitbucketReportData{
+			{Title: "Create", Type: "NUMBER", Value: create},
+			{Title: "Update", Type: "NUMBER", Value: update},
+			{Title: "Replace", Type: "NUMBER", Value: replace},
+			{Title: "Delete", Type: "NUMBER", Value: remove},
+		},
+	}
+
+	details := fmt.Sprintf("%d environment(s): %d to create, %d to update, %d to replace, %d to delete.",
+		len(plans), create, update, replace, remove)
+	if result.ExitReason == "gate_failed" {
+		report.Result = "FAILED"
+		for _, gate := range result.Gates {
+			if !gate.Passed {
+				details += fmt.Sprintf(" Gate %s failed: %s.", gate.Gate, strings.Join(gate.Violations, "; "))
+			}
+		}
+	}
+	report.Details = truncateText(details, bitbucketDetailsLimit)
+	return report
+}
+
+// bitbucketAnnotations converts workflow annotations to Code Insights annotations
+func bitbucketAnnotations(annotations []WorkflowAnnotation) []BitbucketAnnotation {
+	converted := make([]BitbucketAnnotation, 0, len(annotations))
+	for i, annotation := range annotations {
+		if i == bitbucketAnnotationsPerRun {
+			break
+		}
+		severity := "MEDIUM"
+		if annotation.Level == "error" {
+			severity = "HIGH"
+		}
+		converted = append(converted, BitbucketAnnotation{
+			ExternalID:     fmt.Sprintf("%s-%d", bitbucketReportID, i+1),
+			AnnotationType: "BUG",
+			Summary:        truncateText(annotation.Title+": "+annotation.Message, bitbucketSummaryLimit),
+			Severity:       severity,
+			Path:           annotation.File,
+			Line:           annotation.Line,
+		})
+	}
+	return converted
+}
+
+// truncateText shortens text to at most limit characters, marking the cut with an ellipsis
+func truncateText(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// publishReport creates or replaces the report for a commit, then adds its annotations
+func (c *bitbucketClient) publishReport(workspace, repo, commit string, report BitbucketReport, annotations []BitbucketAnnotation) error {
+	path := bitbucketReportPath(workspace, repo, commit)
+	if err := c.do(http.MethodPut, path, report); err != nil {
+		return err
+	}
+	for start := 0; start < len(annotations); start += bitbucketAnnotationsBatch {
+		end := min(start+bitbucketAnnotationsBatch, len(annotations))
+		if err := c.do(http.MethodPost, path+"/annotations", annotations[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bitbucketReportPath is the API path of the commit's Code Insights report
+func bitbucketReportPath(workspace, repo, commit string) string {
+	return fmt.Sprintf("/repositories/%s/%s/commit/%s/reports/%s",
+		url.PathEscape(workspace), url.PathEscape(repo), url.PathEscape(commit), bitbucketReportID)
+}
+
+// publishBitbucketInsights publishes the run's Code Insights report to the
+// repository in BITBUCKET_WORKSPACE / BITBUCKET_REPO_SLUG, using BITBUCKET_TOKEN
+// or the Pipelines proxy, and returns the report's API URL
+func publishBitbucketInsights(commit string, report BitbucketReport, annotations []BitbucketAnnotation) (string, error) {
+	workspace, repo := os.Getenv("BITBUCKET_WORKSPACE"), os.Getenv("BITBUCKET_REPO_SLUG")
+	if workspace == "" || repo == "" || commit == "" {
+		return "", fmt.Errorf("BITBUCKET_WORKSPACE, BITBUCKET_REPO_SLUG and a commit (-commit or BITBUCKET_COMMIT) are required")
+	}
+	client := newBitbucketClient(os.Getenv("BITBUCKET_TOKEN"))
+	if client == nil {
+		return "", fmt.Errorf("set BITBUCKET_TOKEN, or run inside Bitbucket Pipelines")
+	}
+	if err := client.publishReport(workspace, repo, commit, report, annotations); err != nil {
+		return "", err
+	}
+	return client.baseURL + bitbucketReportPath(workspace, repo, commit), nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBitbucketReport(t *testing.T) {
+	plans := []PlanInfo{{RelativePath: "prod", Plan: &TerraformPlan{}}}
+	result := RunResult{
+		ExitReason:   "gate_failed",
+		Environments: []EnvironmentResult{{Environment: "prod", Create: 2, Delete: 1}},
+		Gates:        []GateResult{{Gate: gateFailOn, Violations: []string{"prod: delete aws_s3_bucket.b (rule delete)"}}},
+	}
+
+	report := bitbucketReport(plans, result)
+	if report.Result != "FAILED" {
+		t.Errorf("Expected a failed gate to fail the report, got %s", report.Result)
+	}
+	if !strings.Contains(report.Details, "2 to create") || !strings.Contains(report.Details, "Gate fail_on failed: prod: delete aws_s3_bucket.b") {
+		t.Errorf("Unexpected details: %s", report.Details)
+	}
+
+	annotations := bitbucketAnnotations([]WorkflowAnnotation{
+		{Level: "error", Title: "Terraform plan gate", Message: "delete aws_s3_bucket.b", File: "main.tf", Line: 3},
+		{Level: "warning", Title: "Terraform replace", Message: "aws_db_instance.main will be destroyed and recreated"},
+	})
+	if annotations[0].Severity != "HIGH" || annotations[0].Path != "main.tf" || annotations[1].Severity != "MEDIUM" {
+		t.Errorf("Unexpected annotations: %+v", annotations)
+	}
+	if annotations[0].ExternalID == annotations[1].ExternalID {
+		t.Error("Expected unique annotation IDs")
+	}
+
+	if got := truncateText("abcdef", 4); got != "abc…" {
+		t.Errorf("Unexpected truncation: %s", got)
+	}
+}
+
+func TestBitbucketPublishReport(t *testing.T) {
+	var requests []string
+	var batches []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Missing token on %s", r.URL.Path)
+		}
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/annotations") {
+			body, _ := io.ReadAll(r.Body)
+			var annotations []BitbucketAnnotation
+			if err := json.Unmarshal(body, &annotations); err != nil {
+				t.Error(err)
+			}
+			batches = append(batches, len(annotations))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	t.Setenv("BITBUCKET_API_URL", ts.URL)
+	t.Setenv("BITBUCKET_WORKSPACE", "acme")
+	t.Setenv("BITBUCKET_REPO_SLUG", "infra")
+	t.Setenv("BITBUCKET_TOKEN", "token")
+
+	annotations := make([]BitbucketAnnotation, 150)
+	reportURL, err := publishBitbucketInsights("abc123", BitbucketReport{Result: "PASSED"}, annotations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := "/repositories/acme/infra/commit/abc123/reports/tfplan-commenter"
+	if reportURL != ts.URL+path {
+		t.Errorf("Unexpected report URL %q", reportURL)
+	}
+	expected := []string{"PUT " + path, "POST " + path + "/annotations", "POST " + path + "/annotations"}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected requests:\n%s", strings.Join(requests, "\n"))
+	}
+	if len(batches) != 2 || batches[0] != 100 || batches[1] != 50 {
+		t.Errorf("Expected annotations in batches of 100, got %v", batches)
+	}
+
+	t.Setenv("BITBUCKET_TOKEN", "")
+	t.Setenv("BITBUCKET_BUILD_NUMBER", "")
+	if _, err := publishBitbucketInsights("abc123", BitbucketReport{}, nil); err == nil {
+		t.Error("Expected an error without a token outside Pipelines")
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// defaultBuildkiteAPIURL is used unless BUILDKITE_API_URL is set
+const defaultBuildkiteAPIURL = "https://api.buildkite.com/v2"
+
+// buildkiteAnnotationContext identifies the annotation so later runs in the
+// build replace it instead of adding another
+const buildkiteAnnotationContext = "tfplan-commenter"
+
+// buildkiteAnnotationLimit is the maximum annotation body size in bytes
+const buildkiteAnnotationLimit = 1024 * 1024
+
+// buildkiteStyle picks the annotation style: error when a gate failed,
+// warning for deletes and replacements, info for other changes and success
+// when nothing changes
+func buildkiteStyle(plans []PlanInfo, result RunResult) string {
+	if result.ExitReason == "gate_failed" {
+		return "error"
+	}
+	for _, env := range result.Environments {
+		if env.Delete > 0 || env.Replace > 0 {
+			return "warning"
+		}
+	}
+	for _, planInfo := range plans {
+		if planHasChanges(planInfo.Plan) {
+			return "info"
+		}
+	}
+	return "success"
+}
+
+// annotateBuildkite adds the report to the build as an annotation, with
+// buildkite-agent when it is installed or else through the REST API with
+// BUILDKITE_API_TOKEN. It returns the annotated build's URL.
+func annotateBuildkite(markdown, style string) (string, error) {
+	if len(markdown) > buildkiteAnnotationLimit {
+		return "", fmt.Errorf("report is %d bytes, over the %d byte annotation limit", len(markdown), buildkiteAnnotationLimit)
+	}
+
+	if _, err := exec.LookPath("buildkite-agent"); err == nil {
+		cmd := exec.Command("buildkite-agent", "annotate", "--style", style, "--context", buildkiteAnnotationContext)
+		cmd.Stdin = strings.NewReader(markdown)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("buildkite-agent annotate failed: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		return os.Getenv("BUILDKITE_BUILD_URL"), nil
+	}
+
+	token := os.Getenv("BUILDKITE_API_TOKEN")
+	org, pipeline, build := os.Getenv("BUILDKITE_ORGANIZATION_SLUG"), os.Getenv("BUILDKITE_PIPELINE_SLUG"), os.Getenv("BUILDKITE_BUILD_NUMBER")
+	if token == "" || org == "" || pipeline == "" || build == "" {
+		return "", fmt.Errorf("buildkite-agent is not installed; set BUILDKITE_API_TOKEN, BUILDKITE_ORGANIZATION_SLUG, BUILDKITE_PIPELINE_SLUG and BUILDKITE_BUILD_NUMBER to use the API")
+	}
+	if err := createBuildkiteAnnotation(buildkiteAPIURL(), token, org, pipeline, build, markdown, style); err != nil {
+		return "", err
+	}
+	if buildURL := os.Getenv("BUILDKITE_BUILD_URL"); buildURL != "" {
+		return buildURL, nil
+	}
+	return fmt.Sprintf("%s/organizations/%s/pipelines/%s/builds/%s",
+		buildkiteAPIURL(), url.PathEscape(org), url.PathEscape(pipeline), url.PathEscape(build)), nil
+}
+
+// buildkiteAPIURL returns the API base URL from BUILDKITE_API_URL or the default
+func buildkiteAPIURL() string {
+	if baseURL := os.Getenv("BUILDKITE_API_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return defaultBuildkiteAPIURL
+}
+
+// createBuildkiteAnnotation adds an annotation to a build through the REST API
+func createBuildkiteAnnotation(baseURL, token, org, pipeline, build, body, style string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"body":    body,
+		"style":   style,
+		"context": buildkiteAnnotationContext,
+		"append":  false,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	path := fmt.Sprintf("/organizations/%s/pipelines/%s/builds/%s/annotations",
+		url.PathEscape(org), url.PathEscape(pipeline), url.PathEscape(build))
+	request, err := http.NewRequest(http.MethodPost, baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid Buildkite API request: %w", err)
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("Buildkite API request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("Buildkite API POST %s returned %s: %s", path, response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
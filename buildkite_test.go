@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBuildkiteStyle(t *testing.T) {
+	unchanged := []PlanInfo{{Plan: &TerraformPlan{}}}
+	changed := []PlanInfo{{Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_sqs_queue.q", Change: Change{Actions: []string{"create"}}},
+	}}}}
+
+	cases := []struct {
+		plans  []PlanInfo
+		result RunResult
+		style  string
+	}{
+		{unchanged, RunResult{}, "success"},
+		{changed, RunResult{Environments: []EnvironmentResult{{Create: 1}}}, "info"},
+		{changed, RunResult{Environments: []EnvironmentResult{{Replace: 1}}}, "warning"},
+		{changed, RunResult{ExitReason: "gate_failed"}, "error"},
+	}
+	for _, c := range cases {
+		if style := buildkiteStyle(c.plans, c.result); style != c.style {
+			t.Errorf("Expected %s for %+v, got %s", c.style, c.result, style)
+		}
+	}
+}
+
+func TestCreateBuildkiteAnnotation(t *testing.T) {
+	var payload map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/organizations/acme/pipelines/infra/builds/42/annotations" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	if err := createBuildkiteAnnotation(ts.URL, "token", "acme", "infra", "42", "## Plan", "warning"); err != nil {
+		t.Fatal(err)
+	}
+	if payload["body"] != "## Plan" || payload["style"] != "warning" || payload["context"] != buildkiteAnnotationContext {
+		t.Errorf("Unexpected annotation payload: %v", payload)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("BUILDKITE_API_URL", ts.URL)
+	t.Setenv("BUILDKITE_API_TOKEN", "token")
+	t.Setenv("BUILDKITE_ORGANIZATION_SLUG", "acme")
+	t.Setenv("BUILDKITE_PIPELINE_SLUG", "infra")
+	t.Setenv("BUILDKITE_BUILD_NUMBER", "42")
+	t.Setenv("BUILDKITE_BUILD_URL", "")
+	buildURL, err := annotateBuildkite("## Plan", "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buildURL != ts.URL+"/organizations/acme/pipelines/infra/builds/42" {
+		t.Errorf("Unexpected build URL %q", buildURL)
+	}
+}
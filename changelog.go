@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultChangelogFile is the in-repo trail of infrastructure changes written by -changelog
+const defaultChangelogFile = "CHANGELOG.infra.md"
+
+// changelogTitle starts a new changelog file
+const changelogTitle = "# Infrastructure Changelog\n\n"
+
+// changelogEntry renders a dated entry for the plans, referencing the commit
+// they were planned from when known
+func changelogEntry(plans []PlanInfo, opts ReportOptions, date time.Time, commit string) string {
+	heading := "## " + date.Format("2006-01-02")
+	if commit != "" {
+		heading += fmt.Sprintf(" · `%s`", shortCommit(commit))
+	}
+	return heading + "\n\n" + changelogSections(plans, opts)
+}
+
+// shortCommit abbreviates a commit SHA the way git log --oneline does
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
+
+// appendChangelogEntry appends an entry to the changelog file, creating it
+// with a title when missing. An entry for the same commit is not added
+// twice, so re-running a pipeline keeps the trail clean. It reports whether
+// the entry was written.
+func appendChangelogEntry(filename string, plans []PlanInfo, opts ReportOptions, date time.Time, commit string) (bool, error) {
+	existing, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to read changelog: %w", err)
+	}
+	if commit != "" && strings.Contains(string(existing), fmt.Sprintf(" · `%s`\n", shortCommit(commit))) {
+		return false, nil
+	}
+
+	content := string(existing)
+	if strings.TrimSpace(content) == "" {
+		content = changelogTitle
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n"
+	}
+	content += strings.TrimRight(changelogEntry(plans, opts, date, commit), "\n") + "\n"
+
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		return false, fmt.Errorf("failed to write changelog: %w", err)
+	}
+	return true, nil
+}
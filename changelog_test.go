@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendChangelogEntry(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), defaultChangelogFile)
+	plans := []PlanInfo{{Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+	}}}}
+	date := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		written, err := appendChangelogEntry(filename, plans, ReportOptions{}, date, "0123456789abcdef")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != (i == 0) {
+			t.Errorf("Run %d: expected written=%t", i, i == 0)
+		}
+	}
+	if _, err := appendChangelogEntry(filename, plans, ReportOptions{}, date.AddDate(0, 0, 1), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := changelogTitle +
+		"## 2026-03-01 · `0123456`\n\n### Added\n\n- `aws_sqs_queue.q`\n\n" +
+		"## 2026-03-02\n\n### Added\n\n- `aws_sqs_queue.q`\n"
+	if string(data) != expected {
+		t.Errorf("Unexpected changelog:\n%s", data)
+	}
+	if strings.Contains(string(data), "Terraform Plan Changelog") {
+		t.Error("Expected entries without the report style title")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckResult represents the status of a check block, precondition or
+// postcondition as recorded in the plan's "checks" array
+type CheckResult struct {
+	Address   CheckAddress    `json:"address"`
+	Status    string          `json:"status"`
+	Instances []CheckInstance `json:"instances"`
+}
+
+// CheckAddress identifies the object that declares the checks
+type CheckAddress struct {
+	Kind      string `json:"kind"`
+	ToDisplay string `json:"to_display"`
+}
+
+// CheckInstance is the status of a single instance of a checkable object
+type CheckInstance struct {
+	Address  CheckInstanceAddress `json:"address"`
+	Status   string               `json:"status"`
+	Problems []CheckProblem       `json:"problems"`
+}
+
+// CheckInstanceAddress identifies a single instance of a checkable object
+type CheckInstanceAddress struct {
+	ToDisplay string `json:"to_display"`
+}
+
+// CheckProblem is a failure message reported by a condition
+type CheckProblem struct {
+	Message string `json:"message"`
+}
+
+// formatCheckEntries describes every failing, errored or unknown check as a
+// single markdown line; passing checks are omitted
+func formatCheckEntries(checks []CheckResult) []string {
+	var entries []string
+
+	for _, check := range checks {
+		if check.Status == "pass" {
+			continue
+		}
+
+		kind := check.Address.Kind
+		if kind == "" {
+			kind = "check"
+		}
+
+		// Report per instance when instances carry their own status and problems
+		reported := false
+		for _, instance := range check.Instances {
+			if instance.Status == "pass" {
+				continue
+			}
+			address := instance.Address.ToDisplay
+			if address == "" {
+				address = check.Address.ToDisplay
+			}
+			entries = append(entries, formatCheckEntry(instance.Status, address, kind, instance.Problems))
+			reported = true
+		}
+
+		if !reported {
+			entries = append(entries, formatCheckEntry(check.Status, check.Address.ToDisplay, kind, nil))
+		}
+	}
+
+	sort.Strings(entries)
+	return entries
+}
+
+func formatCheckEntry(status, address, kind string, problems []CheckProblem) string {
+	icon := "❔"
+	switch status {
+	case "fail":
+		icon = "❌"
+	case "error":
+		icon = "⚠️"
+	}
+
+	entry := fmt.Sprintf("%s `%s` (%s, %s)", icon, address, strings.ReplaceAll(kind, "_", " "), status)
+
+	var messages []string
+	for _, problem := range problems {
+		if problem.Message != "" {
+			messages = append(messages, problem.Message)
+		}
+	}
+	if len(messages) > 0 {
+		entry += " - " + strings.Join(messages, "; ")
+	}
+	return entry
+}
+
+// writeChecksSection renders failing and unknown checks for a single plan
+func writeChecksSection(md *strings.Builder, checks []CheckResult) {
+	entries := formatCheckEntries(checks)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("### 🧪 Checks Not Passing\n\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentChecksSection renders failing and unknown checks inside a multi-plan environment section
+func writeEnvironmentChecksSection(md *strings.Builder, checks []CheckResult) {
+	entries := formatCheckEntries(checks)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("**🧪 Checks Not Passing:**\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
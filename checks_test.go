@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatCheckEntries(t *testing.T) {
+	data := []byte(`[
+		{"address": {"kind": "check", "to_display": "check.health"}, "status": "fail",
+		 "instances": [{"address": {"to_display": "check.health"}, "status": "fail", "problems": [{"message": "endpoint returned 503"}]}]},
+		{"address": {"kind": "resource", "to_display": "aws_instance.web"}, "status": "unknown"},
+		{"address": {"kind": "output_value", "to_display": "output.url"}, "status": "pass"}
+	]`)
+
+	var checks []CheckResult
+	if err := json.Unmarshal(data, &checks); err != nil {
+		t.Fatalf("Failed to parse checks: %v", err)
+	}
+
+	expected := []string{
+		"❌ `check.health` (check, fail) - endpoint returned 503",
+		"❔ `aws_instance.web` (resource, unknown)",
+	}
+
+	entries := formatCheckEntries(checks)
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %v", len(expected), len(entries), entries)
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("Entry %d: expected %s, got %s", i, expected[i], entries[i])
+		}
+	}
+
+	markdown := generateMarkdownComment(&TerraformPlan{Checks: checks}, ReportOptions{})
+	if !strings.Contains(markdown, "Checks Not Passing") {
+		t.Error("Expected checks section even when there are no resource changes")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Forges -post-comment can publish to
+const (
+	forgeGitHub = "github"
+	forgeGitea  = "gitea"
+)
+
+// PullRequestContext identifies the pull request a CI run builds
+type PullRequestContext struct {
+	CI         string // CI system the context was detected from
+	Forge      string // forgeGitHub or forgeGitea
+	APIURL     string // Forge API base URL; empty for the GitHub default
+	Repository string // owner/name
+	Number     int
+	RunURL     string // CI run page holding the full report, when known
+}
+
+// commentPublisher posts and updates comments on pull requests
+type commentPublisher interface {
+	createComment(repository string, number int, body string) (string, error)
+	listComments(repository string, number int) ([]issueComment, error)
+	updateComment(repository string, id int64, body string) (string, error)
+	requestReviewers(repository string, number int, users, teams []string) error
+	pullRequestBody(repository string, number int) (string, error)
+	updatePullRequestBody(repository string, number int, body string) error
+	authenticatedLogin() (string, error)
+}
+
+// detectPullRequestContext reads the pull request of the current CI run
+// from Woodpecker, Drone or GitHub Actions environment variables. forge
+// overrides the detected forge, e.g. for Drone with GitHub Enterprise.
+func detectPullRequestContext(forge string) (PullRequestContext, error) {
+	var ctx PullRequestContext
+	var number, forgeURL string
+
+	switch {
+	case os.Getenv("CI") == "woodpecker":
+		ctx.CI = "Woodpecker"
+		ctx.RunURL = os.Getenv("CI_PIPELINE_URL")
+		ctx.Repository = os.Getenv("CI_REPO")
+		number = os.Getenv("CI_COMMIT_PULL_REQUEST")
+		forgeURL = os.Getenv("CI_FORGE_URL")
+		switch os.Getenv("CI_FORGE_TYPE") {
+		case "github":
+			ctx.Forge = forgeGitHub
+		case "gitea", "forgejo":
+			ctx.Forge = forgeGitea
+		}
+	case os.Getenv("DRONE") == "true":
+		ctx.CI = "Drone"
+		ctx.RunURL = os.Getenv("DRONE_BUILD_LINK")
+		ctx.Repository = os.Getenv("DRONE_REPO")
+		number = os.Getenv("DRONE_PULL_REQUEST")
+		if link, err := url.Parse(os.Getenv("DRONE_REPO_LINK")); err == nil && link.Host != "" {
+			forgeURL = link.Scheme + "://" + link.Host
+		}
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		ctx.CI = "GitHub Actions"
+		ctx.Forge = forgeGitHub
+		ctx.Repository = os.Getenv("GITHUB_REPOSITORY")
+		if server, run := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_RUN_ID"); server != "" && run != "" {
+			ctx.RunURL = fmt.Sprintf("%s/%s/actions/runs/%s", server, ctx.Repository, run)
+		}
+		if ref := os.Getenv("GITHUB_REF"); strings.HasPrefix(ref, "refs/pull/") {
+			number = strings.TrimSuffix(strings.TrimPrefix(ref, "refs/pull/"), "/merge")
+		}
+	default:
+		return ctx, fmt.Errorf("no supported CI environment detected (Woodpecker, Drone or GitHub Actions)")
+	}
+
+	if forge != "" {
+		ctx.Forge = forge
+	}
+	if ctx.Forge == "" {
+		// Drone and older Woodpecker don't name the forge; github.com is GitHub, anything else Gitea
+		ctx.Forge = forgeGitea
+		if strings.TrimPrefix(strings.TrimPrefix(forgeURL, "https://"), "http://") == "github.com" {
+			ctx.Forge = forgeGitHub
+		}
+	}
+	switch {
+	case ctx.Forge == forgeGitea && forgeURL != "":
+		ctx.APIURL = strings.TrimSuffix(forgeURL, "/") + "/api/v1"
+	case ctx.Forge == forgeGitHub && forgeURL != "" && !strings.HasSuffix(forgeURL, "://github.com"):
+		ctx.APIURL = strings.TrimSuffix(forgeURL, "/") + "/api/v3"
+	}
+
+	if ctx.Repository == "" {
+		return ctx, fmt.Errorf("%s did not provide the repository", ctx.CI)
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return ctx, fmt.Errorf("%s run is not building a pull request", ctx.CI)
+	}
+	ctx.Number = n
+	return ctx, nil
+}
+
+// newCommentPublisher returns the forge client for a pull request, using
+// GITHUB_TOKEN or GITEA_TOKEN
+func newCommentPublisher(ctx PullRequestContext) (commentPublisher, error) {
+	switch ctx.Forge {
+	case forgeGitHub:
+		client := newGitHubClient(os.Getenv("GITHUB_TOKEN"))
+		if client == nil {
+			return nil, fmt.Errorf("set GITHUB_TOKEN to comment on GitHub")
+		}
+		if ctx.APIURL != "" {
+			client.baseURL = ctx.APIURL
+		}
+		return client, nil
+	case forgeGitea:
+		client := newGiteaClient(ctx.APIURL, os.Getenv("GITEA_TOKEN"))
+		if client == nil {
+			return nil, fmt.Errorf("set GITEA_TOKEN and run in Drone or Woodpecker to comment on Gitea")
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unsupported forge %q (supported: %s, %s)", ctx.Forge, forgeGitHub, forgeGitea)
+	}
+}
+
+// String describes the pull request, e.g. "org/infra#12 on gitea (Drone)"
+func (ctx PullRequestContext) String() string {
+	return fmt.Sprintf("%s#%d on %s (%s)", ctx.Repository, ctx.Number, ctx.Forge, ctx.CI)
+}
+
+// postPullRequestComments posts the report on the pull request the CI run
+// builds, as one comment or with the per-environment strategy one sticky
+// comment per environment, and returns the comment URLs
+func postPullRequestComments(forge, strategy, report string, plans []PlanInfo, opts ReportOptions) ([]string, error) {
+	ctx, err := detectPullRequestContext(forge)
+	if err != nil {
+		return nil, err
+	}
+	publisher, err := newCommentPublisher(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	if strategy == commentStrategyPerEnvironment && len(plans) > 1 {
+		urls, err = postEnvironmentComments(publisher, ctx, plans, opts)
+	} else {
+		var commentURL string
+		commentURL, err = publisher.createComment(ctx.Repository, ctx.Number, fitComment(report, ctx.RunURL))
+		urls = []string{commentURL}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if users, teams := requestedReviewers(opts.Reviewers, plans); len(users) > 0 || len(teams) > 0 {
+		if err := publisher.requestReviewers(ctx.Repository, ctx.Number, users, teams); err != nil {
+			return urls, fmt.Errorf("failed to request reviewers: %w", err)
+		}
+	}
+	return urls, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// clearCIEnvironment unsets the variables detectPullRequestContext reads
+func clearCIEnvironment(t *testing.T) {
+	for _, name := range []string{
+		"CI", "CI_REPO", "CI_COMMIT_PULL_REQUEST", "CI_FORGE_URL", "CI_FORGE_TYPE",
+		"CI_PIPELINE_URL", "DRONE", "DRONE_REPO", "DRONE_PULL_REQUEST", "DRONE_REPO_LINK", "DRONE_BUILD_LINK",
+		"GITHUB_ACTIONS", "GITHUB_REPOSITORY", "GITHUB_REF", "GITHUB_SERVER_URL", "GITHUB_RUN_ID",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestDetectPullRequestContext(t *testing.T) {
+	clearCIEnvironment(t)
+	if _, err := detectPullRequestContext(""); err == nil {
+		t.Error("Expected an error outside CI")
+	}
+
+	t.Setenv("CI", "woodpecker")
+	t.Setenv("CI_REPO", "ops/infra")
+	t.Setenv("CI_COMMIT_PULL_REQUEST", "12")
+	t.Setenv("CI_FORGE_TYPE", "forgejo")
+	t.Setenv("CI_FORGE_URL", "https://code.example.com")
+	ctx, err := detectPullRequestContext("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := PullRequestContext{CI: "Woodpecker", Forge: forgeGitea, APIURL: "https://code.example.com/api/v1", Repository: "ops/infra", Number: 12}
+	if ctx != expected {
+		t.Errorf("Unexpected Woodpecker context: %+v", ctx)
+	}
+
+	clearCIEnvironment(t)
+	t.Setenv("DRONE", "true")
+	t.Setenv("DRONE_REPO", "ops/infra")
+	t.Setenv("DRONE_PULL_REQUEST", "7")
+	t.Setenv("DRONE_REPO_LINK", "https://github.com/ops/infra")
+	t.Setenv("DRONE_BUILD_LINK", "https://drone.example.com/ops/infra/42")
+	ctx, err = detectPullRequestContext("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Forge != forgeGitHub || ctx.APIURL != "" || ctx.Number != 7 || ctx.RunURL != "https://drone.example.com/ops/infra/42" {
+		t.Errorf("Unexpected Drone context: %+v", ctx)
+	}
+
+	t.Setenv("DRONE_REPO_LINK", "https://ghe.example.com/ops/infra")
+	ctx, err = detectPullRequestContext(forgeGitHub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Forge != forgeGitHub || ctx.APIURL != "https://ghe.example.com/api/v3" {
+		t.Errorf("Expected -forge to select GitHub Enterprise: %+v", ctx)
+	}
+
+	t.Setenv("DRONE_PULL_REQUEST", "")
+	if _, err := detectPullRequestContext(""); err == nil {
+		t.Error("Expected an error for a push build")
+	}
+}
+
+func TestGiteaCreateComment(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/ops/infra/issues/12/comments" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			t.Errorf("Unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["body"] != "## Plan" {
+			t.Errorf("Unexpected body %v (%v)", body, err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://code.example.com/ops/infra/pulls/12#issuecomment-1"}`))
+	}))
+	defer ts.Close()
+
+	clearCIEnvironment(t)
+	t.Setenv("CI", "woodpecker")
+	t.Setenv("CI_REPO", "ops/infra")
+	t.Setenv("CI_COMMIT_PULL_REQUEST", "12")
+	t.Setenv("CI_FORGE_TYPE", "gitea")
+	t.Setenv("CI_FORGE_URL", ts.URL)
+	t.Setenv("GITEA_TOKEN", "secret")
+
+	commentURLs, err := postPullRequestComments("", commentStrategyCombined, "## Plan", nil, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(commentURLs) != 1 || commentURLs[0] != "https://code.example.com/ops/infra/pulls/12#issuecomment-1" {
+		t.Errorf("Unexpected comment URLs: %v", commentURLs)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// sourceCloudFormation marks plans converted from an AWS CloudFormation change set
+const sourceCloudFormation = "cloudformation"
+
+// CloudFormationChangeSet represents the output of `aws cloudformation describe-change-set`
+type CloudFormationChangeSet struct {
+	ChangeSetName string                 `json:"ChangeSetName"`
+	ChangeSetId   string                 `json:"ChangeSetId"`
+	StackName     string                 `json:"StackName"`
+	Changes       []CloudFormationChange `json:"Changes"`
+}
+
+// CloudFormationChange represents a single entry in the change set's Changes list
+type CloudFormationChange struct {
+	Type           string                       `json:"Type"`
+	ResourceChange CloudFormationResourceChange `json:"ResourceChange"`
+}
+
+// CloudFormationResourceChange describes the change CloudFormation will make to a resource
+type CloudFormationResourceChange struct {
+	Action             string                 `json:"Action"`
+	LogicalResourceId  string                 `json:"LogicalResourceId"`
+	PhysicalResourceId string                 `json:"PhysicalResourceId"`
+	ResourceType       string                 `json:"ResourceType"`
+	Replacement        string                 `json:"Replacement"`
+	Details            []CloudFormationDetail `json:"Details"`
+}
+
+// CloudFormationDetail describes a single property change on a resource
+type CloudFormationDetail struct {
+	Target CloudFormationTarget `json:"Target"`
+}
+
+// CloudFormationTarget identifies the changed property and, when the change set
+// was created with IncludePropertyValues, its before/after values
+type CloudFormationTarget struct {
+	Attribute          string  `json:"Attribute"`
+	Name               string  `json:"Name"`
+	RequiresRecreation string  `json:"RequiresRecreation"`
+	BeforeValue        *string `json:"BeforeValue"`
+	AfterValue         *string `json:"AfterValue"`
+}
+
+// isCloudFormationChangeSet reports whether the JSON document looks like a change set
+func isCloudFormationChangeSet(data []byte) bool {
+	var probe struct {
+		ChangeSetId *string         `json:"ChangeSetId"`
+		Changes     json.RawMessage `json:"Changes"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.ChangeSetId != nil || probe.Changes != nil
+}
+
+func parseCloudFormationChangeSet(data []byte) (*TerraformPlan, error) {
+	var changeSet CloudFormationChangeSet
+	if err := json.Unmarshal(data, &changeSet); err != nil {
+		return nil, fmt.Errorf("failed to parse change set JSON: %w", err)
+	}
+
+	return convertCloudFormationChangeSet(&changeSet), nil
+}
+
+// convertCloudFormationChangeSet maps a change set onto the Terraform plan structure
+// so it can be rendered by the same report generators
+func convertCloudFormationChangeSet(changeSet *CloudFormationChangeSet) *TerraformPlan {
+	plan := &TerraformPlan{
+		Source:        sourceCloudFormation,
+		ChangeSetName: changeSet.ChangeSetName,
+	}
+
+	for _, c := range changeSet.Changes {
+		if c.Type != "" && c.Type != "Resource" {
+			continue
+		}
+		rc := c.ResourceChange
+
+		actions := cloudFormationActions(rc)
+		if len(actions) == 0 {
+			continue
+		}
+
+		before := make(map[string]interface{})
+		after := make(map[string]interface{})
+		afterUnknown := make(map[string]interface{})
+		var replacePaths [][]interface{}
+
+		if rc.PhysicalResourceId != "" {
+			before["id"] = rc.PhysicalResourceId
+		}
+
+		for _, detail := range rc.Details {
+			name := detail.Target.Name
+			if name == "" {
+				name = detail.Target.Attribute
+			}
+			if name == "" {
+				continue
+			}
+
+			if detail.Target.BeforeValue != nil {
+				before[name] = *detail.Target.BeforeValue
+			}
+			if detail.Target.AfterValue != nil {
+				after[name] = *detail.Target.AfterValue
+			}
+			if detail.Target.BeforeValue == nil && detail.Target.AfterValue == nil {
+				afterUnknown[name] = true
+			}
+			if detail.Target.RequiresRecreation == "Always" || detail.Target.RequiresRecreation == "Conditionally" {
+				replacePaths = append(replacePaths, []interface{}{name})
+			}
+		}
+
+		change := Change{
+			Actions:      actions,
+			AfterUnknown: afterUnknown,
+			ReplacePaths: replacePaths,
+		}
+		switch rc.Action {
+		case "Add":
+			change.After = after
+		case "Remove":
+			change.Before = before
+		default:
+			change.Before = before
+			change.After = after
+		}
+
+		plan.ResourceChanges = append(plan.ResourceChanges, ResourceChange{
+			Address:      fmt.Sprintf("%s.%s", rc.ResourceType, rc.LogicalResourceId),
+			Mode:         "managed",
+			Type:         rc.ResourceType,
+			Name:         rc.LogicalResourceId,
+			ProviderName: "cloudformation",
+			Change:       change,
+		})
+	}
+
+	return plan
+}
+
+// cloudFormationActions translates a change set action into Terraform plan actions
+func cloudFormationActions(rc CloudFormationResourceChange) []string {
+	switch rc.Action {
+	case "Add":
+		return []string{"create"}
+	case "Remove":
+		return []string{"delete"}
+	case "Modify":
+		// Conditional replacements are reported as replacements so reviewers don't miss them
+		if rc.Replacement == "True" || rc.Replacement == "Conditional" {
+			return []string{"delete", "create"}
+		}
+		return []string{"update"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertCloudFormationChangeSet(t *testing.T) {
+	data := []byte(`{
+		"ChangeSetName": "release-42",
+		"ChangeSetId": "arn:aws:cloudformation:us-east-1:123456789012:changeSet/release-42/abc",
+		"StackName": "legacy-stack",
+		"Changes": [
+			{"Type": "Resource", "ResourceChange": {"Action": "Add", "LogicalResourceId": "Queue", "ResourceType": "AWS::SQS::Queue"}},
+			{"Type": "Resource", "ResourceChange": {"Action": "Modify", "LogicalResourceId": "Bucket", "PhysicalResourceId": "legacy-bucket", "ResourceType": "AWS::S3::Bucket", "Replacement": "False",
+				"Details": [{"Target": {"Attribute": "Properties", "Name": "VersioningConfiguration", "BeforeValue": "Suspended", "AfterValue": "Enabled"}}]}},
+			{"Type": "Resource", "ResourceChange": {"Action": "Modify", "LogicalResourceId": "Database", "ResourceType": "AWS::RDS::DBInstance", "Replacement": "True"}},
+			{"Type": "Resource", "ResourceChange": {"Action": "Remove", "LogicalResourceId": "Topic", "PhysicalResourceId": "arn:aws:sns:us-east-1:123456789012:old", "ResourceType": "AWS::SNS::Topic"}}
+		]
+	}`)
+
+	if !isCloudFormationChangeSet(data) {
+		t.Fatal("Expected change set to be detected")
+	}
+	if isCloudFormationChangeSet([]byte(`{"format_version": "1.2", "resource_changes": []}`)) {
+		t.Error("Expected Terraform plan not to be detected as a change set")
+	}
+
+	plan, err := parseCloudFormationChangeSet(data)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	summary := analyzeResourceChanges(plan.ResourceChanges)
+	if len(summary.Create) != 1 || len(summary.Update) != 1 || len(summary.Replace) != 1 || len(summary.Delete) != 1 {
+		t.Fatalf("Unexpected summary: %+v", summary)
+	}
+
+	if summary.Update[0].Address != "AWS::S3::Bucket.Bucket" {
+		t.Errorf("Expected update address AWS::S3::Bucket.Bucket, got %s", summary.Update[0].Address)
+	}
+	if len(summary.Update[0].Changes) != 1 || summary.Update[0].Changes[0].Attribute != "VersioningConfiguration" {
+		t.Errorf("Expected VersioningConfiguration change, got %+v", summary.Update[0].Changes)
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(markdown, "Generated from AWS CloudFormation change set release-42") {
+		t.Error("Expected CloudFormation footer in markdown")
+	}
+}
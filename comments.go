@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Publishing strategies for -post-comment
+const (
+	commentStrategyCombined       = "combined"
+	commentStrategyPerEnvironment = "per-environment"
+)
+
+// Hidden markers identifying the sticky comments of the per-environment strategy
+const (
+	commentMarkerPrefix = "<!-- tfplan-commenter:"
+	commentIndexMarker  = commentMarkerPrefix + "index -->"
+)
+
+// environmentCommentMarker identifies the sticky comment of one environment
+func environmentCommentMarker(relativePath string) string {
+	return fmt.Sprintf("%senv=%s -->", commentMarkerPrefix, relativePath)
+}
+
+// environmentFromMarker returns the environment of a sticky comment body
+func environmentFromMarker(body string) (string, bool) {
+	prefix := commentMarkerPrefix + "env="
+	if !strings.HasPrefix(body, prefix) {
+		return "", false
+	}
+	end := strings.Index(body, " -->")
+	if end < len(prefix) {
+		return "", false
+	}
+	return body[len(prefix):end], true
+}
+
+// postedBy reports whether a comment was written by login. Markers are plain
+// text anyone can post, so only the publisher's own comments are trusted.
+func postedBy(comment issueComment, login string) bool {
+	return login != "" && strings.EqualFold(comment.User.Login, login)
+}
+
+// upsertComment updates the comment carrying marker written by login, or
+// creates it, and returns its URL
+func upsertComment(publisher commentPublisher, ctx PullRequestContext, existing []issueComment, login, marker, body string) (string, error) {
+	body = fitComment(marker+"\n"+body, ctx.RunURL)
+	for _, comment := range existing {
+		if postedBy(comment, login) && strings.HasPrefix(comment.Body, marker) {
+			return publisher.updateComment(ctx.Repository, comment.ID, body)
+		}
+	}
+	return publisher.createComment(ctx.Repository, ctx.Number, body)
+}
+
+// fitComment truncates a comment body at a line boundary to GitHub's comment
+// limit, closing an open code fence and pointing at the CI run for the full
+// report
+func fitComment(body, runURL string) string {
+	length := utf8.RuneCountInString(body)
+	if length <= githubCommentLimit {
+		return body
+	}
+
+	notice := fmt.Sprintf("\n\n---\n⚠️ **Report truncated** to fit the %d character comment limit (%d characters).", githubCommentLimit, length)
+	if runURL != "" {
+		notice += fmt.Sprintf(" The full report is in the [CI run](%s) output.", runURL)
+	} else {
+		notice += " The full report is in the CI run output."
+	}
+
+	runes := []rune(body)
+	kept := string(runes[:githubCommentLimit-utf8.RuneCountInString(notice)-len("\n```")])
+	if i := strings.LastIndex(kept, "\n"); i > 0 {
+		kept = kept[:i]
+	}
+	open := ""
+	for _, line := range strings.Split(kept, "\n") {
+		trimmed := strings.TrimSpace(line)
+		for _, fence := range []string{"```", "~~~"} {
+			if !strings.HasPrefix(trimmed, fence) {
+				continue
+			}
+			if open == "" {
+				open = fence
+			} else if open == fence {
+				open = ""
+			}
+		}
+	}
+	if open != "" {
+		kept += "\n" + open
+	}
+	return kept + notice
+}
+
+// environmentCommentHeading names the environment of a sticky comment
+func environmentCommentHeading(relativePath string, opts ReportOptions) string {
+	return fmt.Sprintf("📁 **Environment:** %s\n\n", formatEnvironment(relativePath, opts.EnvironmentNames))
+}
+
+// postEnvironmentComments keeps one sticky comment per environment, plus an
+// index comment linking them, updating the comments of earlier runs in place.
+// Comments of environments missing from this run are marked as having no
+// changes so stale plans don't linger. It returns the comment URLs, index last.
+func postEnvironmentComments(publisher commentPublisher, ctx PullRequestContext, plans []PlanInfo, opts ReportOptions) ([]string, error) {
+	login, err := publisher.authenticatedLogin()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := publisher.listComments(ctx.Repository, ctx.Number)
+	if err != nil {
+		return nil, err
+	}
+
+	plans = orderEnvironments(plans, opts.EnvironmentOrder)
+	var index strings.Builder
+	index.WriteString("## 📋 Multi-Environment Terraform Plan Summary\n\n")
+	index.WriteString("| Environment | 🟢 Create | 🟡 Update | 🔄 Replace | 🔴 Delete | Details |\n")
+	index.WriteString("|-------------|-----------|-----------|------------|-----------|---------|\n")
+
+	var urls []string
+	current := make(map[string]bool)
+	for _, planInfo := range plans {
+		current[planInfo.RelativePath] = true
+		body := environmentCommentHeading(planInfo.RelativePath, opts) + generateMarkdownComment(planInfo.Plan, opts)
+		commentURL, err := upsertComment(publisher, ctx, existing, login, environmentCommentMarker(planInfo.RelativePath), body)
+		if err != nil {
+			return urls, fmt.Errorf("failed to post comment for %s: %w", planInfo.RelativePath, err)
+		}
+		urls = append(urls, commentURL)
+
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		index.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | [view](%s) |\n",
+			formatEnvironment(planInfo.RelativePath, opts.EnvironmentNames),
+			len(summary.Create), len(summary.Update), len(summary.Replace), len(summary.Delete), commentURL))
+	}
+
+	for _, comment := range existing {
+		env, ok := environmentFromMarker(comment.Body)
+		if !ok || current[env] || !postedBy(comment, login) {
+			continue
+		}
+		current[env] = true
+		body := environmentCommentMarker(env) + "\n" + environmentCommentHeading(env, opts) + "✅ No changes in this environment in the latest plan\n"
+		commentURL, err := publisher.updateComment(ctx.Repository, comment.ID, body)
+		if err != nil {
+			return urls, fmt.Errorf("failed to update comment for %s: %w", env, err)
+		}
+		urls = append(urls, commentURL)
+		index.WriteString(fmt.Sprintf("| %s | 0 | 0 | 0 | 0 | [view](%s) |\n", formatEnvironment(env, opts.EnvironmentNames), commentURL))
+	}
+
+	commentURL, err := upsertComment(publisher, ctx, existing, login, commentIndexMarker, index.String())
+	if err != nil {
+		return urls, fmt.Errorf("failed to post index comment: %w", err)
+	}
+	return append(urls, commentURL), nil
+}
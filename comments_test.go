@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// fakePublisher records comments in memory
+type fakePublisher struct {
+	comments []issueComment
+	created  int
+	updated  int
+}
+
+func (p *fakePublisher) createComment(repository string, number int, body string) (string, error) {
+	p.created++
+	id := int64(len(p.comments) + 1)
+	comment := issueComment{ID: id, Body: body, HTMLURL: fmt.Sprintf("https://example.com/c/%d", id)}
+	comment.User.Login = "tfplan-bot"
+	p.comments = append(p.comments, comment)
+	return p.comments[id-1].HTMLURL, nil
+}
+
+func (p *fakePublisher) listComments(repository string, number int) ([]issueComment, error) {
+	return append([]issueComment{}, p.comments...), nil
+}
+
+func (p *fakePublisher) updateComment(repository string, id int64, body string) (string, error) {
+	p.updated++
+	p.comments[id-1].Body = body
+	return p.comments[id-1].HTMLURL, nil
+}
+
+func (p *fakePublisher) authenticatedLogin() (string, error) {
+	return "tfplan-bot", nil
+}
+
+func (p *fakePublisher) requestReviewers(repository string, number int, users, teams []string) error {
+	return nil
+}
+
+func (p *fakePublisher) pullRequestBody(repository string, number int) (string, error) {
+	return "", nil
+}
+
+func (p *fakePublisher) updatePullRequestBody(repository string, number int, body string) error {
+	return nil
+}
+
+func TestPostEnvironmentComments(t *testing.T) {
+	change := []ResourceChange{{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}}}
+	plans := []PlanInfo{
+		{Plan: &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: change}, RelativePath: "prod"},
+		{Plan: &TerraformPlan{TerraformVersion: "1.9.8"}, RelativePath: "dev"},
+	}
+	publisher := &fakePublisher{comments: []issueComment{{ID: 1, Body: "LGTM"}}}
+	ctx := PullRequestContext{Repository: "ops/infra", Number: 3}
+
+	urls, err := postEnvironmentComments(publisher, ctx, plans, ReportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(urls) != 3 || publisher.created != 3 {
+		t.Fatalf("Expected 2 environment comments and an index, got %v", urls)
+	}
+	if !strings.HasPrefix(publisher.comments[1].Body, environmentCommentMarker("prod")+"\n📁 **Environment:** `prod`\n\n") {
+		t.Errorf("Expected a marker and the environment on the environment comment, got:\n%s", publisher.comments[1].Body)
+	}
+	if strings.Contains(publisher.comments[1].Body, "Multi-Environment") {
+		t.Errorf("Expected a single-plan report in the environment comment, got:\n%s", publisher.comments[1].Body)
+	}
+	index := publisher.comments[3].Body
+	if !strings.HasPrefix(index, commentIndexMarker) || !strings.Contains(index, "| `prod` | 1 | 0 | 0 | 0 | [view](https://example.com/c/2) |") {
+		t.Errorf("Expected an index linking the environment comments, got:\n%s", index)
+	}
+
+	// A later run updates the same comments instead of adding new ones
+	if _, err := postEnvironmentComments(publisher, ctx, plans, ReportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if publisher.created != 3 || publisher.updated != 3 || publisher.comments[0].Body != "LGTM" {
+		t.Errorf("Expected sticky comments to be updated in place, got %d created, %d updated", publisher.created, publisher.updated)
+	}
+
+	// Environments missing from a later run are marked as unchanged
+	if _, err := postEnvironmentComments(publisher, ctx, plans[1:], ReportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if prod := publisher.comments[1].Body; !strings.Contains(prod, "No changes in this environment in the latest plan") || strings.Contains(prod, "aws_sqs_queue.q") {
+		t.Errorf("Expected the stale prod comment to be cleared, got:\n%s", prod)
+	}
+	if index := publisher.comments[3].Body; !strings.Contains(index, "| `prod` | 0 | 0 | 0 | 0 | [view](https://example.com/c/2) |") {
+		t.Errorf("Expected the cleared environment in the index, got:\n%s", index)
+	}
+}
+
+func TestPostEnvironmentCommentsIgnoresForeignMarkers(t *testing.T) {
+	spoofed := issueComment{ID: 1, Body: environmentCommentMarker("prod") + "\nfake plan"}
+	spoofed.User.Login = "mallory"
+	publisher := &fakePublisher{comments: []issueComment{spoofed}}
+	plans := []PlanInfo{{Plan: &TerraformPlan{}, RelativePath: "prod"}, {Plan: &TerraformPlan{}, RelativePath: "dev"}}
+
+	if _, err := postEnvironmentComments(publisher, PullRequestContext{Repository: "ops/infra", Number: 3}, plans, ReportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if publisher.updated != 0 || publisher.comments[0].Body != spoofed.Body {
+		t.Errorf("Expected another user's marker comment to be left alone, got %d updates", publisher.updated)
+	}
+}
+
+func TestFitComment(t *testing.T) {
+	if body := "short"; fitComment(body, "") != body {
+		t.Error("Expected short comments to be left alone")
+	}
+
+	body := "## Report\n\n```diff\n" + strings.Repeat("+ line\n", githubCommentLimit/7) + "```\n"
+	fitted := fitComment(body, "https://ci.example.com/runs/9")
+	if length := utf8.RuneCountInString(fitted); length > githubCommentLimit {
+		t.Errorf("Expected the comment to fit, got %d characters", length)
+	}
+	if !strings.Contains(fitted, "+ line\n```\n\n---\n⚠️ **Report truncated**") {
+		t.Errorf("Expected the open fence to be closed before the notice, got:\n%s", fitted[len(fitted)-300:])
+	}
+	if !strings.HasSuffix(fitted, "The full report is in the [CI run](https://ci.example.com/runs/9) output.") {
+		t.Errorf("Expected a link to the CI run, got:\n%s", fitted[len(fitted)-300:])
+	}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// Config is the optional JSON configuration file passed with -config
+type Config struct {
+	// IgnoreAttributes are attribute path patterns hidden for every resource
+	IgnoreAttributes []string `json:"ignore_attributes"`
+	// ResourceIgnoreAttributes maps resource type globs (e.g. "kubernetes_*")
+	// to attribute path patterns hidden for matching resources
+	ResourceIgnoreAttributes map[string][]string `json:"resource_ignore_attributes"`
+	// Risk configures risk scoring weights and thresholds
+	Risk RiskConfig `json:"risk"`
+	// Thresholds limits change counts per environment
+	Thresholds *ThresholdConfig `json:"thresholds"`
+	// Resources includes or excludes resources from the report and gating
+	Resources *ResourceFilter `json:"resources"`
+	// Security configures the security-relevant changes section
+	Security SecurityConfig `json:"security"`
+	// Naming configures naming convention rules per resource type
+	Naming *NamingConfig `json:"naming"`
+	// TagPolicy lists tags created and updated resources must carry
+	TagPolicy *TagPolicy `json:"tag_policy"`
+	// FailOn lists -fail-on rules, e.g. "delete:aws_kms_key", added to those given on the command line
+	FailOn []string `json:"fail_on"`
+	// EnvironmentNames maps environment relative paths to display names,
+	// e.g. "stacks/eu-west-1/prod" to "Production (Ireland)"
+	EnvironmentNames map[string]string `json:"environment_names"`
+	// EnvironmentOrder lists environment globs in report order, e.g. ["prod*", "staging*"];
+	// unmatched environments follow alphabetically
+	EnvironmentOrder []string `json:"environment_order"`
+	// Tiers group environments, e.g. production / staging / development, with
+	// subtotals and optionally collapsed sections
+	Tiers []TierConfig `json:"tiers"`
+	// PlannedAttributes maps resource type globs to the planned attributes
+	// rendered for created resources with -detail full
+	PlannedAttributes map[string][]string `json:"planned_attributes"`
+	// Approval configures plan approval by reaction or comment in server mode
+	Approval *ApprovalConfig `json:"approval"`
+	// DestructiveAck requires sign-off on deletes and replacements in server mode
+	DestructiveAck *AcknowledgementConfig `json:"destructive_ack"`
+	// Reviewers request reviews when posted plans touch matching resources
+	Reviewers []ReviewerRule `json:"reviewers"`
+	// Owners maps module and address patterns to teams mentioned in the
+	// environments where their resources change
+	Owners []OwnershipRule `json:"owners"`
+}
+
+// IgnoreRules are the compiled attribute ignore patterns from the configuration
+type IgnoreRules struct {
+	global []*regexp.Regexp
+	byType []typeIgnoreRule
+}
+
+type typeIgnoreRule struct {
+	typePattern string
+	attributes  []*regexp.Regexp
+}
+
+// listIndexPattern matches list indexes in attribute paths
+var listIndexPattern = regexp.MustCompile(`\[\d+\]`)
+
+func loadConfig(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := Config{Risk: defaultRiskConfig()}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	if config.Thresholds != nil {
+		if err := config.Thresholds.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range config.Security.Types {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid security type pattern %q: %w", pattern, err)
+		}
+	}
+	if config.Resources != nil {
+		if err := config.Resources.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if config.TagPolicy != nil {
+		if err := config.TagPolicy.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if config.Naming != nil {
+		if err := config.Naming.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, pattern := range config.EnvironmentOrder {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid environment order pattern %q: %w", pattern, err)
+		}
+	}
+	for pattern := range config.PlannedAttributes {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid planned attributes type pattern %q: %w", pattern, err)
+		}
+	}
+	if config.Approval != nil {
+		if err := config.Approval.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if config.DestructiveAck != nil {
+		if err := config.DestructiveAck.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range config.Reviewers {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, rule := range config.Owners {
+		if err := rule.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateTiers(config.Tiers); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+// applyTo sets the report options the configuration controls
+func (c *Config) applyTo(opts *ReportOptions) error {
+	var err error
+	if opts.IgnoreRules, err = c.ignoreRules(); err != nil {
+		return err
+	}
+	if opts.Risk, err = c.Risk.riskModel(); err != nil {
+		return err
+	}
+	opts.Thresholds = c.Thresholds
+	opts.SecurityTypes = c.Security.Types
+	opts.Naming = c.Naming
+	opts.TagPolicy = c.TagPolicy
+	opts.EnvironmentNames = c.EnvironmentNames
+	opts.EnvironmentOrder = c.EnvironmentOrder
+	opts.Tiers = c.Tiers
+	opts.PlannedAttributes = c.PlannedAttributes
+	opts.Approval = c.Approval
+	opts.Acknowledgement = c.DestructiveAck
+	opts.Reviewers = c.Reviewers
+	opts.Owners = c.Owners
+	return nil
+}
+
+// ignoreRules compiles the configured ignore patterns
+func (c *Config) ignoreRules() (*IgnoreRules, error) {
+	rules := &IgnoreRules{}
+
+	var err error
+	if rules.global, err = compileAttributePatterns(c.IgnoreAttributes); err != nil {
+		return nil, err
+	}
+
+	typePatterns := make([]string, 0, len(c.ResourceIgnoreAttributes))
+	for typePattern := range c.ResourceIgnoreAttributes {
+		if _, err := path.Match(typePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type pattern %q: %w", typePattern, err)
+		}
+		typePatterns = append(typePatterns, typePattern)
+	}
+	sort.Strings(typePatterns)
+
+	for _, typePattern := range typePatterns {
+		attributes, err := compileAttributePatterns(c.ResourceIgnoreAttributes[typePattern])
+		if err != nil {
+			return nil, err
+		}
+		rules.byType = append(rules.byType, typeIgnoreRule{typePattern: typePattern, attributes: attributes})
+	}
+
+	return rules, nil
+}
+
+// compileAttributePatterns compiles attribute path regexes. A pattern matches
+// the whole path or any parent of it, so "metadata" also hides metadata.labels.
+func compileAttributePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(`^(?:` + pattern + `)(?:$|[.\[])`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attribute pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// ignores reports whether an attribute of the given resource type should be
+// hidden. Patterns are tried against the path both with and without list
+// indexes, so "metadata.generation" matches metadata[0].generation.
+func (r *IgnoreRules) ignores(resourceType, attribute string) bool {
+	if r == nil {
+		return false
+	}
+
+	paths := []string{attribute}
+	if stripped := listIndexPattern.ReplaceAllString(attribute, ""); stripped != attribute {
+		paths = append(paths, stripped)
+	}
+
+	matches := func(patterns []*regexp.Regexp) bool {
+		for _, re := range patterns {
+			for _, p := range paths {
+				if re.MatchString(p) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	if matches(r.global) {
+		return true
+	}
+	for _, rule := range r.byType {
+		if ok, _ := path.Match(rule.typePattern, resourceType); ok && matches(rule.attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyIgnoreRules removes ignored attribute changes from the summary
+func applyIgnoreRules(summary *ResourceSummary, plan *TerraformPlan, rules *IgnoreRules) {
+	if rules == nil {
+		return
+	}
+
+	types := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		types[rc.Address] = rc.Type
+	}
+
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range details {
+			var kept []AttributeChange
+			for _, change := range details[i].Changes {
+				if !rules.ignores(types[details[i].Address], change.Attribute) {
+					kept = append(kept, change)
+				}
+			}
+			details[i].Changes = kept
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigIgnoreRules(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{
+		"ignore_attributes": ["tags\\.LastModified"],
+		"resource_ignore_attributes": {"kubernetes_*": ["metadata.generation", "metadata.resource_version"]}
+	}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	rules, err := config.ignoreRules()
+	if err != nil {
+		t.Fatalf("Failed to compile rules: %v", err)
+	}
+
+	tests := []struct {
+		resourceType string
+		attribute    string
+		expected     bool
+	}{
+		{"aws_instance", "tags.LastModified", true},
+		{"aws_instance", "tags.LastModifiedBy", false},
+		{"kubernetes_deployment", "metadata[0].generation", true},
+		{"kubernetes_deployment", "metadata[0].labels.app", false},
+		{"aws_instance", "metadata[0].generation", false},
+	}
+	for _, tt := range tests {
+		if got := rules.ignores(tt.resourceType, tt.attribute); got != tt.expected {
+			t.Errorf("ignores(%s, %s) = %v, want %v", tt.resourceType, tt.attribute, got, tt.expected)
+		}
+	}
+}
+
+func TestInvalidIgnorePattern(t *testing.T) {
+	config := &Config{IgnoreAttributes: []string{"tags("}}
+	if _, err := config.ignoreRules(); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestApplyIgnoreRules(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "kubernetes_deployment.app",
+				Type:    "kubernetes_deployment",
+				Change: Change{
+					Actions: []string{"update"},
+					Before: map[string]interface{}{"metadata": []interface{}{
+						map[string]interface{}{"generation": float64(3), "name": "app"},
+					}},
+					After: map[string]interface{}{"metadata": []interface{}{
+						map[string]interface{}{"generation": float64(4), "name": "app-v2"},
+					}},
+				},
+			},
+		},
+	}
+
+	config := &Config{ResourceIgnoreAttributes: map[string][]string{"kubernetes_*": {"metadata"}}}
+	rules, err := config.ignoreRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := analyzePlan(plan)
+	applyIgnoreRules(&summary, plan, rules)
+	if len(summary.Update) != 1 || len(summary.Update[0].Changes) != 0 {
+		t.Errorf("Expected metadata changes to be ignored, got %+v", summary.Update)
+	}
+}
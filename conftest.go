@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ConftestResult is one entry of `conftest test -o json` output
+type ConftestResult struct {
+	Filename  string            `json:"filename"`
+	Namespace string            `json:"namespace"`
+	Failures  []ConftestMessage `json:"failures"`
+	Warnings  []ConftestMessage `json:"warnings"`
+}
+
+// ConftestMessage is a single policy result message
+type ConftestMessage struct {
+	Msg      string                 `json:"msg"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+// conftestAddressKeys are metadata keys policies commonly use for the resource address
+var conftestAddressKeys = []string{"address", "resource", "resource_address"}
+
+func readConftestResults(filename string) ([]ExternalFinding, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy results: %w", err)
+	}
+
+	var results []ConftestResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse conftest JSON: %w", err)
+	}
+	return convertConftestResults(results), nil
+}
+
+func convertConftestResults(results []ConftestResult) []ExternalFinding {
+	var findings []ExternalFinding
+	for _, result := range results {
+		for _, group := range []struct {
+			messages []ConftestMessage
+			level    string
+		}{
+			{result.Failures, findingFailure},
+			{result.Warnings, findingWarning},
+		} {
+			for _, message := range group.messages {
+				finding := ExternalFinding{
+					Tool:    "conftest",
+					Level:   group.level,
+					Message: message.Msg,
+					File:    result.Filename,
+				}
+				if result.Namespace != "" && result.Namespace != "main" {
+					finding.RuleID = result.Namespace
+				}
+				for _, key := range conftestAddressKeys {
+					if address, ok := message.Metadata[key].(string); ok {
+						finding.Address = address
+						break
+					}
+				}
+				findings = append(findings, finding)
+			}
+		}
+	}
+	return findings
+}
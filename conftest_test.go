@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadConftestResults(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "conftest.json")
+	data := `[{
+		"filename": "plan.json",
+		"namespace": "terraform.s3",
+		"successes": 3,
+		"failures": [{"msg": "bucket must be encrypted", "metadata": {"resource": "aws_s3_bucket.logs"}}],
+		"warnings": [{"msg": "aws_instance.web uses a previous generation type"}]
+	}]`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := readConftestResults(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Level != findingFailure || findings[0].Address != "aws_s3_bucket.logs" || findings[0].RuleID != "terraform.s3" {
+		t.Errorf("Unexpected failure finding: %+v", findings[0])
+	}
+	if findings[1].Level != findingWarning || findings[1].Address != "" {
+		t.Errorf("Unexpected warning finding: %+v", findings[1])
+	}
+
+	if err := os.WriteFile(file, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readConftestResults(file); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestPolicyResultsInReport(t *testing.T) {
+	plan := &TerraformPlan{
+		PlanPath: "envs/prod/plan.json",
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_instance.web2", Type: "aws_instance", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+	findings := []ExternalFinding{
+		{Tool: "conftest", Level: findingFailure, Message: "aws_instance.web2 has no owner tag", File: "plan.json"},
+		{Tool: "conftest", Level: findingWarning, Message: "provider version is not pinned"},
+		{Tool: "conftest", Level: findingFailure, Message: "other plan", File: "staging/plan.json"},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{Findings: findings})
+	if !strings.Contains(result, "- `aws_instance.web2`\n  - 🚫 **conftest:** aws_instance.web2 has no owner tag\n") {
+		t.Errorf("Expected finding under the matching resource, got:\n%s", result)
+	}
+	if !strings.Contains(result, "### 🛡️ Policy and Scanner Findings\n\n- ⚠️ **conftest:** provider version is not pinned\n") {
+		t.Errorf("Expected unattached finding section, got:\n%s", result)
+	}
+	if strings.Contains(result, "other plan") {
+		t.Errorf("Expected finding for another plan to be skipped, got:\n%s", result)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// infracostRun as the -infracost value runs Infracost against each plan
+// instead of reading an existing report
+const infracostRun = "run"
+
+// InfracostReport is the JSON output of `infracost breakdown` or `infracost diff`
+type InfracostReport struct {
+	Currency string             `json:"currency"`
+	Projects []InfracostProject `json:"projects"`
+}
+
+// InfracostProject holds the costs Infracost estimated for one plan
+type InfracostProject struct {
+	Name     string `json:"name"`
+	Metadata struct {
+		Path string `json:"path"`
+	} `json:"metadata"`
+	PastBreakdown *InfracostBreakdown `json:"pastBreakdown"`
+	Breakdown     *InfracostBreakdown `json:"breakdown"`
+}
+
+// InfracostBreakdown holds a project's monthly cost as a decimal string
+type InfracostBreakdown struct {
+	TotalMonthlyCost *string `json:"totalMonthlyCost"`
+}
+
+// CostEstimate is the monthly cost of a plan's resources before and after apply
+type CostEstimate struct {
+	Before   float64
+	After    float64
+	Currency string
+}
+
+// Delta returns the monthly cost change
+func (c CostEstimate) Delta() float64 {
+	return c.After - c.Before
+}
+
+func readInfracostReport(filename string) (*InfracostReport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Infracost report: %w", err)
+	}
+
+	var report InfracostReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse Infracost JSON: %w", err)
+	}
+	return &report, nil
+}
+
+// runInfracost estimates the cost of each plan with the infracost CLI.
+// Plans it fails on are reported and left without cost information.
+func runInfracost(plans []PlanInfo) (*InfracostReport, error) {
+	if _, err := exec.LookPath("infracost"); err != nil {
+		return nil, fmt.Errorf("infracost not found in PATH")
+	}
+
+	report := &InfracostReport{}
+	for _, planInfo := range plans {
+		output, err := exec.Command("infracost", "breakdown", "--path", planInfo.Plan.PlanPath, "--format", "json").Output()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: infracost failed for %s: %v\n", planInfo.Plan.PlanPath, err)
+			continue
+		}
+
+		var result InfracostReport
+		if err := json.Unmarshal(output, &result); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to parse infracost output for %s: %v\n", planInfo.Plan.PlanPath, err)
+			continue
+		}
+		for _, project := range result.Projects {
+			project.Metadata.Path = planInfo.Plan.PlanPath
+			report.Projects = append(report.Projects, project)
+		}
+		report.Currency = result.Currency
+	}
+	return report, nil
+}
+
+// runCostEstimation runs Infracost on the plans, continuing without cost
+// information when it isn't available
+func runCostEstimation(plans []PlanInfo) *InfracostReport {
+	report, err := runInfracost(plans)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: skipping cost estimation: %v\n", err)
+		return nil
+	}
+	return report
+}
+
+// estimateFor returns the cost estimate of a plan. A report with a single
+// project applies to any plan; otherwise projects are matched by plan path.
+func (r *InfracostReport) estimateFor(plan *TerraformPlan) (CostEstimate, bool) {
+	if r == nil {
+		return CostEstimate{}, false
+	}
+
+	for _, project := range r.Projects {
+		if len(r.Projects) > 1 && !samePlanFile(project.Metadata.Path, plan.PlanPath) {
+			continue
+		}
+		if project.Breakdown == nil || project.Breakdown.TotalMonthlyCost == nil {
+			return CostEstimate{}, false
+		}
+
+		estimate := CostEstimate{Currency: r.Currency}
+		estimate.After, _ = strconv.ParseFloat(*project.Breakdown.TotalMonthlyCost, 64)
+		if project.PastBreakdown != nil && project.PastBreakdown.TotalMonthlyCost != nil {
+			estimate.Before, _ = strconv.ParseFloat(*project.PastBreakdown.TotalMonthlyCost, 64)
+		}
+		return estimate, true
+	}
+	return CostEstimate{}, false
+}
+
+// formatMoney renders an amount in the report currency, e.g. $1,234.50
+func formatMoney(amount float64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	whole, cents, _ := strings.Cut(strconv.FormatFloat(amount, 'f', 2, 64), ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+
+	if currency == "" || currency == "USD" {
+		return fmt.Sprintf("%s$%s.%s", sign, whole, cents)
+	}
+	return fmt.Sprintf("%s%s.%s %s", sign, whole, cents, currency)
+}
+
+// formatCostDelta renders a monthly cost change with an explicit sign
+func formatCostDelta(delta float64, currency string) string {
+	if delta > 0 {
+		return "+" + formatMoney(delta, currency)
+	}
+	return formatMoney(delta, currency)
+}
+
+// formatCostImpact describes a plan's monthly cost change
+func formatCostImpact(estimate CostEstimate) string {
+	return fmt.Sprintf("**💵 Monthly cost impact:** %s (%s → %s)",
+		formatCostDelta(estimate.Delta(), estimate.Currency),
+		formatMoney(estimate.Before, estimate.Currency),
+		formatMoney(estimate.After, estimate.Currency))
+}
+
+// writeCostSection renders the monthly cost delta of each environment
+func writeCostSection(md *strings.Builder, plans []PlanInfo, report *InfracostReport, names map[string]string) {
+	var total CostEstimate
+	var rows []string
+	for _, planInfo := range plans {
+		estimate, ok := report.estimateFor(planInfo.Plan)
+		if !ok {
+			continue
+		}
+		total.Before += estimate.Before
+		total.After += estimate.After
+		total.Currency = estimate.Currency
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s |",
+			formatEnvironment(planInfo.RelativePath, names),
+			formatMoney(estimate.Before, estimate.Currency),
+			formatMoney(estimate.After, estimate.Currency),
+			formatCostDelta(estimate.Delta(), estimate.Currency)))
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	md.WriteString("### 💵 Cost Impact\n\n")
+	md.WriteString(formatCostImpact(total) + "\n\n")
+	md.WriteString("| Environment | Monthly Before | Monthly After | Change |\n")
+	md.WriteString("|-------------|----------------|---------------|--------|\n")
+	for _, row := range rows {
+		md.WriteString(row + "\n")
+	}
+	md.WriteString("\n")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		expected string
+	}{
+		{0, "USD", "$0.00"},
+		{1234.5, "USD", "$1,234.50"},
+		{-1234567.891, "", "-$1,234,567.89"},
+		{12, "EUR", "12.00 EUR"},
+	}
+
+	for _, tt := range tests {
+		if got := formatMoney(tt.amount, tt.currency); got != tt.expected {
+			t.Errorf("formatMoney(%v, %q) = %q, expected %q", tt.amount, tt.currency, got, tt.expected)
+		}
+	}
+}
+
+func TestInfracostCostImpact(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "infracost.json")
+	data := `{
+		"currency": "USD",
+		"projects": [
+			{"name": "prod", "metadata": {"path": "/repo/tfplans/prod/tfplan.json"},
+			 "pastBreakdown": {"totalMonthlyCost": "100"}, "breakdown": {"totalMonthlyCost": "142.5"}},
+			{"name": "dev", "metadata": {"path": "/repo/tfplans/dev/tfplan.json"},
+			 "pastBreakdown": {"totalMonthlyCost": "20"}, "breakdown": {"totalMonthlyCost": "15"}}
+		]
+	}`
+	if err := os.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := readInfracostReport(file)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	change := []ResourceChange{{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"create"}}}}
+	plans := []PlanInfo{
+		{Plan: &TerraformPlan{PlanPath: "tfplans/dev/tfplan.json", ResourceChanges: change}, RelativePath: "dev"},
+		{Plan: &TerraformPlan{PlanPath: "tfplans/prod/tfplan.json", ResourceChanges: change}, RelativePath: "prod"},
+		{Plan: &TerraformPlan{PlanPath: "tfplans/qa/tfplan.json", ResourceChanges: change}, RelativePath: "qa"},
+	}
+
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{Costs: report})
+	expected := "### 💵 Cost Impact\n\n" +
+		"**💵 Monthly cost impact:** +$37.50 ($120.00 → $157.50)\n\n" +
+		"| Environment | Monthly Before | Monthly After | Change |\n" +
+		"|-------------|----------------|---------------|--------|\n" +
+		"| `dev` | $20.00 | $15.00 | -$5.00 |\n" +
+		"| `prod` | $100.00 | $142.50 | +$42.50 |\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected cost section, got:\n%s", result)
+	}
+	if !strings.Contains(result, "**💵 Monthly cost impact:** +$42.50 ($100.00 → $142.50)\n\n| Action") {
+		t.Errorf("Expected per-environment cost line, got:\n%s", result)
+	}
+
+	// A single project applies to a single plan regardless of path
+	single := &InfracostReport{Projects: report.Projects[:1]}
+	result = generateMarkdownComment(plans[2].Plan, ReportOptions{Costs: single})
+	if !strings.Contains(result, "**💵 Monthly cost impact:** +$42.50") {
+		t.Errorf("Expected cost line in single plan report, got:\n%s", result)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// instanceSizeAttributes hold instance, node or VM sizes across providers
+var instanceSizeAttributes = map[string]bool{
+	"instance_type":  true,
+	"instance_class": true,
+	"node_type":      true,
+	"machine_type":   true,
+	"vm_size":        true,
+	"size":           true,
+}
+
+// storageSizeAttributes hold provisioned storage sizes
+var storageSizeAttributes = map[string]bool{
+	"allocated_storage": true,
+	"volume_size":       true,
+	"size":              true,
+	"disk_size":         true,
+	"disk_size_gb":      true,
+	"storage_mb":        true,
+}
+
+// provisionedIOPSAttributes hold provisioned IOPS or throughput, billed on top of storage
+var provisionedIOPSAttributes = map[string]bool{
+	"iops":                            true,
+	"provisioned_iops":                true,
+	"throughput":                      true,
+	"provisioned_throughput_in_mibps": true,
+}
+
+// provisionedIOPSStorageTypes are storage types billed for provisioned IOPS
+var provisionedIOPSStorageTypes = map[string]bool{"io1": true, "io2": true}
+
+// natGatewayTypes are NAT gateway resources, billed hourly and per GB processed
+var natGatewayTypes = map[string]bool{
+	"aws_nat_gateway":           true,
+	"azurerm_nat_gateway":       true,
+	"google_compute_router_nat": true,
+}
+
+// awsInstanceSizes gives the relative capacity of AWS size suffixes
+var awsInstanceSizes = map[string]float64{
+	"nano":   1.0 / 16,
+	"micro":  1.0 / 8,
+	"small":  1.0 / 4,
+	"medium": 1.0 / 2,
+	"large":  1,
+	"xlarge": 2,
+	"metal":  1000,
+}
+
+var (
+	awsMultipleSizePattern = regexp.MustCompile(`^(\d+)xlarge$`)
+	sizeVersionPattern     = regexp.MustCompile(`[_-]v\d+$`)
+	sizeNumberPattern      = regexp.MustCompile(`\d+`)
+)
+
+// CostSignal lists the reasons a resource change is likely to raise costs
+type CostSignal struct {
+	Address string
+	Reasons []string
+}
+
+// analyzeCostSignals detects cost-relevant changes from before/after values
+// when no cost estimate is available
+func analyzeCostSignals(plan *TerraformPlan) []CostSignal {
+	var signals []CostSignal
+	for _, rc := range plan.ResourceChanges {
+		var reasons []string
+		switch planAction(rc.Change.Actions) {
+		case "create":
+			if natGatewayTypes[rc.Type] {
+				reasons = append(reasons, "new NAT gateway (hourly and data processing charges)")
+			}
+		case "update", "replace":
+			for _, change := range analyzeAttributeChanges(rc.Change) {
+				if reason := costReason(change); reason != "" {
+					reasons = append(reasons, reason)
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			signals = append(signals, CostSignal{Address: rc.Address, Reasons: reasons})
+		}
+	}
+	return signals
+}
+
+// costReason describes why an attribute change affects cost, or returns ""
+func costReason(change AttributeChange) string {
+	name := lastPathSegment(change.Attribute)
+
+	if before, ok := change.Before.(string); ok && instanceSizeAttributes[name] {
+		after, _ := change.After.(string)
+		beforeSize, beforeOk := instanceSize(before)
+		afterSize, afterOk := instanceSize(after)
+		if beforeOk && afterOk && afterSize > beforeSize {
+			return fmt.Sprintf("`%s` upsized: %s → %s", change.Attribute, before, after)
+		}
+		return ""
+	}
+
+	if storageSizeAttributes[name] {
+		before, beforeOk := toFloat(change.Before)
+		after, afterOk := toFloat(change.After)
+		if beforeOk && afterOk && after > before {
+			return fmt.Sprintf("`%s` increased: %s → %s", change.Attribute, formatAttributeValue(change.Before), formatAttributeValue(change.After))
+		}
+		return ""
+	}
+
+	if provisionedIOPSAttributes[name] {
+		before, _ := toFloat(change.Before)
+		after, ok := toFloat(change.After)
+		if ok && after > before && change.IsNew {
+			return fmt.Sprintf("`%s` provisioned: %s", change.Attribute, formatAttributeValue(change.After))
+		}
+		if ok && after > before {
+			return fmt.Sprintf("`%s` increased: %s → %s", change.Attribute, formatAttributeValue(change.Before), formatAttributeValue(change.After))
+		}
+		return ""
+	}
+
+	if name == "storage_type" || name == "volume_type" {
+		after, _ := change.After.(string)
+		before, _ := change.Before.(string)
+		if provisionedIOPSStorageTypes[after] && !provisionedIOPSStorageTypes[before] {
+			return fmt.Sprintf("`%s` switched to provisioned IOPS storage (%s)", change.Attribute, after)
+		}
+	}
+	return ""
+}
+
+// instanceSize returns the relative capacity of an instance size: the size
+// suffix of AWS types (t3.large), otherwise the vCPU count of GCP and Azure
+// types (n2-standard-8, Standard_D4s_v3)
+func instanceSize(value string) (float64, bool) {
+	if i := strings.LastIndex(value, "."); i >= 0 {
+		suffix := value[i+1:]
+		if size, ok := awsInstanceSizes[suffix]; ok {
+			return size, true
+		}
+		if m := awsMultipleSizePattern.FindStringSubmatch(suffix); m != nil {
+			multiple, _ := strconv.ParseFloat(m[1], 64)
+			return 2 * multiple, true
+		}
+		return 0, false
+	}
+
+	numbers := sizeNumberPattern.FindAllString(sizeVersionPattern.ReplaceAllString(value, ""), -1)
+	if len(numbers) == 0 {
+		return 0, false
+	}
+	size, err := strconv.ParseFloat(numbers[len(numbers)-1], 64)
+	return size, err == nil
+}
+
+// annotateCostSignals marks resources with cost-affecting changes
+func annotateCostSignals(summary *ResourceSummary, plan *TerraformPlan) {
+	affected := make(map[string]bool)
+	for _, signal := range analyzeCostSignals(plan) {
+		affected[signal.Address] = true
+	}
+	if len(affected) == 0 {
+		return
+	}
+
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range details {
+			details[i].CostAffecting = affected[details[i].Address]
+		}
+	}
+}
+
+// writeCostSignalsSection renders the rollup of cost-affecting changes
+func writeCostSignalsSection(md *strings.Builder, plan *TerraformPlan) {
+	signals := analyzeCostSignals(plan)
+	if len(signals) == 0 {
+		return
+	}
+
+	md.WriteString("### 💰 Cost-affecting Changes\n\n")
+	writeCostSignals(md, signals)
+}
+
+// writeEnvironmentCostSignalsSection renders cost-affecting changes inside a multi-plan environment section
+func writeEnvironmentCostSignalsSection(md *strings.Builder, plan *TerraformPlan) {
+	signals := analyzeCostSignals(plan)
+	if len(signals) == 0 {
+		return
+	}
+
+	md.WriteString("**💰 Cost-affecting changes:**\n")
+	writeCostSignals(md, signals)
+}
+
+func writeCostSignals(md *strings.Builder, signals []CostSignal) {
+	for _, signal := range signals {
+		md.WriteString(fmt.Sprintf("- 💰 `%s` - %s\n", signal.Address, strings.Join(signal.Reasons, "; ")))
+	}
+	md.WriteString("\n")
+}
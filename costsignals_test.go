@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstanceSize(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected float64
+		ok       bool
+	}{
+		{"t3.micro", 1.0 / 8, true},
+		{"db.r5.large", 1, true},
+		{"m5.4xlarge", 8, true},
+		{"n2-standard-8", 8, true},
+		{"Standard_D4s_v3", 4, true},
+		{"t3.unknown", 0, false},
+		{"shared-core", 0, false},
+	}
+
+	for _, tt := range tests {
+		size, ok := instanceSize(tt.value)
+		if ok != tt.ok || size != tt.expected {
+			t.Errorf("instanceSize(%q) = %v, %v; expected %v, %v", tt.value, size, ok, tt.expected, tt.ok)
+		}
+	}
+}
+
+func TestCostSignals(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_nat_gateway.main", Type: "aws_nat_gateway", Change: Change{Actions: []string{"create"}}},
+			{
+				Address: "aws_db_instance.main",
+				Type:    "aws_db_instance",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_class": "db.t3.medium", "allocated_storage": float64(20), "storage_type": "gp3"},
+					After:   map[string]interface{}{"instance_class": "db.r5.xlarge", "allocated_storage": float64(100), "storage_type": "io2", "iops": float64(3000)},
+				},
+			},
+			{
+				Address: "aws_instance.web",
+				Type:    "aws_instance",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "m5.large"},
+					After:   map[string]interface{}{"instance_type": "m5.medium"},
+				},
+			},
+		},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	expected := "### 💰 Cost-affecting Changes\n\n" +
+		"- 💰 `aws_nat_gateway.main` - new NAT gateway (hourly and data processing charges)\n" +
+		"- 💰 `aws_db_instance.main` - `allocated_storage` increased: 20 → 100; " +
+		"`instance_class` upsized: db.t3.medium → db.r5.xlarge; " +
+		"`iops` provisioned: 3000; " +
+		"`storage_type` switched to provisioned IOPS storage (io2)\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected cost-affecting rollup, got:\n%s", result)
+	}
+	if !strings.Contains(result, "#### `aws_db_instance.main` 💰\n") {
+		t.Errorf("Expected cost indicator on resource, got:\n%s", result)
+	}
+	if strings.Contains(result, "`aws_instance.web` 💰") {
+		t.Errorf("Expected downsizing not to be flagged, got:\n%s", result)
+	}
+}
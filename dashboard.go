@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// defaultDashboardDir is written by the dashboard subcommand when no output directory is given
+const defaultDashboardDir = "dashboard"
+
+// Dashboard chart and table sizes
+const (
+	chartWidth         = 640
+	chartHeight        = 120
+	riskiestPlansLimit = 10
+	riskiestPlansDays  = 30
+)
+
+// dashboardChart is one environment's changes per run
+type dashboardChart struct {
+	Environment string
+	Runs        int
+	Changes     int
+	Bars        []dashboardBar
+}
+
+// dashboardBar is one run in a chart, in SVG coordinates
+type dashboardBar struct {
+	X, Y, Width, Height float64
+	Title               string
+}
+
+type dashboardData struct {
+	Generated     string
+	Period        string
+	Charts        []dashboardChart
+	RiskiestPlans []HistoryRecord
+	RiskiestDays  int
+	Width, Height int
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Terraform Change Dashboard</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 960px; color: #1f2328; }
+h1 { font-size: 1.6em; }
+h2 { font-size: 1.25em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 6px 12px; text-align: left; }
+th { background: #f6f8fa; }
+svg { background: #f6f8fa; border: 1px solid #d0d7de; }
+rect { fill: #0969da; }
+.meta { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>📈 Terraform Change Dashboard</h1>
+<p class="meta">{{.Period}} · generated {{.Generated}}</p>
+
+<h2>🚨 Riskiest Plans (last {{.RiskiestDays}} days)</h2>
+{{if .RiskiestPlans}}<table>
+<tr><th>Date</th><th>Environment</th><th>Risk</th><th>Create</th><th>Update</th><th>Replace</th><th>Delete</th></tr>
+{{range .RiskiestPlans}}<tr><td>{{.Time.Format "2006-01-02 15:04"}}</td><td><code>{{.Environment}}</code></td><td>{{.RiskLevel}} ({{printf "%.0f" .RiskScore}})</td><td>{{.Create}}</td><td>{{.Update}}</td><td>{{.Replace}}</td><td>{{.Delete}}</td></tr>
+{{end}}</table>{{else}}<p>No plans with changes in this period.</p>{{end}}
+
+<h2>📊 Changes per Environment</h2>
+{{range .Charts}}<h3><code>{{.Environment}}</code></h3>
+<p class="meta">{{.Runs}} run(s), {{.Changes}} resource change(s)</p>
+<svg width="{{$.Width}}" height="{{$.Height}}" viewBox="0 0 {{$.Width}} {{$.Height}}" role="img" aria-label="Changes per run in {{.Environment}}">
+{{range .Bars}}<rect x="{{printf "%.1f" .X}}" y="{{printf "%.1f" .Y}}" width="{{printf "%.1f" .Width}}" height="{{printf "%.1f" .Height}}"><title>{{.Title}}</title></rect>
+{{end}}</svg>
+{{end}}
+</body>
+</html>
+`))
+
+// dashboardCharts builds a bar chart of changes per run for each
+// environment, most changed environments first
+func dashboardCharts(records []HistoryRecord) []dashboardChart {
+	byEnv := make(map[string][]HistoryRecord)
+	for _, record := range records {
+		byEnv[record.Environment] = append(byEnv[record.Environment], record)
+	}
+
+	var charts []dashboardChart
+	for _, trend := range environmentTrends(records) {
+		runs := byEnv[trend.Environment]
+		sort.SliceStable(runs, func(i, j int) bool {
+			return runs[i].Time.Before(runs[j].Time)
+		})
+
+		peak := 1
+		for _, run := range runs {
+			peak = max(peak, run.Total())
+		}
+
+		chart := dashboardChart{Environment: trend.Environment, Runs: len(runs), Changes: trend.Changes}
+		slot := float64(chartWidth) / float64(len(runs))
+		for i, run := range runs {
+			height := float64(run.Total()) / float64(peak) * (chartHeight - 10)
+			chart.Bars = append(chart.Bars, dashboardBar{
+				X:      float64(i)*slot + slot*0.1,
+				Y:      chartHeight - height,
+				Width:  slot * 0.8,
+				Height: height,
+				Title: fmt.Sprintf("%s: +%d ~%d ±%d -%d",
+					run.Time.Format("2006-01-02 15:04"), run.Create, run.Update, run.Replace, run.Delete),
+			})
+		}
+		charts = append(charts, chart)
+	}
+	return charts
+}
+
+// riskiestPlans returns the highest scoring runs with changes recorded in the
+// days before the latest record
+func riskiestPlans(records []HistoryRecord, days, limit int) []HistoryRecord {
+	var latest time.Time
+	for _, record := range records {
+		if record.Time.After(latest) {
+			latest = record.Time
+		}
+	}
+	cutoff := latest.AddDate(0, 0, -days)
+
+	var recent []HistoryRecord
+	for _, record := range records {
+		if record.Total() > 0 && !record.Time.Before(cutoff) {
+			recent = append(recent, record)
+		}
+	}
+	sort.SliceStable(recent, func(i, j int) bool {
+		if recent[i].RiskScore != recent[j].RiskScore {
+			return recent[i].RiskScore > recent[j].RiskScore
+		}
+		return recent[i].Time.After(recent[j].Time)
+	})
+	if len(recent) > limit {
+		recent = recent[:limit]
+	}
+	return recent
+}
+
+// generateDashboardHTML renders the history as a static HTML page
+func generateDashboardHTML(records []HistoryRecord, now time.Time) (string, error) {
+	data := dashboardData{
+		Generated:     now.UTC().Format("2006-01-02 15:04 MST"),
+		Period:        "No runs recorded yet",
+		Charts:        dashboardCharts(records),
+		RiskiestPlans: riskiestPlans(records, riskiestPlansDays, riskiestPlansLimit),
+		RiskiestDays:  riskiestPlansDays,
+		Width:         chartWidth,
+		Height:        chartHeight,
+	}
+	if len(records) > 0 {
+		first, last := records[0].Time, records[0].Time
+		for _, record := range records {
+			if record.Time.Before(first) {
+				first = record.Time
+			}
+			if record.Time.After(last) {
+				last = record.Time
+			}
+		}
+		data.Period = fmt.Sprintf("%s to %s", first.Format("2006-01-02"), last.Format("2006-01-02"))
+	}
+
+	var html strings.Builder
+	if err := dashboardTemplate.Execute(&html, data); err != nil {
+		return "", fmt.Errorf("failed to render dashboard: %w", err)
+	}
+	return html.String(), nil
+}
+
+// runDashboardCommand implements `tfplan-commenter dashboard <history-file> [output-dir]`
+func runDashboardCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter dashboard <history-file> [output-dir]")
+		os.Exit(1)
+	}
+
+	outputDir := defaultDashboardDir
+	if len(args) > 1 {
+		outputDir = args[1]
+	}
+
+	records, err := readHistory(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		os.Exit(1)
+	}
+	html, err := generateDashboardHTML(records, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating output directory: %v\n", err)
+		os.Exit(1)
+	}
+	outputFile := filepath.Join(outputDir, "index.html")
+	if err := os.WriteFile(outputFile, []byte(html), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing dashboard: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Dashboard generated from %d record(s): %s\n", len(records), outputFile)
+}
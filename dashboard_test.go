@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateDashboardHTML(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	records := []HistoryRecord{
+		{Time: now.AddDate(0, 0, -60), Environment: "prod", Delete: 9, RiskScore: 90, RiskLevel: "HIGH"},
+		{Time: now.AddDate(0, 0, -2), Environment: "prod", Delete: 1, RiskScore: 40, RiskLevel: "HIGH"},
+		{Time: now.AddDate(0, 0, -1), Environment: "dev<script>", Create: 2, RiskScore: 2, RiskLevel: "LOW"},
+		{Time: now, Environment: "dev<script>", RiskScore: 0, RiskLevel: "LOW"},
+	}
+
+	html, err := generateDashboardHTML(records, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	riskiest := riskiestPlans(records, riskiestPlansDays, riskiestPlansLimit)
+	if len(riskiest) != 2 || riskiest[0].RiskScore != 40 || riskiest[1].Environment != "dev<script>" {
+		t.Errorf("Expected recent plans with changes by risk, got %+v", riskiest)
+	}
+
+	for _, expected := range []string{
+		"2026-01-30 to 2026-03-31",
+		"<td>HIGH (40)</td>",
+		"<code>dev&lt;script&gt;</code>",
+		`<rect x="32.0" y="10.0" width="256.0" height="110.0"><title>2026-01-30 12:00: &#43;0 ~0 ±0 -9</title></rect>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in dashboard, got:\n%s", expected, html)
+		}
+	}
+	if strings.Contains(html, "<script>") {
+		t.Error("Expected environment names to be escaped")
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// decorateReport prepends the -header-file content and appends the
+// -footer-text to a rendered report, each on its own paragraph
+func decorateReport(markdown, header, footer string) string {
+	if header = strings.TrimSpace(header); header != "" {
+		markdown = header + "\n\n" + markdown
+	}
+	if footer = strings.TrimSpace(footer); footer != "" {
+		markdown = strings.TrimRight(markdown, "\n") + "\n\n" + footer + "\n"
+	}
+	return markdown
+}
+
+// generationFooter records when the report was generated, how long it took
+// and by which tool version, so comments can be traced to runs hours later
+func generationFooter(generated time.Time, elapsed time.Duration, location *time.Location) string {
+	return fmt.Sprintf("*Report generated %s in %s by tfplan-commenter %s*\n",
+		generated.In(location).Format("2006-01-02 15:04:05 MST"), elapsed.Round(time.Millisecond), Version)
+}
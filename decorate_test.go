@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDecorateReport(t *testing.T) {
+	report := "## 📋 Terraform Plan Summary\n\n---\n*Generated from Terraform 1.9.8 plan*\n"
+
+	if got := decorateReport(report, "", ""); got != report {
+		t.Errorf("Expected an undecorated report unchanged, got:\n%s", got)
+	}
+
+	expected := "> Apply requires 2 approvals\n\n" + report + "\n[Runbook](https://example.com/runbook)\n"
+	if got := decorateReport(report, "> Apply requires 2 approvals\n", "[Runbook](https://example.com/runbook)"); got != expected {
+		t.Errorf("Unexpected decorated report:\n%s", got)
+	}
+}
+
+func TestGenerationFooter(t *testing.T) {
+	location, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone database unavailable: %v", err)
+	}
+	generated := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	expected := "*Report generated 2024-03-01 13:30:00 CET in 1.235s by tfplan-commenter dev*\n"
+	if got := generationFooter(generated, 1234567*time.Microsecond, location); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
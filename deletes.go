@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// deleteIdentityAttributes are the before-state attributes that identify what
+// a delete destroys, in table order
+var deleteIdentityAttributes = []string{
+	"name", "bucket", "identifier", "id", "arn", "family", "engine", "engine_version",
+	"instance_type", "instance_class", "node_type", "machine_type", "size", "allocated_storage",
+	"availability_zone", "region", "location",
+}
+
+// deleteIdentityTags are the tags recording what a resource was created for
+var deleteIdentityTags = []string{"Name", "Environment", "Owner", "Project", "CreatedBy"}
+
+// deleteIdentifiers pulls the identifying attributes of a deleted resource
+// from its before state, with sensitive values masked
+func deleteIdentifiers(resourceChange ResourceChange) []AttributeChange {
+	before, ok := resourceChange.Change.Before.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	markers := resourceChange.Change.BeforeSensitive
+
+	var identifiers []AttributeChange
+	for _, attr := range deleteIdentityAttributes {
+		if val, exists := before[attr]; exists && val != nil && val != "" {
+			identifiers = append(identifiers, AttributeChange{Attribute: attr, Before: maskSensitive(val, markers, attr)})
+		}
+	}
+
+	tags, _ := before["tags"].(map[string]interface{})
+	for _, key := range deleteIdentityTags {
+		if val, exists := tags[key]; exists && val != nil {
+			identifiers = append(identifiers, AttributeChange{
+				Attribute: "tags." + key,
+				Before:    maskSensitive(val, childMarker(markers, "tags"), key),
+			})
+		}
+	}
+	return identifiers
+}
+
+// formatIdentifierTable renders the identifying attributes of a deleted
+// resource, each line prefixed with indent so the table can nest in a list
+func formatIdentifierTable(identifiers []AttributeChange, indent string) string {
+	var table strings.Builder
+	table.WriteString(indent + "| Attribute | Value |\n")
+	table.WriteString(indent + "|-----------|-------|\n")
+	for _, identifier := range identifiers {
+		table.WriteString(fmt.Sprintf("%s| `%s` | %s |\n",
+			indent, identifier.Attribute, strings.ReplaceAll(formatAttributeValue(identifier.Before), "|", "\\|")))
+	}
+	return table.String()
+}
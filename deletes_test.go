@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeleteIdentifiers(t *testing.T) {
+	change := ResourceChange{
+		Address: "aws_db_instance.main",
+		Type:    "aws_db_instance",
+		Change: Change{
+			Actions: []string{"delete"},
+			Before: map[string]interface{}{
+				"identifier":        "orders",
+				"engine":            "postgres",
+				"allocated_storage": float64(100),
+				"password":          "hunter2",
+				"tags":              map[string]interface{}{"Name": "orders-db", "Team": "payments", "Owner": "secret-owner"},
+			},
+			BeforeSensitive: map[string]interface{}{"tags": map[string]interface{}{"Owner": true}},
+		},
+	}
+
+	identifiers := deleteIdentifiers(change)
+	var attributes []string
+	for _, identifier := range identifiers {
+		attributes = append(attributes, identifier.Attribute)
+	}
+	if got := strings.Join(attributes, ","); got != "identifier,engine,allocated_storage,tags.Name,tags.Owner" {
+		t.Errorf("Unexpected identifiers: %s", got)
+	}
+
+	table := formatIdentifierTable(identifiers, "")
+	if !strings.Contains(table, "| `identifier` | \"orders\" |\n") || !strings.Contains(table, "| `tags.Name` | \"orders-db\" |\n") {
+		t.Errorf("Expected identifier rows, got:\n%s", table)
+	}
+	if strings.Contains(table, "secret-owner") || strings.Contains(table, "hunter2") {
+		t.Errorf("Expected sensitive and unlisted values to be left out, got:\n%s", table)
+	}
+
+	plan := &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: []ResourceChange{change}}
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "#### `aws_db_instance.main`\n\n| Attribute | Value |\n") {
+		t.Errorf("Expected an identifier table under the deleted resource, got:\n%s", result)
+	}
+	if strings.Contains(result, "Resource with") {
+		t.Errorf("Expected no identifier sentence, got:\n%s", result)
+	}
+
+	result = generateMultiPlanMarkdownComment([]PlanInfo{{Plan: plan, RelativePath: "prod"}}, ReportOptions{})
+	if !strings.Contains(result, "- `aws_db_instance.main`\n\n  | Attribute | Value |\n") {
+		t.Errorf("Expected a nested identifier table in environment details, got:\n%s", result)
+	}
+}
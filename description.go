@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markers delimiting the plan summary in a pull request description; text
+// outside them is left untouched
+const (
+	descriptionSummaryStart = commentMarkerPrefix + "summary:start -->"
+	descriptionSummaryEnd   = commentMarkerPrefix + "summary:end -->"
+)
+
+// descriptionSummary renders the headline numbers of a run for the pull
+// request description
+func descriptionSummary(plans []PlanInfo, opts ReportOptions) string {
+	var total EnvironmentResult
+	changed := 0
+	for _, env := range environmentResults(plans, opts) {
+		total.Create += env.Create
+		total.Update += env.Update
+		total.Replace += env.Replace
+		total.Delete += env.Delete
+		if env.Create+env.Update+env.Replace+env.Delete > 0 {
+			changed++
+		}
+	}
+
+	var summary strings.Builder
+	summary.WriteString("**Terraform plan:** ")
+	if changed == 0 {
+		summary.WriteString("✅ no resource changes")
+	} else {
+		summary.WriteString(fmt.Sprintf("🟢 %d to create · 🟡 %d to update · 🔄 %d to replace · 🔴 %d to delete",
+			total.Create, total.Update, total.Replace, total.Delete))
+		if len(plans) > 1 {
+			summary.WriteString(fmt.Sprintf(" in %d of %d environment(s)", changed, len(plans)))
+		}
+	}
+	return summary.String()
+}
+
+// injectSummary inserts the summary between the markers of a description,
+// replacing a previous summary, or appends it when there are no markers
+func injectSummary(description, summary string) string {
+	block := descriptionSummaryStart + "\n" + summary + "\n" + descriptionSummaryEnd
+
+	start := strings.Index(description, descriptionSummaryStart)
+	end := strings.Index(description, descriptionSummaryEnd)
+	if start >= 0 && end > start {
+		return description[:start] + block + description[end+len(descriptionSummaryEnd):]
+	}
+	if strings.TrimSpace(description) == "" {
+		return block
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + block
+}
+
+// updatePullRequestDescription refreshes the plan summary in the description
+// of the detected pull request
+func updatePullRequestDescription(forge string, plans []PlanInfo, opts ReportOptions) (PullRequestContext, error) {
+	ctx, err := detectPullRequestContext(forge)
+	if err != nil {
+		return ctx, err
+	}
+	publisher, err := newCommentPublisher(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	description, err := publisher.pullRequestBody(ctx.Repository, ctx.Number)
+	if err != nil {
+		return ctx, err
+	}
+	updated := injectSummary(description, descriptionSummary(plans, opts))
+	if updated == description {
+		return ctx, nil
+	}
+	return ctx, publisher.updatePullRequestBody(ctx.Repository, ctx.Number, updated)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInjectSummary(t *testing.T) {
+	block := descriptionSummaryStart + "\nnew\n" + descriptionSummaryEnd
+	for description, expected := range map[string]string{
+		"":                block,
+		"Adds a queue.\n": "Adds a queue.\n\n" + block,
+		"Intro\n\n" + descriptionSummaryStart + "\nold\n" + descriptionSummaryEnd + "\n\nOutro": "Intro\n\n" + block + "\n\nOutro",
+	} {
+		if result := injectSummary(description, "new"); result != expected {
+			t.Errorf("injectSummary(%q) = %q, expected %q", description, result, expected)
+		}
+	}
+}
+
+func TestDescriptionSummary(t *testing.T) {
+	changed := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"create"}}},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete", "create"}}},
+	}}
+	plans := []PlanInfo{{Plan: changed, RelativePath: "prod"}, {Plan: &TerraformPlan{}, RelativePath: "dev"}}
+
+	expected := "**Terraform plan:** 🟢 1 to create · 🟡 0 to update · 🔄 1 to replace · 🔴 0 to delete in 1 of 2 environment(s)"
+	if summary := descriptionSummary(plans, ReportOptions{}); summary != expected {
+		t.Errorf("Unexpected summary %q", summary)
+	}
+	if summary := descriptionSummary(plans[1:], ReportOptions{}); summary != "**Terraform plan:** ✅ no resource changes" {
+		t.Errorf("Unexpected summary without changes %q", summary)
+	}
+}
+
+func TestUpdatePullRequestDescription(t *testing.T) {
+	description := "Adds a queue."
+	updates := 0
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/infra/pulls/7" {
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodPatch {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			description = body["body"]
+			updates++
+		}
+		json.NewEncoder(w).Encode(map[string]string{"body": description})
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+	t.Setenv("GITHUB_TOKEN", "secret")
+	clearCIEnvironment(t)
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_REPOSITORY", "org/infra")
+	t.Setenv("GITHUB_REF", "refs/pull/7/merge")
+
+	plans := []PlanInfo{{Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+	}}}}
+	for i := 0; i < 2; i++ {
+		if _, err := updatePullRequestDescription("", plans, ReportOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !strings.HasPrefix(description, "Adds a queue.\n\n"+descriptionSummaryStart+"\n**Terraform plan:** 🟢 1 to create") {
+		t.Errorf("Unexpected description:\n%s", description)
+	}
+	if updates != 1 {
+		t.Errorf("Expected an unchanged summary not to be rewritten, got %d updates", updates)
+	}
+}
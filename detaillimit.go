@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// limitDetails splits entries into those rendered in detail and the overflow
+// summarized after them. A limit of zero or less disables the limit.
+func limitDetails(resources []ResourceDetail, limit int) ([]ResourceDetail, []ResourceDetail) {
+	if limit <= 0 || len(resources) <= limit {
+		return resources, nil
+	}
+	return resources[:limit], resources[limit:]
+}
+
+// writeDetailOverflow summarizes entries left out by -max-detail as a count
+// and a collapsed list of addresses
+func writeDetailOverflow(md *strings.Builder, overflow []ResourceDetail) {
+	if len(overflow) == 0 {
+		return
+	}
+
+	var addresses []string
+	for _, resource := range overflow {
+		if len(resource.Instances) > 0 {
+			addresses = append(addresses, resource.Instances...)
+		} else {
+			addresses = append(addresses, resource.Address)
+		}
+	}
+
+	md.WriteString("<details>\n")
+	md.WriteString(fmt.Sprintf("<summary>... and %d more resource(s)</summary>\n\n", len(addresses)))
+	for _, address := range addresses {
+		md.WriteString(fmt.Sprintf("- `%s`\n", address))
+	}
+	md.WriteString("\n</details>\n\n")
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMaxDetail(t *testing.T) {
+	var changes []ResourceChange
+	for i := 0; i < 5; i++ {
+		changes = append(changes, ResourceChange{
+			Address: fmt.Sprintf("aws_sqs_queue.q%d", i),
+			Type:    "aws_sqs_queue",
+			Change:  Change{Actions: []string{"delete"}},
+		})
+	}
+	plan := &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: changes}
+
+	result := generateMarkdownComment(plan, ReportOptions{MaxDetail: 2})
+	if strings.Count(result, "#### `aws_sqs_queue.") != 2 {
+		t.Errorf("Expected 2 detailed entries, got:\n%s", result)
+	}
+	if !strings.Contains(result, "<summary>... and 3 more resource(s)</summary>\n\n- `aws_sqs_queue.q2`\n- `aws_sqs_queue.q3`\n- `aws_sqs_queue.q4`\n") {
+		t.Errorf("Expected overflow summary, got:\n%s", result)
+	}
+	if !strings.Contains(result, "| 🔴 **Delete** | 5 |") {
+		t.Error("Expected summary counts to include overflow entries")
+	}
+
+	if result := generateMarkdownComment(plan, ReportOptions{}); strings.Contains(result, "more resource(s)") {
+		t.Error("Expected no overflow without -max-detail")
+	}
+}
+
+func TestCollapseLargeEnvironments(t *testing.T) {
+	large := &TerraformPlan{}
+	for i := 0; i < 5; i++ {
+		large.ResourceChanges = append(large.ResourceChanges, ResourceChange{
+			Address: fmt.Sprintf("aws_instance.web[%d]", i), Type: "aws_instance",
+			Change: Change{Actions: []string{"create"}},
+		})
+	}
+	small := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete"}}},
+	}}
+	plans := []PlanInfo{{Plan: large, RelativePath: "noisy"}, {Plan: small, RelativePath: "quiet"}}
+
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{CollapseOver: 3})
+	noisy := result[strings.Index(result, "#### 📁 `noisy`"):strings.Index(result, "#### 📁 `quiet`")]
+	quiet := result[strings.Index(result, "#### 📁 `quiet`"):]
+
+	summaryTable := strings.Index(noisy, "| 🟢 **Create** | 5 |")
+	collapse := strings.Index(noisy, "<details>\n<summary>Show details of 5 change(s)</summary>")
+	if summaryTable < 0 || collapse < summaryTable {
+		t.Errorf("Expected the summary table followed by collapsed details:\n%s", noisy)
+	}
+	if !strings.Contains(noisy, "</details>\n\n---") {
+		t.Errorf("Expected the collapsed block to close before the separator:\n%s", noisy)
+	}
+	if strings.Contains(quiet, "Show details of") {
+		t.Errorf("Expected small environments to stay expanded:\n%s", quiet)
+	}
+
+	if result := generateMultiPlanMarkdownComment(plans, ReportOptions{}); strings.Contains(result, "Show details of") {
+		t.Error("Expected no collapsing by default")
+	}
+}
+
+func TestSummaryOnly(t *testing.T) {
+	plan := &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: []ResourceChange{
+		{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"create"}}},
+		{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete"}}},
+	}}
+
+	result := generateMarkdownComment(plan, ReportOptions{SummaryOnly: true})
+	if !strings.Contains(result, "| 🔴 **Delete** | 1 |") {
+		t.Errorf("Expected the summary table, got:\n%s", result)
+	}
+	if strings.Contains(result, "#### `aws_s3_bucket.logs`") {
+		t.Errorf("Expected no per-resource detail, got:\n%s", result)
+	}
+	if !strings.HasSuffix(result, "*Generated from Terraform 1.9.8 plan*\n") {
+		t.Errorf("Expected the footer, got:\n%s", result)
+	}
+
+	plans := []PlanInfo{{Plan: plan, RelativePath: "prod"}}
+	result = generateMultiPlanMarkdownComment(plans, ReportOptions{SummaryOnly: true})
+	if !strings.Contains(result, "| `prod` | 1 | 0 | 0 | 1 |") {
+		t.Errorf("Expected a per-environment row, got:\n%s", result)
+	}
+	if strings.Contains(result, "Environment Details") || strings.Contains(result, "aws_s3_bucket.logs") {
+		t.Errorf("Expected no environment details, got:\n%s", result)
+	}
+}
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// diffValues compares a before/after value pair at the given attribute path,
+// recursing into maps and lists so changes are reported at their full path
+// (e.g. versioning[0].enabled) rather than as opaque collections
+func diffValues(changes *[]AttributeChange, path string,
+	before interface{}, beforeExists bool, after interface{}, afterExists bool,
+	beforeSensitive, afterSensitive, afterUnknown interface{}) {
+
+	// Values unknown until apply are omitted from "after"
+	if afterUnknown == true {
+		after = unknownValue{}
+		afterExists = true
+	}
+
+	// Descend into matching containers unless the whole value is masked
+	if beforeExists && afterExists && beforeSensitive != true && afterSensitive != true && afterUnknown != true {
+		switch b := before.(type) {
+		case map[string]interface{}:
+			if a, ok := after.(map[string]interface{}); ok {
+				for _, key := range unionKeys(b, a, afterUnknown) {
+					beforeVal, beforeOk := b[key]
+					afterVal, afterOk := a[key]
+					diffValues(changes, formatPathKey(path, key),
+						beforeVal, beforeOk, afterVal, afterOk,
+						childMarker(beforeSensitive, key),
+						childMarker(afterSensitive, key),
+						childMarker(afterUnknown, key))
+				}
+				return
+			}
+		case []interface{}:
+			if a, ok := after.([]interface{}); ok {
+				// Align elements when no per-element markers need to stay in step
+				if !containsTrueMarker(beforeSensitive) && !containsTrueMarker(afterSensitive) && !containsTrueMarker(afterUnknown) {
+					diffLists(changes, path, b, a)
+					return
+				}
+
+				length := len(b)
+				if len(a) > length {
+					length = len(a)
+				}
+				for i := 0; i < length; i++ {
+					var beforeVal, afterVal interface{}
+					if i < len(b) {
+						beforeVal = b[i]
+					}
+					if i < len(a) {
+						afterVal = a[i]
+					}
+					diffValues(changes, fmt.Sprintf("%s[%d]", path, i),
+						beforeVal, i < len(b), afterVal, i < len(a),
+						childMarker(beforeSensitive, i),
+						childMarker(afterSensitive, i),
+						childMarker(afterUnknown, i))
+				}
+				return
+			}
+		case string:
+			// JSON-encoded documents (IAM policies, container definitions) are
+			// compared structurally so reformatting isn't reported as a rewrite
+			if a, ok := after.(string); ok && before != after {
+				beforeDoc, beforeOk := decodeJSONDocument(b)
+				afterDoc, afterOk := decodeJSONDocument(a)
+				if beforeOk && afterOk {
+					diffValues(changes, path, beforeDoc, true, afterDoc, true, nil, nil, nil)
+					return
+				}
+
+				// Encoded user data is compared as the underlying script
+				if isBase64Attribute(path) {
+					beforeText, beforeOk := decodeBase64Text(b)
+					afterText, afterOk := decodeBase64Text(a)
+					if beforeOk && afterOk {
+						if beforeText != afterText {
+							*changes = append(*changes, AttributeChange{
+								Attribute: path,
+								Before:    beforeText,
+								After:     afterText,
+								Decoded:   true,
+							})
+						}
+						return
+					}
+				}
+			}
+		}
+	}
+
+	changed := beforeExists && afterExists && !deepEqual(before, after)
+	before = maskValue(before, beforeSensitive)
+	after = maskValue(after, afterSensitive)
+
+	if !beforeExists && afterExists {
+		// New attribute
+		*changes = append(*changes, AttributeChange{
+			Attribute: path,
+			Before:    nil,
+			After:     after,
+			IsNew:     true,
+		})
+	} else if beforeExists && !afterExists {
+		// Removed attribute
+		*changes = append(*changes, AttributeChange{
+			Attribute: path,
+			Before:    before,
+			After:     nil,
+			IsRemoved: true,
+		})
+	} else if changed {
+		// Changed attribute
+		*changes = append(*changes, AttributeChange{
+			Attribute: path,
+			Before:    before,
+			After:     after,
+		})
+	}
+}
+
+// decodeJSONDocument parses a string holding a JSON object or array
+func decodeJSONDocument(value string) (interface{}, bool) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return nil, false
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(trimmed), &doc); err != nil {
+		return nil, false
+	}
+	return doc, true
+}
+
+// unionKeys returns the sorted keys present in either map, plus keys an
+// after_unknown marker flags as unknown
+func unionKeys(before, after map[string]interface{}, afterUnknown interface{}) []string {
+	seen := make(map[string]bool)
+	for key := range before {
+		seen[key] = true
+	}
+	for key := range after {
+		seen[key] = true
+	}
+	if unknown, ok := afterUnknown.(map[string]interface{}); ok {
+		for key, marker := range unknown {
+			if isUnknown(marker) {
+				seen[key] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// childMarker returns the sensitivity/unknown marker for a map key or list
+// index. A marker of true for a whole value applies to all of its children.
+func childMarker(marker interface{}, step interface{}) interface{} {
+	switch v := marker.(type) {
+	case bool:
+		return v
+	case map[string]interface{}:
+		if key, ok := step.(string); ok {
+			return v[key]
+		}
+	case []interface{}:
+		if index, ok := step.(int); ok && index < len(v) {
+			return v[index]
+		}
+	}
+	return nil
+}
+
+// formatPathKey appends a map key to an attribute path, quoting keys that
+// aren't plain identifiers (e.g. tags["kubernetes.io/role"])
+func formatPathKey(path, key string) string {
+	if !identifierPattern.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// listKeyAttributes identify an element of a list of objects, so a modified
+// element can be told apart from one that was removed and another added
+var listKeyAttributes = []string{"name", "key", "id", "sid", "rule_number", "priority", "cidr_block"}
+
+// maxAlignedListLength bounds the quadratic alignment; longer lists are compared by index
+const maxAlignedListLength = 500
+
+// diffLists aligns two lists using their longest common subsequence and
+// reports unmatched elements as added, removed or modified
+func diffLists(changes *[]AttributeChange, path string, before, after []interface{}) {
+	if len(before) > maxAlignedListLength || len(after) > maxAlignedListLength {
+		diffListsByIndex(changes, path, before, after)
+		return
+	}
+
+	// lcs[i][j] is the length of the common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if deepEqual(before[i], after[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var elementChanges []AttributeChange
+	added, removed := 0, 0
+	var removedRun, addedRun []int
+
+	flush := func() {
+		a, r := pairListElements(&elementChanges, path, before, after, removedRun, addedRun)
+		added += a
+		removed += r
+		removedRun, addedRun = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && deepEqual(before[i], after[j]):
+			flush()
+			i++
+			j++
+		case j < len(after) && (i == len(before) || lcs[i][j+1] >= lcs[i+1][j]):
+			addedRun = append(addedRun, j)
+			j++
+		default:
+			removedRun = append(removedRun, i)
+			i++
+		}
+	}
+	flush()
+
+	if added > 0 || removed > 0 {
+		var parts []string
+		if added > 0 {
+			parts = append(parts, fmt.Sprintf("%d added", added))
+		}
+		if removed > 0 {
+			parts = append(parts, fmt.Sprintf("%d removed", removed))
+		}
+		*changes = append(*changes, AttributeChange{
+			Attribute: path,
+			Note:      strings.Join(parts, ", "),
+		})
+	}
+	*changes = append(*changes, elementChanges...)
+}
+
+// pairListElements matches removed and added elements from one unaligned run.
+// Elements sharing an identifying key, or runs of equal length, are treated as
+// modified and diffed recursively; the rest are reported as added or removed.
+func pairListElements(changes *[]AttributeChange, path string, before, after []interface{}, removedRun, addedRun []int) (int, int) {
+	pairs := make(map[int]int)
+	paired := make(map[int]bool)
+
+	for _, r := range removedRun {
+		for _, a := range addedRun {
+			if !paired[a] && sameListElementKey(before[r], after[a]) {
+				pairs[r] = a
+				paired[a] = true
+				break
+			}
+		}
+	}
+
+	if len(pairs) == 0 && len(removedRun) == len(addedRun) {
+		for k, r := range removedRun {
+			pairs[r] = addedRun[k]
+			paired[addedRun[k]] = true
+		}
+	}
+
+	removed := 0
+	for _, r := range removedRun {
+		if a, ok := pairs[r]; ok {
+			diffValues(changes, fmt.Sprintf("%s[%d]", path, a), before[r], true, after[a], true, nil, nil, nil)
+			continue
+		}
+		*changes = append(*changes, AttributeChange{
+			Attribute: fmt.Sprintf("%s[%d]", path, r),
+			Before:    inlineValue{before[r]},
+			IsRemoved: true,
+		})
+		removed++
+	}
+
+	added := 0
+	for _, a := range addedRun {
+		if paired[a] {
+			continue
+		}
+		*changes = append(*changes, AttributeChange{
+			Attribute: fmt.Sprintf("%s[%d]", path, a),
+			After:     inlineValue{after[a]},
+			IsNew:     true,
+		})
+		added++
+	}
+
+	return added, removed
+}
+
+// sameListElementKey reports whether two object elements share an identifying attribute
+func sameListElementKey(before, after interface{}) bool {
+	b, ok := before.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	a, ok := after.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, key := range listKeyAttributes {
+		bv, bok := b[key]
+		av, aok := a[key]
+		if bok && aok && bv != nil && deepEqual(bv, av) {
+			return true
+		}
+	}
+	return false
+}
+
+// diffListsByIndex compares list elements position by position
+func diffListsByIndex(changes *[]AttributeChange, path string, before, after []interface{}) {
+	length := len(before)
+	if len(after) > length {
+		length = len(after)
+	}
+	for i := 0; i < length; i++ {
+		var beforeVal, afterVal interface{}
+		if i < len(before) {
+			beforeVal = before[i]
+		}
+		if i < len(after) {
+			afterVal = after[i]
+		}
+		diffValues(changes, fmt.Sprintf("%s[%d]", path, i),
+			beforeVal, i < len(before), afterVal, i < len(after), nil, nil, nil)
+	}
+}
+
+// inlineValue renders a whole list element compactly, so added and removed
+// elements show their content rather than just a key count
+type inlineValue struct {
+	value interface{}
+}
+
+// maxInlineValueLength keeps inline elements readable in a single list item
+const maxInlineValueLength = 120
+
+func (v inlineValue) String() string {
+	if s, ok := v.value.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	data, err := json.Marshal(v.value)
+	if err != nil {
+		return fmt.Sprintf("%v", v.value)
+	}
+	text := []rune(string(data))
+	if len(text) > maxInlineValueLength {
+		text = append(text[:maxInlineValueLength], '…')
+	}
+	return fmt.Sprintf("`%s`", string(text))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAnalyzeAttributeChangesNested(t *testing.T) {
+	change := Change{
+		Actions: []string{"update"},
+		Before: map[string]interface{}{
+			"versioning": []interface{}{
+				map[string]interface{}{"enabled": false, "mfa_delete": false},
+			},
+			"tags": map[string]interface{}{"kubernetes.io/role": "old", "Team": "infra"},
+		},
+		After: map[string]interface{}{
+			"versioning": []interface{}{
+				map[string]interface{}{"enabled": true, "mfa_delete": false},
+			},
+			"tags": map[string]interface{}{"kubernetes.io/role": "new", "Team": "infra", "Owner": "sre"},
+		},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	expected := []struct {
+		attribute string
+		isNew     bool
+	}{
+		{"tags.Owner", true},
+		{`tags["kubernetes.io/role"]`, false},
+		{"versioning[0].enabled", false},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %+v", len(expected), changes)
+	}
+	for i, e := range expected {
+		if changes[i].Attribute != e.attribute || changes[i].IsNew != e.isNew {
+			t.Errorf("Change %d: expected %s (new=%v), got %+v", i, e.attribute, e.isNew, changes[i])
+		}
+	}
+}
+
+func TestAnalyzeAttributeChangesNestedMarkers(t *testing.T) {
+	change := Change{
+		Actions: []string{"update"},
+		Before: map[string]interface{}{
+			"settings": map[string]interface{}{"token": "old-secret", "size": float64(1)},
+		},
+		After: map[string]interface{}{
+			"settings": map[string]interface{}{"token": "new-secret", "size": float64(1)},
+		},
+		BeforeSensitive: map[string]interface{}{"settings": map[string]interface{}{"token": true}},
+		AfterSensitive:  map[string]interface{}{"settings": map[string]interface{}{"token": true}},
+		AfterUnknown:    map[string]interface{}{"settings": map[string]interface{}{"endpoint": true}},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", changes)
+	}
+	if changes[0].Attribute != "settings.endpoint" || formatAttributeValue(changes[0].After) != "(known after apply)" {
+		t.Errorf("Expected unknown nested endpoint, got %+v", changes[0])
+	}
+	if changes[1].Attribute != "settings.token" || formatAttributeValue(changes[1].Before) != "(sensitive)" {
+		t.Errorf("Expected masked nested token, got %+v", changes[1])
+	}
+}
+
+func TestDiffListsAlignsElements(t *testing.T) {
+	rule := func(port float64, cidr string) map[string]interface{} {
+		return map[string]interface{}{"from_port": port, "to_port": port, "cidr_blocks": []interface{}{cidr}}
+	}
+
+	change := Change{
+		Actions: []string{"update"},
+		Before: map[string]interface{}{
+			"ingress": []interface{}{rule(22, "10.0.0.0/8"), rule(80, "0.0.0.0/0"), rule(443, "0.0.0.0/0")},
+		},
+		After: map[string]interface{}{
+			"ingress": []interface{}{rule(80, "0.0.0.0/0"), rule(443, "0.0.0.0/0"), rule(8443, "10.0.0.0/8")},
+		},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	if len(changes) != 3 {
+		t.Fatalf("Expected summary plus 2 element changes, got %+v", changes)
+	}
+	if changes[0].Attribute != "ingress" || changes[0].Note != "1 added, 1 removed" {
+		t.Errorf("Expected list summary note, got %+v", changes[0])
+	}
+	if changes[1].Attribute != "ingress[0]" || !changes[1].IsRemoved {
+		t.Errorf("Expected removed first rule, got %+v", changes[1])
+	}
+	if changes[2].Attribute != "ingress[2]" || !changes[2].IsNew {
+		t.Errorf("Expected added last rule, got %+v", changes[2])
+	}
+	expected := "`{\"cidr_blocks\":[\"10.0.0.0/8\"],\"from_port\":8443,\"to_port\":8443}`"
+	if got := formatAttributeValue(changes[2].After); got != expected {
+		t.Errorf("Expected added element %s, got %s", expected, got)
+	}
+}
+
+func TestDiffListsPairsModifiedElements(t *testing.T) {
+	change := Change{
+		Actions: []string{"update"},
+		Before: map[string]interface{}{
+			"statement": []interface{}{
+				map[string]interface{}{"sid": "Read", "actions": "s3:GetObject"},
+				map[string]interface{}{"sid": "Write", "actions": "s3:PutObject"},
+			},
+		},
+		After: map[string]interface{}{
+			"statement": []interface{}{
+				map[string]interface{}{"sid": "Read", "actions": "s3:GetObject"},
+				map[string]interface{}{"sid": "Write", "actions": "s3:*"},
+				map[string]interface{}{"sid": "List", "actions": "s3:ListBucket"},
+			},
+		},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changes, got %+v", changes)
+	}
+	if changes[0].Note != "1 added" {
+		t.Errorf("Expected only one added element, got %+v", changes[0])
+	}
+	if changes[1].Attribute != "statement[1].actions" {
+		t.Errorf("Expected modified element to be diffed by key, got %+v", changes[1])
+	}
+	if changes[2].Attribute != "statement[2]" || !changes[2].IsNew {
+		t.Errorf("Expected new statement, got %+v", changes[2])
+	}
+}
+
+func TestAnalyzeAttributeChangesJSONStrings(t *testing.T) {
+	before := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`
+	reformatted := "{\n  \"Statement\": [\n    {\"Resource\": \"*\", \"Action\": \"s3:GetObject\", \"Effect\": \"Allow\"}\n  ],\n  \"Version\": \"2012-10-17\"\n}"
+	changed := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:PutObject","Resource":"*"}]}`
+
+	whitespaceOnly := analyzeAttributeChanges(Change{
+		Actions: []string{"update"},
+		Before:  map[string]interface{}{"policy": before},
+		After:   map[string]interface{}{"policy": reformatted},
+	})
+	if len(whitespaceOnly) != 0 {
+		t.Errorf("Expected reformatted policy to produce no changes, got %+v", whitespaceOnly)
+	}
+
+	changes := analyzeAttributeChanges(Change{
+		Actions: []string{"update"},
+		Before:  map[string]interface{}{"policy": before},
+		After:   map[string]interface{}{"policy": changed},
+	})
+	if len(changes) != 1 || changes[0].Attribute != "policy.Statement[0].Action" {
+		t.Fatalf("Expected structural policy change, got %+v", changes)
+	}
+	if changes[0].Before != "s3:GetObject" || changes[0].After != "s3:PutObject" {
+		t.Errorf("Unexpected values: %+v", changes[0])
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// publicRegistries host provider documentation at registry.terraform.io
+var publicRegistries = map[string]bool{
+	"registry.terraform.io": true,
+	"registry.opentofu.org": true,
+}
+
+// registryDocsURL returns the registry documentation page of a resource or
+// data source type, or "" for providers outside the public registry
+func registryDocsURL(providerName, resourceType string, dataSource bool) string {
+	parts := strings.Split(providerName, "/")
+	if len(parts) != 3 || !publicRegistries[parts[0]] {
+		return ""
+	}
+	namespace, provider := parts[1], parts[2]
+
+	// Pages are named after the type without its provider prefix, e.g. aws_instance → instance
+	page := strings.TrimPrefix(resourceType, provider+"_")
+	kind := "resources"
+	if dataSource {
+		kind = "data-sources"
+	}
+	return fmt.Sprintf("https://registry.terraform.io/providers/%s/%s/latest/docs/%s/%s", namespace, provider, kind, page)
+}
+
+// resourceTypeDocs maps the managed resource types of a plan to their documentation pages
+func resourceTypeDocs(plan *TerraformPlan) map[string]string {
+	links := make(map[string]string)
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode == "data" {
+			continue
+		}
+		if url := registryDocsURL(rc.ProviderName, rc.Type, false); url != "" {
+			links[rc.Type] = url
+		}
+	}
+	return links
+}
+
+// annotateDocsLinks attaches documentation links to every resource in the summary
+func annotateDocsLinks(summary *ResourceSummary, plan *TerraformPlan) {
+	types := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		types[rc.Address] = rc.Type
+	}
+	links := resourceTypeDocs(plan)
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range details {
+			details[i].DocsLink = links[types[details[i].Address]]
+		}
+	}
+}
+
+// formatResourceType renders a resource type as code, linked to its
+// documentation when a link is known
+func formatResourceType(resourceType string, links map[string]string) string {
+	if url, ok := links[resourceType]; ok {
+		return fmt.Sprintf("[`%s`](%s)", resourceType, url)
+	}
+	return fmt.Sprintf("`%s`", resourceType)
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryDocsURL(t *testing.T) {
+	tests := []struct {
+		providerName string
+		resourceType string
+		dataSource   bool
+		expected     string
+	}{
+		{"registry.terraform.io/hashicorp/aws", "aws_instance", false, "https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/instance"},
+		{"registry.terraform.io/hashicorp/aws", "aws_ami", true, "https://registry.terraform.io/providers/hashicorp/aws/latest/docs/data-sources/ami"},
+		{"registry.opentofu.org/integrations/github", "github_repository", false, "https://registry.terraform.io/providers/integrations/github/latest/docs/resources/repository"},
+		{"registry.terraform.io/hashicorp/time", "time_sleep", false, "https://registry.terraform.io/providers/hashicorp/time/latest/docs/resources/sleep"},
+		{"app.terraform.io/acme/internal", "internal_thing", false, ""},
+		{"cloudformation", "AWS::S3::Bucket", false, ""},
+	}
+	for _, tt := range tests {
+		if got := registryDocsURL(tt.providerName, tt.resourceType, tt.dataSource); got != tt.expected {
+			t.Errorf("registryDocsURL(%q, %q) = %q, expected %q", tt.providerName, tt.resourceType, got, tt.expected)
+		}
+	}
+}
+
+func TestLinkDocs(t *testing.T) {
+	plan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_instance.web", Mode: "managed", Type: "aws_instance", ProviderName: "registry.terraform.io/hashicorp/aws", Change: Change{Actions: []string{"create"}}},
+		{Address: "aws_s3_bucket.logs", Mode: "managed", Type: "aws_s3_bucket", ProviderName: "registry.terraform.io/hashicorp/aws", Change: Change{Actions: []string{"delete"}}},
+	}}
+
+	result := generateMarkdownComment(plan, ReportOptions{LinkDocs: true})
+	for _, expected := range []string{
+		"`aws_instance.web` [📖](https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/instance)",
+		"| [`aws_s3_bucket`](https://registry.terraform.io/providers/hashicorp/aws/latest/docs/resources/s3_bucket) |",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+
+	if result := generateMarkdownComment(plan, ReportOptions{}); strings.Contains(result, "registry.terraform.io") {
+		t.Error("Expected no documentation links without LinkDocs")
+	}
+}
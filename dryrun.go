@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// githubCommentLimit is the maximum length of a GitHub issue or pull request
+// comment body, in characters
+const githubCommentLimit = 65536
+
+// publishTargets are the destinations a run writes to or sends to, as
+// configured by flags
+type publishTargets struct {
+	OutputFile  string
+	Append      bool
+	AttestKey   string
+	AuditLog    string
+	HistoryFile string
+	MetricsFile string
+	Pushgateway string
+	StatsD      string
+	Traces      string
+	ResultFile  string
+	GitLab      string
+	Changelog   string
+	Bitbucket   bool
+	Buildkite   bool
+	Jenkins     string
+	Infracost   bool   // Cost estimation is skipped, since infracost may call its pricing API
+	Comment     string // Pull request to comment on, or why it can't be detected
+	Description string // Pull request whose description gets the summary, or why it can't be detected
+}
+
+// describe lists what a run would publish, for -dry-run
+func (t publishTargets) describe(markdown string, plans int) []string {
+	var actions []string
+
+	if t.Infracost {
+		actions = append(actions, fmt.Sprintf("run infracost breakdown on %d plan(s) and add cost estimates to the report", plans))
+	}
+	if t.Append {
+		actions = append(actions, fmt.Sprintf("append report to %s (%d bytes)", t.OutputFile, len(markdown)))
+	} else {
+		actions = append(actions, fmt.Sprintf("write report to %s (%d bytes)", t.OutputFile, len(markdown)))
+	}
+	if length := utf8.RuneCountInString(markdown); length > githubCommentLimit {
+		actions = append(actions, fmt.Sprintf("note: report is %d characters, over the %d character GitHub comment limit; -post-comment truncates it and links the CI run",
+			length, githubCommentLimit))
+	}
+	if t.Comment != "" {
+		actions = append(actions, fmt.Sprintf("post report as a comment on %s", t.Comment))
+	}
+	if t.Description != "" {
+		actions = append(actions, fmt.Sprintf("refresh the plan summary in the description of %s", t.Description))
+	}
+	if t.AttestKey != "" {
+		actions = append(actions, fmt.Sprintf("write attestation signed with %s to %s", t.AttestKey, t.OutputFile+attestationExtension))
+	}
+	if t.GitLab != "" {
+		actions = append(actions, fmt.Sprintf("write GitLab terraform report to %s", t.GitLab))
+	}
+	if t.Changelog != "" {
+		actions = append(actions, fmt.Sprintf("append a changelog entry to %s", t.Changelog))
+	}
+	if t.HistoryFile != "" {
+		actions = append(actions, fmt.Sprintf("append %d history record(s) to %s", plans, t.HistoryFile))
+	}
+	if t.MetricsFile != "" {
+		actions = append(actions, fmt.Sprintf("write metrics to %s", t.MetricsFile))
+	}
+	if t.Pushgateway != "" {
+		actions = append(actions, fmt.Sprintf("push metrics to %s", t.Pushgateway))
+	}
+	if t.StatsD != "" {
+		actions = append(actions, fmt.Sprintf("send metrics to StatsD at %s", t.StatsD))
+	}
+	if t.Traces != "" {
+		actions = append(actions, fmt.Sprintf("export traces to %s", t.Traces))
+	}
+	if t.Bitbucket {
+		actions = append(actions, "publish a Bitbucket Code Insights report for the commit")
+	}
+	if t.Buildkite {
+		actions = append(actions, fmt.Sprintf("annotate the Buildkite build (%d bytes)", len(markdown)))
+	}
+	if t.Jenkins != "" {
+		actions = append(actions, fmt.Sprintf("write Jenkins %s and %s to %s", jenkinsDescriptionFile, jenkinsBadgeFile, t.Jenkins))
+	}
+	if t.ResultFile != "" {
+		actions = append(actions, fmt.Sprintf("write run result to %s", t.ResultFile))
+	}
+	if t.AuditLog != "" {
+		if strings.HasPrefix(t.AuditLog, "http://") || strings.HasPrefix(t.AuditLog, "https://") {
+			actions = append(actions, fmt.Sprintf("POST audit record to %s", t.AuditLog))
+		} else {
+			actions = append(actions, fmt.Sprintf("append audit record to %s", t.AuditLog))
+		}
+	}
+	return actions
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDescribePublishTargets(t *testing.T) {
+	targets := publishTargets{
+		OutputFile:  "comment.md",
+		Append:      true,
+		AttestKey:   "key.pem",
+		AuditLog:    "https://audit.example.com/records",
+		HistoryFile: "history.jsonl",
+		StatsD:      "127.0.0.1:8125",
+		ResultFile:  "result.json",
+		Infracost:   true,
+	}
+
+	expected := []string{
+		"run infracost breakdown on 2 plan(s) and add cost estimates to the report",
+		"append report to comment.md (6 bytes)",
+		"write attestation signed with key.pem to comment.md.intoto.jsonl",
+		"append 2 history record(s) to history.jsonl",
+		"send metrics to StatsD at 127.0.0.1:8125",
+		"write run result to result.json",
+		"POST audit record to https://audit.example.com/records",
+	}
+	if got := targets.describe("report", 2); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected dry run actions:\n%s", strings.Join(got, "\n"))
+	}
+
+	actions := publishTargets{OutputFile: "comment.md"}.describe(strings.Repeat("x", githubCommentLimit+1), 1)
+	if len(actions) != 2 || !strings.Contains(actions[1], "over the 65536 character GitHub comment limit") {
+		t.Errorf("Expected an oversized report to be flagged, got:\n%s", strings.Join(actions, "\n"))
+	}
+}
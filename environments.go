@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// environmentName returns an environment's configured display name, or its
+// relative path when none is configured
+func environmentName(path string, names map[string]string) string {
+	if name, ok := names[path]; ok && name != "" {
+		return name
+	}
+	return path
+}
+
+// formatEnvironment renders an environment in the report: its display name,
+// or its relative path as code
+func formatEnvironment(path string, names map[string]string) string {
+	if name, ok := names[path]; ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("`%s`", path)
+}
+
+// matchesEnvironment reports whether a glob matches an environment's relative
+// path or its last directory
+func matchesEnvironment(pattern, env string) bool {
+	if ok, _ := path.Match(pattern, env); ok {
+		return true
+	}
+	ok, _ := path.Match(pattern, path.Base(env))
+	return ok
+}
+
+// environmentPriority returns the index of the first order pattern matching an
+// environment, or len(order) when none does
+func environmentPriority(env string, order []string) int {
+	for i, pattern := range order {
+		if matchesEnvironment(pattern, env) {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// orderEnvironments returns the plans sorted by the configured environment
+// order. Environments matching the same pattern, or none, keep their order.
+func orderEnvironments(plans []PlanInfo, order []string) []PlanInfo {
+	if len(order) == 0 {
+		return plans
+	}
+	ordered := append([]PlanInfo(nil), plans...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return environmentPriority(ordered[i].RelativePath, order) < environmentPriority(ordered[j].RelativePath, order)
+	})
+	return ordered
+}
+
+// environmentPath names the environment of a discovered plan file: its
+// directory relative to the root, or "root" for a plan at the root
+func environmentPath(rootDir, planPath string) string {
+	relPath, err := filepath.Rel(rootDir, filepath.Dir(planPath))
+	if err != nil {
+		relPath = filepath.Dir(planPath)
+	}
+	if relPath == "." {
+		relPath = "root"
+	}
+	return relPath
+}
+
+// parseEnvironmentPatterns splits a comma-separated -only/-except value into globs
+func parseEnvironmentPatterns(spec string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(spec, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid environment pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// filterPlanPaths keeps discovered plan files whose environment matches an
+// only pattern (when any are given) and no except pattern
+func filterPlanPaths(rootDir string, paths, only, except []string) []string {
+	matchesAny := func(patterns []string, env string) bool {
+		for _, pattern := range patterns {
+			if matchesEnvironment(pattern, env) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var kept []string
+	for _, planPath := range paths {
+		env := filepath.ToSlash(environmentPath(rootDir, planPath))
+		if len(only) > 0 && !matchesAny(only, env) {
+			continue
+		}
+		if matchesAny(except, env) {
+			continue
+		}
+		kept = append(kept, planPath)
+	}
+	return kept
+}
+
+// writeEnvironmentSummarySection renders one row of change counts per
+// environment, in place of the environment details of -summary-only reports
+func writeEnvironmentSummarySection(md *strings.Builder, plans []PlanInfo, opts ReportOptions) {
+	md.WriteString("### 🏗️ Environment Summary\n\n")
+	md.WriteString("| Environment | 🟢 Create | 🟡 Update | 🔄 Replace | 🔴 Delete | Risk |\n")
+	md.WriteString("|-------------|-----------|-----------|------------|-----------|------|\n")
+	for _, planInfo := range plans {
+		summary := analyzePlan(planInfo.Plan)
+		risk := assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk)
+		md.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %s %s |\n",
+			formatEnvironment(planInfo.RelativePath, opts.EnvironmentNames),
+			len(summary.Create), len(summary.Update), len(summary.Replace), len(summary.Delete),
+			riskEmojis[risk.Level], risk.Level))
+	}
+	md.WriteString("\n")
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvironmentNames(t *testing.T) {
+	names := map[string]string{"stacks/eu-west-1/prod": "Production (Ireland)"}
+	if got := formatEnvironment("stacks/eu-west-1/prod", names); got != "Production (Ireland)" {
+		t.Errorf("Expected the display name, got %q", got)
+	}
+	if got := formatEnvironment("stacks/eu-west-1/dev", names); got != "`stacks/eu-west-1/dev`" {
+		t.Errorf("Expected the path as code, got %q", got)
+	}
+	if got := environmentName("stacks/eu-west-1/dev", names); got != "stacks/eu-west-1/dev" {
+		t.Errorf("Expected the path, got %q", got)
+	}
+
+	plans := []PlanInfo{
+		{
+			Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+				{Address: "aws_s3_bucket.logs", Change: Change{Actions: []string{"delete"}}},
+			}},
+			RelativePath: "stacks/eu-west-1/prod",
+		},
+		{
+			Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+				{Address: "aws_s3_bucket.logs", Change: Change{Actions: []string{"create"}}},
+			}},
+			RelativePath: "stacks/eu-west-1/dev",
+		},
+	}
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{EnvironmentNames: names})
+	for _, expected := range []string{"#### 📁 Production (Ireland)\n", "highest in Production (Ireland)", "#### 📁 `stacks/eu-west-1/dev`\n"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "`stacks/eu-west-1/prod`") {
+		t.Error("Expected the mapped path not to be shown")
+	}
+}
+
+func TestOrderEnvironments(t *testing.T) {
+	var plans []PlanInfo
+	for _, env := range []string{"eu/dev", "eu/prod", "eu/staging", "us/dev", "us/prod"} {
+		plans = append(plans, PlanInfo{RelativePath: env})
+	}
+
+	ordered := orderEnvironments(plans, []string{"prod*", "staging"})
+	var got []string
+	for _, plan := range ordered {
+		got = append(got, plan.RelativePath)
+	}
+	expected := "eu/prod,us/prod,eu/staging,eu/dev,us/dev"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("Expected %s, got %s", expected, strings.Join(got, ","))
+	}
+	if plans[0].RelativePath != "eu/dev" {
+		t.Error("Expected the input order to be left unchanged")
+	}
+
+	if ordered := orderEnvironments(plans, []string{"us/*"}); ordered[0].RelativePath != "us/dev" || ordered[2].RelativePath != "eu/dev" {
+		t.Errorf("Expected full path patterns to match, got %v", ordered)
+	}
+}
+
+func TestFilterPlanPaths(t *testing.T) {
+	root := filepath.Join("plans")
+	var paths []string
+	for _, env := range []string{"prod/eu", "prod/us", "sandbox/alice", "staging/eu", ""} {
+		paths = append(paths, filepath.Join(root, env, planFileName))
+	}
+
+	environments := func(paths []string) string {
+		var envs []string
+		for _, p := range paths {
+			envs = append(envs, environmentPath(root, p))
+		}
+		return strings.Join(envs, ",")
+	}
+
+	if got := environments(filterPlanPaths(root, paths, []string{"prod/*"}, nil)); got != "prod/eu,prod/us" {
+		t.Errorf("Unexpected -only result %s", got)
+	}
+	if got := environments(filterPlanPaths(root, paths, nil, []string{"sandbox/*", "root"})); got != "prod/eu,prod/us,staging/eu" {
+		t.Errorf("Unexpected -except result %s", got)
+	}
+	if got := environments(filterPlanPaths(root, paths, []string{"eu"}, []string{"staging/*"})); got != "prod/eu" {
+		t.Errorf("Unexpected combined result %s", got)
+	}
+
+	if patterns, err := parseEnvironmentPatterns(" prod/*, ,staging "); err != nil || len(patterns) != 2 {
+		t.Errorf("Unexpected patterns %v (%v)", patterns, err)
+	}
+	if _, err := parseEnvironmentPatterns("["); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// publicACLs are canned ACLs that grant access to everyone
+var publicACLs = map[string]bool{
+	"public-read":        true,
+	"public-read-write":  true,
+	"authenticated-read": true,
+}
+
+// publicMembers are GCP IAM members that include everyone
+var publicMembers = map[string]bool{
+	"allUsers":              true,
+	"allAuthenticatedUsers": true,
+}
+
+// detectPublicExposure flags storage and database resources that become
+// publicly accessible in the planned state
+func detectPublicExposure(rc ResourceChange) []securityFinding {
+	before := publicExposures(rc.Type, rc.Change.Before)
+	var exposed []string
+	for exposure := range publicExposures(rc.Type, rc.Change.After) {
+		if !before[exposure] {
+			exposed = append(exposed, exposure)
+		}
+	}
+	sort.Strings(exposed)
+
+	findings := make([]securityFinding, len(exposed))
+	for i, exposure := range exposed {
+		findings[i] = securityFinding{
+			Address: rc.Address,
+			Title:   "Public exposure",
+			Detail:  exposure,
+		}
+	}
+	return findings
+}
+
+// publicExposures describes the ways a resource's values make it publicly accessible
+func publicExposures(resourceType string, values interface{}) map[string]bool {
+	exposures := make(map[string]bool)
+	attrs, ok := values.(map[string]interface{})
+	if !ok {
+		return exposures
+	}
+
+	switch resourceType {
+	case "aws_s3_bucket", "aws_s3_bucket_acl":
+		if acl, ok := attrs["acl"].(string); ok && publicACLs[acl] {
+			exposures[fmt.Sprintf("uses the %s ACL", acl)] = true
+		}
+	case "aws_s3_bucket_public_access_block":
+		for _, setting := range []string{"block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"} {
+			if attrs[setting] == false {
+				exposures[fmt.Sprintf("disables %s", setting)] = true
+			}
+		}
+	case "aws_s3_bucket_policy":
+		if policy, ok := attrs["policy"].(string); ok {
+			if doc, ok := decodeJSONDocument(policy); ok && allowsAnyPrincipal(doc) {
+				exposures["has a policy allowing any principal (\"*\")"] = true
+			}
+		}
+	case "aws_db_instance", "aws_rds_cluster_instance", "aws_redshift_cluster", "aws_dms_replication_instance":
+		if attrs["publicly_accessible"] == true {
+			exposures["is publicly accessible"] = true
+		}
+	case "google_storage_bucket_iam_member", "google_storage_bucket_iam_binding":
+		members := stringList(attrs["members"])
+		if member, ok := attrs["member"].(string); ok {
+			members = append(members, member)
+		}
+		for _, member := range members {
+			if publicMembers[member] {
+				exposures[fmt.Sprintf("grants %v to %s", attrs["role"], member)] = true
+			}
+		}
+	case "google_sql_database_instance":
+		for _, settings := range objectList(attrs["settings"]) {
+			for _, ipConfig := range objectList(settings["ip_configuration"]) {
+				for _, network := range objectList(ipConfig["authorized_networks"]) {
+					if value, ok := network["value"].(string); ok && worldOpenSources[value] {
+						exposures[fmt.Sprintf("authorizes connections from %s", value)] = true
+					}
+				}
+			}
+		}
+	case "azurerm_storage_account":
+		if attrs["allow_nested_items_to_be_public"] == true || attrs["allow_blob_public_access"] == true {
+			exposures["allows public blob access"] = true
+		}
+	case "azurerm_storage_container":
+		if accessType, ok := attrs["container_access_type"].(string); ok && accessType != "" && accessType != "private" {
+			exposures[fmt.Sprintf("has %s public access", accessType)] = true
+		}
+	}
+	return exposures
+}
+
+// allowsAnyPrincipal reports whether a policy document has an Allow statement for Principal "*"
+func allowsAnyPrincipal(doc interface{}) bool {
+	policy, ok := doc.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	statements, ok := policy["Statement"].([]interface{})
+	if !ok {
+		statements = []interface{}{policy["Statement"]}
+	}
+	for _, s := range statements {
+		statement, ok := s.(map[string]interface{})
+		if !ok || statement["Effect"] != "Allow" {
+			continue
+		}
+		switch principal := statement["Principal"].(type) {
+		case string:
+			if principal == "*" {
+				return true
+			}
+		case map[string]interface{}:
+			if principal["AWS"] == "*" {
+				return true
+			}
+			if list, ok := principal["AWS"].([]interface{}); ok {
+				for _, p := range list {
+					if p == "*" {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectPublicExposure(t *testing.T) {
+	tests := []struct {
+		name     string
+		rc       ResourceChange
+		expected []string
+	}{
+		{
+			name: "rds made public",
+			rc: ResourceChange{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"publicly_accessible": false},
+				After:   map[string]interface{}{"publicly_accessible": true},
+			}},
+			expected: []string{"is publicly accessible"},
+		},
+		{
+			name: "already public",
+			rc: ResourceChange{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"publicly_accessible": true},
+				After:   map[string]interface{}{"publicly_accessible": true},
+			}},
+		},
+		{
+			name: "public access block relaxed",
+			rc: ResourceChange{Address: "aws_s3_bucket_public_access_block.b", Type: "aws_s3_bucket_public_access_block", Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"block_public_acls": true, "block_public_policy": true},
+				After:   map[string]interface{}{"block_public_acls": true, "block_public_policy": false},
+			}},
+			expected: []string{"disables block_public_policy"},
+		},
+		{
+			name: "bucket policy for everyone",
+			rc: ResourceChange{Address: "aws_s3_bucket_policy.site", Type: "aws_s3_bucket_policy", Change: Change{
+				Actions: []string{"create"},
+				After: map[string]interface{}{
+					"policy": `{"Statement":[{"Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::site/*"}]}`,
+				},
+			}},
+			expected: []string{`has a policy allowing any principal ("*")`},
+		},
+		{
+			name: "gcs bucket for all users",
+			rc: ResourceChange{Address: "google_storage_bucket_iam_member.public", Type: "google_storage_bucket_iam_member", Change: Change{
+				Actions: []string{"create"},
+				After:   map[string]interface{}{"role": "roles/storage.objectViewer", "member": "allUsers"},
+			}},
+			expected: []string{"grants roles/storage.objectViewer to allUsers"},
+		},
+	}
+
+	for _, tt := range tests {
+		var details []string
+		for _, finding := range detectPublicExposure(tt.rc) {
+			details = append(details, finding.Detail)
+		}
+		if !reflect.DeepEqual(details, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, details)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ResourceFilter limits which resource changes are reported and gated, under
+// "resources" in the -config file. Patterns are globs matched against the
+// resource address (with and without its module path) and the resource type.
+type ResourceFilter struct {
+	Include []string `json:"include"` // When set, only matching resources are kept
+	Exclude []string `json:"exclude"` // Matching resources are dropped
+
+	Address   *regexp.Regexp `json:"-"` // -filter-address: only matching addresses are kept
+	Type      *regexp.Regexp `json:"-"` // -filter-type: only matching resource types are kept
+	Modules   []string       `json:"-"` // -module: only resources under these module addresses are kept
+	Providers []string       `json:"-"` // -provider: only resources of these providers are kept
+}
+
+func (f *ResourceFilter) validate() error {
+	for _, pattern := range append(append([]string{}, f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid resource filter pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// keeps reports whether a resource change passes the filter
+func (f *ResourceFilter) keeps(rc ResourceChange) bool {
+	if f == nil {
+		return true
+	}
+	if f.Address != nil && !f.Address.MatchString(rc.Address) {
+		return false
+	}
+	if f.Type != nil && !f.Type.MatchString(rc.Type) {
+		return false
+	}
+	if len(f.Modules) > 0 && !inAnyModule(rc.ModuleAddress, f.Modules) {
+		return false
+	}
+	if len(f.Providers) > 0 && !matchesProvider(rc.ProviderName, f.Providers) {
+		return false
+	}
+	if len(f.Include) > 0 && !matchesResource(f.Include, rc) {
+		return false
+	}
+	return !matchesResource(f.Exclude, rc)
+}
+
+// parseModuleScopes splits a comma-separated -module value into module addresses
+func parseModuleScopes(spec string) ([]string, error) {
+	var modules []string
+	for _, module := range strings.Split(spec, ",") {
+		module = strings.TrimSpace(module)
+		if module == "" {
+			continue
+		}
+		if !strings.HasPrefix(module, "module.") {
+			return nil, fmt.Errorf("invalid module address %q: expected module.<name>", module)
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+// inAnyModule reports whether a module address is one of the given modules or
+// nested under one, including any of their instances such as module.app["eu"]
+func inAnyModule(moduleAddress string, modules []string) bool {
+	for _, module := range modules {
+		if moduleAddress == module ||
+			strings.HasPrefix(moduleAddress, module+".") ||
+			strings.HasPrefix(moduleAddress, module+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesProvider reports whether a provider address such as
+// registry.terraform.io/hashicorp/aws matches any of the given providers,
+// written as a short name (aws), a source (hashicorp/aws) or a full address
+func matchesProvider(providerName string, providers []string) bool {
+	for _, provider := range providers {
+		if providerName == provider ||
+			shortProviderName(providerName) == provider ||
+			strings.HasSuffix(providerName, "/"+provider) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesResource(patterns []string, rc ResourceChange) bool {
+	candidates := []string{rc.Address, rc.Type}
+	if rc.ModuleAddress != "" {
+		candidates = append(candidates, strings.TrimPrefix(rc.Address, rc.ModuleAddress+"."))
+	}
+	for _, pattern := range patterns {
+		for _, candidate := range candidates {
+			if ok, _ := path.Match(pattern, candidate); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// applyResourceFilter drops filtered resource changes and drift from a plan so
+// they are left out of the report and of gating decisions alike
+func applyResourceFilter(plan *TerraformPlan, filter *ResourceFilter) {
+	if filter == nil {
+		return
+	}
+	kept := filterResourceChanges(plan.ResourceChanges, filter)
+	plan.FilteredOut += countChanges(plan.ResourceChanges) - countChanges(kept)
+	plan.ResourceChanges = kept
+	plan.ResourceDrift = filterResourceChanges(plan.ResourceDrift, filter)
+}
+
+// countChanges counts resource changes other than no-ops and reads
+func countChanges(changes []ResourceChange) int {
+	count := 0
+	for _, rc := range changes {
+		if action := planAction(rc.Change.Actions); action != "no-op" && action != "read" {
+			count++
+		}
+	}
+	return count
+}
+
+// writeFilteredNote tells reviewers that the report doesn't show every change
+func writeFilteredNote(md *strings.Builder, plan *TerraformPlan) {
+	if plan.FilteredOut > 0 {
+		md.WriteString(fmt.Sprintf("> 🔎 %d resource change(s) filtered out of this report\n\n", plan.FilteredOut))
+	}
+}
+
+func filterResourceChanges(changes []ResourceChange, filter *ResourceFilter) []ResourceChange {
+	var kept []ResourceChange
+	for _, rc := range changes {
+		if filter.keeps(rc) {
+			kept = append(kept, rc)
+		}
+	}
+	return kept
+}
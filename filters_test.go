@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestApplyResourceFilter(t *testing.T) {
+	newPlan := func() *TerraformPlan {
+		return &TerraformPlan{
+			ResourceChanges: []ResourceChange{
+				{Address: "null_resource.trigger", Type: "null_resource"},
+				{Address: "module.app.null_resource.hook", ModuleAddress: "module.app", Type: "null_resource"},
+				{Address: "aws_kms_key.main", Type: "aws_kms_key"},
+				{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket"},
+			},
+			ResourceDrift: []ResourceChange{
+				{Address: "null_resource.trigger", Type: "null_resource"},
+			},
+		}
+	}
+	addresses := func(changes []ResourceChange) []string {
+		var result []string
+		for _, rc := range changes {
+			result = append(result, rc.Address)
+		}
+		return result
+	}
+
+	plan := newPlan()
+	applyResourceFilter(plan, &ResourceFilter{Exclude: []string{"null_resource.*"}})
+	if got := addresses(plan.ResourceChanges); len(got) != 2 || got[0] != "aws_kms_key.main" {
+		t.Errorf("Expected null resources to be excluded, got %v", got)
+	}
+	if len(plan.ResourceDrift) != 0 {
+		t.Errorf("Expected drift to be filtered too, got %v", addresses(plan.ResourceDrift))
+	}
+
+	plan = newPlan()
+	applyResourceFilter(plan, &ResourceFilter{Include: []string{"aws_*"}, Exclude: []string{"aws_s3_bucket"}})
+	if got := addresses(plan.ResourceChanges); len(got) != 1 || got[0] != "aws_kms_key.main" {
+		t.Errorf("Expected only the KMS key to remain, got %v", got)
+	}
+
+	if err := (&ResourceFilter{Exclude: []string{"["}}).validate(); err == nil {
+		t.Error("Expected error for invalid pattern")
+	}
+}
+
+func TestRegexResourceFilter(t *testing.T) {
+	plan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "module.network.aws_vpc.main", Type: "aws_vpc", Change: Change{Actions: []string{"update"}}},
+		{Address: "module.network.aws_subnet.a", Type: "aws_subnet", Change: Change{Actions: []string{"create"}}},
+		{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"delete"}}},
+		{Address: "aws_instance.db", Type: "aws_instance", Change: Change{Actions: []string{"no-op"}}},
+	}}
+	filter := &ResourceFilter{Address: regexp.MustCompile(`^module\.network\.`), Type: regexp.MustCompile(`^aws_(vpc|instance)$`)}
+	applyResourceFilter(plan, filter)
+
+	if len(plan.ResourceChanges) != 1 || plan.ResourceChanges[0].Address != "module.network.aws_vpc.main" {
+		t.Fatalf("Unexpected resources after filtering: %+v", plan.ResourceChanges)
+	}
+	if plan.FilteredOut != 2 {
+		t.Errorf("Expected 2 filtered changes (no-ops aren't counted), got %d", plan.FilteredOut)
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "> 🔎 2 resource change(s) filtered out of this report") {
+		t.Errorf("Expected the filtered note in output:\n%s", result)
+	}
+}
+
+func TestModuleScope(t *testing.T) {
+	modules, err := parseModuleScopes("module.networking, module.dns")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		moduleAddress string
+		expected      bool
+	}{
+		{"module.networking", true},
+		{"module.networking.module.subnets", true},
+		{`module.networking["eu"]`, true},
+		{"module.dns", true},
+		{"module.networking_legacy", false},
+		{"module.app.module.networking", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := inAnyModule(tt.moduleAddress, modules); got != tt.expected {
+			t.Errorf("inAnyModule(%q) = %v, expected %v", tt.moduleAddress, got, tt.expected)
+		}
+	}
+
+	if _, err := parseModuleScopes("networking"); err == nil {
+		t.Error("Expected an error for an address without the module. prefix")
+	}
+}
+
+func TestProviderScope(t *testing.T) {
+	tests := []struct {
+		providerName string
+		providers    []string
+		expected     bool
+	}{
+		{"registry.terraform.io/hashicorp/aws", []string{"aws"}, true},
+		{"registry.terraform.io/hashicorp/aws", []string{"kubernetes", "hashicorp/aws"}, true},
+		{"registry.terraform.io/hashicorp/aws", []string{"registry.terraform.io/hashicorp/aws"}, true},
+		{"registry.terraform.io/hashicorp/awscc", []string{"aws"}, false},
+		{"registry.terraform.io/hashicorp/kubernetes", []string{"aws"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesProvider(tt.providerName, tt.providers); got != tt.expected {
+			t.Errorf("matchesProvider(%q, %v) = %v, expected %v", tt.providerName, tt.providers, got, tt.expected)
+		}
+	}
+
+	plan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "aws_s3_bucket.logs", ProviderName: "registry.terraform.io/hashicorp/aws", Change: Change{Actions: []string{"create"}}},
+		{Address: "kubernetes_namespace.app", ProviderName: "registry.terraform.io/hashicorp/kubernetes", Change: Change{Actions: []string{"create"}}},
+	}}
+	applyResourceFilter(plan, &ResourceFilter{Providers: []string{"kubernetes"}})
+	if len(plan.ResourceChanges) != 1 || plan.ResourceChanges[0].Address != "kubernetes_namespace.app" || plan.FilteredOut != 1 {
+		t.Errorf("Unexpected filtered plan: %+v (filtered %d)", plan.ResourceChanges, plan.FilteredOut)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Finding levels
+const (
+	findingFailure = "failure"
+	findingWarning = "warning"
+)
+
+// ExternalFinding is a policy violation or scanner finding produced by an
+// external tool and merged into the report
+type ExternalFinding struct {
+	Tool    string // e.g. "conftest"
+	Level   string // failure or warning
+	RuleID  string
+	Message string
+	Address string // Resource address, when the tool reports one
+	File    string // Plan file the finding was produced for, when known
+}
+
+// appliesTo reports whether a finding was produced for the given plan
+func (f ExternalFinding) appliesTo(plan *TerraformPlan) bool {
+	return f.File == "" || plan.PlanPath == "" || samePlanFile(f.File, plan.PlanPath)
+}
+
+// samePlanFile reports whether a path reported by an external tool refers to
+// the plan file, allowing either to be relative to a different directory
+func samePlanFile(reported, planPath string) bool {
+	reported, planPath = filepath.Clean(reported), filepath.Clean(planPath)
+	separator := string(filepath.Separator)
+	return reported == planPath ||
+		strings.HasSuffix(planPath, separator+strings.TrimPrefix(reported, separator)) ||
+		strings.HasSuffix(reported, separator+planPath)
+}
+
+// attachFindings attaches findings to the resources they concern, matched by
+// the reported address or by an address mentioned in the message. Findings
+// for this plan that match no resource are returned.
+func attachFindings(summary *ResourceSummary, plan *TerraformPlan, findings []ExternalFinding) []ExternalFinding {
+	details := make(map[string][]*ResourceDetail)
+	for _, list := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range list {
+			address := list[i].Address
+			details[address] = append(details[address], &list[i])
+			if base, _, ok := splitInstanceAddress(address); ok {
+				details[base] = append(details[base], &list[i])
+			}
+		}
+	}
+
+	// Longer addresses are tried first so aws_instance.web2 isn't taken for aws_instance.web
+	addresses := make([]string, 0, len(details))
+	for address := range details {
+		addresses = append(addresses, address)
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		return len(addresses[i]) > len(addresses[j])
+	})
+
+	var unattached []ExternalFinding
+	for _, finding := range findings {
+		if !finding.appliesTo(plan) {
+			continue
+		}
+
+		targets := details[finding.Address]
+		if len(targets) == 0 {
+			for _, address := range addresses {
+				if strings.Contains(finding.Message, address) {
+					targets = details[address]
+					break
+				}
+			}
+		}
+		if len(targets) == 0 {
+			unattached = append(unattached, finding)
+			continue
+		}
+		for _, detail := range targets {
+			detail.Findings = append(detail.Findings, finding)
+		}
+	}
+	return unattached
+}
+
+func formatFinding(finding ExternalFinding) string {
+	icon := "🚫"
+	if finding.Level == findingWarning {
+		icon = "⚠️"
+	}
+	source := finding.Tool
+	if finding.RuleID != "" {
+		source += " " + finding.RuleID
+	}
+	return fmt.Sprintf("%s **%s:** %s", icon, source, finding.Message)
+}
+
+// formatFindingLines renders a resource's findings as list items
+func formatFindingLines(findings []ExternalFinding, indent string) string {
+	var lines strings.Builder
+	for _, finding := range findings {
+		lines.WriteString(fmt.Sprintf("%s- %s\n", indent, formatFinding(finding)))
+	}
+	return lines.String()
+}
+
+// writeUnattachedFindingsSection renders findings that don't concern a specific resource
+func writeUnattachedFindingsSection(md *strings.Builder, findings []ExternalFinding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	md.WriteString("### 🛡️ Policy and Scanner Findings\n\n")
+	md.WriteString(formatFindingLines(findings, ""))
+	md.WriteString("\n")
+}
+
+// writeEnvironmentUnattachedFindingsSection renders unattached findings inside a multi-plan environment section
+func writeEnvironmentUnattachedFindingsSection(md *strings.Builder, findings []ExternalFinding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	md.WriteString("**🛡️ Policy and scanner findings:**\n")
+	md.WriteString(formatFindingLines(findings, ""))
+	md.WriteString("\n")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// forgeClient calls the pull request REST API that GitHub and Gitea (or
+// Forgejo) share. The forges differ only in base URL, authentication, headers
+// and how results are paged.
+type forgeClient struct {
+	name      string // Forge name used in errors
+	baseURL   string
+	token     string
+	header    http.Header // Authentication and content negotiation headers
+	pageParam string      // Query parameter setting the page size
+	pageSize  int
+	http      *http.Client
+
+	loginOnce sync.Once
+	login     string
+	loginErr  error
+}
+
+// newForgeClient returns a client for the API at baseURL
+func newForgeClient(name, baseURL, token string, header http.Header, pageParam string, pageSize int) forgeClient {
+	return forgeClient{
+		name:      name,
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		token:     token,
+		header:    header,
+		pageParam: pageParam,
+		pageSize:  pageSize,
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a JSON request and decodes a JSON response into result when given
+func (c *forgeClient) do(method, path string, body, result interface{}) error {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, c.baseURL+path, payload)
+	if err != nil {
+		return fmt.Errorf("invalid %s API request: %w", c.name, err)
+	}
+	for name, values := range c.header {
+		request.Header[name] = values
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return fmt.Errorf("%s API request failed: %w", c.name, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("%s API %s %s returned %s: %s", c.name, method, path, response.Status, strings.TrimSpace(string(message)))
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s API response: %w", c.name, err)
+	}
+	return nil
+}
+
+// authenticatedLogin returns the login of the token's user, resolved once
+func (c *forgeClient) authenticatedLogin() (string, error) {
+	c.loginOnce.Do(func() {
+		var user struct {
+			Login string `json:"login"`
+		}
+		if err := c.do(http.MethodGet, "/user", nil, &user); err != nil {
+			c.loginErr = fmt.Errorf("failed to resolve the token's login: %w", err)
+			return
+		}
+		c.login = user.Login
+	})
+	return c.login, c.loginErr
+}
+
+// createComment posts a comment on a pull request and returns its URL
+func (c *forgeClient) createComment(repository string, number int, body string) (string, error) {
+	var comment struct {
+		HTMLURL string `json:"html_url"`
+	}
+	path := fmt.Sprintf("/repos/%s/issues/%d/comments", repository, number)
+	if err := c.do(http.MethodPost, path, map[string]string{"body": body}, &comment); err != nil {
+		return "", err
+	}
+	return comment.HTMLURL, nil
+}
+
+// issueComment is a pull request comment as returned by GitHub and Gitea
+type issueComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// listComments returns every comment on a pull request
+func (c *forgeClient) listComments(repository string, number int) ([]issueComment, error) {
+	var comments []issueComment
+	for page := 1; ; page++ {
+		var batch []issueComment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?%s=%d&page=%d", repository, number, c.pageParam, c.pageSize, page)
+		if err := c.do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			return comments, nil
+		}
+		comments = append(comments, batch...)
+	}
+}
+
+// updateComment replaces the body of a comment and returns its URL
+func (c *forgeClient) updateComment(repository string, id int64, body string) (string, error) {
+	var comment issueComment
+	path := fmt.Sprintf("/repos/%s/issues/comments/%d", repository, id)
+	if err := c.do(http.MethodPatch, path, map[string]string{"body": body}, &comment); err != nil {
+		return "", err
+	}
+	return comment.HTMLURL, nil
+}
+
+// requestReviewers asks users and teams to review a pull request
+func (c *forgeClient) requestReviewers(repository string, number int, users, teams []string) error {
+	body := map[string][]string{"reviewers": users, "team_reviewers": teams}
+	return c.do(http.MethodPost, fmt.Sprintf("/repos/%s/pulls/%d/requested_reviewers", repository, number), body, nil)
+}
+
+// pullRequestBody returns the description of a pull request
+func (c *forgeClient) pullRequestBody(repository string, number int) (string, error) {
+	var pull struct {
+		Body string `json:"body"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), nil, &pull); err != nil {
+		return "", err
+	}
+	return pull.Body, nil
+}
+
+// updatePullRequestBody replaces the description of a pull request
+func (c *forgeClient) updatePullRequestBody(repository string, number int, body string) error {
+	return c.do(http.MethodPatch, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), map[string]string{"body": body}, nil)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestForgeClientListComments(t *testing.T) {
+	var authorization, pageSize string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user" {
+			http.Error(w, "resource not accessible by integration", http.StatusForbidden)
+			return
+		}
+		authorization = r.Header.Get("Authorization")
+		// The server caps pages at 2 comments, below the requested size
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize = r.URL.Query().Get("limit") + r.URL.Query().Get("per_page")
+		if page > 3 {
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		fmt.Fprintf(w, `[{"id": %d}, {"id": %d}]`, 2*page-1, 2*page)
+	}))
+	defer ts.Close()
+
+	gitea := newGiteaClient(ts.URL, "secret")
+	comments, err := gitea.listComments("ops/infra", 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 6 || comments[5].ID != 6 {
+		t.Errorf("Expected every page of comments, got %v", comments)
+	}
+	if authorization != "token secret" || pageSize != "50" {
+		t.Errorf("Expected Gitea authentication and page size, got %q and %q", authorization, pageSize)
+	}
+
+	github := newGitHubClientAt(ts.URL, "secret")
+	if _, err := github.listComments("org/infra", 7); err != nil {
+		t.Fatal(err)
+	}
+	if authorization != "Bearer secret" || pageSize != "100" {
+		t.Errorf("Expected GitHub authentication and page size, got %q and %q", authorization, pageSize)
+	}
+
+	if _, err := gitea.authenticatedLogin(); err == nil {
+		t.Error("Expected an error when the login can't be resolved")
+	}
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if login, err := github.authenticatedLogin(); err != nil || login != githubActionsLogin {
+		t.Errorf("Expected the GitHub Actions login fallback, got %q, %v", login, err)
+	}
+}
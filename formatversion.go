@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// supportedFormatMajor is the newest plan format major version this tool understands
+const supportedFormatMajor = 1
+
+// parseFormatVersion splits a format_version such as "1.2" into major and minor parts
+func parseFormatVersion(version string) (int, int, error) {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("malformed format_version %q", version)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed format_version %q", version)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed format_version %q", version)
+	}
+	return major, minor, nil
+}
+
+// validateFormatVersion checks the plan's format_version up front, returning an
+// error for malformed versions and warnings for versions whose schema differs
+// from what the report relies on. A missing version is assumed to be the
+// current 1.x schema.
+func validateFormatVersion(plan *TerraformPlan) ([]string, error) {
+	if plan.FormatVersion == "" {
+		return []string{fmt.Sprintf(
+			"Plan has no format_version; assuming the %d.x schema of `terraform show -json <planfile>`", supportedFormatMajor)}, nil
+	}
+
+	major, minor, err := parseFormatVersion(plan.FormatVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []string
+	switch {
+	case major > supportedFormatMajor:
+		warnings = append(warnings, fmt.Sprintf(
+			"Plan format version %s is newer than supported (%d.x); the report may be incomplete",
+			plan.FormatVersion, supportedFormatMajor))
+	case major == 0 && minor < 2:
+		// 0.1 plans (Terraform 0.12-0.14) predate resource_drift, action_reason and
+		// replace_paths, so drift is absent and explanations fall back to heuristics
+		warnings = append(warnings, fmt.Sprintf(
+			"Plan format version %s predates drift detection, replacement reasons and sensitivity markers; explanations are approximate and values are masked by attribute name",
+			plan.FormatVersion))
+	case (major == 0 || (major == 1 && minor < 1)) && len(plan.ResourceDrift) > 0:
+		// relevant_attributes arrived in 1.1 (Terraform 1.2); without it drift
+		// can't be narrowed to what affects this plan
+		warnings = append(warnings, fmt.Sprintf(
+			"Plan format version %s has no relevant_attributes; drift may include changes that don't affect this plan",
+			plan.FormatVersion))
+	}
+
+	return warnings, nil
+}
+
+// adaptFormatVersion fills in what older plan schemas lack so the rest of
+// the report can rely on the 1.x schema. Plans without a valid version are
+// left as they are.
+func adaptFormatVersion(plan *TerraformPlan) {
+	maskLegacyAttributes(plan, nil)
+}
+
+// maskLegacyAttributes marks attributes of 0.1 plans whose names match
+// redactPattern (defaultRedactPattern when nil) as sensitive, at any depth.
+// Those plans carry few or no before_sensitive/after_sensitive markers;
+// existing markers are kept, so masking only ever widens.
+func maskLegacyAttributes(plan *TerraformPlan, redactPattern *regexp.Regexp) {
+	major, minor, err := parseFormatVersion(plan.FormatVersion)
+	if err != nil || major != 0 || minor >= 2 {
+		return
+	}
+	if redactPattern == nil {
+		redactPattern = regexp.MustCompile(defaultRedactPattern)
+	}
+	for i := range plan.ResourceChanges {
+		change := &plan.ResourceChanges[i].Change
+		change.BeforeSensitive = legacySensitiveMarkers(change.Before, change.BeforeSensitive, redactPattern)
+		change.AfterSensitive = legacySensitiveMarkers(change.After, change.AfterSensitive, redactPattern)
+	}
+}
+
+// legacySensitiveMarkers adds markers for the attributes of a value whose
+// names match redactPattern to its existing markers, descending into nested
+// objects and lists
+func legacySensitiveMarkers(value, markers interface{}, redactPattern *regexp.Regexp) interface{} {
+	if sensitive, ok := markers.(bool); ok && sensitive {
+		return markers
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		merged := make(map[string]interface{})
+		if existing, ok := markers.(map[string]interface{}); ok {
+			for name, marker := range existing {
+				merged[name] = marker
+			}
+		}
+		for name, child := range v {
+			if redactPattern.MatchString(name) {
+				merged[name] = true
+			} else if marker := legacySensitiveMarkers(child, merged[name], redactPattern); marker != nil {
+				merged[name] = marker
+			}
+		}
+		return merged
+	case []interface{}:
+		existing, _ := markers.([]interface{})
+		merged := make([]interface{}, len(v))
+		for i, child := range v {
+			var marker interface{}
+			if i < len(existing) {
+				marker = existing[i]
+			}
+			merged[i] = legacySensitiveMarkers(child, marker, redactPattern)
+		}
+		return merged
+	}
+	return markers
+}
+
+// writeWarnings renders plan-level warnings as a blockquote
+func writeWarnings(md *strings.Builder, warnings []string) {
+	for _, warning := range warnings {
+		md.WriteString(fmt.Sprintf("> ⚠️ %s\n", warning))
+	}
+	if len(warnings) > 0 {
+		md.WriteString("\n")
+	}
+}
+
+// planStatusWarnings describes problems reported by the applyable, complete and
+// errored fields (Terraform 1.6+). Older plans omit these fields and produce none.
+func planStatusWarnings(plan *TerraformPlan) []string {
+	var warnings []string
+	if plan.Errored {
+		warnings = append(warnings, "🛑 **Plan errored** - Terraform reported errors while planning, so this summary is partial")
+	}
+	if plan.Applyable != nil && !*plan.Applyable && !plan.Errored {
+		warnings = append(warnings, "⚠️ **Plan is not applyable** - applying this plan will not make any changes")
+	}
+	if plan.Complete != nil && !*plan.Complete {
+		warnings = append(warnings, "⚠️ **Plan is incomplete** - targeted or deferred changes mean further plan/apply rounds are needed")
+	}
+	return warnings
+}
+
+// writePlanStatus renders plan status warnings as a prominent blockquote
+func writePlanStatus(md *strings.Builder, plan *TerraformPlan) {
+	warnings := planStatusWarnings(plan)
+	for _, warning := range warnings {
+		md.WriteString(fmt.Sprintf("> %s\n", warning))
+	}
+	if len(warnings) > 0 {
+		md.WriteString("\n")
+	}
+}
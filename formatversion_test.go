@@ -0,0 +1,166 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestValidateFormatVersion(t *testing.T) {
+	tests := []struct {
+		version      string
+		wantErr      bool
+		wantWarnings int
+	}{
+		{"1.2", false, 0},
+		{"1.0", false, 0},
+		{"0.2", false, 0},
+		{"0.1", false, 1},
+		{"2.0", false, 1},
+		{"", false, 1},
+		{"latest", true, 0},
+	}
+
+	for _, test := range tests {
+		warnings, err := validateFormatVersion(&TerraformPlan{FormatVersion: test.version})
+		if (err != nil) != test.wantErr {
+			t.Errorf("validateFormatVersion(%q) error = %v, wantErr %v", test.version, err, test.wantErr)
+		}
+		if len(warnings) != test.wantWarnings {
+			t.Errorf("validateFormatVersion(%q) returned %d warnings, expected %d", test.version, len(warnings), test.wantWarnings)
+		}
+	}
+}
+
+func TestReadTerraformPlanFormatVersion(t *testing.T) {
+	dir := t.TempDir()
+
+	missing := filepath.Join(dir, "missing.json")
+	if err := os.WriteFile(missing, []byte(`{"resource_changes": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := readTerraformPlan(missing)
+	if err != nil {
+		t.Fatalf("Expected plans without format_version to be read, got %v", err)
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "no format_version") {
+		t.Errorf("Expected a missing format_version warning, got %v", plan.Warnings)
+	}
+
+	future := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(future, []byte(`{"format_version": "2.0", "resource_changes": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = readTerraformPlan(future)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(markdown, "> ⚠️ Plan format version 2.0 is newer than supported") {
+		t.Error("Expected format version warning in markdown")
+	}
+}
+
+func TestLegacyFormatVersions(t *testing.T) {
+	dir := t.TempDir()
+	legacy := filepath.Join(dir, "legacy.json")
+	content := `{"format_version": "0.1", "resource_changes": [{"address": "aws_db_instance.main", "type": "aws_db_instance",
+		"change": {"actions": ["update"], "before": {"password": "hunter2", "engine": "postgres"}, "after": {"password": "hunter3", "engine": "postgres"}}}]}`
+	if err := os.WriteFile(legacy, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := readTerraformPlan(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if strings.Contains(markdown, "hunter") || !strings.Contains(markdown, "password") {
+		t.Errorf("Expected 0.1 plan secrets to be masked by attribute name, got:\n%s", markdown)
+	}
+
+	nested := filepath.Join(dir, "nested.json")
+	content = `{"format_version": "0.1", "resource_changes": [{"address": "aws_ecs_task_definition.app", "type": "aws_ecs_task_definition",
+		"change": {"actions": ["update"],
+			"before": {"container": [{"env": {"DB_PASSWORD": "hunter2", "REGION": "eu-west-1"}}], "signing_passphrase": "opensesame"},
+			"after": {"container": [{"env": {"DB_PASSWORD": "hunter3", "REGION": "eu-west-2"}}], "signing_passphrase": "letmein"}}}]}`
+	if err := os.WriteFile(nested, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan, err = readTerraformPlan(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markdown = generateMarkdownComment(plan, ReportOptions{RedactPattern: regexp.MustCompile(`(?i)(password|passphrase)`)})
+	for _, secret := range []string{"hunter", "opensesame", "letmein"} {
+		if strings.Contains(markdown, secret) {
+			t.Errorf("Expected nested and -redact-pattern matches to be masked, found %q in:\n%s", secret, markdown)
+		}
+	}
+	if !strings.Contains(markdown, "eu-west-2") {
+		t.Errorf("Expected non-secret nested values to be shown, got:\n%s", markdown)
+	}
+
+	drift := []ResourceChange{{Address: "aws_instance.web", Change: Change{Actions: []string{"update"}}}}
+	if warnings, _ := validateFormatVersion(&TerraformPlan{FormatVersion: "1.0", ResourceDrift: drift}); len(warnings) != 1 || !strings.Contains(warnings[0], "relevant_attributes") {
+		t.Errorf("Expected a drift relevance warning for 1.0 plans, got %v", warnings)
+	}
+	if warnings, _ := validateFormatVersion(&TerraformPlan{FormatVersion: "1.1", ResourceDrift: drift}); len(warnings) != 0 {
+		t.Errorf("Expected no warning once relevant_attributes exist, got %v", warnings)
+	}
+
+	current := &TerraformPlan{FormatVersion: "1.2", ResourceChanges: []ResourceChange{{Change: Change{Before: map[string]interface{}{"password": "x"}}}}}
+	adaptFormatVersion(current)
+	if current.ResourceChanges[0].Change.BeforeSensitive != nil {
+		t.Error("Expected 1.x plans to keep Terraform's own sensitivity markers")
+	}
+}
+
+func TestPlanStatusWarnings(t *testing.T) {
+	yes, no := true, false
+
+	if warnings := planStatusWarnings(&TerraformPlan{}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for plans without status fields, got %v", warnings)
+	}
+	if warnings := planStatusWarnings(&TerraformPlan{Applyable: &yes, Complete: &yes}); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for complete applyable plan, got %v", warnings)
+	}
+
+	plan := &TerraformPlan{Applyable: &no, Complete: &no}
+	warnings := planStatusWarnings(plan)
+	if len(warnings) != 2 {
+		t.Fatalf("Expected 2 warnings, got %v", warnings)
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(markdown, "> ⚠️ **Plan is incomplete**") {
+		t.Error("Expected incomplete plan warning in markdown")
+	}
+
+	errored := planStatusWarnings(&TerraformPlan{Errored: true, Applyable: &no})
+	if len(errored) != 1 || !strings.Contains(errored[0], "Plan errored") {
+		t.Errorf("Expected only the errored warning, got %v", errored)
+	}
+}
+
+func TestMultiPlanStatusWithoutChanges(t *testing.T) {
+	plans := []PlanInfo{
+		{Plan: &TerraformPlan{Errored: true}, RelativePath: "env1/dev"},
+		{Plan: &TerraformPlan{}, RelativePath: "env1/prod"},
+	}
+	markdown := generateMultiPlanMarkdownComment(plans, ReportOptions{})
+	for _, expected := range []string{
+		"**1 environment(s) have errored, incomplete or non-applyable plans**",
+		"#### 📁 `env1/dev`",
+		"> 🛑 **Plan errored**",
+	} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in a report without changes, got:\n%s", expected, markdown)
+		}
+	}
+	if strings.Contains(markdown, "env1/prod") {
+		t.Errorf("Expected environments without problems to be left out, got:\n%s", markdown)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// exitCodeGateFailed is returned when a -fail-on rule matches a planned change.
+// It is distinct from 1 (error) and 2 (changes present with -detailed-exitcode).
+const exitCodeGateFailed = 3
+
+// gateActions are the actions -fail-on rules can match
+var gateActions = []string{"create", "update", "replace", "delete"}
+
+// FailRule matches planned changes that should fail the run, e.g. "delete"
+// or "replace:aws_db_*"
+type FailRule struct {
+	Action      string
+	TypePattern string // Resource type glob; empty matches every type
+}
+
+// parseFailOn parses a comma-separated -fail-on value such as
+// "delete,replace:aws_db_instance"
+func parseFailOn(spec string) ([]FailRule, error) {
+	var rules []FailRule
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		action, typePattern, _ := strings.Cut(part, ":")
+		if !isGateAction(action) {
+			return nil, fmt.Errorf("unknown action %q (supported: %s)", action, strings.Join(gateActions, ", "))
+		}
+		if _, err := path.Match(typePattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid resource type pattern %q: %w", typePattern, err)
+		}
+		rules = append(rules, FailRule{Action: action, TypePattern: typePattern})
+	}
+	return rules, nil
+}
+
+func isGateAction(action string) bool {
+	for _, a := range gateActions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+func (r FailRule) matches(rc ResourceChange) bool {
+	if planAction(rc.Change.Actions) != r.Action {
+		return false
+	}
+	if r.TypePattern == "" {
+		return true
+	}
+	ok, _ := path.Match(r.TypePattern, rc.Type)
+	return ok
+}
+
+func (r FailRule) String() string {
+	if r.TypePattern == "" {
+		return r.Action
+	}
+	return r.Action + ":" + r.TypePattern
+}
+
+// findGateViolations lists the planned changes matching any fail rule
+func findGateViolations(plans []PlanInfo, rules []FailRule) []string {
+	var violations []string
+	for _, planInfo := range plans {
+		for _, rc := range planInfo.Plan.ResourceChanges {
+			for _, rule := range rules {
+				if !rule.matches(rc) {
+					continue
+				}
+				violation := fmt.Sprintf("%s %s (rule %s)", planAction(rc.Change.Actions), rc.Address, rule)
+				if planInfo.RelativePath != "" {
+					violation = planInfo.RelativePath + ": " + violation
+				}
+				violations = append(violations, violation)
+				break
+			}
+		}
+	}
+	return violations
+}
+
+// exitCodeChanges is returned with -detailed-exitcode when any plan has changes,
+// mirroring `terraform plan -detailed-exitcode`
+const exitCodeChanges = 2
+
+// planHasChanges reports whether a plan would change any resource or output
+func planHasChanges(plan *TerraformPlan) bool {
+	for _, rc := range plan.ResourceChanges {
+		if action := planAction(rc.Change.Actions); action != "no-op" && action != "read" {
+			return true
+		}
+	}
+	for _, output := range plan.OutputChanges {
+		if action := planAction(output.Actions); action != "no-op" && action != "read" {
+			return true
+		}
+	}
+	return false
+}
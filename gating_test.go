@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFailOn(t *testing.T) {
+	rules, err := parseFailOn("delete, replace:aws_db_*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []FailRule{{Action: "delete"}, {Action: "replace", TypePattern: "aws_db_*"}}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, rules)
+	}
+
+	if rules, err := parseFailOn(""); err != nil || len(rules) != 0 {
+		t.Errorf("Expected no rules for empty spec, got %+v, %v", rules, err)
+	}
+	if _, err := parseFailOn("destroy"); err == nil {
+		t.Error("Expected error for unknown action")
+	}
+}
+
+func TestFindGateViolations(t *testing.T) {
+	plans := []PlanInfo{
+		{
+			RelativePath: "prod",
+			Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+				{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"delete", "create"}}},
+				{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"delete", "create"}}},
+				{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"update"}}},
+			}},
+		},
+		{
+			Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+				{Address: "aws_vpc.old", Type: "aws_vpc", Change: Change{Actions: []string{"delete"}}},
+			}},
+		},
+	}
+
+	rules, _ := parseFailOn("delete,replace:aws_db_*")
+	violations := findGateViolations(plans, rules)
+	expected := []string{
+		"prod: replace aws_db_instance.main (rule replace:aws_db_*)",
+		"delete aws_vpc.old (rule delete)",
+	}
+	if !reflect.DeepEqual(violations, expected) {
+		t.Errorf("Expected %v, got %v", expected, violations)
+	}
+}
+
+func TestPlanHasChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		plan     *TerraformPlan
+		expected bool
+	}{
+		{"empty", &TerraformPlan{}, false},
+		{"no-op and reads", &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_vpc.main", Change: Change{Actions: []string{"no-op"}}},
+			{Address: "data.aws_ami.ubuntu", Change: Change{Actions: []string{"read"}}},
+		}}, false},
+		{"resource update", &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_vpc.main", Change: Change{Actions: []string{"update"}}},
+		}}, true},
+		{"output only", &TerraformPlan{OutputChanges: map[string]Change{
+			"endpoint": {Actions: []string{"create"}},
+		}}, true},
+	}
+
+	for _, tt := range tests {
+		if got := planHasChanges(tt.plan); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
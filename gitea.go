@@ -0,0 +1,22 @@
+package main
+
+import (
+	"net/http"
+)
+
+// giteaClient calls the Gitea (or Forgejo) REST API with a token
+type giteaClient struct {
+	forgeClient
+}
+
+// newGiteaClient returns a client for the API at baseURL, e.g.
+// https://gitea.example.com/api/v1, or nil when no token is available
+func newGiteaClient(baseURL, token string) *giteaClient {
+	if token == "" || baseURL == "" {
+		return nil
+	}
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Authorization", "token "+token)
+	return &giteaClient{forgeClient: newForgeClient("Gitea", baseURL, token, header, "limit", 50)}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// defaultGitHubAPIURL is used unless GITHUB_API_URL points at GitHub Enterprise Server
+const defaultGitHubAPIURL = "https://api.github.com"
+
+// githubClient calls the GitHub REST API with a token
+type githubClient struct {
+	forgeClient
+}
+
+// newGitHubClient returns a client for the API in GITHUB_API_URL, or nil when
+// no token is available
+func newGitHubClient(token string) *githubClient {
+	if token == "" {
+		return nil
+	}
+	return newGitHubClientAt(githubAPIURL(), token)
+}
+
+// newGitHubClientAt returns a client for the API at baseURL. The token is
+// optional for public endpoints such as releases.
+func newGitHubClientAt(baseURL, token string) *githubClient {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return &githubClient{forgeClient: newForgeClient("GitHub", baseURL, token, header, "per_page", 100)}
+}
+
+// githubAPIURL returns the API base URL from GITHUB_API_URL or the default
+func githubAPIURL() string {
+	if baseURL := os.Getenv("GITHUB_API_URL"); baseURL != "" {
+		return strings.TrimSuffix(baseURL, "/")
+	}
+	return defaultGitHubAPIURL
+}
+
+// download fetches a binary resource such as an artifact archive, following
+// the redirect to storage
+func (c *githubClient) download(url string) ([]byte, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub API request: %w", err)
+	}
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub download failed: %w", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("GitHub download of %s returned %s", url, response.Status)
+	}
+	return io.ReadAll(io.LimitReader(response.Body, maxRequestBytes))
+}
+
+// workflowArtifact is a workflow run artifact as listed by the API
+type workflowArtifact struct {
+	Name               string `json:"name"`
+	ArchiveDownloadURL string `json:"archive_download_url"`
+	Expired            bool   `json:"expired"`
+}
+
+// listArtifacts returns every artifact of a workflow run
+func (c *githubClient) listArtifacts(repository string, runID int64) ([]workflowArtifact, error) {
+	var artifacts []workflowArtifact
+	for page := 1; ; page++ {
+		var batch struct {
+			Artifacts []workflowArtifact `json:"artifacts"`
+		}
+		path := fmt.Sprintf("/repos/%s/actions/runs/%d/artifacts?per_page=100&page=%d", repository, runID, page)
+		if err := c.do(http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, batch.Artifacts...)
+		if len(batch.Artifacts) < 100 {
+			return artifacts, nil
+		}
+	}
+}
+
+// githubActionsLogin authors comments posted with the GITHUB_TOKEN of a
+// workflow, which may not read GET /user
+const githubActionsLogin = "github-actions[bot]"
+
+// authenticatedLogin returns the login of the token's user, resolved once,
+// falling back to githubActionsLogin inside GitHub Actions
+func (c *githubClient) authenticatedLogin() (string, error) {
+	login, err := c.forgeClient.authenticatedLogin()
+	if err != nil && os.Getenv("GITHUB_ACTIONS") == "true" {
+		return githubActionsLogin, nil
+	}
+	return login, err
+}
+
+// pullRequestHead returns the head commit SHA of a pull request
+func (c *githubClient) pullRequestHead(repository string, number int) (string, error) {
+	var pull struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.do(http.MethodGet, fmt.Sprintf("/repos/%s/pulls/%d", repository, number), nil, &pull); err != nil {
+		return "", err
+	}
+	return pull.Head.SHA, nil
+}
+
+// commentReaction is a reaction on a comment
+type commentReaction struct {
+	Content string `json:"content"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+}
+
+// commentReactions returns the reactions on a pull request comment
+func (c *githubClient) commentReactions(repository string, id int64) ([]commentReaction, error) {
+	var reactions []commentReaction
+	path := fmt.Sprintf("/repos/%s/issues/comments/%d/reactions?per_page=100", repository, id)
+	if err := c.do(http.MethodGet, path, nil, &reactions); err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// setCommitStatus sets a commit status; state is pending, success, failure or error
+func (c *githubClient) setCommitStatus(repository, sha, state, context, description string) error {
+	body := map[string]string{"state": state, "context": context, "description": description}
+	return c.do(http.MethodPost, fmt.Sprintf("/repos/%s/statuses/%s", repository, sha), body, nil)
+}
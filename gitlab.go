@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultGitLabAPIURL is used unless GITLAB_API_URL or CI_API_V4_URL points at a self-managed instance
+const defaultGitLabAPIURL = "https://gitlab.com/api/v4"
+
+// gitlabClient calls the GitLab REST API with a token
+type gitlabClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// newGitLabClient returns a client for the configured GitLab API, or nil when
+// no token is available
+func newGitLabClient(token string) *gitlabClient {
+	if token == "" {
+		return nil
+	}
+	baseURL := os.Getenv("GITLAB_API_URL")
+	if baseURL == "" {
+		baseURL = os.Getenv("CI_API_V4_URL")
+	}
+	if baseURL == "" {
+		baseURL = defaultGitLabAPIURL
+	}
+	return &gitlabClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// do sends a request and returns the response body
+func (c *gitlabClient) do(method, path string, body interface{}) ([]byte, error) {
+	var payload io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		payload = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, c.baseURL+path, payload)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab API request: %w", err)
+	}
+	request.Header.Set("PRIVATE-TOKEN", c.token)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := c.http.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("GitLab API request failed: %w", err)
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxRequestBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitLab API response: %w", err)
+	}
+	if response.StatusCode/100 != 2 {
+		if len(data) > 1024 {
+			data = data[:1024]
+		}
+		return nil, fmt.Errorf("GitLab API %s %s returned %s: %s", method, path, response.Status, strings.TrimSpace(string(data)))
+	}
+	return data, nil
+}
+
+// projectPath returns the API path of a project by numeric ID or full path
+func projectPath(project string) string {
+	return "/projects/" + url.PathEscape(project)
+}
+
+// downloadJobArtifacts fetches the artifacts archive of a job
+func (c *gitlabClient) downloadJobArtifacts(project string, jobID int) ([]byte, error) {
+	return c.do(http.MethodGet, fmt.Sprintf("%s/jobs/%d/artifacts", projectPath(project), jobID), nil)
+}
+
+// createMergeRequestNote posts a comment on a merge request and returns its note ID
+func (c *gitlabClient) createMergeRequestNote(project string, iid int, body string) (int, error) {
+	path := fmt.Sprintf("%s/merge_requests/%d/notes", projectPath(project), iid)
+	data, err := c.do(http.MethodPost, path, map[string]string{"body": body})
+	if err != nil {
+		return 0, err
+	}
+	var note struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(data, &note); err != nil {
+		return 0, fmt.Errorf("failed to decode GitLab API response: %w", err)
+	}
+	return note.ID, nil
+}
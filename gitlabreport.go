@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// GitLabTerraformReport is the artifacts:reports:terraform format GitLab's
+// merge request widget reads. As in GitLab's own jq recipe, a replacement
+// counts as both a create and a delete.
+type GitLabTerraformReport struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+// gitlabTerraformReport totals the resource changes of every plan in the job
+func gitlabTerraformReport(plans []PlanInfo) GitLabTerraformReport {
+	var report GitLabTerraformReport
+	for _, planInfo := range plans {
+		for _, rc := range planInfo.Plan.ResourceChanges {
+			if containsAction(rc.Change.Actions, "create") {
+				report.Create++
+			}
+			if containsAction(rc.Change.Actions, "update") {
+				report.Update++
+			}
+			if containsAction(rc.Change.Actions, "delete") {
+				report.Delete++
+			}
+		}
+	}
+	return report
+}
+
+// writeGitLabTerraformReport writes the report JSON for artifacts:reports:terraform
+func writeGitLabTerraformReport(filename string, report GitLabTerraformReport) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("failed to encode GitLab terraform report: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write GitLab terraform report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitLabTerraformReport(t *testing.T) {
+	plans := []PlanInfo{
+		{Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_sqs_queue.q", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_db_instance.main", Change: Change{Actions: []string{"delete", "create"}}},
+		}}},
+		{Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_instance.web", Change: Change{Actions: []string{"update"}}},
+			{Address: "aws_s3_bucket.b", Change: Change{Actions: []string{"delete"}}},
+			{Address: "data.aws_ami.ubuntu", Change: Change{Actions: []string{"read"}}},
+		}}},
+	}
+
+	report := gitlabTerraformReport(plans)
+	if report != (GitLabTerraformReport{Create: 2, Update: 1, Delete: 2}) {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	filename := filepath.Join(t.TempDir(), "tfplan-report.json")
+	if err := writeGitLabTerraformReport(filename, report); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"create":2,"update":1,"delete":2}` {
+		t.Errorf("Unexpected report JSON: %s", data)
+	}
+}
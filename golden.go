@@ -0,0 +1,23 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// exitCodeCheckFailed is returned when -check finds the report differs from
+// the golden file
+const exitCodeCheckFailed = 4
+
+// checkGolden compares a rendered report against a committed golden file and
+// returns a unified diff from the golden file to the report, empty when they match
+func checkGolden(markdown, goldenFile string) (string, error) {
+	golden, err := os.ReadFile(goldenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read golden file: %w", err)
+	}
+	if string(golden) == markdown {
+		return "", nil
+	}
+	return fmt.Sprintf("--- %s\n+++ rendered report\n%s", goldenFile, unifiedDiff(string(golden), markdown)), nil
+}
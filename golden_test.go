@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckGolden(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "golden.md")
+	if err := os.WriteFile(golden, []byte("## Summary\n\n- `aws_instance.web`\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := checkGolden("## Summary\n\n- `aws_instance.web`\n", golden)
+	if err != nil || diff != "" {
+		t.Errorf("Expected match, got diff %q, error %v", diff, err)
+	}
+
+	diff, err = checkGolden("## Summary\n\n- `aws_instance.api`\n", golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "- - `aws_instance.web`") || !strings.Contains(diff, "+ - `aws_instance.api`") {
+		t.Errorf("Expected unified diff, got:\n%s", diff)
+	}
+
+	if _, err := checkGolden("", filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Error("Expected error for missing golden file")
+	}
+}
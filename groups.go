@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// changeGroup holds the changes made to resources sharing a key such as a
+// module address or resource type
+type changeGroup struct {
+	Key     string
+	Create  []ResourceDetail
+	Update  []ResourceDetail
+	Replace []ResourceDetail
+	Delete  []ResourceDetail
+}
+
+func (g *changeGroup) total() int {
+	return len(g.Create) + len(g.Update) + len(g.Replace) + len(g.Delete)
+}
+
+// formatCounts renders the group's per-action counts, e.g. "2 create, 1 update"
+func (g *changeGroup) formatCounts() string {
+	var counts []string
+	for _, c := range []struct {
+		n     int
+		label string
+	}{
+		{len(g.Create), "create"},
+		{len(g.Update), "update"},
+		{len(g.Replace), "replace"},
+		{len(g.Delete), "delete"},
+	} {
+		if c.n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.n, c.label))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// groupChanges groups a summary's changes by a key derived from each resource
+// change. Groups are sorted by key.
+func groupChanges(plan *TerraformPlan, summary ResourceSummary, key func(ResourceChange) string) []*changeGroup {
+	keys := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		keys[rc.Address] = key(rc)
+	}
+
+	groups := make(map[string]*changeGroup)
+	group := func(address string) *changeGroup {
+		k := keys[address]
+		if groups[k] == nil {
+			groups[k] = &changeGroup{Key: k}
+		}
+		return groups[k]
+	}
+
+	for _, resource := range summary.Create {
+		g := group(resource.Address)
+		g.Create = append(g.Create, resource)
+	}
+	for _, resource := range summary.Update {
+		g := group(resource.Address)
+		g.Update = append(g.Update, resource)
+	}
+	for _, resource := range summary.Replace {
+		g := group(resource.Address)
+		g.Replace = append(g.Replace, resource)
+	}
+	for _, resource := range summary.Delete {
+		g := group(resource.Address)
+		g.Delete = append(g.Delete, resource)
+	}
+
+	sorted := make([]*changeGroup, 0, len(groups))
+	for _, g := range groups {
+		sorted = append(sorted, g)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+	return sorted
+}
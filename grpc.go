@@ -0,0 +1,350 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// grpcServicePath prefixes the RPC paths of the RenderService in
+// api/tfplan_commenter.proto
+const grpcServicePath = "/tfplancommenter.v1.RenderService/"
+
+// gRPC status codes returned by the service
+const (
+	grpcOK                 = 0
+	grpcInvalidArgument    = 3
+	grpcFailedPrecondition = 9
+	grpcUnimplemented      = 12
+	grpcInternal           = 13
+	grpcUnavailable        = 14
+	grpcUnauthenticated    = 16
+)
+
+// grpcSignatureMetadata carries the request message signature when the
+// server has a shared secret
+const grpcSignatureMetadata = "X-Hub-Signature-256"
+
+// grpcError is an RPC failure with its gRPC status code
+type grpcError struct {
+	code    int
+	message string
+}
+
+func (e *grpcError) Error() string {
+	return e.message
+}
+
+func grpcErrorf(code int, format string, args ...interface{}) error {
+	return &grpcError{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// handleGRPC serves the RenderService RPCs: one length-prefixed protobuf
+// message in, one out, with the status in the grpc-status trailer
+func (s *server) handleGRPC(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 {
+		http.Error(w, "gRPC requires HTTP/2", http.StatusHTTPVersionNotSupported)
+		return
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/grpc" && !strings.HasPrefix(contentType, "application/grpc+proto") {
+		http.Error(w, fmt.Sprintf("unsupported content type %q", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Add("Trailer", "Grpc-Status")
+	w.Header().Add("Trailer", "Grpc-Message")
+
+	response, err := s.serveGRPC(r)
+	if err == nil {
+		_, err = w.Write(grpcFrame(response))
+	}
+	code, message := grpcOK, ""
+	if err != nil {
+		code, message = grpcInternal, err.Error()
+		var rpcErr *grpcError
+		if errors.As(err, &rpcErr) {
+			code = rpcErr.code
+		}
+	}
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", grpcEncodeMessage(message))
+}
+
+// serveGRPC reads, authenticates and dispatches one RPC, returning the
+// serialized response message
+func (s *server) serveGRPC(r *http.Request) ([]byte, error) {
+	message, err := readGRPCFrame(io.LimitReader(r.Body, maxRequestBytes+5))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authenticateGRPC(r, message); err != nil {
+		return nil, err
+	}
+
+	switch method := r.PathValue("method"); method {
+	case "RenderPlan":
+		return s.grpcRenderPlan(message)
+	case "RenderMultiPlan":
+		return s.grpcRenderMultiPlan(message)
+	case "Publish":
+		return s.grpcPublish(message)
+	default:
+		return nil, grpcErrorf(grpcUnimplemented, "unknown method %q", method)
+	}
+}
+
+// authenticateGRPC accepts callers with a verified client certificate or a
+// valid signature of the request message. Like requireSignature, it lets
+// everything through when the server has no secret.
+func (s *server) authenticateGRPC(r *http.Request, message []byte) error {
+	if s.secret == "" || (r.TLS != nil && len(r.TLS.VerifiedChains) > 0) {
+		return nil
+	}
+	expected := signPayload(s.secret, message)
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(grpcSignatureMetadata)), []byte(expected)) != 1 {
+		return grpcErrorf(grpcUnauthenticated, "invalid or missing %s metadata", strings.ToLower(grpcSignatureMetadata))
+	}
+	return nil
+}
+
+func (s *server) grpcRenderPlan(message []byte) ([]byte, error) {
+	var data, options []byte
+	err := decodeProto(message, func(field int, _ uint64, value []byte) {
+		switch field {
+		case 1:
+			data = value
+		case 2:
+			options = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.grpcOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := parseTerraformPlan(data, "")
+	if err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "%v", err)
+	}
+	return appendProtoBytes(nil, 1, []byte(generateMarkdownComment(plan, opts))), nil
+}
+
+func (s *server) grpcRenderMultiPlan(message []byte) ([]byte, error) {
+	var files [][]byte
+	var options []byte
+	err := decodeProto(message, func(field int, _ uint64, value []byte) {
+		switch field {
+		case 1:
+			files = append(files, value)
+		case 2:
+			options = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.grpcOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, grpcErrorf(grpcInvalidArgument, "plans are required")
+	}
+
+	var plans []PlanInfo
+	for _, file := range files {
+		var path string
+		var data []byte
+		err := decodeProto(file, func(field int, _ uint64, value []byte) {
+			switch field {
+			case 1:
+				path = string(value)
+			case 2:
+				data = value
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		plan, err := parseTerraformPlan(data, path)
+		if err != nil {
+			return nil, grpcErrorf(grpcInvalidArgument, "%s: %v", path, err)
+		}
+		plans = append(plans, PlanInfo{Plan: plan, RelativePath: path})
+	}
+	return appendProtoBytes(nil, 1, []byte(generateMultiPlanMarkdownComment(plans, opts))), nil
+}
+
+func (s *server) grpcPublish(message []byte) ([]byte, error) {
+	if s.github == nil {
+		return nil, grpcErrorf(grpcFailedPrecondition, "publishing is not configured (set GITHUB_TOKEN on the server)")
+	}
+	var repository, commit string
+	var number uint64
+	var data, options []byte
+	err := decodeProto(message, func(field int, varint uint64, value []byte) {
+		switch field {
+		case 1:
+			repository = string(value)
+		case 2:
+			number = varint
+		case 3:
+			data = value
+		case 4:
+			options = value
+		case 5:
+			commit = string(value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	opts, err := s.grpcOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	pullRequest := int(int32(number))
+	if repository == "" || pullRequest <= 0 || len(data) == 0 {
+		return nil, grpcErrorf(grpcInvalidArgument, "repository, pull_request and plan are required")
+	}
+	plan, err := parseTerraformPlan(data, "")
+	if err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "%v", err)
+	}
+
+	plans := []PlanInfo{{Plan: plan}}
+	commentURL, err := s.publishPlanComment(repository, pullRequest, commit, "", plans, generateMarkdownComment(plan, opts))
+	if err != nil {
+		return nil, grpcErrorf(grpcUnavailable, "%v", err)
+	}
+	return appendProtoBytes(nil, 1, []byte(commentURL)), nil
+}
+
+// grpcOptions applies a RenderOptions message the way the HTTP API applies
+// its query parameters
+func (s *server) grpcOptions(message []byte) (ReportOptions, error) {
+	query := url.Values{}
+	err := decodeProto(message, func(field int, varint uint64, value []byte) {
+		switch field {
+		case 1:
+			query.Set("format", string(value))
+		case 2:
+			query.Set("sort", string(value))
+		case 3:
+			query.Set("group_by", string(value))
+		case 4:
+			query.Set("max_detail", strconv.Itoa(int(int32(varint))))
+		case 5:
+			query.Set("style", string(value))
+		}
+	})
+	if err != nil {
+		return s.opts, err
+	}
+	opts, err := s.queryOptions(query)
+	if err != nil {
+		return opts, grpcErrorf(grpcInvalidArgument, "%v", err)
+	}
+	return opts, nil
+}
+
+// readGRPCFrame reads a single uncompressed length-prefixed message
+func readGRPCFrame(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "failed to read request message: %v", err)
+	}
+	if header[0] != 0 {
+		return nil, grpcErrorf(grpcUnimplemented, "compressed messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > maxRequestBytes {
+		return nil, grpcErrorf(grpcInvalidArgument, "request message is %d bytes, over the %d byte limit", length, maxRequestBytes)
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(r, message); err != nil {
+		return nil, grpcErrorf(grpcInvalidArgument, "failed to read request message: %v", err)
+	}
+	return message, nil
+}
+
+// grpcFrame prefixes a message with the uncompressed flag and its length
+func grpcFrame(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+	return append(frame, message...)
+}
+
+// grpcEncodeMessage percent-encodes a grpc-message trailer value
+func grpcEncodeMessage(message string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(message); i++ {
+		if c := message[i]; c >= ' ' && c <= '~' && c != '%' {
+			encoded.WriteByte(c)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+	return encoded.String()
+}
+
+// decodeProto calls field for each field of a protobuf message, with the
+// value of varint fields or the contents of length-delimited ones. Fixed-width
+// fields are skipped; the service's messages have none.
+func decodeProto(message []byte, field func(number int, varint uint64, value []byte)) error {
+	for len(message) > 0 {
+		key, n := binary.Uvarint(message)
+		if n <= 0 {
+			return grpcErrorf(grpcInvalidArgument, "malformed request message")
+		}
+		message = message[n:]
+
+		number := int(key >> 3)
+		switch key & 7 {
+		case 0:
+			value, n := binary.Uvarint(message)
+			if n <= 0 {
+				return grpcErrorf(grpcInvalidArgument, "malformed request message")
+			}
+			message = message[n:]
+			field(number, value, nil)
+		case 1, 5:
+			size := 8
+			if key&7 == 5 {
+				size = 4
+			}
+			if len(message) < size {
+				return grpcErrorf(grpcInvalidArgument, "malformed request message")
+			}
+			message = message[size:]
+		case 2:
+			length, n := binary.Uvarint(message)
+			if n <= 0 || length > uint64(len(message)-n) {
+				return grpcErrorf(grpcInvalidArgument, "malformed request message")
+			}
+			message = message[n:]
+			field(number, 0, message[:length])
+			message = message[length:]
+		default:
+			return grpcErrorf(grpcInvalidArgument, "malformed request message")
+		}
+	}
+	return nil
+}
+
+// appendProtoBytes appends a length-delimited protobuf field
+func appendProtoBytes(message []byte, number int, value []byte) []byte {
+	message = binary.AppendUvarint(message, uint64(number)<<3|2)
+	message = binary.AppendUvarint(message, uint64(len(value)))
+	return append(message, value...)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// callGRPC sends one unary RPC and returns the response message and status
+func callGRPC(t *testing.T, ts *httptest.Server, method string, message []byte, header http.Header) ([]byte, string, string) {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodPost, ts.URL+grpcServicePath+method, bytes.NewReader(grpcFrame(message)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	request.Header.Set("Content-Type", "application/grpc")
+	request.Header.Set("TE", "trailers")
+
+	response, err := ts.Client().Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.ProtoMajor != 2 {
+		t.Fatalf("Expected HTTP/2, got %s", response.Proto)
+	}
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reply []byte
+	if len(body) > 0 {
+		reply, err = readGRPCFrame(bytes.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return reply, response.Trailer.Get("Grpc-Status"), response.Trailer.Get("Grpc-Message")
+}
+
+// protoString returns field 1 of a response message
+func protoString(t *testing.T, message []byte) string {
+	t.Helper()
+	var value string
+	if err := decodeProto(message, func(field int, _ uint64, data []byte) {
+		if field == 1 {
+			value = string(data)
+		}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func newGRPCTestServer(s *server) *httptest.Server {
+	ts := httptest.NewUnstartedServer(s.handler())
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	return ts
+}
+
+func TestGRPCRenderPlan(t *testing.T) {
+	ts := newGRPCTestServer(&server{opts: ReportOptions{Sort: sortByAddress}})
+	defer ts.Close()
+
+	options := appendProtoBytes(nil, 1, []byte(formatMarkdown))
+	request := appendProtoBytes(appendProtoBytes(nil, 1, []byte(serverTestPlan)), 2, options)
+	reply, status, message := callGRPC(t, ts, "RenderPlan", request, nil)
+	if status != "0" {
+		t.Fatalf("Expected status 0, got %s: %s", status, message)
+	}
+	if markdown := protoString(t, reply); !strings.Contains(markdown, "aws_instance.web") {
+		t.Errorf("Expected the resource in the rendered report, got:\n%s", markdown)
+	}
+
+	badOptions := appendProtoBytes(nil, 2, []byte("bogus"))
+	request = appendProtoBytes(appendProtoBytes(nil, 1, []byte(serverTestPlan)), 2, badOptions)
+	if _, status, _ := callGRPC(t, ts, "RenderPlan", request, nil); status != "3" {
+		t.Errorf("Expected INVALID_ARGUMENT for an invalid sort, got %s", status)
+	}
+	if _, status, _ := callGRPC(t, ts, "RenderPlan", appendProtoBytes(nil, 1, []byte("{")), nil); status != "3" {
+		t.Errorf("Expected INVALID_ARGUMENT for an invalid plan, got %s", status)
+	}
+	if _, status, _ := callGRPC(t, ts, "Destroy", nil, nil); status != "12" {
+		t.Errorf("Expected UNIMPLEMENTED for an unknown method, got %s", status)
+	}
+}
+
+func TestGRPCRenderMultiPlan(t *testing.T) {
+	ts := newGRPCTestServer(&server{opts: ReportOptions{Sort: sortByAddress}})
+	defer ts.Close()
+
+	var request []byte
+	for _, path := range []string{"env1/dev", "env1/prod"} {
+		file := appendProtoBytes(appendProtoBytes(nil, 1, []byte(path)), 2, []byte(serverTestPlan))
+		request = appendProtoBytes(request, 1, file)
+	}
+	reply, status, message := callGRPC(t, ts, "RenderMultiPlan", request, nil)
+	if status != "0" {
+		t.Fatalf("Expected status 0, got %s: %s", status, message)
+	}
+	markdown := protoString(t, reply)
+	for _, expected := range []string{"Multi-Environment", "env1/dev", "env1/prod"} {
+		if !strings.Contains(markdown, expected) {
+			t.Errorf("Expected %q in the rendered report, got:\n%s", expected, markdown)
+		}
+	}
+
+	if _, status, _ := callGRPC(t, ts, "RenderMultiPlan", nil, nil); status != "3" {
+		t.Errorf("Expected INVALID_ARGUMENT without plans, got %s", status)
+	}
+}
+
+func TestGRPCPublish(t *testing.T) {
+	var posted string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+			return
+		case r.URL.Path == "/repos/org/infra/issues/7/comments" && r.Method == http.MethodGet:
+			io.WriteString(w, `[]`)
+			return
+		case r.URL.Path != "/repos/org/infra/issues/7/comments" || r.Method != http.MethodPost:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+			return
+		}
+		var comment map[string]string
+		json.NewDecoder(r.Body).Decode(&comment)
+		posted = comment["body"]
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7#issuecomment-1"}`)
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+
+	ts := newGRPCTestServer(&server{opts: ReportOptions{Sort: sortByAddress}, github: newGitHubClient("token"), secret: "s3cret"})
+	defer ts.Close()
+
+	request := appendProtoBytes(nil, 1, []byte("org/infra"))
+	request = binary.AppendUvarint(binary.AppendUvarint(request, 2<<3), 7)
+	request = appendProtoBytes(request, 3, []byte(serverTestPlan))
+	request = appendProtoBytes(request, 5, []byte("abc123"))
+
+	if _, status, _ := callGRPC(t, ts, "Publish", request, nil); status != "16" {
+		t.Errorf("Expected UNAUTHENTICATED without a signature, got %s", status)
+	}
+
+	header := http.Header{}
+	header.Set(grpcSignatureMetadata, signPayload("s3cret", request))
+	reply, status, message := callGRPC(t, ts, "Publish", request, header)
+	if status != "0" {
+		t.Fatalf("Expected status 0, got %s: %s", status, message)
+	}
+	if url := protoString(t, reply); url != "https://github.com/org/infra/pull/7#issuecomment-1" {
+		t.Errorf("Unexpected comment URL %q", url)
+	}
+	if !strings.Contains(posted, "aws_instance.web") {
+		t.Errorf("Expected the report to be posted, got:\n%s", posted)
+	}
+
+	unconfigured := newGRPCTestServer(&server{})
+	defer unconfigured.Close()
+	if _, status, _ := callGRPC(t, unconfigured, "Publish", request, nil); status != "9" {
+		t.Errorf("Expected FAILED_PRECONDITION without a token, got %s", status)
+	}
+}
+
+func TestGRPCRequiresHTTP2(t *testing.T) {
+	ts := httptest.NewServer((&server{}).handler())
+	defer ts.Close()
+
+	response, err := http.Post(ts.URL+grpcServicePath+"RenderPlan", "application/grpc", bytes.NewReader(grpcFrame(nil)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusHTTPVersionNotSupported {
+		t.Errorf("Expected 505 over HTTP/1.1, got %d", response.StatusCode)
+	}
+}
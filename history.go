@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistoryRecord is one environment's changes in one run, stored as a row of
+// an SQLite -history database or as a line of JSON in any other -history file
+type HistoryRecord struct {
+	Time        time.Time `json:"time"`
+	Environment string    `json:"environment"`
+	Create      int       `json:"create"`
+	Update      int       `json:"update"`
+	Replace     int       `json:"replace"`
+	Delete      int       `json:"delete"`
+	RiskScore   float64   `json:"risk_score"`
+	RiskLevel   string    `json:"risk_level"`
+}
+
+// Total returns the number of changed resources
+func (r HistoryRecord) Total() int {
+	return r.Create + r.Update + r.Replace + r.Delete
+}
+
+// historyRecords summarizes each plan of a run for the history store
+func historyRecords(plans []PlanInfo, opts ReportOptions, now time.Time) []HistoryRecord {
+	records := make([]HistoryRecord, 0, len(plans))
+	for _, planInfo := range plans {
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		risk := assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk)
+
+		env := planInfo.RelativePath
+		if env == "" {
+			env = planInfo.Plan.PlanPath
+		}
+		records = append(records, HistoryRecord{
+			Time:        now.UTC(),
+			Environment: env,
+			Create:      len(summary.Create),
+			Update:      len(summary.Update),
+			Replace:     len(summary.Replace),
+			Delete:      len(summary.Delete),
+			RiskScore:   risk.Score,
+			RiskLevel:   risk.Level,
+		})
+	}
+	return records
+}
+
+// appendHistory adds records to the history file, creating it if needed
+func appendHistory(filename string, records []HistoryRecord) error {
+	if isSQLiteHistory(filename) {
+		return appendSQLiteHistory(filename, records)
+	}
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	return nil
+}
+
+// readHistory loads every record from a history file
+func readHistory(filename string) ([]HistoryRecord, error) {
+	if isSQLiteHistory(filename) {
+		return readSQLiteHistory(filename)
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	var records []HistoryRecord
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("invalid history record on line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+	return records, nil
+}
+
+// EnvironmentTrend aggregates an environment's history
+type EnvironmentTrend struct {
+	Environment string
+	Runs        int // Runs that planned this environment
+	ChangedRuns int // Runs with at least one change
+	Changes     int
+	Deletes     int // Deletions and replacements
+	MaxRisk     float64
+	LastChanged time.Time
+}
+
+// environmentTrends aggregates history per environment, most changed first
+func environmentTrends(records []HistoryRecord) []EnvironmentTrend {
+	byEnv := make(map[string]*EnvironmentTrend)
+	for _, record := range records {
+		trend := byEnv[record.Environment]
+		if trend == nil {
+			trend = &EnvironmentTrend{Environment: record.Environment}
+			byEnv[record.Environment] = trend
+		}
+		trend.Runs++
+		trend.Changes += record.Total()
+		trend.Deletes += record.Delete + record.Replace
+		trend.MaxRisk = max(trend.MaxRisk, record.RiskScore)
+		if record.Total() > 0 {
+			trend.ChangedRuns++
+			if record.Time.After(trend.LastChanged) {
+				trend.LastChanged = record.Time
+			}
+		}
+	}
+
+	trends := make([]EnvironmentTrend, 0, len(byEnv))
+	for _, trend := range byEnv {
+		trends = append(trends, *trend)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Changes != trends[j].Changes {
+			return trends[i].Changes > trends[j].Changes
+		}
+		return trends[i].Environment < trends[j].Environment
+	})
+	return trends
+}
+
+// weekStart returns the Monday starting the week of t
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// generateTrendsMarkdown renders churn and change frequency per environment
+// and the number of changes per week
+func generateTrendsMarkdown(records []HistoryRecord) string {
+	var md strings.Builder
+
+	md.WriteString("## 📈 Terraform Change Trends\n\n")
+	if len(records) == 0 {
+		md.WriteString("No runs recorded yet.\n")
+		return md.String()
+	}
+
+	first, last := records[0].Time, records[0].Time
+	for _, record := range records {
+		if record.Time.Before(first) {
+			first = record.Time
+		}
+		if record.Time.After(last) {
+			last = record.Time
+		}
+	}
+	md.WriteString(fmt.Sprintf("**Period:** %s to %s\n\n", first.Format("2006-01-02"), last.Format("2006-01-02")))
+
+	md.WriteString("### 🔥 Most Changed Environments\n\n")
+	md.WriteString("| Environment | Runs | Runs with Changes | Resources Changed | Deletes/Replaces | Max Risk | Last Changed |\n")
+	md.WriteString("|-------------|------|-------------------|-------------------|------------------|----------|--------------|\n")
+	for _, trend := range environmentTrends(records) {
+		lastChanged := "-"
+		if !trend.LastChanged.IsZero() {
+			lastChanged = trend.LastChanged.Format("2006-01-02")
+		}
+		md.WriteString(fmt.Sprintf("| `%s` | %d | %d | %d | %d | %.0f | %s |\n",
+			trend.Environment, trend.Runs, trend.ChangedRuns, trend.Changes, trend.Deletes, trend.MaxRisk, lastChanged))
+	}
+	md.WriteString("\n")
+
+	weekly := make(map[time.Time]int)
+	for _, record := range records {
+		weekly[weekStart(record.Time)] += record.Total()
+	}
+	weeks := make([]time.Time, 0, len(weekly))
+	for week := range weekly {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool {
+		return weeks[i].Before(weeks[j])
+	})
+
+	md.WriteString("### 📅 Changes per Week\n\n")
+	md.WriteString("| Week of | Resources Changed |\n")
+	md.WriteString("|---------|-------------------|\n")
+	for _, week := range weeks {
+		md.WriteString(fmt.Sprintf("| %s | %d |\n", week.Format("2006-01-02"), weekly[week]))
+	}
+	md.WriteString("\n")
+
+	return md.String()
+}
+
+// runReportCommand implements `tfplan-commenter report trends <history-file> [output.md]`
+func runReportCommand(args []string) {
+	if len(args) < 2 || args[0] != "trends" {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter report trends <history-file> [output.md]")
+		os.Exit(1)
+	}
+
+	outputFile := "terraform-trends.md"
+	if len(args) > 2 {
+		outputFile = args[2]
+	}
+
+	records, err := readHistory(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading history: %v\n", err)
+		os.Exit(1)
+	}
+	if err := os.WriteFile(outputFile, []byte(generateTrendsMarkdown(records)), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Trend report generated from %d record(s): %s\n", len(records), outputFile)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryRoundTrip(t *testing.T) {
+	for _, name := range []string{"history.jsonl", "history.sqlite"} {
+		t.Run(name, func(t *testing.T) {
+			testHistoryRoundTrip(t, filepath.Join(t.TempDir(), name))
+		})
+	}
+}
+
+func testHistoryRoundTrip(t *testing.T, file string) {
+	plan := func(actions ...string) *TerraformPlan {
+		var changes []ResourceChange
+		for i, action := range actions {
+			changes = append(changes, ResourceChange{
+				Address: "aws_instance.web" + strings.Repeat("x", i),
+				Type:    "aws_instance",
+				Change:  Change{Actions: []string{action}},
+			})
+		}
+		return &TerraformPlan{ResourceChanges: changes}
+	}
+
+	monday := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	runs := []struct {
+		time  time.Time
+		plans []PlanInfo
+	}{
+		{monday, []PlanInfo{{Plan: plan("create", "create"), RelativePath: "dev"}, {Plan: plan("delete"), RelativePath: "prod"}}},
+		{monday.AddDate(0, 0, 3), []PlanInfo{{Plan: plan("update"), RelativePath: "dev"}, {Plan: plan(), RelativePath: "prod"}}},
+		{monday.AddDate(0, 0, 8), []PlanInfo{{Plan: plan("update"), RelativePath: "dev"}}},
+	}
+	for _, run := range runs {
+		if err := appendHistory(file, historyRecords(run.plans, ReportOptions{}, run.time)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := readHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Fatalf("Expected 5 records, got %+v", records)
+	}
+
+	result := generateTrendsMarkdown(records)
+	for _, expected := range []string{
+		"**Period:** 2026-03-02 to 2026-03-10\n\n",
+		"| `dev` | 3 | 3 | 4 | 0 | 2 | 2026-03-10 |\n| `prod` | 2 | 1 | 1 | 1 | 20 | 2026-03-02 |",
+		"| 2026-03-02 | 4 |\n| 2026-03-09 | 1 |\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in trends, got:\n%s", expected, result)
+		}
+	}
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// iamPolicyAttributes lists the attributes of AWS resources that hold IAM policy documents
+var iamPolicyAttributes = map[string][]string{
+	"aws_iam_policy":       {"policy"},
+	"aws_iam_role_policy":  {"policy"},
+	"aws_iam_user_policy":  {"policy"},
+	"aws_iam_group_policy": {"policy"},
+	"aws_iam_role":         {"assume_role_policy", "inline_policy"},
+}
+
+// IAMDelta summarizes the permissions a resource change grants and revokes
+type IAMDelta struct {
+	Address string
+	Added   []string // e.g. "Allow iam:PassRole on *"
+	Removed []string
+}
+
+// analyzeIAMChanges computes permission deltas for IAM resources across the
+// AWS, GCP and Azure providers
+func analyzeIAMChanges(plan *TerraformPlan) []IAMDelta {
+	var deltas []IAMDelta
+	for _, rc := range plan.ResourceChanges {
+		action := planAction(rc.Change.Actions)
+		if action == "no-op" || action == "read" {
+			continue
+		}
+
+		before := iamGrants(rc.Type, rc.Change.Before)
+		after := iamGrants(rc.Type, rc.Change.After)
+		delta := IAMDelta{Address: rc.Address}
+		for grant := range after {
+			if !before[grant] {
+				delta.Added = append(delta.Added, grant)
+			}
+		}
+		for grant := range before {
+			if !after[grant] {
+				delta.Removed = append(delta.Removed, grant)
+			}
+		}
+		if len(delta.Added) == 0 && len(delta.Removed) == 0 {
+			continue
+		}
+		sort.Strings(delta.Added)
+		sort.Strings(delta.Removed)
+		deltas = append(deltas, delta)
+	}
+
+	sort.SliceStable(deltas, func(i, j int) bool {
+		return deltas[i].Address < deltas[j].Address
+	})
+	return deltas
+}
+
+// iamGrants describes the permissions granted by an IAM resource's values
+func iamGrants(resourceType string, values interface{}) map[string]bool {
+	grants := make(map[string]bool)
+	attrs, ok := values.(map[string]interface{})
+	if !ok {
+		return grants
+	}
+
+	switch {
+	case iamPolicyAttributes[resourceType] != nil:
+		for _, attribute := range iamPolicyAttributes[resourceType] {
+			var documents []string
+			if policy, ok := attrs[attribute].(string); ok {
+				documents = append(documents, policy)
+			}
+			// Inline policies are nested blocks with their own policy document
+			for _, inline := range objectList(attrs[attribute]) {
+				if policy, ok := inline["policy"].(string); ok {
+					documents = append(documents, policy)
+				}
+			}
+			for _, document := range documents {
+				if doc, ok := decodeJSONDocument(document); ok {
+					addPolicyStatementGrants(grants, doc)
+				}
+			}
+		}
+	case resourceType == "aws_iam_role_policy_attachment", resourceType == "aws_iam_user_policy_attachment",
+		resourceType == "aws_iam_group_policy_attachment", resourceType == "aws_iam_policy_attachment":
+		if arn, ok := attrs["policy_arn"].(string); ok {
+			grants[fmt.Sprintf("Attach %s", arn)] = true
+		}
+	case strings.HasPrefix(resourceType, "google_") && strings.HasSuffix(resourceType, "_iam_member"):
+		if member, ok := attrs["member"].(string); ok {
+			grants[formatRoleGrant(attrs["role"], member)] = true
+		}
+	case strings.HasPrefix(resourceType, "google_") && strings.HasSuffix(resourceType, "_iam_binding"):
+		for _, member := range stringList(attrs["members"]) {
+			grants[formatRoleGrant(attrs["role"], member)] = true
+		}
+	case strings.HasPrefix(resourceType, "google_") && strings.HasSuffix(resourceType, "_iam_policy"):
+		if policy, ok := attrs["policy_data"].(string); ok {
+			if doc, ok := decodeJSONDocument(policy); ok {
+				if bindings, ok := doc.(map[string]interface{}); ok {
+					for _, binding := range objectList(bindings["bindings"]) {
+						for _, member := range stringList(binding["members"]) {
+							grants[formatRoleGrant(binding["role"], member)] = true
+						}
+					}
+				}
+			}
+		}
+	case resourceType == "azurerm_role_assignment":
+		role := attrs["role_definition_name"]
+		if role == nil {
+			role = attrs["role_definition_id"]
+		}
+		grants[fmt.Sprintf("Assign %v to %v on %v", role, attrs["principal_id"], attrs["scope"])] = true
+	case resourceType == "azurerm_role_definition":
+		scopes := strings.Join(stringList(attrs["assignable_scopes"]), ", ")
+		for _, permission := range objectList(attrs["permissions"]) {
+			for _, action := range append(stringList(permission["actions"]), stringList(permission["data_actions"])...) {
+				grants[fmt.Sprintf("Allow %s on %s", action, scopes)] = true
+			}
+			for _, action := range append(stringList(permission["not_actions"]), stringList(permission["not_data_actions"])...) {
+				grants[fmt.Sprintf("Exclude %s on %s", action, scopes)] = true
+			}
+		}
+	}
+	return grants
+}
+
+func formatRoleGrant(role interface{}, member string) string {
+	return fmt.Sprintf("Grant %v to %s", role, member)
+}
+
+// addPolicyStatementGrants expands an AWS policy document into one grant per
+// effect, action and resource (or principal, for trust policies)
+func addPolicyStatementGrants(grants map[string]bool, doc interface{}) {
+	policy, ok := doc.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	statements, ok := policy["Statement"].([]interface{})
+	if !ok {
+		statements = []interface{}{policy["Statement"]}
+	}
+	for _, s := range statements {
+		statement, ok := s.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		effect, _ := statement["Effect"].(string)
+		actions := stringOrList(statement["Action"])
+		if notActions := stringOrList(statement["NotAction"]); len(notActions) > 0 {
+			for _, action := range notActions {
+				actions = append(actions, "everything except "+action)
+			}
+		}
+
+		var targets []string
+		for _, resource := range stringOrList(statement["Resource"]) {
+			targets = append(targets, "on "+resource)
+		}
+		for _, resource := range stringOrList(statement["NotResource"]) {
+			targets = append(targets, "on everything except "+resource)
+		}
+		for _, principal := range formatPrincipals(statement["Principal"]) {
+			targets = append(targets, "for "+principal)
+		}
+
+		suffix := ""
+		if statement["Condition"] != nil {
+			suffix = " (conditional)"
+		}
+		for _, action := range actions {
+			for _, target := range targets {
+				grants[fmt.Sprintf("%s %s %s%s", effect, action, target, suffix)] = true
+			}
+		}
+	}
+}
+
+// stringOrList normalizes policy fields that may be a string or a list of strings
+func stringOrList(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	return stringList(value)
+}
+
+// formatPrincipals renders a policy Principal such as {"Service": "ec2.amazonaws.com"}
+func formatPrincipals(value interface{}) []string {
+	if s, ok := value.(string); ok {
+		return []string{s}
+	}
+	principals, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, kind := range unionKeys(principals, nil, nil) {
+		for _, principal := range stringOrList(principals[kind]) {
+			result = append(result, fmt.Sprintf("%s:%s", kind, principal))
+		}
+	}
+	return result
+}
+
+// isBroadGrant reports whether a grant uses a wildcard action, resource or principal
+func isBroadGrant(grant string) bool {
+	return strings.Contains(grant, "*") || strings.Contains(grant, "allUsers") || strings.Contains(grant, "everything except")
+}
+
+// formatIAMDelta renders a delta as list items, flagging wildcard grants
+func formatIAMDelta(delta IAMDelta) []string {
+	var lines []string
+	for _, grant := range delta.Added {
+		line := fmt.Sprintf("  - ➕ adds `%s`", grant)
+		if isBroadGrant(grant) {
+			line += " ⚠️"
+		}
+		lines = append(lines, line)
+	}
+	for _, grant := range delta.Removed {
+		lines = append(lines, fmt.Sprintf("  - ➖ removes `%s`", grant))
+	}
+	return lines
+}
+
+// writeIAMSection renders the permission deltas of IAM resources
+func writeIAMSection(md *strings.Builder, plan *TerraformPlan) {
+	deltas := analyzeIAMChanges(plan)
+	if len(deltas) == 0 {
+		return
+	}
+
+	md.WriteString("### 🔐 IAM Changes\n\n")
+	for _, delta := range deltas {
+		md.WriteString(fmt.Sprintf("- `%s`\n", delta.Address))
+		for _, line := range formatIAMDelta(delta) {
+			md.WriteString(line + "\n")
+		}
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentIAMSection renders IAM permission deltas inside a multi-plan environment section
+func writeEnvironmentIAMSection(md *strings.Builder, plan *TerraformPlan) {
+	deltas := analyzeIAMChanges(plan)
+	if len(deltas) == 0 {
+		return
+	}
+
+	md.WriteString("**🔐 IAM changes:**\n")
+	for _, delta := range deltas {
+		md.WriteString(fmt.Sprintf("- `%s`\n", delta.Address))
+		for _, line := range formatIAMDelta(delta) {
+			md.WriteString(line + "\n")
+		}
+	}
+	md.WriteString("\n")
+}
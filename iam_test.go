@@ -0,0 +1,69 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzeIAMChanges(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "aws_iam_policy.deploy",
+				Type:    "aws_iam_policy",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"policy": `{"Statement":[{"Effect":"Allow","Action":["s3:GetObject"],"Resource":"arn:aws:s3:::b/*"}]}`},
+					After:   map[string]interface{}{"policy": `{"Statement":[{"Effect":"Allow","Action":["s3:GetObject","iam:PassRole"],"Resource":"*"}]}`},
+				},
+			},
+			{
+				Address: "aws_iam_role.app",
+				Type:    "aws_iam_role",
+				Change: Change{
+					Actions: []string{"create"},
+					After: map[string]interface{}{
+						"assume_role_policy": `{"Statement":{"Effect":"Allow","Action":"sts:AssumeRole","Principal":{"Service":"ec2.amazonaws.com"}}}`,
+					},
+				},
+			},
+			{
+				Address: "google_project_iam_binding.viewers",
+				Type:    "google_project_iam_binding",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"role": "roles/viewer", "members": []interface{}{"user:a@example.com"}},
+					After:   map[string]interface{}{"role": "roles/viewer", "members": []interface{}{"user:b@example.com"}},
+				},
+			},
+		},
+	}
+
+	deltas := analyzeIAMChanges(plan)
+	expected := []IAMDelta{
+		{
+			Address: "aws_iam_policy.deploy",
+			Added:   []string{"Allow iam:PassRole on *", "Allow s3:GetObject on *"},
+			Removed: []string{"Allow s3:GetObject on arn:aws:s3:::b/*"},
+		},
+		{
+			Address: "aws_iam_role.app",
+			Added:   []string{"Allow sts:AssumeRole for Service:ec2.amazonaws.com"},
+		},
+		{
+			Address: "google_project_iam_binding.viewers",
+			Added:   []string{"Grant roles/viewer to user:b@example.com"},
+			Removed: []string{"Grant roles/viewer to user:a@example.com"},
+		},
+	}
+	if !reflect.DeepEqual(deltas, expected) {
+		t.Errorf("Unexpected deltas:\n%+v", deltas)
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "### 🔐 IAM Changes\n\n- `aws_iam_policy.deploy`\n  - ➕ adds `Allow iam:PassRole on *` ⚠️\n") {
+		t.Errorf("Expected IAM section, got:\n%s", result)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// worldOpenSources are source ranges that admit traffic from anywhere
+var worldOpenSources = map[string]bool{
+	"0.0.0.0/0": true,
+	"::/0":      true,
+	"*":         true,
+	"Internet":  true,
+	"Any":       true,
+}
+
+// detectWorldOpenIngress flags ingress rules open to the world in the planned
+// state that weren't already open before
+func detectWorldOpenIngress(rc ResourceChange) []securityFinding {
+	before := worldOpenIngressRules(rc.Type, rc.Change.Before)
+	var opened []string
+	for rule := range worldOpenIngressRules(rc.Type, rc.Change.After) {
+		if !before[rule] {
+			opened = append(opened, rule)
+		}
+	}
+	sort.Strings(opened)
+
+	findings := make([]securityFinding, len(opened))
+	for i, rule := range opened {
+		findings[i] = securityFinding{
+			Address: rc.Address,
+			Title:   "World-open ingress",
+			Detail:  "allows " + rule,
+		}
+	}
+	return findings
+}
+
+// worldOpenIngressRules describes each world-open ingress rule of a security
+// group or firewall resource, e.g. "tcp/22 from 0.0.0.0/0"
+func worldOpenIngressRules(resourceType string, values interface{}) map[string]bool {
+	rules := make(map[string]bool)
+	attrs, ok := values.(map[string]interface{})
+	if !ok {
+		return rules
+	}
+
+	switch resourceType {
+	case "aws_security_group":
+		for _, rule := range objectList(attrs["ingress"]) {
+			addAWSIngressRule(rules, rule)
+		}
+	case "aws_security_group_rule":
+		if attrs["type"] == "ingress" {
+			addAWSIngressRule(rules, attrs)
+		}
+	case "aws_vpc_security_group_ingress_rule":
+		for _, key := range []string{"cidr_ipv4", "cidr_ipv6"} {
+			if source, ok := attrs[key].(string); ok && worldOpenSources[source] {
+				rules[formatIngressRule(attrs["ip_protocol"], attrs["from_port"], attrs["to_port"], source)] = true
+			}
+		}
+	case "google_compute_firewall":
+		if direction, _ := attrs["direction"].(string); direction != "" && direction != "INGRESS" {
+			break
+		}
+		for _, source := range stringList(attrs["source_ranges"]) {
+			if !worldOpenSources[source] {
+				continue
+			}
+			for _, allow := range objectList(attrs["allow"]) {
+				ports := stringList(allow["ports"])
+				if len(ports) == 0 {
+					ports = []string{"all"}
+				}
+				for _, port := range ports {
+					rules[fmt.Sprintf("%v/%s from %s", allow["protocol"], port, source)] = true
+				}
+			}
+		}
+	case "azurerm_network_security_rule":
+		addAzureInboundRule(rules, attrs)
+	case "azurerm_network_security_group":
+		for _, rule := range objectList(attrs["security_rule"]) {
+			addAzureInboundRule(rules, rule)
+		}
+	}
+	return rules
+}
+
+func addAWSIngressRule(rules map[string]bool, rule map[string]interface{}) {
+	sources := append(stringList(rule["cidr_blocks"]), stringList(rule["ipv6_cidr_blocks"])...)
+	for _, source := range sources {
+		if worldOpenSources[source] {
+			rules[formatIngressRule(rule["protocol"], rule["from_port"], rule["to_port"], source)] = true
+		}
+	}
+}
+
+func addAzureInboundRule(rules map[string]bool, rule map[string]interface{}) {
+	if !strings.EqualFold(fmt.Sprint(rule["direction"]), "Inbound") || !strings.EqualFold(fmt.Sprint(rule["access"]), "Allow") {
+		return
+	}
+	sources := stringList(rule["source_address_prefixes"])
+	if prefix, ok := rule["source_address_prefix"].(string); ok {
+		sources = append(sources, prefix)
+	}
+	for _, source := range sources {
+		if worldOpenSources[source] {
+			rules[fmt.Sprintf("%v/%v from %s", rule["protocol"], rule["destination_port_range"], source)] = true
+		}
+	}
+}
+
+// formatIngressRule renders a protocol and port range, e.g. "tcp/22" or "tcp/80-443"
+func formatIngressRule(protocol, fromPort, toPort interface{}, source string) string {
+	if protocol == "-1" || protocol == "all" {
+		return fmt.Sprintf("all traffic from %s", source)
+	}
+	ports := fmt.Sprint(fromPort)
+	if !deepEqual(fromPort, toPort) {
+		ports = fmt.Sprintf("%v-%v", fromPort, toPort)
+	}
+	return fmt.Sprintf("%v/%s from %s", protocol, ports, source)
+}
+
+// objectList returns the objects in a list attribute such as nested blocks
+func objectList(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	var objects []map[string]interface{}
+	for _, item := range list {
+		if object, ok := item.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+// stringList returns the strings in a list attribute
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	var values []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			values = append(values, s)
+		}
+	}
+	return values
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectWorldOpenIngress(t *testing.T) {
+	sshRule := map[string]interface{}{
+		"protocol": "tcp", "from_port": float64(22), "to_port": float64(22),
+		"cidr_blocks": []interface{}{"10.0.0.0/8"},
+	}
+	openHTTPS := map[string]interface{}{
+		"protocol": "tcp", "from_port": float64(443), "to_port": float64(443),
+		"cidr_blocks": []interface{}{"0.0.0.0/0"},
+	}
+	openSSH := map[string]interface{}{
+		"protocol": "tcp", "from_port": float64(22), "to_port": float64(22),
+		"cidr_blocks": []interface{}{"0.0.0.0/0"}, "ipv6_cidr_blocks": []interface{}{"::/0"},
+	}
+
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "aws_security_group.web",
+				Type:    "aws_security_group",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"ingress": []interface{}{sshRule, openHTTPS}},
+					After:   map[string]interface{}{"ingress": []interface{}{openSSH, openHTTPS}},
+				},
+			},
+			{
+				Address: "google_compute_firewall.allow_all",
+				Type:    "google_compute_firewall",
+				Change: Change{
+					Actions: []string{"create"},
+					After: map[string]interface{}{
+						"source_ranges": []interface{}{"0.0.0.0/0"},
+						"allow":         []interface{}{map[string]interface{}{"protocol": "tcp", "ports": []interface{}{"3389"}}},
+					},
+				},
+			},
+		},
+	}
+
+	findings := findSecurityFindings(plan)
+	details := make([]string, len(findings))
+	for i, f := range findings {
+		details[i] = f.Address + " " + f.Detail
+	}
+	expected := []string{
+		"aws_security_group.web allows tcp/22 from 0.0.0.0/0",
+		"aws_security_group.web allows tcp/22 from ::/0",
+		"google_compute_firewall.allow_all allows tcp/3389 from 0.0.0.0/0",
+	}
+	if strings.Join(details, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected findings:\n%s", strings.Join(details, "\n"))
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "> ⚠️ **World-open ingress:** `aws_security_group.web` allows tcp/22 from 0.0.0.0/0\n>\n") {
+		t.Errorf("Expected world-open ingress warning, got:\n%s", result)
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// instanceAddressPattern splits a count/for_each instance address into its
+// resource address and instance key
+var instanceAddressPattern = regexp.MustCompile(`^(.*)\[([^\]]*)\]$`)
+
+// splitInstanceAddress returns the resource address and instance key of an
+// address such as aws_instance.web[3] or aws_instance.web["a"]
+func splitInstanceAddress(address string) (string, string, bool) {
+	match := instanceAddressPattern.FindStringSubmatch(address)
+	if match == nil {
+		return address, "", false
+	}
+	return match[1], match[2], true
+}
+
+// collapseInstances merges instances of the same count/for_each resource that
+// carry identical changes into a single entry listing all instance addresses
+func collapseInstances(resources []ResourceDetail) []ResourceDetail {
+	var collapsed []ResourceDetail
+	index := make(map[string]int)
+
+	for _, resource := range resources {
+		base, _, ok := splitInstanceAddress(resource.Address)
+		if !ok {
+			collapsed = append(collapsed, resource)
+			continue
+		}
+
+		key := base + "\x00" + instanceSignature(resource)
+		if i, exists := index[key]; exists {
+			collapsed[i].Instances = append(collapsed[i].Instances, resource.Address)
+			continue
+		}
+
+		index[key] = len(collapsed)
+		resource.Instances = []string{resource.Address}
+		collapsed = append(collapsed, resource)
+	}
+
+	// Single instances are rendered as before
+	for i := range collapsed {
+		if len(collapsed[i].Instances) == 1 {
+			collapsed[i].Instances = nil
+		}
+	}
+	return collapsed
+}
+
+// instanceSignature identifies the rendered content of a resource's details
+func instanceSignature(resource ResourceDetail) string {
+	var signature strings.Builder
+	signature.WriteString(resource.ForceReason)
+	signature.WriteString("\x00")
+	signature.WriteString(strings.Join(resource.ChangeDrivers, ","))
+	signature.WriteString("\x00")
+	signature.WriteString(resource.SourceLink)
+	signature.WriteString(fmt.Sprintf("\x00%v", resource.CostAffecting))
+	for _, finding := range resource.Findings {
+		signature.WriteString("\x00")
+		signature.WriteString(formatFinding(finding))
+	}
+	for _, identifier := range resource.Identifiers {
+		signature.WriteString("\x00")
+		signature.WriteString(formatAttributeChange(identifier))
+	}
+	for _, change := range resource.Changes {
+		signature.WriteString("\x00")
+		signature.WriteString(formatAttributeChange(change))
+	}
+	return signature.String()
+}
+
+// formatInstanceRange renders collapsed instance addresses compactly, e.g.
+// aws_instance.web[0..24] for contiguous count indexes or
+// aws_instance.web[*] for for_each keys
+func formatInstanceRange(instances []string) string {
+	base, _, _ := splitInstanceAddress(instances[0])
+
+	indexes := make([]int, 0, len(instances))
+	for _, instance := range instances {
+		_, key, _ := splitInstanceAddress(instance)
+		n, err := strconv.Atoi(key)
+		if err != nil {
+			return base + "[*]"
+		}
+		indexes = append(indexes, n)
+	}
+
+	min, max := indexes[0], indexes[0]
+	for _, n := range indexes {
+		if n < min {
+			min = n
+		}
+		if n > max {
+			max = n
+		}
+	}
+	if max-min+1 == len(indexes) {
+		return fmt.Sprintf("%s[%d..%d]", base, min, max)
+	}
+	return base + "[*]"
+}
+
+// formatInstanceList renders the addresses of a collapsed entry as a
+// collapsed block indented by the given prefix
+func formatInstanceList(resource ResourceDetail, indent string) string {
+	if len(resource.Instances) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(resource.Instances))
+	for i, instance := range resource.Instances {
+		quoted[i] = fmt.Sprintf("`%s`", instance)
+	}
+
+	var list strings.Builder
+	list.WriteString(fmt.Sprintf("%s<details>\n%s<summary>%d instances</summary>\n\n", indent, indent, len(resource.Instances)))
+	list.WriteString(fmt.Sprintf("%s%s\n\n", indent, strings.Join(quoted, ", ")))
+	list.WriteString(fmt.Sprintf("%s</details>\n", indent))
+	return list.String()
+}
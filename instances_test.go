@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCollapseInstances(t *testing.T) {
+	var resources []ResourceDetail
+	for i := 0; i < 25; i++ {
+		resources = append(resources, ResourceDetail{Address: fmt.Sprintf("aws_instance.web[%d]", i)})
+	}
+	resources = append(resources,
+		ResourceDetail{Address: `aws_s3_bucket.logs["a"]`},
+		ResourceDetail{Address: `aws_s3_bucket.logs["b"]`, ForceReason: "different"},
+		ResourceDetail{Address: "aws_vpc.main"},
+	)
+
+	collapsed := collapseInstances(resources)
+	if len(collapsed) != 4 {
+		t.Fatalf("Expected 4 entries, got %+v", collapsed)
+	}
+	if label := formatResourceLabel(collapsed[0]); label != "`aws_instance.web[0..24]` *(25 instances)*" {
+		t.Errorf("Unexpected label: %s", label)
+	}
+	if collapsed[1].Instances != nil || collapsed[2].Instances != nil {
+		t.Error("Expected instances with different details to stay separate")
+	}
+
+	list := formatResourceList(resources, 3)
+	if list != `aws_instance.web[0..24] (25 instances), aws_s3_bucket.logs[*] (2 instances), aws_vpc.main` {
+		t.Errorf("Unexpected resource list: %s", list)
+	}
+}
+
+func TestCollapsedInstancesRendering(t *testing.T) {
+	var changes []ResourceChange
+	for _, key := range []string{"a", "b", "c"} {
+		changes = append(changes, ResourceChange{
+			Address: `aws_sqs_queue.q["` + key + `"]`,
+			Type:    "aws_sqs_queue",
+			Change:  Change{Actions: []string{"create"}},
+		})
+	}
+
+	result := generateMarkdownComment(&TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: changes}, ReportOptions{})
+	if !strings.Contains(result, "- `aws_sqs_queue.q[*]` *(3 instances)*\n  <details>\n  <summary>3 instances</summary>") {
+		t.Errorf("Expected collapsed instances, got:\n%s", result)
+	}
+	if !strings.Contains(result, "| 🟢 **Create** | 3 |") {
+		t.Errorf("Expected counts to include every instance, got:\n%s", result)
+	}
+}
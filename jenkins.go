@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files written by -jenkins-output
+const (
+	jenkinsDescriptionFile = "description.html"
+	jenkinsBadgeFile       = "badge.txt"
+)
+
+// jenkinsBadge renders a one-line build summary for badges and build names,
+// e.g. "Terraform: +2 ~1 ±0 -1 (risk HIGH)"
+func jenkinsBadge(result RunResult) string {
+	var create, update, replace, remove int
+	risk := riskNone
+	for _, env := range result.Environments {
+		create += env.Create
+		update += env.Update
+		replace += env.Replace
+		remove += env.Delete
+		if riskLevelOrder[env.RiskLevel] > riskLevelOrder[risk] {
+			risk = env.RiskLevel
+		}
+	}
+
+	badge := fmt.Sprintf("Terraform: +%d ~%d ±%d -%d (risk %s)", create, update, replace, remove, risk)
+	if result.ExitReason == "gate_failed" {
+		badge += " - gate failed"
+	}
+	return badge
+}
+
+// jenkinsDescription renders the run as an HTML fragment using only the
+// tags Jenkins' Safe HTML markup formatter keeps, for the build description
+func jenkinsDescription(result RunResult) string {
+	var fragment strings.Builder
+
+	fragment.WriteString(fmt.Sprintf("<b>%s</b>\n", html.EscapeString(jenkinsBadge(result))))
+	fragment.WriteString("<table>\n")
+	fragment.WriteString("<tr><th>Environment</th><th>Create</th><th>Update</th><th>Replace</th><th>Delete</th><th>Risk</th></tr>\n")
+	for _, env := range result.Environments {
+		fragment.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%d</td><td>%d</td><td>%d</td><td>%d</td><td>%s</td></tr>\n",
+			html.EscapeString(env.Environment), env.Create, env.Update, env.Replace, env.Delete, html.EscapeString(env.RiskLevel)))
+	}
+	fragment.WriteString("</table>\n")
+
+	var violations []string
+	for _, gate := range result.Gates {
+		for _, violation := range gate.Violations {
+			violations = append(violations, fmt.Sprintf("<li>%s: %s</li>", html.EscapeString(gate.Gate), html.EscapeString(violation)))
+		}
+	}
+	if len(violations) > 0 {
+		fragment.WriteString("<ul>\n" + strings.Join(violations, "\n") + "\n</ul>\n")
+	}
+	return fragment.String()
+}
+
+// writeJenkinsOutputs writes the build description fragment and badge text
+// to dir and returns their paths
+func writeJenkinsOutputs(dir string, result RunResult) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create Jenkins output directory: %w", err)
+	}
+
+	files := map[string]string{
+		jenkinsDescriptionFile: jenkinsDescription(result),
+		jenkinsBadgeFile:       jenkinsBadge(result) + "\n",
+	}
+	var written []string
+	for _, name := range []string{jenkinsDescriptionFile, jenkinsBadgeFile} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write Jenkins output: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJenkinsOutputs(t *testing.T) {
+	result := RunResult{
+		ExitReason: "gate_failed",
+		Environments: []EnvironmentResult{
+			{Environment: "prod", Create: 1, Delete: 1, RiskLevel: riskHigh},
+			{Environment: "<dev>", Update: 2, RiskLevel: riskLow},
+		},
+		Gates: []GateResult{{Gate: gateFailOn, Violations: []string{"prod: delete aws_s3_bucket.b (rule delete)"}}},
+	}
+
+	if badge := jenkinsBadge(result); badge != "Terraform: +1 ~2 ±0 -1 (risk HIGH) - gate failed" {
+		t.Errorf("Unexpected badge: %s", badge)
+	}
+
+	description := jenkinsDescription(result)
+	if !strings.Contains(description, "<tr><td>prod</td><td>1</td><td>0</td><td>0</td><td>1</td><td>HIGH</td></tr>") {
+		t.Errorf("Expected an environment row, got:\n%s", description)
+	}
+	if !strings.Contains(description, "<td>&lt;dev&gt;</td>") {
+		t.Errorf("Expected values to be HTML escaped, got:\n%s", description)
+	}
+	if !strings.Contains(description, "<li>fail_on: prod: delete aws_s3_bucket.b (rule delete)</li>") {
+		t.Errorf("Expected gate violations, got:\n%s", description)
+	}
+
+	dir := filepath.Join(t.TempDir(), "jenkins")
+	written, err := writeJenkinsOutputs(dir, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("Expected 2 files, got %v", written)
+	}
+	badge, err := os.ReadFile(filepath.Join(dir, jenkinsBadgeFile))
+	if err != nil || string(badge) != jenkinsBadge(result)+"\n" {
+		t.Errorf("Unexpected badge file: %q (%v)", badge, err)
+	}
+}
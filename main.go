@@ -1,14 +1,18 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 // Version information - will be set during build
@@ -20,9 +24,67 @@ var (
 
 // TerraformPlan represents the structure of a Terraform plan JSON
 type TerraformPlan struct {
-	FormatVersion    string           `json:"format_version"`
-	TerraformVersion string           `json:"terraform_version"`
-	ResourceChanges  []ResourceChange `json:"resource_changes"`
+	FormatVersion      string                  `json:"format_version"`
+	TerraformVersion   string                  `json:"terraform_version"`
+	ResourceChanges    []ResourceChange        `json:"resource_changes"`
+	ResourceDrift      []ResourceChange        `json:"resource_drift"`
+	OutputChanges      map[string]Change       `json:"output_changes"`
+	Checks             []CheckResult           `json:"checks"`
+	Variables          map[string]PlanVariable `json:"variables"`
+	Configuration      *Configuration          `json:"configuration"`
+	RelevantAttributes []RelevantAttribute     `json:"relevant_attributes"`
+	PriorState         *PriorState             `json:"prior_state"`
+	Applyable          *bool                   `json:"applyable"`
+	Complete           *bool                   `json:"complete"`
+	Errored            bool                    `json:"errored"`
+	PlannedValues      *StateValues            `json:"planned_values"`
+
+	// PlanPath is the file the plan was read from
+	PlanPath string `json:"-"`
+	// Digest is the hex SHA-256 of the plan file's bytes
+	Digest string `json:"-"`
+	// FilteredOut counts resource changes left out by resource filters
+	FilteredOut int `json:"-"`
+	// Warnings are problems found while reading the plan
+	Warnings []string `json:"-"`
+
+	// Source and ChangeSetName are set when the plan was converted from another format
+	Source        string `json:"-"`
+	ChangeSetName string `json:"-"`
+}
+
+// Configuration represents the plan's "configuration" block
+type Configuration struct {
+	ProviderConfig map[string]ProviderConfig `json:"provider_config"`
+	RootModule     ConfigModule              `json:"root_module"`
+}
+
+// ConfigModule represents a module in the plan's configuration block
+type ConfigModule struct {
+	Resources   []ConfigResource          `json:"resources"`
+	ModuleCalls map[string]ModuleCall     `json:"module_calls"`
+	Variables   map[string]ConfigVariable `json:"variables"`
+}
+
+// ConfigResource represents a resource declaration in the configuration block
+type ConfigResource struct {
+	Address           string                 `json:"address"`
+	Mode              string                 `json:"mode"`
+	Type              string                 `json:"type"`
+	Name              string                 `json:"name"`
+	ProviderConfigKey string                 `json:"provider_config_key"`
+	Expressions       map[string]interface{} `json:"expressions"`
+}
+
+// ModuleCall represents a module block in the configuration
+type ModuleCall struct {
+	Source string        `json:"source"`
+	Module *ConfigModule `json:"module"`
+}
+
+// ConfigVariable represents a variable declaration in the configuration block
+type ConfigVariable struct {
+	Sensitive bool `json:"sensitive"`
 }
 
 // PlanInfo holds a plan with its relative path information
@@ -33,21 +95,33 @@ type PlanInfo struct {
 
 // ResourceChange represents a single resource change in the plan
 type ResourceChange struct {
-	Address       string `json:"address"`
-	ModuleAddress string `json:"module_address"`
-	Mode          string `json:"mode"`
-	Type          string `json:"type"`
-	Name          string `json:"name"`
-	ProviderName  string `json:"provider_name"`
-	Change        Change `json:"change"`
+	Address         string `json:"address"`
+	ModuleAddress   string `json:"module_address"`
+	Mode            string `json:"mode"`
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	ProviderName    string `json:"provider_name"`
+	PreviousAddress string `json:"previous_address"`
+	Deposed         string `json:"deposed"`
+	ActionReason    string `json:"action_reason"`
+	Change          Change `json:"change"`
 }
 
 // Change represents the actual change being made to a resource
 type Change struct {
-	Actions      []string    `json:"actions"`
-	Before       interface{} `json:"before"`
-	After        interface{} `json:"after"`
-	AfterUnknown interface{} `json:"after_unknown"`
+	Actions         []string        `json:"actions"`
+	Before          interface{}     `json:"before"`
+	After           interface{}     `json:"after"`
+	AfterUnknown    interface{}     `json:"after_unknown"`
+	BeforeSensitive interface{}     `json:"before_sensitive"`
+	AfterSensitive  interface{}     `json:"after_sensitive"`
+	Importing       *Importing      `json:"importing"`
+	ReplacePaths    [][]interface{} `json:"replace_paths"`
+}
+
+// Importing describes an import operation planned for a resource (Terraform 1.5+)
+type Importing struct {
+	ID string `json:"id"`
 }
 
 // ResourceSummary holds the summary of changes for each action type
@@ -56,13 +130,111 @@ type ResourceSummary struct {
 	Update  []ResourceDetail
 	Delete  []ResourceDetail
 	Replace []ResourceDetail
+	Move    []ResourceDetail
+	Import  []ResourceDetail
+	Deposed []ResourceDetail
+	Read    []ResourceDetail
+	NoOp    []ResourceDetail
+}
+
+// affectedResources counts the distinct resources with changes. Moves and
+// imports annotate resources that may also be created, updated or replaced,
+// so they only add to the count when they are a resource's only change.
+func (s ResourceSummary) affectedResources() int {
+	count := len(s.Create) + len(s.Update) + len(s.Delete) + len(s.Replace) + len(s.Deposed)
+	counted := make(map[string]bool)
+	for _, group := range [][]ResourceDetail{s.Create, s.Update, s.Delete, s.Replace} {
+		for _, resource := range group {
+			counted[resource.Address] = true
+		}
+	}
+	for _, group := range [][]ResourceDetail{s.Move, s.Import} {
+		for _, resource := range group {
+			if !counted[resource.Address] {
+				counted[resource.Address] = true
+				count++
+			}
+		}
+	}
+	return count
 }
 
 // ResourceDetail holds detailed information about a resource change
 type ResourceDetail struct {
-	Address     string
-	Changes     []AttributeChange
-	ForceReason string // For resources being deleted/replaced
+	Address         string
+	PreviousAddress string // For resources moved from another address
+	ImportID        string // For resources being imported
+	DeposedKey      string // For deposed objects left behind by create_before_destroy
+	Changes         []AttributeChange
+	ForceReason     string            // For resources being deleted/replaced
+	Identifiers     []AttributeChange // Identifying before-state attributes of deleted resources
+	ChangeDrivers   []string          // Upstream attributes that caused this change
+	SourceLink      string            // Markdown link to the declaring .tf file
+	DocsLink        string            // Registry documentation page of the resource type
+	Instances       []string          // Instance addresses when collapsed from count/for_each
+	Findings        []ExternalFinding
+	CostAffecting   bool // Heuristically likely to raise costs
+}
+
+// ReportOptions controls optional sections of the generated report
+type ReportOptions struct {
+	ShowReads bool // List data sources that will be read during apply
+	ShowNoOp  bool // List unchanged resources in a collapsed section
+
+	ShowVariables bool           // Render input variable values
+	RedactPattern *regexp.Regexp // Variable names whose values are redacted
+
+	ShowPriorState bool // Render managed resource counts from prior_state
+	ShowPlanned    bool // Render key planned attributes of created resources
+
+	Detail            string              // Detail level: "summary" (default) or "full"
+	PlannedAttributes map[string][]string // Planned attributes shown per resource type glob with -detail full
+
+	LinkSources bool   // Link resources to the .tf files that declare them
+	LinkDocs    bool   // Link resource types to their registry documentation
+	SourceRoot  string // Root module directory (defaults to the plan file's directory)
+	SourceURL   string // Base VCS URL for source links (defaults to relative links)
+
+	ShowProviders bool           // Render required providers and version changes
+	PreviousPlan  *TerraformPlan // Earlier plan to compare provider constraints against
+
+	IgnoreRules *IgnoreRules // Attribute changes hidden from the report
+
+	GroupBy string // "module" to organize changes by module instead of by action
+
+	ShowRegions bool // Render a breakdown of changes by region and account
+
+	Sort        string // Resource ordering: address (default), type, action or impact
+	SortReverse bool   // Reverse the resource and section ordering
+
+	MaxDetail int // Entries rendered in detail per section before the rest are summarized (0 = no limit)
+
+	SummaryOnly bool // Render only the summary tables, without per-resource detail
+
+	Style string // Built-in report style: default, compact, detailed or changelog
+
+	CollapseOver int // Environments with more changes render their details collapsed (0 = never)
+
+	Risk *RiskModel // Risk scoring weights (defaults when nil)
+
+	Thresholds *ThresholdConfig // Change count limits per environment
+
+	SecurityTypes []string      // Security-relevant resource type globs (defaults when nil)
+	Naming        *NamingConfig // Naming convention rules
+	Baseline      *Baseline     // Expected changes hidden from the report
+	TagPolicy     *TagPolicy    // Required tags
+
+	Findings []ExternalFinding // Policy and scanner results to merge into the report
+	Costs    *InfracostReport  // Infracost estimates, when available
+
+	EnvironmentNames map[string]string // Display names by environment relative path
+	EnvironmentOrder []string          // Environment globs in report order
+	Tiers            []TierConfig      // Environment tiers with subtotals
+
+	Approval        *ApprovalConfig        // Plan approval by reaction or comment (server mode)
+	Acknowledgement *AcknowledgementConfig // Sign-off on destructive changes (server mode)
+	Reviewers       []ReviewerRule         // Reviewers requested when posted plans match
+	Owners          []OwnershipRule        // Teams mentioned in environments changing their resources
 }
 
 // AttributeChange represents a change to a specific attribute
@@ -72,11 +244,105 @@ type AttributeChange struct {
 	After     interface{}
 	IsNew     bool
 	IsRemoved bool
+	Note      string // Summary such as "1 added, 1 removed" instead of before/after values
+	Decoded   bool   // Before/after were decoded from base64
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "baseline":
+			runBaselineCommand(os.Args[2:])
+			return
+		case "diff":
+			runDiffCommand(os.Args[2:])
+			return
+		case "validate":
+			runValidateCommand(os.Args[2:])
+			return
+		case "report":
+			runReportCommand(os.Args[2:])
+			return
+		case "dashboard":
+			runDashboardCommand(os.Args[2:])
+			return
+		case "serve":
+			runServeCommand(os.Args[2:])
+			return
+		case "sign":
+			runSignCommand(os.Args[2:])
+			return
+		case "verify":
+			runVerifyCommand(os.Args[2:])
+			return
+		case "self-update":
+			runSelfUpdateCommand(os.Args[2:])
+			return
+		}
+	}
+
 	var showVersion = flag.Bool("version", false, "Show version information")
 	var showHelp = flag.Bool("help", false, "Show help information")
+	var showReads = flag.Bool("show-reads", false, "List data sources that will be read during apply")
+	var showNoOp = flag.Bool("show-noop", false, "List unchanged resources in a collapsed section")
+	var showVariables = flag.Bool("show-variables", false, "Render input variable values (sensitive values are redacted)")
+	var showPriorState = flag.Bool("show-prior-state", false, "Render managed resource counts per type before the change")
+	var detail = flag.String("detail", detailSummary, "Detail level: summary|full (full renders planned attributes of created resources)")
+	var showPlanned = flag.Bool("show-planned", false, "Render key planned attributes of created resources")
+	var linkSources = flag.Bool("link-sources", false, "Link resources to the .tf files that declare them")
+	var linkDocs = flag.Bool("link-docs", false, "Link resource types to their Terraform Registry documentation")
+	var sourceRoot = flag.String("source-root", "", "Root module directory for -link-sources (default: plan file's directory)")
+	var sourceURL = flag.String("source-url", "", "Base VCS URL for -link-sources, e.g. https://github.com/org/repo/blob/main")
+	var showProviders = flag.Bool("show-providers", false, "Render required providers and flag version constraint changes")
+	var previousPlanFile = flag.String("previous-plan", "", "Earlier plan JSON to compare provider constraints against")
+	var redactPattern = flag.String("redact-pattern", defaultRedactPattern, "Regex of variable names, and attribute names in 0.1 plans, whose values are redacted")
+	var showRegions = flag.Bool("show-regions", false, "Render a breakdown of changes by region and account")
+	var sortBy = flag.String("sort", sortByAddress, "Resource ordering: "+formatSortKeys())
+	var sortReverse = flag.Bool("sort-reverse", false, "Reverse the -sort ordering")
+	var maxDetail = flag.Int("max-detail", 0, "Maximum detailed entries per section; the rest are summarized (0 = no limit)")
+	var style = flag.String("style", styleDefault, "Built-in report style: "+formatStyles())
+	var summaryOnly = flag.Bool("summary-only", false, "Render only the overall and per-environment summary tables")
+	var collapseOver = flag.Int("collapse-over", 0, "Collapse the details of environments with more changes than this (0 = never)")
+	var failOn = flag.String("fail-on", "", "Exit with code 3 when matching changes exist, e.g. delete,replace:aws_db_instance")
+	var detailedExitCode = flag.Bool("detailed-exitcode", false, "Exit with code 2 when changes are present, like terraform plan")
+	var policyResults = flag.String("policy-results", "", "conftest JSON output to merge into the report")
+	var scanResults = flag.String("scan-results", "", "Comma-separated Checkov, tfsec or Trivy JSON reports to merge into the report")
+	var infracost = flag.String("infracost", "", "Infracost breakdown/diff JSON, or \""+infracostRun+"\" to run infracost on each plan")
+	var baselineFile = flag.String("baseline", "", "Baseline JSON of expected changes to leave out of the report and gating")
+	var timezone = flag.String("timezone", "UTC", "Time zone of the report generation time, e.g. Europe/Berlin")
+	var headerFile = flag.String("header-file", "", "Markdown file prepended to the report, e.g. approval instructions")
+	var footerText = flag.String("footer-text", "", "Markdown appended to the report, e.g. runbook links")
+	var appendOutput = flag.Bool("append", false, "Append the report to the output file under a separator header instead of overwriting it")
+	var postComment = flag.Bool("post-comment", false, "Post the report on the pull request detected from Woodpecker, Drone or GitHub Actions")
+	var commentStrategy = flag.String("comment-strategy", commentStrategyCombined, "-post-comment strategy: combined|per-environment (sticky comment per environment plus an index)")
+	var prDescription = flag.Bool("pr-description", false, "Insert or refresh a plan summary block in the description of the detected pull request")
+	var forge = flag.String("forge", "", "Forge of the pull request for -post-comment and -pr-description: github|gitea (default: detected)")
+	var changelog = flag.Bool("changelog", false, "Append a dated, commit-referenced changelog entry for the plan to -changelog-file")
+	var changelogFile = flag.String("changelog-file", defaultChangelogFile, "Changelog file written by -changelog")
+	var gitlabReport = flag.String("gitlab-report", "", "Write create/update/delete counts as a GitLab artifacts:reports:terraform JSON file")
+	var bitbucketInsights = flag.Bool("bitbucket-insights", false, "Publish a Bitbucket Code Insights report with per-resource annotations for the commit")
+	var buildkiteAnnotate = flag.Bool("buildkite-annotate", false, "Add the report to the Buildkite build as an annotation styled by its content")
+	var jenkinsOutput = flag.String("jenkins-output", "", "Write a Safe HTML build description fragment and badge text for Jenkins to a directory")
+	var githubAnnotations = flag.Bool("github-annotations", false, "Print GitHub Actions ::warning::/::error:: commands for destructive and policy-violating changes")
+	var resultFile = flag.String("result-file", "", "Write a JSON run result (exit reason, per-environment counts, gate verdicts, outputs) to a file")
+	var dryRun = flag.Bool("dry-run", false, "Render the report and print what would be written or sent, without doing it")
+	var checkFile = flag.String("check", "", "Compare the report against a golden file instead of writing it, exiting 4 on mismatch")
+	var historyFile = flag.String("history", "", "Record each environment's change counts and risk score in a history file (SQLite for .sqlite/.sqlite3/.db, else JSON Lines)")
+	var metricsFile = flag.String("metrics-file", "", "Write run metrics in OpenMetrics text format to a file")
+	var pushgateway = flag.String("pushgateway", "", "Push run metrics to a Prometheus Pushgateway URL")
+	var statsdAddress = flag.String("statsd", "", "Send run metrics to a StatsD/DogStatsD agent, e.g. 127.0.0.1:8125")
+	var otlpEndpoint = flag.String("otlp-endpoint", "", "OTLP/HTTP traces URL for run phase spans (default: OTEL_EXPORTER_OTLP_* environment)")
+	var attestKey = flag.String("attest-key", "", "PEM private key to sign an in-toto attestation of the report (written to <output>"+attestationExtension+")")
+	var commit = flag.String("commit", "", "Commit SHA recorded in the attestation and changelog (default: from CI environment)")
+	var auditLog = flag.String("audit-log", "", "Append a JSON audit record of the run to a file, or POST it to an http(s) URL")
+	var onlyEnvironments = flag.String("only", "", "Directory mode: comma-separated environment globs to include, e.g. \"prod/*\"")
+	var exceptEnvironments = flag.String("except", "", "Directory mode: comma-separated environment globs to exclude, e.g. \"sandbox/*\"")
+	var filterAddress = flag.String("filter-address", "", "Only report resources whose address matches this regex")
+	var filterType = flag.String("filter-type", "", "Only report resources whose type matches this regex")
+	var moduleScope = flag.String("module", "", "Only report resources under these comma-separated module addresses, including nested modules")
+	var providerScope = flag.String("provider", "", "Only report resources of these comma-separated providers, e.g. aws,kubernetes")
+	var groupBy = flag.String("group-by", "", "Organize changes by \"module\" instead of by action")
+	var configFile = flag.String("config", "", "JSON configuration file (attribute ignore rules, ...)")
 	flag.Parse()
 
 	if *showVersion {
@@ -97,6 +363,180 @@ func main() {
 		os.Exit(1)
 	}
 
+	redact, err := regexp.Compile(*redactPattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -redact-pattern: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := ReportOptions{
+		ShowReads:     *showReads,
+		ShowNoOp:      *showNoOp,
+		ShowVariables: *showVariables,
+		RedactPattern: redact,
+
+		ShowPriorState: *showPriorState,
+		ShowPlanned:    *showPlanned,
+
+		Detail: *detail,
+
+		LinkSources: *linkSources,
+		LinkDocs:    *linkDocs,
+		SourceRoot:  *sourceRoot,
+		SourceURL:   *sourceURL,
+
+		ShowProviders: *showProviders,
+
+		GroupBy:     *groupBy,
+		ShowRegions: *showRegions,
+
+		Sort:        *sortBy,
+		SortReverse: *sortReverse,
+
+		MaxDetail: *maxDetail,
+
+		CollapseOver: *collapseOver,
+		SummaryOnly:  *summaryOnly,
+	}
+
+	if !isValidSortKey(opts.Sort) {
+		fmt.Fprintf(os.Stderr, "Invalid -sort %q: supported values are: %s\n", opts.Sort, formatSortKeys())
+		os.Exit(1)
+	}
+
+	location, err := time.LoadLocation(*timezone)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -timezone %q: %v\n", *timezone, err)
+		os.Exit(1)
+	}
+
+	if *commentStrategy != commentStrategyCombined && *commentStrategy != commentStrategyPerEnvironment {
+		fmt.Fprintf(os.Stderr, "Invalid -comment-strategy %q: supported values are: %s|%s\n", *commentStrategy, commentStrategyCombined, commentStrategyPerEnvironment)
+		os.Exit(1)
+	}
+
+	if *forge != "" && *forge != forgeGitHub && *forge != forgeGitea {
+		fmt.Fprintf(os.Stderr, "Invalid -forge %q: supported values are: %s|%s\n", *forge, forgeGitHub, forgeGitea)
+		os.Exit(1)
+	}
+
+	if !isValidStyle(*style) {
+		fmt.Fprintf(os.Stderr, "Invalid -style %q: supported values are: %s\n", *style, formatStyles())
+		os.Exit(1)
+	}
+	applyStyle(&opts, *style)
+
+	if opts.Detail != detailSummary && opts.Detail != detailFull {
+		fmt.Fprintf(os.Stderr, "Invalid -detail %q: supported values are: %s|%s\n", opts.Detail, detailSummary, detailFull)
+		os.Exit(1)
+	}
+
+	if opts.GroupBy != "" && opts.GroupBy != groupByModule {
+		fmt.Fprintf(os.Stderr, "Invalid -group-by %q: supported values are: %s\n", opts.GroupBy, groupByModule)
+		os.Exit(1)
+	}
+
+	failRules, err := parseFailOn(*failOn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -fail-on: %v\n", err)
+		os.Exit(1)
+	}
+
+	var resourceFilter *ResourceFilter
+	if *configFile != "" {
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.applyTo(&opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		resourceFilter = config.Resources
+
+		configRules, err := parseFailOn(strings.Join(config.FailOn, ","))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: invalid fail_on: %v\n", err)
+			os.Exit(1)
+		}
+		failRules = append(failRules, configRules...)
+	}
+
+	if *filterAddress != "" || *filterType != "" || *moduleScope != "" || *providerScope != "" {
+		filter := ResourceFilter{}
+		if resourceFilter != nil {
+			filter = *resourceFilter
+		}
+		if *filterAddress != "" {
+			if filter.Address, err = regexp.Compile(*filterAddress); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -filter-address: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if *filterType != "" {
+			if filter.Type, err = regexp.Compile(*filterType); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid -filter-type: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if filter.Modules, err = parseModuleScopes(*moduleScope); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid -module: %v\n", err)
+			os.Exit(1)
+		}
+		for _, provider := range strings.Split(*providerScope, ",") {
+			if provider = strings.TrimSpace(provider); provider != "" {
+				filter.Providers = append(filter.Providers, provider)
+			}
+		}
+		resourceFilter = &filter
+	}
+
+	if *policyResults != "" {
+		findings, err := readConftestResults(*policyResults)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading policy results: %v\n", err)
+			os.Exit(1)
+		}
+		opts.Findings = append(opts.Findings, findings...)
+	}
+
+	if *scanResults != "" {
+		for _, file := range strings.Split(*scanResults, ",") {
+			findings, err := readScanResults(strings.TrimSpace(file))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading scan results: %v\n", err)
+				os.Exit(1)
+			}
+			opts.Findings = append(opts.Findings, findings...)
+		}
+	}
+
+	if *infracost != "" && *infracost != infracostRun {
+		opts.Costs, err = readInfracostReport(*infracost)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading Infracost report: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *baselineFile != "" {
+		opts.Baseline, err = readBaseline(*baselineFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading baseline: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *previousPlanFile != "" {
+		previous, err := readTerraformPlan(*previousPlanFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading previous plan file: %v\n", err)
+			os.Exit(1)
+		}
+		opts.PreviousPlan = previous
+	}
+
 	inputPath := args[0]
 	outputFile := "terraform-plan-comment.md"
 	if len(args) > 1 {
@@ -112,63 +552,638 @@ func main() {
 
 	var plans []PlanInfo
 	var markdown string
+	var parseDuration time.Duration
+	parseStart := time.Now()
+
+	var tr *tracer
+	tracesEndpoint := otlpTracesEndpoint(*otlpEndpoint)
+	if tracesEndpoint != "" {
+		tr = newTracer()
+	}
+	runSpan := tr.start("tfplan-commenter", nil)
+	exportTraces := func() {
+		runSpan.finish()
+		if err := tr.export(tracesEndpoint); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if fileInfo.IsDir() && opts.PreviousPlan != nil {
+		fmt.Fprintf(os.Stderr, "-previous-plan is only supported when processing a single plan file\n")
+		os.Exit(1)
+	}
+	if !fileInfo.IsDir() && (*onlyEnvironments != "" || *exceptEnvironments != "") {
+		fmt.Fprintf(os.Stderr, "-only and -except are only supported when processing a directory\n")
+		os.Exit(1)
+	}
+	only, err := parseEnvironmentPatterns(*onlyEnvironments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -only value: %v\n", err)
+		os.Exit(1)
+	}
+	except, err := parseEnvironmentPatterns(*exceptEnvironments)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid -except value: %v\n", err)
+		os.Exit(1)
+	}
 
 	if fileInfo.IsDir() {
 		// Process directory containing multiple plan files
-		plans, err = findAndReadPlanFiles(inputPath)
+		discoverySpan := tr.start("discovery", runSpan)
+		paths, err := discoverPlanFiles(inputPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error processing directory: %v\n", err)
 			os.Exit(1)
 		}
+		discoverySpan.setAttribute("plans.found", len(paths))
+		if len(only) > 0 || len(except) > 0 {
+			found := len(paths)
+			paths = filterPlanPaths(inputPath, paths, only, except)
+			fmt.Printf("Selected %d of %d plan file(s) with -only/-except\n", len(paths), found)
+		}
+		discoverySpan.finish()
+
+		parseSpan := tr.start("parse", runSpan)
+		plans = readPlanFiles(inputPath, paths)
+		parseDuration = time.Since(parseStart)
+		parseSpan.setAttribute("plans.with_changes", len(plans))
+		parseSpan.finish()
 
 		if len(plans) == 0 {
 			fmt.Fprintf(os.Stderr, "No tfplan.json files found in directory: %s\n", inputPath)
 			os.Exit(1)
 		}
 
-		markdown = generateMultiPlanMarkdownComment(plans)
+		analysisSpan := tr.start("analysis", runSpan)
+		for _, planInfo := range plans {
+			applyResourceFilter(planInfo.Plan, resourceFilter)
+			applyBaseline(planInfo.Plan, planInfo.RelativePath, opts.Baseline)
+		}
+		if *infracost == infracostRun && !*dryRun {
+			opts.Costs = runCostEstimation(plans)
+		}
+		analysisSpan.finish()
+
+		renderSpan := tr.start("render", runSpan)
+		markdown = generateMultiPlanMarkdownComment(plans, opts)
+		renderSpan.finish()
 	} else {
 		// Process single plan file
+		parseSpan := tr.start("parse", runSpan)
 		plan, err := readTerraformPlan(inputPath)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error reading plan file: %v\n", err)
 			os.Exit(1)
 		}
+		parseDuration = time.Since(parseStart)
+		parseSpan.finish()
+
+		analysisSpan := tr.start("analysis", runSpan)
+		applyResourceFilter(plan, resourceFilter)
+		applyBaseline(plan, "", opts.Baseline)
+
+		plans = []PlanInfo{{Plan: plan}}
+		if *infracost == infracostRun && !*dryRun {
+			opts.Costs = runCostEstimation(plans)
+		}
+		analysisSpan.finish()
+
+		renderSpan := tr.start("render", runSpan)
+		markdown = generateMarkdownComment(plan, opts)
+		renderSpan.finish()
+	}
+
+	// The generation footer changes on every run, so golden file checks leave it out
+	if *checkFile == "" {
+		markdown = strings.TrimRight(markdown, "\n") + "\n\n" + generationFooter(time.Now(), time.Since(parseStart), location)
+	}
+
+	if *headerFile != "" || *footerText != "" {
+		var header []byte
+		if *headerFile != "" {
+			if header, err = os.ReadFile(*headerFile); err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading header file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		markdown = decorateReport(markdown, string(header), *footerText)
+	}
+
+	if *checkFile != "" {
+		diff, err := checkGolden(markdown, *checkFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking report: %v\n", err)
+			os.Exit(1)
+		}
+		exportTraces()
+		if diff != "" {
+			fmt.Fprintf(os.Stderr, "Report differs from %s:\n%s", *checkFile, diff)
+			os.Exit(exitCodeCheckFailed)
+		}
+		fmt.Printf("Report matches %s\n", *checkFile)
+		return
+	}
 
-		markdown = generateMarkdownComment(plan)
+	if *dryRun {
+		pullRequest := ""
+		if *postComment || *prDescription {
+			if ctx, err := detectPullRequestContext(*forge); err != nil {
+				pullRequest = "unavailable: " + err.Error()
+			} else {
+				pullRequest = ctx.String()
+			}
+		}
+		changelogTarget := ""
+		if *changelog {
+			changelogTarget = *changelogFile
+		}
+		targets := publishTargets{
+			OutputFile:  outputFile,
+			Append:      *appendOutput,
+			AttestKey:   *attestKey,
+			AuditLog:    *auditLog,
+			HistoryFile: *historyFile,
+			MetricsFile: *metricsFile,
+			Pushgateway: *pushgateway,
+			StatsD:      *statsdAddress,
+			Traces:      tracesEndpoint,
+			ResultFile:  *resultFile,
+			GitLab:      *gitlabReport,
+			Changelog:   changelogTarget,
+			Bitbucket:   *bitbucketInsights,
+			Buildkite:   *buildkiteAnnotate,
+			Jenkins:     *jenkinsOutput,
+			Infracost:   *infracost == infracostRun,
+		}
+		if *postComment {
+			targets.Comment = pullRequest
+		}
+		if *prDescription {
+			targets.Description = pullRequest
+		}
+		fmt.Printf("Dry run: rendered %d plan(s); nothing was written or sent. Would:\n", len(plans))
+		for _, action := range targets.describe(markdown, len(plans)) {
+			fmt.Printf("  - %s\n", action)
+		}
+		return
 	}
 
 	// Write to output file
-	err = os.WriteFile(outputFile, []byte(markdown), 0644)
+	publishSpan := tr.start("publish", runSpan)
+	report := []byte(markdown)
+	if *appendOutput {
+		report, err = appendReport(outputFile, inputPath, markdown)
+	} else {
+		err = os.WriteFile(outputFile, report, 0644)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
 		os.Exit(1)
 	}
 
+	if *commit == "" {
+		*commit = ciCommit()
+	}
+	outputs := []string{outputFile}
+	if *attestKey != "" {
+		attestationFile, err := writeAttestation(outputFile, report, plans, *attestKey, *commit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error attesting report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Report attestation written: %s\n", attestationFile)
+		outputs = append(outputs, attestationFile)
+	}
+
+	if *postComment {
+		commentURLs, err := postPullRequestComments(*forge, *commentStrategy, markdown, plans, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error posting pull request comment: %v\n", err)
+			os.Exit(1)
+		}
+		for _, commentURL := range commentURLs {
+			fmt.Printf("Pull request comment posted: %s\n", commentURL)
+		}
+		outputs = append(outputs, commentURLs...)
+	}
+
+	if *prDescription {
+		ctx, err := updatePullRequestDescription(*forge, plans, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating pull request description: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Pull request description summary updated: %s\n", ctx)
+	}
+
+	if *gitlabReport != "" {
+		if err := writeGitLabTerraformReport(*gitlabReport, gitlabTerraformReport(plans)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing GitLab report: %v\n", err)
+			os.Exit(1)
+		}
+		outputs = append(outputs, *gitlabReport)
+	}
+
+	if *changelog {
+		written, err := appendChangelogEntry(*changelogFile, plans, opts, time.Now().In(location), *commit)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating changelog: %v\n", err)
+			os.Exit(1)
+		}
+		if written {
+			fmt.Printf("Changelog entry appended: %s\n", *changelogFile)
+		} else {
+			fmt.Printf("Changelog already has an entry for commit %s\n", shortCommit(*commit))
+		}
+		outputs = append(outputs, *changelogFile)
+	}
+
+	if *historyFile != "" {
+		if err := appendHistory(*historyFile, historyRecords(plans, opts, time.Now())); err != nil {
+			fmt.Fprintf(os.Stderr, "Error recording history: %v\n", err)
+			os.Exit(1)
+		}
+		outputs = append(outputs, *historyFile)
+	}
+
+	if *metricsFile != "" || *pushgateway != "" || *statsdAddress != "" {
+		metrics := collectMetrics(RunMetrics{Plans: plans, ParseDuration: parseDuration}, opts)
+		if *metricsFile != "" {
+			if err := os.WriteFile(*metricsFile, []byte(formatMetrics(metrics, true)), 0644); err != nil {
+				fmt.Fprintf(os.Stderr, "Error writing metrics file: %v\n", err)
+				os.Exit(1)
+			}
+			outputs = append(outputs, *metricsFile)
+		}
+		if *pushgateway != "" {
+			if err := pushMetrics(*pushgateway, metrics); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else {
+				outputs = append(outputs, *pushgateway)
+			}
+		}
+		if *statsdAddress != "" {
+			if err := sendStatsD(*statsdAddress, metrics, ciRepository()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			} else {
+				outputs = append(outputs, "udp://"+*statsdAddress)
+			}
+		}
+	}
+
+	publishSpan.finish()
+	exportTraces()
+
 	if fileInfo.IsDir() {
 		fmt.Printf("Multi-plan comment generated from %d plan(s): %s\n", len(plans), outputFile)
 	} else {
 		fmt.Printf("Terraform plan comment generated: %s\n", outputFile)
 	}
+
+	if *githubAnnotations {
+		for _, annotation := range workflowAnnotations(plans, failRules, opts) {
+			fmt.Println(annotation)
+		}
+	}
+
+	result := RunResult{
+		Environments: environmentResults(plans, opts),
+		Gates:        []GateResult{},
+		Outputs:      outputs,
+	}
+	for _, planInfo := range plans {
+		if planHasChanges(planInfo.Plan) {
+			result.HasChanges = true
+		}
+	}
+
+	if len(failRules) > 0 {
+		gate := GateResult{Gate: gateFailOn, Violations: findGateViolations(plans, failRules)}
+		if len(gate.Violations) > 0 {
+			fmt.Fprintf(os.Stderr, "Plan contains changes matching -fail-on:\n")
+			for _, violation := range gate.Violations {
+				fmt.Fprintf(os.Stderr, "  - %s\n", violation)
+			}
+		}
+		result.Gates = append(result.Gates, gate)
+	}
+	if opts.Thresholds.failsOnExceed() {
+		gate := GateResult{Gate: gateThresholds}
+		for _, planInfo := range plans {
+			if breaches := checkThresholds(planInfo.Plan, planInfo.RelativePath, opts.Thresholds); len(breaches) > 0 {
+				violation := fmt.Sprintf("Change thresholds exceeded%s: %s", formatEnvironmentSuffix(environmentName(planInfo.RelativePath, opts.EnvironmentNames)), strings.Join(breaches, ", "))
+				fmt.Fprintln(os.Stderr, violation)
+				gate.Violations = append(gate.Violations, violation)
+			}
+		}
+		result.Gates = append(result.Gates, gate)
+	}
+	if opts.Naming != nil && opts.Naming.Fail {
+		gate := GateResult{Gate: gateNaming}
+		for _, planInfo := range plans {
+			for _, naming := range checkNaming(planInfo.Plan, opts.Naming) {
+				violation := fmt.Sprintf("Naming convention violated%s: %s", formatEnvironmentSuffix(environmentName(planInfo.RelativePath, opts.EnvironmentNames)), formatNamingViolation(naming))
+				fmt.Fprintln(os.Stderr, violation)
+				gate.Violations = append(gate.Violations, violation)
+			}
+		}
+		result.Gates = append(result.Gates, gate)
+	}
+	if opts.TagPolicy != nil && opts.TagPolicy.Fail {
+		gate := GateResult{Gate: gateTagPolicy}
+		for _, planInfo := range plans {
+			for _, tags := range checkTagPolicy(planInfo.Plan, opts.TagPolicy) {
+				violation := fmt.Sprintf("Required tags missing%s: %s", formatEnvironmentSuffix(environmentName(planInfo.RelativePath, opts.EnvironmentNames)), formatTagViolation(tags))
+				fmt.Fprintln(os.Stderr, violation)
+				gate.Violations = append(gate.Violations, violation)
+			}
+		}
+		result.Gates = append(result.Gates, gate)
+	}
+
+	result.ExitReason = "success"
+	for i := range result.Gates {
+		result.Gates[i].Passed = len(result.Gates[i].Violations) == 0
+		if !result.Gates[i].Passed {
+			result.ExitCode, result.ExitReason = exitCodeGateFailed, "gate_failed"
+		}
+	}
+	if result.ExitCode == 0 && *detailedExitCode && result.HasChanges {
+		result.ExitCode, result.ExitReason = exitCodeChanges, "changes"
+	}
+
+	if *bitbucketInsights {
+		report := bitbucketReport(plans, result)
+		annotations := bitbucketAnnotations(workflowAnnotations(plans, failRules, opts))
+		reportURL, err := publishBitbucketInsights(*commit, report, annotations)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error publishing Bitbucket Code Insights report: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Bitbucket Code Insights report published: %s\n", report.Result)
+		result.Outputs = append(result.Outputs, reportURL)
+	}
+
+	if *buildkiteAnnotate {
+		style := buildkiteStyle(plans, result)
+		buildURL, err := annotateBuildkite(markdown, style)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating Buildkite annotation: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Buildkite annotation created: %s\n", style)
+		if buildURL != "" {
+			result.Outputs = append(result.Outputs, buildURL)
+		}
+	}
+
+	if *jenkinsOutput != "" {
+		written, err := writeJenkinsOutputs(*jenkinsOutput, result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing Jenkins outputs: %v\n", err)
+			os.Exit(1)
+		}
+		result.Outputs = append(result.Outputs, written...)
+	}
+
+	if *resultFile != "" {
+		if err := writeRunResult(*resultFile, result); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing run result: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The audit record goes last so it lists every destination of the run
+	if *auditLog != "" {
+		audited := result.Outputs
+		if *resultFile != "" {
+			audited = append(audited, *resultFile)
+		}
+		if err := writeAuditRecord(*auditLog, auditRecord(plans, report, audited, *commit, time.Now())); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing audit record: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if result.ExitCode != 0 {
+		os.Exit(result.ExitCode)
+	}
 }
 
 func printUsage() {
 	fmt.Printf("tfplan-commenter version %s\n\n", Version)
 	fmt.Println("Usage: tfplan-commenter [options] <input> [output.md]")
+	fmt.Println("       tfplan-commenter baseline <input> [baseline.json]")
+	fmt.Println("       tfplan-commenter diff <old-plan.json> <new-plan.json> [output.md]")
+	fmt.Println("       tfplan-commenter validate <input>")
+	fmt.Println("       tfplan-commenter report trends <history-file> [output.md]")
+	fmt.Println("       tfplan-commenter dashboard <history-file> [output-dir]")
+	fmt.Println("       tfplan-commenter serve [-addr :8080] [-config file] [-tls-cert file -tls-key file [-tls-client-ca file]]")
+	fmt.Println("                              [-insecure-no-signature]")
+	fmt.Println("       tfplan-commenter sign <file|->")
+	fmt.Println("       tfplan-commenter verify -key <public-key> [-attestation file] <report>")
+	fmt.Println("       tfplan-commenter self-update [-check] [-version tag] [-key public-key | -insecure-skip-signature]")
 	fmt.Println()
 	fmt.Println("Arguments:")
-	fmt.Println("  input        Path to a Terraform plan JSON file or directory containing tfplan.json files")
+	fmt.Println("  input        Path to a Terraform plan JSON file, CloudFormation change set JSON file,")
+	fmt.Println("               or directory containing tfplan.json files")
 	fmt.Println("  output.md    Output markdown file (default: terraform-plan-comment.md)")
 	fmt.Println()
+	fmt.Println("Commands:")
+	fmt.Println("  baseline     Record the input's current changes as expected, for use with -baseline")
+	fmt.Println("               (default output: " + defaultBaselineFile + ")")
+	fmt.Println("  diff         Report how a re-run plan differs from an earlier plan of the same workspace")
+	fmt.Println("               (default output: " + defaultPlanDiffFile + ")")
+	fmt.Println("  validate     Check plan files for structural problems before generating a report")
+	fmt.Println("  report trends")
+	fmt.Println("               Summarize churn and change frequency per environment from a -history file")
+	fmt.Println("  dashboard    Generate a static HTML dashboard from a -history file")
+	fmt.Println("               (default output: " + defaultDashboardDir + "/index.html)")
+	fmt.Println("  serve        Serve POST /render (plan JSON in, markdown out), POST /render-multi and POST /publish")
+	fmt.Println("               (posts the report on a pull request using the server's GITHUB_TOKEN). The same")
+	fmt.Println("               operations are served over gRPC (HTTP/2) as described in api/tfplan_commenter.proto.")
+	fmt.Println("               POST /webhook/github (workflow_run) and /webhook/gitlab (pipeline) render")
+	fmt.Println("               the tfplan.json files in the run's artifacts and comment on the PR/MR")
+	fmt.Println("               (GitLab uses GITLAB_TOKEN and GITLAB_API_URL or CI_API_V4_URL). When")
+	fmt.Println("               " + webhookSecretEnv + " is set, request bodies must carry a valid")
+	fmt.Println("               " + signatureHeader + " HMAC (GitLab webhooks may send X-Gitlab-Token). With a")
+	fmt.Println("               forge token, serve refuses to start without the secret unless -insecure-no-signature")
+	fmt.Println("               With an \"approval\" section in -config, 👍 reactions or /approve-plan comments")
+	fmt.Println("               from listed users on the plan comment set the " + approvalStatusContext)
+	fmt.Println("               commit status; issue_comment webhooks and POST /approval re-evaluate it.")
+	fmt.Println("               A \"destructive_ack\" section fails " + destructiveStatusContext + " on deletes and")
+	fmt.Println("               replacements until a listed user comments /acknowledge-destroy. \"reviewers\"")
+	fmt.Println("               rules also apply to published plans (GitLab requests users only). Both statuses only")
+	fmt.Println("               pass while the plan's commit (the run's head_sha, or \"commit\" in POST /publish)")
+	fmt.Println("               is the pull request's head")
+	fmt.Println("  sign         Print the " + signatureHeader + " value of a payload using " + webhookSecretEnv)
+	fmt.Println("  verify       Check a report against its signed attestation (see -attest-key)")
+	fmt.Println("  self-update  Replace the running binary with the latest GitHub release after verifying it")
+	fmt.Println("               against checksums.txt and its signature (-key), or the checksums alone with")
+	fmt.Println("               -insecure-skip-signature")
+	fmt.Println()
 	fmt.Println("Options:")
 	fmt.Println("  -version     Show version information")
 	fmt.Println("  -help        Show this help message")
+	fmt.Println("  -show-reads  List data sources that will be read during apply")
+	fmt.Println("  -show-noop   List unchanged resources in a collapsed section")
+	fmt.Println("  -show-variables")
+	fmt.Println("               Render input variable values (sensitive values are redacted)")
+	fmt.Println("  -show-prior-state")
+	fmt.Println("               Render managed resource counts per type before the change")
+	fmt.Println("  -detail summary|full")
+	fmt.Println("               full also renders planned attributes of created resources (see planned_attributes)")
+	fmt.Println("  -show-planned")
+	fmt.Println("               Render key planned attributes (instance type, engine, CIDR, ...) of created resources")
+	fmt.Println("  -link-sources")
+	fmt.Println("               Link resources to the .tf files that declare them")
+	fmt.Println("  -source-root <dir>")
+	fmt.Println("               Root module directory for -link-sources (default: plan file's directory)")
+	fmt.Println("  -source-url <url>")
+	fmt.Println("               Base VCS URL for -link-sources (default: relative links)")
+	fmt.Println("  -link-docs")
+	fmt.Println("               Link resource types to their Terraform Registry documentation pages")
+	fmt.Println("  -show-providers")
+	fmt.Println("               Render required providers and flag constraint changes vs .terraform.lock.hcl")
+	fmt.Println("  -previous-plan <file>")
+	fmt.Println("               Earlier plan JSON to compare provider constraints against")
+	fmt.Println("  -redact-pattern <regex>")
+	fmt.Println("               Variable names, and attribute names in 0.1 plans, whose values are redacted (default matches password, secret, token, ...)")
+	fmt.Println("  -show-regions")
+	fmt.Println("               Render a breakdown of changes by region and account (from provider config and ARNs)")
+	fmt.Println("  -sort address|type|action|impact")
+	fmt.Println("               Resource ordering; action and impact list deletes and replacements first")
+	fmt.Println("  -sort-reverse")
+	fmt.Println("               Reverse the -sort ordering")
+	fmt.Println("  -max-detail <n>")
+	fmt.Println("               Maximum detailed entries per section; the rest are listed in a collapsed block")
+	fmt.Println("  -style default|compact|detailed|changelog")
+	fmt.Println("               Built-in report style: compact renders only summary tables, detailed enables every")
+	fmt.Println("               optional section, changelog lists changed resources as Added/Changed/Removed entries")
+	fmt.Println("  -summary-only")
+	fmt.Println("               Render only the overall and per-environment tables, for chat or PR descriptions")
+	fmt.Println("  -collapse-over <n>")
+	fmt.Println("               Directory mode: show only the summary table of environments with more than n")
+	fmt.Println("               changes, with their details in a collapsed block")
+	fmt.Println("  -fail-on <rules>")
+	fmt.Println("               Exit with code 3 when matching changes exist; comma-separated actions")
+	fmt.Println("               (create, update, replace, delete), optionally qualified by a resource")
+	fmt.Println("               type glob, e.g. delete,replace:aws_db_*")
+	fmt.Println("  -detailed-exitcode")
+	fmt.Println("               Exit with 0 when there are no changes and 2 when changes are present")
+	fmt.Println("  -policy-results <file>")
+	fmt.Println("               conftest JSON output (conftest test -o json) to merge next to affected resources")
+	fmt.Println("  -scan-results <file,...>")
+	fmt.Println("               Checkov, tfsec or Trivy JSON reports whose findings are attached to resources")
+	fmt.Println("  -infracost <file|run>")
+	fmt.Println("               Infracost breakdown/diff JSON, or \"run\" to invoke infracost; adds monthly cost deltas")
+	fmt.Println("  -baseline <file>")
+	fmt.Println("               Expected changes (from the baseline command) left out of the report and gating")
+	fmt.Println("  -timezone <name>")
+	fmt.Println("               Time zone of the generation time in the report footer (default: UTC)")
+	fmt.Println("  -header-file <file>")
+	fmt.Println("               Markdown prepended to the report, e.g. \"Apply requires 2 approvals\"")
+	fmt.Println("  -footer-text <text>")
+	fmt.Println("               Markdown appended to the report, e.g. links to runbooks")
+	fmt.Println("  -append      Append the report to the output file under a header naming the input,")
+	fmt.Println("               so several runs (e.g. pipeline stages) accumulate into one file")
+	fmt.Println("  -post-comment")
+	fmt.Println("               Post the report on the pull request of a Woodpecker, Drone or GitHub Actions run")
+	fmt.Println("               (GITHUB_TOKEN for GitHub, GITEA_TOKEN for Gitea and Forgejo); \"reviewers\" rules")
+	fmt.Println("               in -config request reviews when matching resource types or environments change")
+	fmt.Println("  -comment-strategy combined|per-environment")
+	fmt.Println("               per-environment keeps one comment per environment, updated in place on later runs,")
+	fmt.Println("               and an index comment linking them")
+	fmt.Println("  -pr-description")
+	fmt.Println("               Insert or refresh a one-line plan summary between markers in the pull request")
+	fmt.Println("               description, detected and authenticated as for -post-comment")
+	fmt.Println("  -forge github|gitea")
+	fmt.Println("               Override the forge detected for -post-comment, e.g. Drone with GitHub Enterprise")
+	fmt.Println("  -changelog   Append a dated entry listing added, changed, replaced and removed resources")
+	fmt.Println("               to -changelog-file (default: " + defaultChangelogFile + "), referencing -commit; an")
+	fmt.Println("               entry for the same commit is not added twice")
+	fmt.Println("  -gitlab-report <file>")
+	fmt.Println("               Write change counts in the artifacts:reports:terraform format for GitLab's MR widget")
+	fmt.Println("  -bitbucket-insights")
+	fmt.Println("               Publish a Code Insights report and annotations for the commit (BITBUCKET_WORKSPACE,")
+	fmt.Println("               BITBUCKET_REPO_SLUG; BITBUCKET_TOKEN unless running in Pipelines)")
+	fmt.Println("  -buildkite-annotate")
+	fmt.Println("               Annotate the Buildkite build with the report (error, warning, info or success style),")
+	fmt.Println("               with buildkite-agent or else the API (BUILDKITE_API_TOKEN)")
+	fmt.Println("  -jenkins-output <dir>")
+	fmt.Println("               Write " + jenkinsDescriptionFile + " (build description using Safe HTML tags only) and")
+	fmt.Println("               " + jenkinsBadgeFile + " (one-line summary for badges or the build name) for Jenkins")
+	fmt.Println("  -github-annotations")
+	fmt.Println("               Print GitHub Actions workflow commands: warnings for deletes and replaces, errors")
+	fmt.Println("               for gate violations, placed on the declaring resource block when it can be found")
+	fmt.Println("  -result-file <file>")
+	fmt.Println("               Write the run result as JSON: exit code and reason, per-environment counts,")
+	fmt.Println("               gate verdicts and output locations, for later pipeline steps to branch on")
+	fmt.Println("  -dry-run     Discover, parse and render, then print the files and endpoints the run would")
+	fmt.Println("               write to or call, with sizes, without writing or sending anything")
+	fmt.Println("  -check <golden.md>")
+	fmt.Println("               Compare the report against a committed golden file instead of writing it;")
+	fmt.Println("               exits with code 4 and prints a diff on mismatch")
+	fmt.Println("  -history <file>")
+	fmt.Println("               Record each environment's change counts and risk score in a history file: an SQLite")
+	fmt.Println("               database (table \"history\") for .sqlite, .sqlite3 and .db files, JSON Lines otherwise")
+	fmt.Println("  -metrics-file <file>")
+	fmt.Println("               Write change counts per environment, risk, plan size and parse duration as OpenMetrics text")
+	fmt.Println("  -pushgateway <url>")
+	fmt.Println("               Push the same metrics to a Prometheus Pushgateway (job \"" + pushgatewayJob + "\")")
+	fmt.Println("  -statsd <host:port>")
+	fmt.Println("               Send the same metrics as DogStatsD gauges tagged with environment and repository")
+	fmt.Println("  -otlp-endpoint <url>")
+	fmt.Println("               Export discovery, parse, analysis, render and publish spans over OTLP/HTTP")
+	fmt.Println("               (default: OTEL_EXPORTER_OTLP_TRACES_ENDPOINT or OTEL_EXPORTER_OTLP_ENDPOINT)")
+	fmt.Println("  -attest-key <file>")
+	fmt.Println("               Sign an in-toto attestation (DSSE envelope) binding the report to the plan hashes")
+	fmt.Println("               and commit; written to <output>" + attestationExtension + " (Ed25519, ECDSA or RSA PEM key)")
+	fmt.Println("  -commit <sha>")
+	fmt.Println("               Commit recorded in the attestation and changelog (default: GITHUB_SHA, CI_COMMIT_SHA, ...)")
+	fmt.Println("  -audit-log <file|url>")
+	fmt.Println("               Append a JSON Lines audit record (input plan hashes, change counts, report hash,")
+	fmt.Println("               outputs, actor, commit, time) to a file, or POST it to an http(s) endpoint")
+	fmt.Println("               (signed with " + signatureHeader + " when " + webhookSecretEnv + " is set)")
+	fmt.Println("  -only <globs>, -except <globs>")
+	fmt.Println("               Directory mode: include or exclude environments by relative path or directory")
+	fmt.Println("               name, e.g. -only \"prod/*\" -except \"sandbox/*\"")
+	fmt.Println("  -filter-address <regex>, -filter-type <regex>")
+	fmt.Println("               Only report (and gate on) resources whose address or type matches, e.g.")
+	fmt.Println("               -filter-address '^module\\.network\\.'; the report notes how many were filtered out")
+	fmt.Println("  -module <addresses>")
+	fmt.Println("               Only report resources under a module subtree, e.g. -module module.networking")
+	fmt.Println("               (includes nested modules and module instances)")
+	fmt.Println("  -provider <names>")
+	fmt.Println("               Only report resources of these providers, by short name (aws), source")
+	fmt.Println("               (hashicorp/aws) or full address, e.g. -provider aws,kubernetes")
+	fmt.Println("  -group-by module")
+	fmt.Println("               Organize changes under their module address with per-module counts")
+	fmt.Println("  -config <file>")
+	fmt.Println("               JSON configuration file, e.g.")
+	fmt.Println("               {\"ignore_attributes\": [\"tags\\\\.LastModified\"],")
+	fmt.Println("                \"resource_ignore_attributes\": {\"kubernetes_*\": [\"metadata.generation\"]},")
+	fmt.Println("                \"risk\": {\"weights\": {\"delete\": 20}, \"thresholds\": {\"high\": 50}},")
+	fmt.Println("                \"thresholds\": {\"default\": {\"max_deletes\": 0, \"max_total_changes\": 200}}}")
+	fmt.Println("               Exceeded thresholds exit with code 3 unless thresholds.on_exceed is \"warn\"")
+	fmt.Println("               Filter resources with {\"resources\": {\"exclude\": [\"null_resource.*\"]}} and add")
+	fmt.Println("               gating rules with {\"fail_on\": [\"delete:aws_kms_key\"]}")
+	fmt.Println("               Show friendly environment names with")
+	fmt.Println("               {\"environment_names\": {\"stacks/eu-west-1/prod\": \"Production (Ireland)\"}} and list")
+	fmt.Println("               environments first with {\"environment_order\": [\"prod*\", \"staging*\"]}")
+	fmt.Println("               Group environments into tiers with subtotals, collapsing some by default:")
+	fmt.Println("               {\"tiers\": [{\"name\": \"Production\", \"environments\": [\"prod*\"]},")
+	fmt.Println("                          {\"name\": \"Development\", \"environments\": [\"dev*\"], \"collapsed\": true}]}")
+	fmt.Println("               Mention owning teams in environments where their modules change:")
+	fmt.Println("               {\"owners\": [{\"patterns\": [\"module.network\"], \"teams\": [\"@org/networking\"]}]}")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  # Process single plan file")
 	fmt.Println("  tfplan-commenter tfplan.json")
 	fmt.Println("  tfplan-commenter tfplan.json my-comment.md")
 	fmt.Println()
+	fmt.Println("  # Process CloudFormation change set (aws cloudformation describe-change-set output)")
+	fmt.Println("  tfplan-commenter changeset.json")
+	fmt.Println()
 	fmt.Println("  # Process directory with multiple plan files")
 	fmt.Println("  tfplan-commenter ./tfplans/")
 	fmt.Println("  tfplan-commenter ./environments/ multi-env-comment.md")
@@ -185,7 +1200,19 @@ func printUsage() {
 }
 
 func findAndReadPlanFiles(rootDir string) ([]PlanInfo, error) {
-	var plans []PlanInfo
+	paths, err := discoverPlanFiles(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	return readPlanFiles(rootDir, paths), nil
+}
+
+// planFileName is the file name looked for in plan directories and artifact archives
+const planFileName = "tfplan.json"
+
+// discoverPlanFiles returns the paths of the tfplan.json files under rootDir
+func discoverPlanFiles(rootDir string) ([]string, error) {
+	var paths []string
 
 	err := filepath.Walk(rootDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -193,44 +1220,47 @@ func findAndReadPlanFiles(rootDir string) ([]PlanInfo, error) {
 		}
 
 		// Look for files named "tfplan.json"
-		if !info.IsDir() && info.Name() == "tfplan.json" {
-			// Read and parse the plan file
-			plan, err := readTerraformPlan(path)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: Failed to read plan file %s: %v\n", path, err)
-				return nil // Continue processing other files
-			}
+		if !info.IsDir() && info.Name() == planFileName {
+			paths = append(paths, path)
+		}
 
-			// Skip plans with no changes
-			if hasNoChanges(plan) {
-				fmt.Printf("Skipping %s (no changes)\n", path)
-				return nil
-			}
+		return nil
+	})
 
-			// Calculate relative path from root directory
-			relPath, err := filepath.Rel(rootDir, filepath.Dir(path))
-			if err != nil {
-				relPath = filepath.Dir(path)
-			}
+	if err != nil {
+		return nil, fmt.Errorf("error walking directory: %w", err)
+	}
+	return paths, nil
+}
 
-			// Clean up the relative path (remove leading ./ if present)
-			if relPath == "." {
-				relPath = "root"
-			}
+// readPlanFiles reads discovered plan files, skipping unreadable plans and
+// plans with nothing to report
+func readPlanFiles(rootDir string, paths []string) []PlanInfo {
+	var plans []PlanInfo
+	progress := newProgressReporter(len(paths))
 
-			plans = append(plans, PlanInfo{
-				Plan:         plan,
-				RelativePath: relPath,
-			})
+	for _, path := range paths {
+		// Read and parse the plan file
+		progress.start(path)
+		plan, err := readTerraformPlan(path)
+		progress.finish()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read plan file %s: %v\n", path, err)
+			continue // Continue processing other files
+		}
 
-			fmt.Printf("Found plan with changes: %s\n", path)
+		// Skip plans with no changes
+		if hasNoChanges(plan) {
+			fmt.Printf("Skipping %s (no changes)\n", path)
+			continue
 		}
 
-		return nil
-	})
+		plans = append(plans, PlanInfo{
+			Plan:         plan,
+			RelativePath: environmentPath(rootDir, path),
+		})
 
-	if err != nil {
-		return nil, fmt.Errorf("error walking directory: %w", err)
+		fmt.Printf("Found plan with changes: %s\n", path)
 	}
 
 	// Sort plans by relative path for consistent output
@@ -238,11 +1268,23 @@ func findAndReadPlanFiles(rootDir string) ([]PlanInfo, error) {
 		return plans[i].RelativePath < plans[j].RelativePath
 	})
 
-	return plans, nil
+	return plans
 }
 
+// hasNoChanges reports whether a plan has nothing worth reporting: no
+// resource changes and nothing reported besides them
 func hasNoChanges(plan *TerraformPlan) bool {
-	return len(plan.ResourceChanges) == 0
+	return len(plan.ResourceChanges) == 0 && !hasPlanNotices(plan)
+}
+
+// hasPlanNotices reports whether a plan has drift, output changes, checks not
+// passing, or errored or incomplete status. Terraform marks every plan without
+// changes as not applyable, so that alone isn't a notice.
+func hasPlanNotices(plan *TerraformPlan) bool {
+	return len(formatDriftEntries(plan.ResourceDrift)) > 0 ||
+		len(formatOutputChanges(plan.OutputChanges)) > 0 ||
+		len(formatCheckEntries(plan.Checks)) > 0 ||
+		plan.Errored || (plan.Complete != nil && !*plan.Complete)
 }
 
 func readTerraformPlan(filename string) (*TerraformPlan, error) {
@@ -257,34 +1299,85 @@ func readTerraformPlan(filename string) (*TerraformPlan, error) {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
 
+	plan, err := parseTerraformPlan(data, filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", filename, warning)
+	}
+	return plan, nil
+}
+
+// parseTerraformPlan parses plan JSON, or a CloudFormation change set, read from filename
+func parseTerraformPlan(data []byte, filename string) (*TerraformPlan, error) {
+	digest := sha256.Sum256(data)
+	if isCloudFormationChangeSet(data) {
+		plan, err := parseCloudFormationChangeSet(data)
+		if err != nil {
+			return nil, err
+		}
+		plan.Digest = hex.EncodeToString(digest[:])
+		return plan, nil
+	}
+
 	var plan TerraformPlan
-	err = json.Unmarshal(data, &plan)
+	err := json.Unmarshal(data, &plan)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
+	plan.PlanPath = filename
+	plan.Digest = hex.EncodeToString(digest[:])
+
+	warnings, err := validateFormatVersion(&plan)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
+	}
+	plan.Warnings = warnings
+	adaptFormatVersion(&plan)
 
 	return &plan, nil
 }
 
-func generateMultiPlanMarkdownComment(plans []PlanInfo) string {
+func generateMultiPlanMarkdownComment(plans []PlanInfo, opts ReportOptions) string {
 	var md strings.Builder
+	plans = orderEnvironments(plans, opts.EnvironmentOrder)
+	for _, planInfo := range plans {
+		maskLegacyAttributes(planInfo.Plan, opts.RedactPattern)
+	}
+	if opts.Style == styleChangelog {
+		return generateChangelogMarkdown(plans, opts)
+	}
 
 	// Header
 	md.WriteString("## 📋 Multi-Environment Terraform Plan Summary\n\n")
 
 	// Overall statistics across all plans
-	totalCreate, totalUpdate, totalDelete, totalReplace := 0, 0, 0, 0
+	var overallRisk RiskAssessment
+	var riskiestEnv string
+	totalCreate, totalUpdate, totalDelete, totalReplace, totalMove, totalImport, totalDeposed := 0, 0, 0, 0, 0, 0, 0
+	totalChanges := 0
 	var allTerraformVersions []string
 
 	for _, planInfo := range plans {
 		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		if risk := assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk); risk.Score > overallRisk.Score {
+			overallRisk, riskiestEnv = risk, planInfo.RelativePath
+		}
 		totalCreate += len(summary.Create)
 		totalUpdate += len(summary.Update)
 		totalDelete += len(summary.Delete)
 		totalReplace += len(summary.Replace)
+		totalMove += len(summary.Move)
+		totalImport += len(summary.Import)
+		totalDeposed += len(summary.Deposed)
+		totalChanges += summary.affectedResources()
 
 		// Collect unique Terraform versions
 		version := planInfo.Plan.TerraformVersion
+		if planInfo.Plan.Source == sourceCloudFormation {
+			version = "CloudFormation"
+		}
 		found := false
 		for _, v := range allTerraformVersions {
 			if v == version {
@@ -297,13 +1390,42 @@ func generateMultiPlanMarkdownComment(plans []PlanInfo) string {
 		}
 	}
 
-	totalChanges := totalCreate + totalUpdate + totalDelete + totalReplace
+	writeVersionMismatchSection(&md, plans, opts)
+
+	partial := 0
+	for _, planInfo := range plans {
+		if len(planStatusWarnings(planInfo.Plan)) > 0 {
+			partial++
+		}
+	}
+	if partial > 0 {
+		md.WriteString(fmt.Sprintf("> ⚠️ **%d environment(s) have errored, incomplete or non-applyable plans** - see details below\n\n", partial))
+	}
 
 	if totalChanges == 0 {
 		md.WriteString("✅ **No changes detected across all environments** - Infrastructure is up to date!\n\n")
+		for _, planInfo := range plans {
+			if hasPlanNotices(planInfo.Plan) || len(planStatusWarnings(planInfo.Plan)) > 0 {
+				writeEnvironmentDetails(&md, planInfo, opts)
+			}
+		}
 		return md.String()
 	}
 
+	exceeded := 0
+	for _, planInfo := range plans {
+		if len(checkThresholds(planInfo.Plan, planInfo.RelativePath, opts.Thresholds)) > 0 {
+			exceeded++
+		}
+	}
+	if exceeded > 0 {
+		md.WriteString(fmt.Sprintf("> 🚨 **%d environment(s) exceed change thresholds** - see details below\n\n", exceeded))
+	}
+
+	if riskiestEnv != "" {
+		md.WriteString(fmt.Sprintf("%s - highest in %s\n\n", formatRisk(overallRisk), formatEnvironment(riskiestEnv, opts.EnvironmentNames)))
+	}
+
 	md.WriteString(fmt.Sprintf("**Environments processed:** %d\n", len(plans)))
 	md.WriteString(fmt.Sprintf("**Total resources affected:** %d\n\n", totalChanges))
 
@@ -324,140 +1446,372 @@ func generateMultiPlanMarkdownComment(plans []PlanInfo) string {
 	if totalDelete > 0 {
 		md.WriteString(fmt.Sprintf("| 🔴 **Delete** | %d |\n", totalDelete))
 	}
+	if totalMove > 0 {
+		md.WriteString(fmt.Sprintf("| 🚚 **Move** | %d |\n", totalMove))
+	}
+	if totalImport > 0 {
+		md.WriteString(fmt.Sprintf("| 📥 **Import** | %d |\n", totalImport))
+	}
+	if totalDeposed > 0 {
+		md.WriteString(fmt.Sprintf("| 🗑️ **Destroy deposed** | %d |\n", totalDeposed))
+	}
 
 	md.WriteString("\n")
 
+	writeCostSection(&md, plans, opts.Costs, opts.EnvironmentNames)
+
 	// Environment-specific sections
-	md.WriteString("### 🏗️ Environment Details\n\n")
+	if opts.SummaryOnly {
+		writeEnvironmentSummarySection(&md, plans, opts)
+	} else if len(opts.Tiers) > 0 {
+		groups := groupByTier(plans, opts.Tiers)
+		writeTierSummarySection(&md, groups)
+		writeTieredEnvironmentDetails(&md, groups, opts)
+	} else {
+		md.WriteString("### 🏗️ Environment Details\n\n")
+		for _, planInfo := range plans {
+			writeEnvironmentDetails(&md, planInfo, opts)
+		}
+	}
 
-	for _, planInfo := range plans {
-		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
-		envTotalChanges := len(summary.Create) + len(summary.Update) + len(summary.Delete) + len(summary.Replace)
+	// Footer
+	if len(allTerraformVersions) == 1 && allTerraformVersions[0] == "CloudFormation" {
+		md.WriteString("*Generated from AWS CloudFormation change sets*\n")
+	} else if len(allTerraformVersions) == 1 {
+		md.WriteString(fmt.Sprintf("*Generated from Terraform %s plans*\n", allTerraformVersions[0]))
+	} else {
+		md.WriteString(fmt.Sprintf("*Generated from Terraform plans (versions: %s)*\n", strings.Join(allTerraformVersions, ", ")))
+	}
 
-		// Environment header
-		md.WriteString(fmt.Sprintf("#### 📁 `%s`\n\n", planInfo.RelativePath))
+	return md.String()
+}
 
-		if envTotalChanges == 0 {
-			md.WriteString("✅ No changes in this environment\n\n")
-			continue
+// writeEnvironmentDetails renders one environment's section of a multi-plan report
+func writeEnvironmentDetails(md *strings.Builder, planInfo PlanInfo, opts ReportOptions) {
+	summary := analyzePlan(planInfo.Plan)
+	applyIgnoreRules(&summary, planInfo.Plan, opts.IgnoreRules)
+	applyBaselineAttributes(&summary, planInfo.RelativePath, opts.Baseline)
+	sortSummary(&summary, planInfo.Plan, opts.Sort, opts.SortReverse)
+	if opts.LinkSources {
+		annotateSourceLinks(&summary, planInfo.Plan, opts)
+	}
+	if opts.LinkDocs {
+		annotateDocsLinks(&summary, planInfo.Plan)
+	}
+	unattached := attachFindings(&summary, planInfo.Plan, opts.Findings)
+	annotateCostSignals(&summary, planInfo.Plan)
+	envTotalChanges := summary.affectedResources()
+
+	// Environment header
+	md.WriteString(fmt.Sprintf("#### 📁 %s\n\n", formatEnvironment(planInfo.RelativePath, opts.EnvironmentNames)))
+	writePlanStatus(md, planInfo.Plan)
+	writeWarnings(md, planInfo.Plan.Warnings)
+	writeFilteredNote(md, planInfo.Plan)
+
+	if envTotalChanges == 0 {
+		md.WriteString("✅ No changes in this environment\n\n")
+		if opts.ShowReads {
+			writeEnvironmentReadsSection(md, summary.Read)
 		}
+		if opts.ShowNoOp {
+			writeNoOpSection(md, summary.NoOp)
+		}
+		writeEnvironmentDriftSection(md, planInfo.Plan.ResourceDrift)
+		writeEnvironmentOutputsSection(md, planInfo.Plan.OutputChanges)
+		writeEnvironmentChecksSection(md, planInfo.Plan.Checks)
+		if opts.ShowVariables {
+			writeVariablesSection(md, planInfo.Plan, opts.RedactPattern)
+		}
+		return
+	}
 
-		// Environment summary table
-		md.WriteString("| Action | Count | Resources |\n")
-		md.WriteString("|--------|-------|----------|\n")
+	md.WriteString(formatRisk(assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk)) + "\n\n")
+	if teams := owningTeams(planInfo.Plan, opts.Owners); len(teams) > 0 {
+		md.WriteString(formatOwners(teams) + "\n\n")
+	}
+	writeThresholdBanner(md, checkThresholds(planInfo.Plan, planInfo.RelativePath, opts.Thresholds))
+	if estimate, ok := opts.Costs.estimateFor(planInfo.Plan); ok {
+		md.WriteString(formatCostImpact(estimate) + "\n\n")
+	}
 
-		if len(summary.Create) > 0 {
-			md.WriteString(fmt.Sprintf("| 🟢 **Create** | %d | %s |\n",
-				len(summary.Create),
-				formatResourceList(summary.Create, 3)))
-		}
+	// Environment summary table
+	md.WriteString("| Action | Count | Resources |\n")
+	md.WriteString("|--------|-------|----------|\n")
 
-		if len(summary.Update) > 0 {
-			md.WriteString(fmt.Sprintf("| 🟡 **Update** | %d | %s |\n",
-				len(summary.Update),
-				formatResourceList(summary.Update, 3)))
-		}
+	if len(summary.Create) > 0 {
+		md.WriteString(fmt.Sprintf("| 🟢 **Create** | %d | %s |\n",
+			len(summary.Create),
+			formatResourceList(summary.Create, 3)))
+	}
 
-		if len(summary.Replace) > 0 {
-			md.WriteString(fmt.Sprintf("| 🔄 **Replace** | %d | %s |\n",
-				len(summary.Replace),
-				formatResourceList(summary.Replace, 3)))
-		}
+	if len(summary.Update) > 0 {
+		md.WriteString(fmt.Sprintf("| 🟡 **Update** | %d | %s |\n",
+			len(summary.Update),
+			formatResourceList(summary.Update, 3)))
+	}
 
-		if len(summary.Delete) > 0 {
-			md.WriteString(fmt.Sprintf("| 🔴 **Delete** | %d | %s |\n",
-				len(summary.Delete),
-				formatResourceList(summary.Delete, 3)))
-		}
+	if len(summary.Replace) > 0 {
+		md.WriteString(fmt.Sprintf("| 🔄 **Replace** | %d | %s |\n",
+			len(summary.Replace),
+			formatResourceList(summary.Replace, 3)))
+	}
 
-		md.WriteString("\n")
+	if len(summary.Delete) > 0 {
+		md.WriteString(fmt.Sprintf("| 🔴 **Delete** | %d | %s |\n",
+			len(summary.Delete),
+			formatResourceList(summary.Delete, 3)))
+	}
 
+	if len(summary.Move) > 0 {
+		md.WriteString(fmt.Sprintf("| 🚚 **Move** | %d | %s |\n",
+			len(summary.Move),
+			formatResourceList(summary.Move, 3)))
+	}
+
+	if len(summary.Import) > 0 {
+		md.WriteString(fmt.Sprintf("| 📥 **Import** | %d | %s |\n",
+			len(summary.Import),
+			formatResourceList(summary.Import, 3)))
+	}
+
+	if len(summary.Deposed) > 0 {
+		md.WriteString(fmt.Sprintf("| 🗑️ **Destroy deposed** | %d | %s |\n",
+			len(summary.Deposed),
+			formatResourceList(summary.Deposed, 3)))
+	}
+
+	md.WriteString("\n")
+
+	// Very large environments keep only the summary table visible
+	collapsed := opts.CollapseOver > 0 && envTotalChanges > opts.CollapseOver
+	if collapsed {
+		md.WriteString(fmt.Sprintf("<details>\n<summary>Show details of %d change(s)</summary>\n\n", envTotalChanges))
+	}
+
+	writeEnvironmentSecuritySection(md, planInfo.Plan, summary, opts)
+	writeEnvironmentUnattachedFindingsSection(md, unattached)
+	writeEnvironmentIAMSection(md, planInfo.Plan)
+	writeEnvironmentCostSignalsSection(md, planInfo.Plan)
+	writeEnvironmentNamingSection(md, planInfo.Plan, opts.Naming)
+	writeEnvironmentTagPolicySection(md, planInfo.Plan, opts.TagPolicy)
+	writeEnvironmentResourceTypeSection(md, planInfo.Plan, summary, opts.LinkDocs)
+	if opts.ShowRegions {
+		writeEnvironmentRegionSection(md, planInfo.Plan, summary)
+	}
+
+	if opts.ShowPriorState {
+		writePriorStateSection(md, planInfo.Plan)
+	}
+	if opts.ShowProviders {
+		writeProvidersSection(md, planInfo.Plan, opts)
+	}
+
+	if opts.GroupBy == groupByModule {
+		writeEnvironmentModuleGroupsSection(md, planInfo.Plan, summary)
+	} else {
 		// Detailed sections for this environment
-		if len(summary.Create) > 0 {
-			md.WriteString("**🟢 Resources to be Created:**\n")
-			for _, resource := range summary.Create {
-				md.WriteString(fmt.Sprintf("- `%s`\n", resource.Address))
-			}
-			md.WriteString("\n")
-		}
-
-		if len(summary.Update) > 0 {
-			md.WriteString("**🟡 Resources to be Updated:**\n")
-			for _, resource := range summary.Update {
-				md.WriteString(fmt.Sprintf("- `%s`", resource.Address))
-				if len(resource.Changes) > 0 {
-					md.WriteString(" - ")
-					var changeDescs []string
-					for _, change := range resource.Changes {
-						if change.IsNew {
-							changeDescs = append(changeDescs, fmt.Sprintf("%s *(new)*", change.Attribute))
-						} else if change.IsRemoved {
-							changeDescs = append(changeDescs, fmt.Sprintf("%s *(removed)*", change.Attribute))
+		sections := map[string]func(){
+			"create": func() {
+				if len(summary.Create) > 0 {
+					md.WriteString("**🟢 Resources to be Created:**\n")
+					planned := plannedValuesByAddress(planInfo.Plan)
+					types := resourceTypesByAddress(planInfo.Plan)
+					shown, overflow := limitDetails(collapseInstances(summary.Create), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("- %s", formatResourceLabel(resource)))
+						attrs := plannedAttributeLines(types[resource.Address], planned[resource.Address], opts)
+						if opts.Detail == detailFull {
+							md.WriteString("\n")
+							for _, attr := range attrs {
+								md.WriteString(fmt.Sprintf("  - %s\n", attr))
+							}
 						} else {
-							changeDescs = append(changeDescs, change.Attribute)
+							if len(attrs) > 0 {
+								md.WriteString(fmt.Sprintf(" (%s)", strings.Join(attrs, ", ")))
+							}
+							md.WriteString("\n")
+						}
+						md.WriteString(formatFindingLines(resource.Findings, "  "))
+					}
+					md.WriteString("\n")
+					writeDetailOverflow(md, overflow)
+				}
+			},
+			"update": func() {
+				tagOnly, updates := splitTagOnlyResources(summary.Update)
+				if len(updates) > 0 {
+					md.WriteString("**🟡 Resources to be Updated:**\n")
+					shown, overflow := limitDetails(collapseInstances(updates), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("- %s", formatResourceLabel(resource)))
+						if len(resource.ChangeDrivers) > 0 {
+							md.WriteString(fmt.Sprintf(" *(because %s changed)*", formatChangeDrivers(resource.ChangeDrivers)))
+						}
+						if len(resource.Changes) > 0 {
+							md.WriteString(" - ")
+							var changeDescs []string
+							tags, changes := splitTagChanges(resource.Changes)
+							for _, change := range changes {
+								if change.Note != "" {
+									changeDescs = append(changeDescs, fmt.Sprintf("%s *(%s)*", change.Attribute, change.Note))
+								} else if change.IsNew {
+									changeDescs = append(changeDescs, fmt.Sprintf("%s *(new)*", change.Attribute))
+								} else if change.IsRemoved {
+									changeDescs = append(changeDescs, fmt.Sprintf("%s *(removed)*", change.Attribute))
+								} else {
+									changeDescs = append(changeDescs, change.Attribute)
+								}
+							}
+							if len(tags) > 0 {
+								changeDescs = append(changeDescs, fmt.Sprintf("%d tag(s)", len(tags)))
+							}
+							md.WriteString(strings.Join(changeDescs, ", "))
+						}
+						md.WriteString("\n")
+						md.WriteString(formatFindingLines(resource.Findings, "  "))
+					}
+					md.WriteString("\n")
+					writeDetailOverflow(md, overflow)
+				}
+				writeEnvironmentTagOnlySection(md, tagOnly)
+			},
+			"replace": func() {
+				if len(summary.Replace) > 0 {
+					md.WriteString("**🔄 Resources to be Replaced:**\n")
+					shown, overflow := limitDetails(collapseInstances(summary.Replace), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("- %s", formatResourceLabel(resource)))
+						if resource.ForceReason != "" {
+							md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
+						}
+						md.WriteString("\n")
+						md.WriteString(formatFindingLines(resource.Findings, "  "))
+					}
+					md.WriteString("\n")
+					writeDetailOverflow(md, overflow)
+				}
+			},
+			"delete": func() {
+				if len(summary.Delete) > 0 {
+					md.WriteString("**🔴 Resources to be Deleted:**\n")
+					shown, overflow := limitDetails(collapseInstances(summary.Delete), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("- %s", formatResourceLabel(resource)))
+						if resource.ForceReason != "" {
+							md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
+						}
+						md.WriteString("\n")
+						md.WriteString(formatFindingLines(resource.Findings, "  "))
+						if len(resource.Identifiers) > 0 {
+							md.WriteString("\n" + formatIdentifierTable(resource.Identifiers, "  ") + "\n")
 						}
 					}
-					md.WriteString(strings.Join(changeDescs, ", "))
+					md.WriteString("\n")
+					writeDetailOverflow(md, overflow)
 				}
-				md.WriteString("\n")
-			}
-			md.WriteString("\n")
+			},
 		}
+		for _, action := range sectionOrder(opts.Sort, opts.SortReverse) {
+			sections[action]()
+		}
+	}
 
-		if len(summary.Replace) > 0 {
-			md.WriteString("**🔄 Resources to be Replaced:**\n")
-			for _, resource := range summary.Replace {
-				md.WriteString(fmt.Sprintf("- `%s`", resource.Address))
-				if resource.ForceReason != "" {
-					md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
-				}
-				md.WriteString("\n")
-			}
-			md.WriteString("\n")
+	if len(summary.Move) > 0 {
+		md.WriteString("**🚚 Resources to be Moved:**\n")
+		for _, resource := range summary.Move {
+			md.WriteString(fmt.Sprintf("- `%s` → `%s`\n", resource.PreviousAddress, resource.Address))
 		}
+		md.WriteString("\n")
+	}
 
-		if len(summary.Delete) > 0 {
-			md.WriteString("**🔴 Resources to be Deleted:**\n")
-			for _, resource := range summary.Delete {
-				md.WriteString(fmt.Sprintf("- `%s`", resource.Address))
-				if resource.ForceReason != "" {
-					md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
-				}
-				md.WriteString("\n")
-			}
-			md.WriteString("\n")
+	if len(summary.Import) > 0 {
+		md.WriteString("**📥 Resources to be Imported:**\n")
+		for _, resource := range summary.Import {
+			md.WriteString(fmt.Sprintf("- `%s` (ID: `%s`)\n", resource.Address, resource.ImportID))
 		}
+		md.WriteString("\n")
+	}
 
-		md.WriteString("---\n\n")
+	if len(summary.Deposed) > 0 {
+		md.WriteString("**🗑️ Deposed Objects to be Destroyed:**\n")
+		for _, resource := range summary.Deposed {
+			md.WriteString(fmt.Sprintf("- `%s` (deposed object `%s`)\n", resource.Address, resource.DeposedKey))
+		}
+		md.WriteString("\n")
 	}
 
-	// Footer
-	if len(allTerraformVersions) == 1 {
-		md.WriteString(fmt.Sprintf("*Generated from Terraform %s plans*\n", allTerraformVersions[0]))
-	} else {
-		md.WriteString(fmt.Sprintf("*Generated from Terraform plans (versions: %s)*\n", strings.Join(allTerraformVersions, ", ")))
+	if opts.ShowReads {
+		writeEnvironmentReadsSection(md, summary.Read)
+	}
+	if opts.ShowNoOp {
+		writeNoOpSection(md, summary.NoOp)
+	}
+	writeEnvironmentDriftSection(md, planInfo.Plan.ResourceDrift)
+	writeEnvironmentOutputsSection(md, planInfo.Plan.OutputChanges)
+	writeEnvironmentChecksSection(md, planInfo.Plan.Checks)
+	if opts.ShowVariables {
+		writeVariablesSection(md, planInfo.Plan, opts.RedactPattern)
+	}
+	if collapsed {
+		md.WriteString("</details>\n\n")
 	}
 
-	return md.String()
+	md.WriteString("---\n\n")
 }
 
-func generateMarkdownComment(plan *TerraformPlan) string {
-	summary := analyzeResourceChanges(plan.ResourceChanges)
+func generateMarkdownComment(plan *TerraformPlan, opts ReportOptions) string {
+	maskLegacyAttributes(plan, opts.RedactPattern)
+	if opts.Style == styleChangelog {
+		return generateChangelogMarkdown([]PlanInfo{{Plan: plan}}, opts)
+	}
+
+	summary := analyzePlan(plan)
+	applyIgnoreRules(&summary, plan, opts.IgnoreRules)
+	applyBaselineAttributes(&summary, "", opts.Baseline)
+	sortSummary(&summary, plan, opts.Sort, opts.SortReverse)
+	if opts.LinkSources {
+		annotateSourceLinks(&summary, plan, opts)
+	}
+	if opts.LinkDocs {
+		annotateDocsLinks(&summary, plan)
+	}
+	unattached := attachFindings(&summary, plan, opts.Findings)
+	annotateCostSignals(&summary, plan)
 
 	var md strings.Builder
 
 	// Header
 	md.WriteString("## 📋 Terraform Plan Summary\n\n")
+	writePlanStatus(&md, plan)
+	writeWarnings(&md, plan.Warnings)
+	writeFilteredNote(&md, plan)
 
 	// Overall statistics
-	totalChanges := len(summary.Create) + len(summary.Update) + len(summary.Delete) + len(summary.Replace)
+	totalChanges := summary.affectedResources()
 
 	if totalChanges == 0 {
 		md.WriteString("✅ **No changes detected** - Infrastructure is up to date!\n\n")
+		if opts.ShowReads {
+			writeReadsSection(&md, summary.Read)
+		}
+		if opts.ShowNoOp {
+			writeNoOpSection(&md, summary.NoOp)
+		}
+		writeOutputsSection(&md, plan.OutputChanges)
+		writeChecksSection(&md, plan.Checks)
+		writeDriftSection(&md, plan.ResourceDrift)
+		if opts.ShowVariables {
+			writeVariablesSection(&md, plan, opts.RedactPattern)
+		}
 		return md.String()
 	}
 
+	md.WriteString(formatRisk(assessRisk(plan, summary, plan.PlanPath, opts.Risk)) + "\n\n")
+	writeThresholdBanner(&md, checkThresholds(plan, "", opts.Thresholds))
 	md.WriteString(fmt.Sprintf("**Total resources affected:** %d\n\n", totalChanges))
+	if estimate, ok := opts.Costs.estimateFor(plan); ok {
+		md.WriteString(formatCostImpact(estimate) + "\n\n")
+	}
 
 	// Summary table
 	md.WriteString("| Action | Count | Resources |\n")
@@ -487,87 +1841,443 @@ func generateMarkdownComment(plan *TerraformPlan) string {
 			formatResourceList(summary.Delete, 3)))
 	}
 
+	if len(summary.Move) > 0 {
+		md.WriteString(fmt.Sprintf("| 🚚 **Move** | %d | %s |\n",
+			len(summary.Move),
+			formatResourceList(summary.Move, 3)))
+	}
+
+	if len(summary.Import) > 0 {
+		md.WriteString(fmt.Sprintf("| 📥 **Import** | %d | %s |\n",
+			len(summary.Import),
+			formatResourceList(summary.Import, 3)))
+	}
+
+	if len(summary.Deposed) > 0 {
+		md.WriteString(fmt.Sprintf("| 🗑️ **Destroy deposed** | %d | %s |\n",
+			len(summary.Deposed),
+			formatResourceList(summary.Deposed, 3)))
+	}
+
 	md.WriteString("\n")
+	if opts.SummaryOnly {
+		writePlanFooter(&md, plan)
+		return md.String()
+	}
 
-	// Detailed sections for each action type
-	if len(summary.Create) > 0 {
-		md.WriteString("### 🟢 Resources to be Created\n\n")
-		for _, resource := range summary.Create {
-			md.WriteString(fmt.Sprintf("- `%s`\n", resource.Address))
-		}
-		md.WriteString("\n")
+	writeSecuritySection(&md, plan, summary, opts)
+	writeUnattachedFindingsSection(&md, unattached)
+	writeIAMSection(&md, plan)
+	writeCostSignalsSection(&md, plan)
+	writeNamingSection(&md, plan, opts.Naming)
+	writeTagPolicySection(&md, plan, opts.TagPolicy)
+	writeResourceTypeSection(&md, plan, summary, opts.LinkDocs)
+	if opts.ShowRegions {
+		writeRegionSection(&md, plan, summary)
 	}
 
-	if len(summary.Update) > 0 {
-		md.WriteString("### 🟡 Resources to be Updated\n\n")
-		for _, resource := range summary.Update {
-			md.WriteString(fmt.Sprintf("#### `%s`\n\n", resource.Address))
-			if len(resource.Changes) > 0 {
-				md.WriteString("**Attributes being modified:**\n\n")
-				for _, change := range resource.Changes {
-					if change.IsNew {
-						md.WriteString(fmt.Sprintf("- **%s**: %s *(new)*\n",
-							change.Attribute, formatAttributeValue(change.After)))
-					} else if change.IsRemoved {
-						md.WriteString(fmt.Sprintf("- **%s**: %s *(removed)*\n",
-							change.Attribute, formatAttributeValue(change.Before)))
-					} else {
-						md.WriteString(fmt.Sprintf("- **%s**: %s → %s\n",
-							change.Attribute,
-							formatAttributeValue(change.Before),
-							formatAttributeValue(change.After)))
+	if opts.ShowPriorState {
+		writePriorStateSection(&md, plan)
+	}
+	if opts.ShowProviders {
+		writeProvidersSection(&md, plan, opts)
+	}
+
+	if opts.GroupBy == groupByModule {
+		writeModuleGroupsSection(&md, plan, summary)
+	} else {
+		// Detailed sections for each action type
+		sections := map[string]func(){
+			"create": func() {
+				if len(summary.Create) > 0 {
+					md.WriteString("### 🟢 Resources to be Created\n\n")
+					planned := plannedValuesByAddress(plan)
+					types := resourceTypesByAddress(plan)
+					shown, overflow := limitDetails(collapseInstances(summary.Create), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("- %s\n", formatResourceLabel(resource)))
+						md.WriteString(formatInstanceList(resource, "  "))
+						md.WriteString(formatFindingLines(resource.Findings, "  "))
+						for _, attr := range plannedAttributeLines(types[resource.Address], planned[resource.Address], opts) {
+							md.WriteString(fmt.Sprintf("  - %s\n", attr))
+						}
 					}
+					md.WriteString("\n")
+					writeDetailOverflow(&md, overflow)
 				}
-			} else {
-				md.WriteString("*No specific attribute changes detected*\n")
-			}
-			md.WriteString("\n")
+			},
+			"update": func() {
+				tagOnly, updates := splitTagOnlyResources(summary.Update)
+				if len(updates) > 0 {
+					md.WriteString("### 🟡 Resources to be Updated\n\n")
+					shown, overflow := limitDetails(collapseInstances(updates), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("#### %s\n\n", formatResourceLabel(resource)))
+						if list := formatInstanceList(resource, ""); list != "" {
+							md.WriteString(list + "\n")
+						}
+						if findings := formatFindingLines(resource.Findings, ""); findings != "" {
+							md.WriteString(findings + "\n")
+						}
+						if len(resource.ChangeDrivers) > 0 {
+							md.WriteString(fmt.Sprintf("**Changed because:** %s changed\n\n", formatChangeDrivers(resource.ChangeDrivers)))
+						}
+						tags, changes := splitTagChanges(resource.Changes)
+						if len(changes) > 0 {
+							md.WriteString("**Attributes being modified:**\n\n")
+							for _, change := range changes {
+								md.WriteString(formatAttributeChange(change))
+							}
+						}
+						if len(tags) > 0 {
+							if len(changes) > 0 {
+								md.WriteString("\n")
+							}
+							md.WriteString("**Tags:**\n\n")
+							md.WriteString(formatTagTable(tags))
+						}
+						if len(resource.Changes) == 0 {
+							md.WriteString("*No specific attribute changes detected*\n")
+						}
+						md.WriteString("\n")
+					}
+					writeDetailOverflow(&md, overflow)
+				}
+				writeTagOnlySection(&md, tagOnly)
+			},
+			"replace": func() {
+				if len(summary.Replace) > 0 {
+					md.WriteString("### 🔄 Resources to be Replaced\n\n")
+					shown, overflow := limitDetails(collapseInstances(summary.Replace), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("#### %s\n\n", formatResourceLabel(resource)))
+						if list := formatInstanceList(resource, ""); list != "" {
+							md.WriteString(list + "\n")
+						}
+						if findings := formatFindingLines(resource.Findings, ""); findings != "" {
+							md.WriteString(findings + "\n")
+						}
+						if resource.ForceReason != "" {
+							md.WriteString(fmt.Sprintf("**Reason for replacement:** %s\n\n", resource.ForceReason))
+						}
+						if len(resource.ChangeDrivers) > 0 {
+							md.WriteString(fmt.Sprintf("**Changed because:** %s changed\n\n", formatChangeDrivers(resource.ChangeDrivers)))
+						}
+						if len(resource.Changes) > 0 {
+							md.WriteString("**Attribute changes:**\n\n")
+							for _, change := range resource.Changes {
+								md.WriteString(formatAttributeChange(change))
+							}
+						}
+						md.WriteString("\n")
+					}
+					writeDetailOverflow(&md, overflow)
+				}
+			},
+			"delete": func() {
+				if len(summary.Delete) > 0 {
+					md.WriteString("### 🔴 Resources to be Deleted\n\n")
+					shown, overflow := limitDetails(collapseInstances(summary.Delete), opts.MaxDetail)
+					for _, resource := range shown {
+						md.WriteString(fmt.Sprintf("#### %s\n\n", formatResourceLabel(resource)))
+						if list := formatInstanceList(resource, ""); list != "" {
+							md.WriteString(list + "\n")
+						}
+						if findings := formatFindingLines(resource.Findings, ""); findings != "" {
+							md.WriteString(findings + "\n")
+						}
+						if resource.ForceReason != "" {
+							md.WriteString(fmt.Sprintf("**Reason for deletion:** %s\n\n", resource.ForceReason))
+						}
+						if len(resource.Identifiers) > 0 {
+							md.WriteString(formatIdentifierTable(resource.Identifiers, "") + "\n")
+						}
+					}
+					writeDetailOverflow(&md, overflow)
+				}
+			},
+		}
+		for _, action := range sectionOrder(opts.Sort, opts.SortReverse) {
+			sections[action]()
 		}
 	}
 
-	if len(summary.Replace) > 0 {
-		md.WriteString("### 🔄 Resources to be Replaced\n\n")
-		for _, resource := range summary.Replace {
-			md.WriteString(fmt.Sprintf("#### `%s`\n\n", resource.Address))
-			if resource.ForceReason != "" {
-				md.WriteString(fmt.Sprintf("**Reason for replacement:** %s\n\n", resource.ForceReason))
+	if len(summary.Move) > 0 {
+		md.WriteString("### 🚚 Resources to be Moved\n\n")
+		for _, resource := range summary.Move {
+			md.WriteString(fmt.Sprintf("- `%s` → `%s`\n", resource.PreviousAddress, resource.Address))
+		}
+		md.WriteString("\n")
+	}
+
+	if len(summary.Import) > 0 {
+		md.WriteString("### 📥 Resources to be Imported\n\n")
+		for _, resource := range summary.Import {
+			md.WriteString(fmt.Sprintf("- `%s` (ID: `%s`)\n", resource.Address, resource.ImportID))
+		}
+		md.WriteString("\n")
+	}
+
+	if len(summary.Deposed) > 0 {
+		md.WriteString("### 🗑️ Deposed Objects to be Destroyed\n\n")
+		md.WriteString("*Left behind by a failed create_before_destroy replacement:*\n\n")
+		for _, resource := range summary.Deposed {
+			md.WriteString(fmt.Sprintf("- `%s` (deposed object `%s`)\n", resource.Address, resource.DeposedKey))
+		}
+		md.WriteString("\n")
+	}
+
+	if opts.ShowReads {
+		writeReadsSection(&md, summary.Read)
+	}
+	if opts.ShowNoOp {
+		writeNoOpSection(&md, summary.NoOp)
+	}
+	writeOutputsSection(&md, plan.OutputChanges)
+	writeChecksSection(&md, plan.Checks)
+	writeDriftSection(&md, plan.ResourceDrift)
+	if opts.ShowVariables {
+		writeVariablesSection(&md, plan, opts.RedactPattern)
+	}
+
+	writePlanFooter(&md, plan)
+	return md.String()
+}
+
+// writePlanFooter renders the footer of a single-plan report
+func writePlanFooter(md *strings.Builder, plan *TerraformPlan) {
+	md.WriteString("---\n")
+	if plan.Source == sourceCloudFormation {
+		md.WriteString(fmt.Sprintf("*Generated from AWS CloudFormation change set %s*\n", plan.ChangeSetName))
+	} else {
+		md.WriteString(fmt.Sprintf("*Generated from Terraform %s plan*\n", plan.TerraformVersion))
+	}
+}
+
+// writeReadsSection renders data sources whose read is deferred until apply
+func writeReadsSection(md *strings.Builder, reads []ResourceDetail) {
+	if len(reads) == 0 {
+		return
+	}
+
+	md.WriteString("### 📖 Data Sources to be Read\n\n")
+	for _, resource := range reads {
+		md.WriteString(fmt.Sprintf("- `%s`", resource.Address))
+		if resource.ForceReason != "" {
+			md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
+		}
+		md.WriteString("\n")
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentReadsSection renders deferred data source reads inside a multi-plan environment section
+func writeEnvironmentReadsSection(md *strings.Builder, reads []ResourceDetail) {
+	if len(reads) == 0 {
+		return
+	}
+
+	md.WriteString("**📖 Data Sources to be Read:**\n")
+	for _, resource := range reads {
+		md.WriteString(fmt.Sprintf("- `%s`", resource.Address))
+		if resource.ForceReason != "" {
+			md.WriteString(fmt.Sprintf(" - %s", resource.ForceReason))
+		}
+		md.WriteString("\n")
+	}
+	md.WriteString("\n")
+}
+
+// writeNoOpSection renders unchanged resources inside a collapsed block so the
+// full inventory is available without drowning out the actual changes
+func writeNoOpSection(md *strings.Builder, resources []ResourceDetail) {
+	if len(resources) == 0 {
+		return
+	}
+
+	md.WriteString("<details>\n")
+	md.WriteString(fmt.Sprintf("<summary>⚪ Unchanged resources (%d)</summary>\n\n", len(resources)))
+	for _, resource := range resources {
+		md.WriteString(fmt.Sprintf("- `%s`\n", resource.Address))
+	}
+	md.WriteString("\n</details>\n\n")
+}
+
+// writeOutputsSection renders created, updated and removed root module outputs
+func writeOutputsSection(md *strings.Builder, outputs map[string]Change) {
+	entries := formatOutputChanges(outputs)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("### 📤 Outputs\n\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentOutputsSection renders output changes inside a multi-plan environment section
+func writeEnvironmentOutputsSection(md *strings.Builder, outputs map[string]Change) {
+	entries := formatOutputChanges(outputs)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("**📤 Output Changes:**\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
+
+// formatOutputChanges describes each changed output as a single markdown line,
+// masking values that are marked sensitive
+func formatOutputChanges(outputs map[string]Change) []string {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []string
+	for _, name := range names {
+		change := outputs[name]
+		actions := change.Actions
+
+		before := formatOutputValue(change.Before, change.BeforeSensitive, false)
+		after := formatOutputValue(change.After, change.AfterSensitive, change.AfterUnknown == true)
+
+		if containsAction(actions, "create") && !containsAction(actions, "delete") {
+			entries = append(entries, fmt.Sprintf("`%s`: %s *(new)*", name, after))
+		} else if containsAction(actions, "delete") && !containsAction(actions, "create") {
+			entries = append(entries, fmt.Sprintf("`%s`: %s *(removed)*", name, before))
+		} else if containsAction(actions, "update") || containsAction(actions, "create") {
+			entries = append(entries, fmt.Sprintf("`%s`: %s → %s", name, before, after))
+		}
+	}
+	return entries
+}
+
+func formatOutputValue(val interface{}, sensitive interface{}, unknown bool) string {
+	if unknown {
+		return "(known after apply)"
+	}
+	if sensitive == true {
+		return "(sensitive)"
+	}
+	return formatAttributeValue(val)
+}
+
+// writeDriftSection renders changes Terraform detected outside of its own applies
+func writeDriftSection(md *strings.Builder, drift []ResourceChange) {
+	entries := formatDriftEntries(drift)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("### 🌀 Detected Drift\n\n")
+	md.WriteString("*The following resources were changed outside of Terraform since the last apply:*\n\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentDriftSection renders detected drift inside a multi-plan environment section
+func writeEnvironmentDriftSection(md *strings.Builder, drift []ResourceChange) {
+	entries := formatDriftEntries(drift)
+	if len(entries) == 0 {
+		return
+	}
+
+	md.WriteString("**🌀 Detected Drift:**\n")
+	for _, entry := range entries {
+		md.WriteString(fmt.Sprintf("- %s\n", entry))
+	}
+	md.WriteString("\n")
+}
+
+// formatDriftEntries describes each drifted resource as a single markdown line
+func formatDriftEntries(drift []ResourceChange) []string {
+	sorted := make([]ResourceChange, len(drift))
+	copy(sorted, drift)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Address < sorted[j].Address
+	})
+
+	var entries []string
+	for _, change := range sorted {
+		actions := change.Change.Actions
+		if containsAction(actions, "no-op") || len(actions) == 0 {
+			continue
+		}
+
+		entry := fmt.Sprintf("`%s`", change.Address)
+		if containsAction(actions, "delete") && !containsAction(actions, "create") {
+			entry += " *(deleted outside of Terraform)*"
+		} else {
+			entry += " *(modified outside of Terraform)*"
+			var attrs []string
+			for _, attrChange := range analyzeAttributeChanges(change.Change) {
+				attrs = append(attrs, fmt.Sprintf("%s: %s → %s",
+					attrChange.Attribute,
+					formatAttributeValue(attrChange.Before),
+					formatAttributeValue(attrChange.After)))
 			}
-			if len(resource.Changes) > 0 {
-				md.WriteString("**Attribute changes:**\n\n")
-				for _, change := range resource.Changes {
-					if change.IsNew {
-						md.WriteString(fmt.Sprintf("- **%s**: %s *(new)*\n",
-							change.Attribute, formatAttributeValue(change.After)))
-					} else if change.IsRemoved {
-						md.WriteString(fmt.Sprintf("- **%s**: %s *(removed)*\n",
-							change.Attribute, formatAttributeValue(change.Before)))
-					} else {
-						md.WriteString(fmt.Sprintf("- **%s**: %s → %s\n",
-							change.Attribute,
-							formatAttributeValue(change.Before),
-							formatAttributeValue(change.After)))
-					}
-				}
+			sort.Strings(attrs)
+			if len(attrs) > 0 {
+				entry += " - " + strings.Join(attrs, ", ")
 			}
-			md.WriteString("\n")
 		}
+		entries = append(entries, entry)
 	}
+	return entries
+}
 
-	if len(summary.Delete) > 0 {
-		md.WriteString("### 🔴 Resources to be Deleted\n\n")
-		for _, resource := range summary.Delete {
-			md.WriteString(fmt.Sprintf("#### `%s`\n\n", resource.Address))
-			if resource.ForceReason != "" {
-				md.WriteString(fmt.Sprintf("**Resource details:** %s\n\n", resource.ForceReason))
-			}
+// analyzePlan summarizes a plan's resource changes, annotating each change
+// with the upstream attributes that drove it
+func analyzePlan(plan *TerraformPlan) ResourceSummary {
+	summary := analyzeResourceChanges(plan.ResourceChanges)
+
+	drivers := findChangeDrivers(plan)
+	if len(drivers) == 0 {
+		return summary
+	}
+
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range details {
+			details[i].ChangeDrivers = drivers[details[i].Address]
 		}
 	}
 
-	// Footer
-	md.WriteString("---\n")
-	md.WriteString(fmt.Sprintf("*Generated from Terraform %s plan*\n", plan.TerraformVersion))
+	return summary
+}
 
-	return md.String()
+// formatResourceLabel renders a resource address along with its source link, if any
+func formatResourceLabel(resource ResourceDetail) string {
+	label := fmt.Sprintf("`%s`", resource.Address)
+	if len(resource.Instances) > 0 {
+		label = fmt.Sprintf("`%s` *(%d instances)*", formatInstanceRange(resource.Instances), len(resource.Instances))
+	}
+	if resource.CostAffecting {
+		label += " 💰"
+	}
+	if resource.DocsLink != "" {
+		label += fmt.Sprintf(" [📖](%s)", resource.DocsLink)
+	}
+	if resource.SourceLink == "" {
+		return label
+	}
+	return fmt.Sprintf("%s (%s)", label, resource.SourceLink)
+}
+
+func formatChangeDrivers(drivers []string) string {
+	quoted := make([]string, len(drivers))
+	for i, driver := range drivers {
+		quoted[i] = fmt.Sprintf("`%s`", driver)
+	}
+	return strings.Join(quoted, ", ")
 }
 
 func analyzeResourceChanges(changes []ResourceChange) ResourceSummary {
@@ -576,6 +2286,11 @@ func analyzeResourceChanges(changes []ResourceChange) ResourceSummary {
 		Update:  make([]ResourceDetail, 0),
 		Delete:  make([]ResourceDetail, 0),
 		Replace: make([]ResourceDetail, 0),
+		Move:    make([]ResourceDetail, 0),
+		Import:  make([]ResourceDetail, 0),
+		Deposed: make([]ResourceDetail, 0),
+		Read:    make([]ResourceDetail, 0),
+		NoOp:    make([]ResourceDetail, 0),
 	}
 
 	for _, change := range changes {
@@ -587,18 +2302,45 @@ func analyzeResourceChanges(changes []ResourceChange) ResourceSummary {
 			Changes: analyzeAttributeChanges(change.Change),
 		}
 
+		// Resources relocated by a moved block are reported separately from
+		// any other change they carry
+		if change.PreviousAddress != "" && change.PreviousAddress != change.Address {
+			summary.Move = append(summary.Move, ResourceDetail{
+				Address:         resourceName,
+				PreviousAddress: change.PreviousAddress,
+			})
+		}
+
+		// Resources adopted by an import block are reported separately as well
+		if change.Change.Importing != nil {
+			summary.Import = append(summary.Import, ResourceDetail{
+				Address:  resourceName,
+				ImportID: change.Change.Importing.ID,
+			})
+		}
+
 		// Determine the primary action
-		if containsAction(actions, "create") && containsAction(actions, "delete") {
+		if change.Deposed != "" && containsAction(actions, "delete") {
+			// Cleanup of a deposed object, not a delete of the current instance
+			detail.DeposedKey = change.Deposed
+			summary.Deposed = append(summary.Deposed, detail)
+		} else if containsAction(actions, "create") && containsAction(actions, "delete") {
 			// This is a replace operation
-			detail.ForceReason = determineReplaceReason(change.Change)
+			detail.ForceReason = determineReplaceReason(change)
 			summary.Replace = append(summary.Replace, detail)
 		} else if containsAction(actions, "create") {
 			summary.Create = append(summary.Create, detail)
 		} else if containsAction(actions, "update") {
 			summary.Update = append(summary.Update, detail)
 		} else if containsAction(actions, "delete") {
-			detail.ForceReason = determineDeleteReason(change.Change)
+			detail.ForceReason = determineDeleteReason(change)
+			detail.Identifiers = deleteIdentifiers(change)
 			summary.Delete = append(summary.Delete, detail)
+		} else if containsAction(actions, "read") {
+			detail.ForceReason = describeActionReason(change.ActionReason)
+			summary.Read = append(summary.Read, detail)
+		} else if containsAction(actions, "no-op") {
+			summary.NoOp = append(summary.NoOp, detail)
 		}
 	}
 
@@ -607,6 +2349,11 @@ func analyzeResourceChanges(changes []ResourceChange) ResourceSummary {
 	sortResourceDetails(summary.Update)
 	sortResourceDetails(summary.Delete)
 	sortResourceDetails(summary.Replace)
+	sortResourceDetails(summary.Move)
+	sortResourceDetails(summary.Import)
+	sortResourceDetails(summary.Deposed)
+	sortResourceDetails(summary.Read)
+	sortResourceDetails(summary.NoOp)
 
 	return summary
 }
@@ -625,9 +2372,26 @@ func formatResourceList(resources []ResourceDetail, maxDisplay int) string {
 		return ""
 	}
 
-	resourceNames := make([]string, len(resources))
-	for i, r := range resources {
-		resourceNames[i] = r.Address
+	// Instances of the same count/for_each resource are listed once
+	var resourceNames []string
+	instances := make(map[string][]string)
+	for _, r := range resources {
+		base, _, ok := splitInstanceAddress(r.Address)
+		if !ok {
+			resourceNames = append(resourceNames, r.Address)
+			continue
+		}
+		if _, seen := instances[base]; !seen {
+			resourceNames = append(resourceNames, base)
+		}
+		instances[base] = append(instances[base], r.Address)
+	}
+	for i, name := range resourceNames {
+		if group := instances[name]; len(group) > 1 {
+			resourceNames[i] = fmt.Sprintf("%s (%d instances)", formatInstanceRange(group), len(group))
+		} else if len(group) == 1 {
+			resourceNames[i] = group[0]
+		}
 	}
 
 	if len(resourceNames) <= maxDisplay {
@@ -650,54 +2414,56 @@ func analyzeAttributeChanges(change Change) []AttributeChange {
 		return changes
 	}
 
-	// Find all unique keys
-	allKeys := make(map[string]bool)
-	for key := range beforeMap {
-		allKeys[key] = true
-	}
-	for key := range afterMap {
-		allKeys[key] = true
-	}
-
-	// Analyze each attribute
-	for key := range allKeys {
-		beforeVal, beforeExists := beforeMap[key]
-		afterVal, afterExists := afterMap[key]
-
+	// Analyze each top-level attribute, descending into nested values
+	for _, key := range unionKeys(beforeMap, afterMap, change.AfterUnknown) {
 		// Skip certain system attributes that are not meaningful to users
 		if shouldSkipAttribute(key) {
 			continue
 		}
 
-		if !beforeExists && afterExists {
-			// New attribute
-			changes = append(changes, AttributeChange{
-				Attribute: key,
-				Before:    nil,
-				After:     afterVal,
-				IsNew:     true,
-			})
-		} else if beforeExists && !afterExists {
-			// Removed attribute
-			changes = append(changes, AttributeChange{
-				Attribute: key,
-				Before:    beforeVal,
-				After:     nil,
-				IsRemoved: true,
-			})
-		} else if beforeExists && afterExists && !deepEqual(beforeVal, afterVal) {
-			// Changed attribute
-			changes = append(changes, AttributeChange{
-				Attribute: key,
-				Before:    beforeVal,
-				After:     afterVal,
-			})
-		}
+		beforeVal, beforeExists := beforeMap[key]
+		afterVal, afterExists := afterMap[key]
+
+		diffValues(&changes, formatPathKey("", key),
+			beforeVal, beforeExists, afterVal, afterExists,
+			childMarker(change.BeforeSensitive, key),
+			childMarker(change.AfterSensitive, key),
+			childMarker(change.AfterUnknown, key))
 	}
 
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Attribute < changes[j].Attribute
+	})
+
 	return changes
 }
 
+// formatAttributeChange renders a single attribute change as a markdown list item
+func formatAttributeChange(change AttributeChange) string {
+	if change.Note != "" {
+		return fmt.Sprintf("- **%s**: %s\n", change.Attribute, change.Note)
+	} else if change.IsNew {
+		return fmt.Sprintf("- **%s**: %s *(new)*\n",
+			change.Attribute, formatAttributeValue(change.After))
+	} else if change.IsRemoved {
+		return fmt.Sprintf("- **%s**: %s *(removed)*\n",
+			change.Attribute, formatAttributeValue(change.Before))
+	}
+
+	label := fmt.Sprintf("**%s**", change.Attribute)
+	if change.Decoded {
+		label += " *(decoded from base64)*"
+	}
+	if isLongStringChange(change.Before, change.After) {
+		return fmt.Sprintf("- %s:\n\n%s\n",
+			label, formatDiffBlock(unifiedDiff(change.Before.(string), change.After.(string))))
+	}
+	return fmt.Sprintf("- %s: %s → %s\n",
+		label,
+		formatAttributeValue(change.Before),
+		formatAttributeValue(change.After))
+}
+
 func shouldSkipAttribute(key string) bool {
 	skipAttributes := []string{
 		"id", "arn", "tags_all", "timeouts",
@@ -711,25 +2477,131 @@ func shouldSkipAttribute(key string) bool {
 	return false
 }
 
+// deepEqual compares decoded JSON values semantically: numbers are compared by
+// value regardless of Go type, and null is equal to an empty list or map since
+// providers commonly flip between the two without a real change
 func deepEqual(a, b interface{}) bool {
-	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+	if isEmptyValue(a) && isEmptyValue(b) {
+		return true
+	}
+
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, exists := bv[key]
+			if !exists || !deepEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !deepEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if an, ok := toFloat(a); ok {
+		bn, ok := toFloat(b)
+		return ok && an == bn
+	}
+	return a == b
+}
+
+// isEmptyValue reports whether a value is null or an empty collection
+func isEmptyValue(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(val) == 0
+	case []interface{}:
+		return len(val) == 0
+	}
+	return false
 }
 
-func determineReplaceReason(change Change) string {
-	changes := analyzeAttributeChanges(change)
+// toFloat converts the numeric types that can appear in decoded values
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
 
-	// Look for attributes that commonly force replacement
-	forceReplaceAttrs := []string{"name", "family", "engine", "vpc_id", "availability_zone"}
+// actionReasonDescriptions explains the action_reason values Terraform records in plans
+var actionReasonDescriptions = map[string]string{
+	"replace_because_tainted":           "Resource is tainted and must be replaced",
+	"replace_because_cannot_update":     "Provider cannot update the changed attributes in-place",
+	"replace_by_request":                "Replacement was requested explicitly (-replace)",
+	"replace_by_triggers":               "Replacement triggered by replace_triggered_by",
+	"delete_because_no_resource_config": "Resource is no longer present in the configuration",
+	"delete_because_no_module":          "Containing module is no longer present in the configuration",
+	"delete_because_wrong_repetition":   "Instance key no longer matches the resource's count/for_each mode",
+	"delete_because_count_index":        "Instance index is beyond the configured count",
+	"delete_because_each_key":           "Instance key is no longer in the for_each collection",
+	"delete_because_no_move_target":     "Moved block target does not exist in the configuration",
+	"read_because_config_unknown":       "Configuration depends on values not known until apply",
+	"read_because_dependency_pending":   "Depends on a resource with pending changes",
+}
 
-	for _, attrChange := range changes {
-		for _, forceAttr := range forceReplaceAttrs {
-			if attrChange.Attribute == forceAttr {
-				return fmt.Sprintf("Attribute '%s' changed from '%v' to '%v' (forces replacement)",
-					attrChange.Attribute, attrChange.Before, attrChange.After)
-			}
+func describeActionReason(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	if description, ok := actionReasonDescriptions[reason]; ok {
+		return description
+	}
+	return reason
+}
+
+func determineReplaceReason(resourceChange ResourceChange) string {
+	reason := describeActionReason(resourceChange.ActionReason)
+
+	// replace_paths names exactly which attributes force the replacement
+	var paths []string
+	for _, path := range resourceChange.Change.ReplacePaths {
+		if formatted := formatAttributePath(path); formatted != "" {
+			paths = append(paths, fmt.Sprintf("`%s`", formatted))
+		}
+	}
+
+	if len(paths) > 0 {
+		verb := "forces"
+		if len(paths) > 1 {
+			verb = "force"
+		}
+		if reason != "" {
+			return fmt.Sprintf("%s: changing %s %s replacement", reason, strings.Join(paths, ", "), verb)
 		}
+		return fmt.Sprintf("Changing %s %s replacement", strings.Join(paths, ", "), verb)
+	}
+
+	if reason != "" {
+		return reason
 	}
 
+	changes := analyzeAttributeChanges(resourceChange.Change)
 	if len(changes) > 0 {
 		return fmt.Sprintf("Multiple attribute changes require replacement")
 	}
@@ -737,27 +2609,36 @@ func determineReplaceReason(change Change) string {
 	return "Resource configuration requires replacement"
 }
 
-func determineDeleteReason(change Change) string {
-	// For delete operations, we mainly care about what's being removed
-	beforeMap, ok := change.Before.(map[string]interface{})
-	if !ok {
-		return "Resource marked for deletion"
-	}
-
-	// Identify key attributes that might explain why it's being deleted
-	keyAttrs := []string{"name", "id", "family", "engine"}
-	var identifiers []string
-
-	for _, attr := range keyAttrs {
-		if val, exists := beforeMap[attr]; exists && val != nil {
-			identifiers = append(identifiers, fmt.Sprintf("%s: %v", attr, val))
+// formatAttributePath renders a plan attribute path such as
+// ["network_interface", 0, "subnet_id"] as network_interface[0].subnet_id
+func formatAttributePath(path []interface{}) string {
+	var b strings.Builder
+	for _, step := range path {
+		switch v := step.(type) {
+		case string:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(v)
+		case float64:
+			b.WriteString(fmt.Sprintf("[%d]", int(v)))
+		case int:
+			b.WriteString(fmt.Sprintf("[%d]", v))
 		}
 	}
+	return b.String()
+}
 
-	if len(identifiers) > 0 {
-		return fmt.Sprintf("Resource with %s", strings.Join(identifiers, ", "))
+// determineDeleteReason explains a delete. What is being destroyed is shown
+// by the identifier table instead, so the generic fallback is only used for
+// resources without identifying attributes.
+func determineDeleteReason(resourceChange ResourceChange) string {
+	if reason := describeActionReason(resourceChange.ActionReason); reason != "" {
+		return reason
+	}
+	if len(deleteIdentifiers(resourceChange)) > 0 {
+		return ""
 	}
-
 	return "Resource marked for deletion"
 }
 
@@ -773,6 +2654,12 @@ func formatAttributeValue(val interface{}) string {
 	}
 
 	switch v := val.(type) {
+	case sensitiveValue:
+		return v.String()
+	case unknownValue:
+		return v.String()
+	case inlineValue:
+		return v.String()
 	case string:
 		if v == "" {
 			return "(empty)"
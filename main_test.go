@@ -2,6 +2,7 @@ package main
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -113,7 +114,7 @@ func TestFormatResourceList(t *testing.T) {
 func TestGenerateMultiPlanMarkdownComment(t *testing.T) {
 	// Test with empty plans
 	emptyPlans := []PlanInfo{}
-	result := generateMultiPlanMarkdownComment(emptyPlans)
+	result := generateMultiPlanMarkdownComment(emptyPlans, ReportOptions{})
 	if !strings.Contains(result, "No changes detected across all environments") {
 		t.Error("Expected message about no changes for empty plans")
 	}
@@ -135,7 +136,7 @@ func TestGenerateMultiPlanMarkdownComment(t *testing.T) {
 			RelativePath: "env1",
 		},
 	}
-	result = generateMultiPlanMarkdownComment(plans)
+	result = generateMultiPlanMarkdownComment(plans, ReportOptions{})
 	if !strings.Contains(result, "Multi-Environment Terraform Plan Summary") {
 		t.Error("Expected multi-environment header")
 	}
@@ -170,7 +171,7 @@ func TestGenerateMarkdownComment(t *testing.T) {
 		ResourceChanges:  []ResourceChange{},
 	}
 
-	markdown := generateMarkdownComment(plan)
+	markdown := generateMarkdownComment(plan, ReportOptions{})
 
 	if !strings.Contains(markdown, "📋 Terraform Plan Summary") {
 		t.Error("Expected markdown to contain plan summary header")
@@ -195,7 +196,7 @@ func TestGenerateMarkdownComment(t *testing.T) {
 		},
 	}
 
-	markdownWithChanges := generateMarkdownComment(planWithChanges)
+	markdownWithChanges := generateMarkdownComment(planWithChanges, ReportOptions{})
 	if !strings.Contains(markdownWithChanges, "1.9.8") {
 		t.Error("Expected markdown with changes to contain Terraform version")
 	}
@@ -221,3 +222,416 @@ func TestReadTerraformPlan(t *testing.T) {
 		t.Error("Expected error when reading invalid JSON")
 	}
 }
+
+func TestDirectoryModeKeepsPlansWithoutResourceChanges(t *testing.T) {
+	plans := map[string]string{
+		"drift":   `{"format_version": "1.2", "resource_drift": [{"address": "aws_instance.web", "change": {"actions": ["delete"]}}]}`,
+		"outputs": `{"format_version": "1.2", "output_changes": {"url": {"actions": ["create"], "after": "https://example.com"}}}`,
+		"checks":  `{"format_version": "1.2", "checks": [{"address": {"kind": "check", "to_display": "check.health"}, "status": "fail"}]}`,
+		"errored": `{"format_version": "1.2", "errored": true}`,
+		"partial": `{"format_version": "1.2", "complete": false}`,
+		"noop":    `{"format_version": "1.2", "applyable": false, "complete": true, "output_changes": {"url": {"actions": ["no-op"]}}}`,
+	}
+	expected := map[string]string{
+		"drift":   "`aws_instance.web` *(deleted outside of Terraform)*",
+		"outputs": "`url`: \"https://example.com\" *(new)*",
+		"checks":  "`check.health`",
+		"errored": "**Plan errored**",
+		"partial": "**Plan is incomplete**",
+	}
+
+	root := t.TempDir()
+	for env, plan := range plans {
+		if err := os.MkdirAll(filepath.Join(root, env), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, env, planFileName), []byte(plan), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := findAndReadPlanFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envs []string
+	for _, planInfo := range found {
+		envs = append(envs, planInfo.RelativePath)
+	}
+	if strings.Join(envs, ",") != "checks,drift,errored,outputs,partial" {
+		t.Errorf("Expected every plan but the no-op one to be kept, got %v", envs)
+	}
+
+	markdown := generateMultiPlanMarkdownComment(found, ReportOptions{})
+	if !strings.Contains(markdown, "No changes detected across all environments") {
+		t.Errorf("Expected no resource changes to be reported, got:\n%s", markdown)
+	}
+	for env, entry := range expected {
+		if !strings.Contains(markdown, "#### 📁 `"+env+"`") || !strings.Contains(markdown, entry) {
+			t.Errorf("Expected the %s environment with %q, got:\n%s", env, entry, markdown)
+		}
+	}
+}
+
+func TestDriftSection(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceDrift: []ResourceChange{
+			{
+				Address: "aws_instance.web",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"instance_type": "t3.micro"},
+					After:   map[string]interface{}{"instance_type": "t3.large"},
+				},
+			},
+			{
+				Address: "aws_s3_bucket.logs",
+				Change: Change{
+					Actions: []string{"delete"},
+				},
+			},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(markdown, "No changes detected") {
+		t.Error("Expected drift-only plan to report no planned changes")
+	}
+	if !strings.Contains(markdown, "Detected Drift") {
+		t.Error("Expected drift section in markdown")
+	}
+	if !strings.Contains(markdown, "`aws_instance.web` *(modified outside of Terraform)* - instance_type: \"t3.micro\" → \"t3.large\"") {
+		t.Error("Expected modified drift entry with attribute change")
+	}
+	if !strings.Contains(markdown, "`aws_s3_bucket.logs` *(deleted outside of Terraform)*") {
+		t.Error("Expected deleted drift entry")
+	}
+}
+
+func TestFormatOutputChanges(t *testing.T) {
+	outputs := map[string]Change{
+		"endpoint": {
+			Actions: []string{"update"},
+			Before:  "old.example.com",
+			After:   "new.example.com",
+		},
+		"password": {
+			Actions:         []string{"update"},
+			Before:          "hunter2",
+			After:           "hunter3",
+			BeforeSensitive: true,
+			AfterSensitive:  true,
+		},
+		"queue_url": {
+			Actions:      []string{"create"},
+			AfterUnknown: true,
+		},
+		"legacy": {
+			Actions: []string{"delete"},
+			Before:  "gone",
+		},
+		"port": {
+			Actions: []string{"no-op"},
+			Before:  6379,
+			After:   6379,
+		},
+	}
+
+	expected := []string{
+		"`endpoint`: \"old.example.com\" → \"new.example.com\"",
+		"`legacy`: \"gone\" *(removed)*",
+		"`password`: (sensitive) → (sensitive)",
+		"`queue_url`: (known after apply) *(new)*",
+	}
+
+	result := formatOutputChanges(outputs)
+	if len(result) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %v", len(expected), len(result), result)
+	}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("Entry %d: expected %s, got %s", i, expected[i], result[i])
+		}
+	}
+}
+
+func TestMovedResources(t *testing.T) {
+	changes := []ResourceChange{
+		{
+			Address:         "module.network.aws_vpc.main",
+			PreviousAddress: "aws_vpc.main",
+			Change: Change{
+				Actions: []string{"no-op"},
+			},
+		},
+		{
+			Address: "aws_s3_bucket.logs",
+			Change: Change{
+				Actions: []string{"no-op"},
+			},
+		},
+	}
+
+	summary := analyzeResourceChanges(changes)
+	if len(summary.Move) != 1 {
+		t.Fatalf("Expected 1 moved resource, got %d", len(summary.Move))
+	}
+	if len(summary.Create) != 0 || len(summary.Delete) != 0 {
+		t.Error("Expected moved resource not to be reported as create/delete")
+	}
+
+	markdown := generateMarkdownComment(&TerraformPlan{ResourceChanges: changes}, ReportOptions{})
+	if !strings.Contains(markdown, "- `aws_vpc.main` → `module.network.aws_vpc.main`") {
+		t.Error("Expected moved section with old → new address")
+	}
+}
+
+func TestMovedAndUpdatedResourceCountedOnce(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "module.network.aws_vpc.main", PreviousAddress: "aws_vpc.main",
+			Change: Change{Actions: []string{"update"}, Before: map[string]interface{}{"cidr_block": "10.0.0.0/16"}, After: map[string]interface{}{"cidr_block": "10.1.0.0/16"}}},
+		{Address: "module.network.aws_subnet.a", PreviousAddress: "aws_subnet.a", Change: Change{Actions: []string{"no-op"}}},
+	}
+
+	summary := analyzeResourceChanges(changes)
+	if len(summary.Move) != 2 || len(summary.Update) != 1 || summary.affectedResources() != 2 {
+		t.Fatalf("Expected 2 affected resources, got %d", summary.affectedResources())
+	}
+
+	plan := &TerraformPlan{ResourceChanges: changes}
+	if markdown := generateMarkdownComment(plan, ReportOptions{}); !strings.Contains(markdown, "**Total resources affected:** 2\n") {
+		t.Errorf("Expected the moved and updated resource to count once, got:\n%s", markdown)
+	}
+	plans := []PlanInfo{{Plan: plan, RelativePath: "prod"}, {Plan: plan, RelativePath: "dev"}}
+	if markdown := generateMultiPlanMarkdownComment(plans, ReportOptions{}); !strings.Contains(markdown, "**Total resources affected:** 4\n") {
+		t.Errorf("Expected multi-plan totals to count moved resources once, got:\n%s", markdown)
+	}
+}
+
+func TestImportedResources(t *testing.T) {
+	changes := []ResourceChange{
+		{
+			Address: "aws_s3_bucket.legacy",
+			Change: Change{
+				Actions:   []string{"no-op"},
+				Importing: &Importing{ID: "legacy-bucket"},
+			},
+		},
+	}
+
+	summary := analyzeResourceChanges(changes)
+	if len(summary.Import) != 1 || summary.Import[0].ImportID != "legacy-bucket" {
+		t.Fatalf("Expected 1 imported resource with ID, got %+v", summary.Import)
+	}
+
+	markdown := generateMarkdownComment(&TerraformPlan{ResourceChanges: changes}, ReportOptions{})
+	if strings.Contains(markdown, "No changes detected") {
+		t.Error("Expected import-only plan not to be reported as unchanged")
+	}
+	if !strings.Contains(markdown, "- `aws_s3_bucket.legacy` (ID: `legacy-bucket`)") {
+		t.Error("Expected imported section with source ID")
+	}
+}
+
+func TestImportedAndUpdatedResourceCountedOnce(t *testing.T) {
+	changes := []ResourceChange{
+		{Address: "aws_s3_bucket.legacy", Change: Change{Actions: []string{"update"}, Importing: &Importing{ID: "legacy-bucket"},
+			Before: map[string]interface{}{"acl": "private"}, After: map[string]interface{}{"acl": "log-delivery-write"}}},
+		{Address: "aws_s3_bucket.moved", PreviousAddress: "aws_s3_bucket.old", Change: Change{Actions: []string{"no-op"}, Importing: &Importing{ID: "moved"}}},
+	}
+
+	summary := analyzeResourceChanges(changes)
+	if len(summary.Import) != 2 || len(summary.Update) != 1 || summary.affectedResources() != 2 {
+		t.Fatalf("Expected 2 affected resources, got %d", summary.affectedResources())
+	}
+	if markdown := generateMarkdownComment(&TerraformPlan{ResourceChanges: changes}, ReportOptions{}); !strings.Contains(markdown, "**Total resources affected:** 2\n") {
+		t.Errorf("Expected imported resources to count once, got:\n%s", markdown)
+	}
+}
+
+func TestDeposedObjects(t *testing.T) {
+	changes := []ResourceChange{
+		{
+			Address: "aws_instance.web",
+			Deposed: "00000001",
+			Change: Change{
+				Actions: []string{"delete"},
+			},
+		},
+		{
+			Address: "aws_instance.old",
+			Change: Change{
+				Actions: []string{"delete"},
+			},
+		},
+	}
+
+	summary := analyzeResourceChanges(changes)
+	if len(summary.Deposed) != 1 || summary.Deposed[0].DeposedKey != "00000001" {
+		t.Fatalf("Expected 1 deposed object, got %+v", summary.Deposed)
+	}
+	if len(summary.Delete) != 1 || summary.Delete[0].Address != "aws_instance.old" {
+		t.Errorf("Expected only the regular delete in Delete, got %+v", summary.Delete)
+	}
+
+	markdown := generateMarkdownComment(&TerraformPlan{ResourceChanges: changes}, ReportOptions{})
+	if !strings.Contains(markdown, "- `aws_instance.web` (deposed object `00000001`)") {
+		t.Error("Expected deposed section in markdown")
+	}
+}
+
+func TestActionReason(t *testing.T) {
+	replace := ResourceChange{
+		Address:      "aws_db_instance.main",
+		ActionReason: "replace_because_tainted",
+		Change: Change{
+			Actions: []string{"delete", "create"},
+			Before:  map[string]interface{}{"name": "db"},
+			After:   map[string]interface{}{"name": "db"},
+		},
+	}
+	if reason := determineReplaceReason(replace); reason != "Resource is tainted and must be replaced" {
+		t.Errorf("Unexpected replace reason: %s", reason)
+	}
+
+	remove := ResourceChange{
+		Address:      "aws_s3_bucket.old",
+		ActionReason: "delete_because_no_resource_config",
+		Change: Change{
+			Actions: []string{"delete"},
+			Before:  map[string]interface{}{"id": "old-bucket"},
+		},
+	}
+	expected := "Resource is no longer present in the configuration"
+	if reason := determineDeleteReason(remove); reason != expected {
+		t.Errorf("Expected %q, got %q", expected, reason)
+	}
+
+	// Without action_reason the identifier table says what is destroyed
+	remove.ActionReason = ""
+	if reason := determineDeleteReason(remove); reason != "" {
+		t.Errorf("Unexpected fallback delete reason: %s", reason)
+	}
+	remove.Change.Before = nil
+	if reason := determineDeleteReason(remove); reason != "Resource marked for deletion" {
+		t.Errorf("Unexpected fallback delete reason: %s", reason)
+	}
+}
+
+func TestReplacePaths(t *testing.T) {
+	path := []interface{}{"network_interface", float64(0), "subnet_id"}
+	if formatted := formatAttributePath(path); formatted != "network_interface[0].subnet_id" {
+		t.Errorf("Unexpected path formatting: %s", formatted)
+	}
+
+	change := ResourceChange{
+		Address:      "aws_instance.web",
+		ActionReason: "replace_because_cannot_update",
+		Change: Change{
+			Actions:      []string{"delete", "create"},
+			ReplacePaths: [][]interface{}{{"ami"}, path},
+		},
+	}
+	expected := "Provider cannot update the changed attributes in-place: changing `ami`, `network_interface[0].subnet_id` force replacement"
+	if reason := determineReplaceReason(change); reason != expected {
+		t.Errorf("Expected %q, got %q", expected, reason)
+	}
+
+	change.ActionReason = ""
+	change.Change.ReplacePaths = [][]interface{}{{"name"}}
+	if reason := determineReplaceReason(change); reason != "Changing `name` forces replacement" {
+		t.Errorf("Unexpected reason without action_reason: %s", reason)
+	}
+}
+
+func TestShowReads(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address:      "data.aws_ami.latest",
+				Mode:         "data",
+				ActionReason: "read_because_config_unknown",
+				Change: Change{
+					Actions: []string{"read"},
+				},
+			},
+			{
+				Address: "aws_instance.web",
+				Change: Change{
+					Actions: []string{"create"},
+				},
+			},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if strings.Contains(markdown, "Data Sources to be Read") {
+		t.Error("Expected reads to be hidden by default")
+	}
+
+	markdown = generateMarkdownComment(plan, ReportOptions{ShowReads: true})
+	if !strings.Contains(markdown, "- `data.aws_ami.latest` - Configuration depends on values not known until apply") {
+		t.Error("Expected data source read with reason when ShowReads is set")
+	}
+}
+
+func TestShowNoOp(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "aws_vpc.main",
+				Change: Change{
+					Actions: []string{"no-op"},
+				},
+			},
+			{
+				Address: "aws_instance.web",
+				Change: Change{
+					Actions: []string{"create"},
+				},
+			},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if strings.Contains(markdown, "Unchanged resources") {
+		t.Error("Expected unchanged resources to be hidden by default")
+	}
+
+	markdown = generateMarkdownComment(plan, ReportOptions{ShowNoOp: true})
+	if !strings.Contains(markdown, "<summary>⚪ Unchanged resources (1)</summary>") {
+		t.Error("Expected collapsed unchanged resources section")
+	}
+	if !strings.Contains(markdown, "- `aws_vpc.main`") {
+		t.Error("Expected unchanged resource address in output")
+	}
+}
+
+func TestDeepEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     interface{}
+		expected bool
+	}{
+		{"int vs float", 1, float64(1), true},
+		{"float vs float", float64(1.5), float64(1.5), true},
+		{"number vs string", float64(1), "1", false},
+		{"nil vs empty list", nil, []interface{}{}, true},
+		{"nil vs empty map", nil, map[string]interface{}{}, true},
+		{"nil vs string", nil, "", false},
+		{"nil vs list", nil, []interface{}{"a"}, false},
+		{"list order", []interface{}{"a", "b"}, []interface{}{"b", "a"}, false},
+		{"nested maps", map[string]interface{}{"a": []interface{}{1}}, map[string]interface{}{"a": []interface{}{float64(1)}}, true},
+		{"extra key", map[string]interface{}{"a": nil}, map[string]interface{}{"b": nil}, false},
+		{"string with brackets", "[a b]", []interface{}{"a", "b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deepEqual(tt.a, tt.b); got != tt.expected {
+				t.Errorf("deepEqual(%#v, %#v) = %v, want %v", tt.a, tt.b, got, tt.expected)
+			}
+		})
+	}
+}
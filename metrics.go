@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// pushgatewayJob is the job label metrics are pushed under
+const pushgatewayJob = "tfplan_commenter"
+
+// RunMetrics are the measurements of one run exported to Prometheus
+type RunMetrics struct {
+	Plans         []PlanInfo
+	ParseDuration time.Duration
+}
+
+// metricSample is one labelled value of a metric family
+type metricSample struct {
+	labels map[string]string
+	value  float64
+}
+
+// metricFamily is a gauge and its samples
+type metricFamily struct {
+	name    string
+	help    string
+	samples []metricSample
+}
+
+// collectMetrics gathers per-environment change counts, risk and plan size
+func collectMetrics(run RunMetrics, opts ReportOptions) []metricFamily {
+	changes := metricFamily{name: "tfplan_resource_changes", help: "Resources changed by the plan, by action"}
+	risk := metricFamily{name: "tfplan_risk_score", help: "Risk score of the plan"}
+	size := metricFamily{name: "tfplan_plan_size_bytes", help: "Size of the plan JSON file"}
+
+	for _, planInfo := range run.Plans {
+		env := planInfo.RelativePath
+		if env == "" {
+			env = planInfo.Plan.PlanPath
+		}
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+
+		for _, action := range []struct {
+			name  string
+			count int
+		}{
+			{"create", len(summary.Create)},
+			{"update", len(summary.Update)},
+			{"replace", len(summary.Replace)},
+			{"delete", len(summary.Delete)},
+		} {
+			changes.samples = append(changes.samples, metricSample{
+				labels: map[string]string{"environment": env, "action": action.name},
+				value:  float64(action.count),
+			})
+		}
+
+		score := assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk).Score
+		risk.samples = append(risk.samples, metricSample{labels: map[string]string{"environment": env}, value: score})
+
+		if info, err := os.Stat(planInfo.Plan.PlanPath); err == nil {
+			size.samples = append(size.samples, metricSample{labels: map[string]string{"environment": env}, value: float64(info.Size())})
+		}
+	}
+
+	duration := metricFamily{
+		name:    "tfplan_parse_duration_seconds",
+		help:    "Time spent reading and parsing plan files",
+		samples: []metricSample{{value: run.ParseDuration.Seconds()}},
+	}
+	return []metricFamily{changes, risk, size, duration}
+}
+
+// formatMetrics renders metric families in the Prometheus text format, or in
+// OpenMetrics text when openMetrics is set
+func formatMetrics(families []metricFamily, openMetrics bool) string {
+	var text strings.Builder
+	for _, family := range families {
+		if len(family.samples) == 0 {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("# HELP %s %s\n", family.name, family.help))
+		text.WriteString(fmt.Sprintf("# TYPE %s gauge\n", family.name))
+		for _, sample := range family.samples {
+			text.WriteString(fmt.Sprintf("%s%s %g\n", family.name, formatMetricLabels(sample.labels), sample.value))
+		}
+	}
+	if openMetrics {
+		text.WriteString("# EOF\n")
+	}
+	return text.String()
+}
+
+func formatMetricLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, escaper.Replace(labels[name]))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// pushMetrics replaces the run's metrics on a Prometheus Pushgateway
+func pushMetrics(gatewayURL string, families []metricFamily) error {
+	url := strings.TrimSuffix(gatewayURL, "/") + "/metrics/job/" + pushgatewayJob
+	request, err := http.NewRequest(http.MethodPut, url, strings.NewReader(formatMetrics(families, false)))
+	if err != nil {
+		return fmt.Errorf("invalid Pushgateway URL: %w", err)
+	}
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("Pushgateway returned %s", response.Status)
+	}
+	return nil
+}
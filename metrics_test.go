@@ -0,0 +1,57 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	planPath := filepath.Join(t.TempDir(), "tfplan.json")
+	if err := os.WriteFile(planPath, []byte(strings.Repeat(" ", 128)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	plan := &TerraformPlan{
+		PlanPath: planPath,
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"delete"}}},
+		},
+	}
+	run := RunMetrics{Plans: []PlanInfo{{Plan: plan, RelativePath: `dev/"eu"`}}, ParseDuration: 1500 * time.Millisecond}
+	metrics := collectMetrics(run, ReportOptions{})
+
+	text := formatMetrics(metrics, true)
+	for _, expected := range []string{
+		"# TYPE tfplan_resource_changes gauge\n",
+		`tfplan_resource_changes{action="create",environment="dev/\"eu\""} 0` + "\n",
+		`tfplan_resource_changes{action="delete",environment="dev/\"eu\""} 1` + "\n",
+		`tfplan_plan_size_bytes{environment="dev/\"eu\""} 128` + "\n",
+		"tfplan_parse_duration_seconds 1.5\n# EOF\n",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in metrics, got:\n%s", expected, text)
+		}
+	}
+
+	var pushed, method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		pushed, method, path = string(body), r.Method, r.URL.Path
+	}))
+	defer server.Close()
+
+	if err := pushMetrics(server.URL+"/", metrics); err != nil {
+		t.Fatal(err)
+	}
+	if method != http.MethodPut || path != "/metrics/job/tfplan_commenter" {
+		t.Errorf("Unexpected push request %s %s", method, path)
+	}
+	if !strings.Contains(pushed, "tfplan_risk_score") || strings.Contains(pushed, "# EOF") {
+		t.Errorf("Expected Prometheus text format push, got:\n%s", pushed)
+	}
+}
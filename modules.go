@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupByModule organizes resource changes under their module address
+const groupByModule = "module"
+
+// rootModuleLabel is shown for resources declared in the root module
+const rootModuleLabel = "(root)"
+
+// groupByModuleAddress groups a summary's changes by module_address. Sorting
+// by address places nested modules after their parents.
+func groupByModuleAddress(plan *TerraformPlan, summary ResourceSummary) []*changeGroup {
+	return groupChanges(plan, summary, func(rc ResourceChange) string {
+		return rc.ModuleAddress
+	})
+}
+
+// moduleDepth returns how deeply a module address is nested
+func moduleDepth(address string) int {
+	if address == "" {
+		return 0
+	}
+	return strings.Count(address, "module.")
+}
+
+func formatModuleLabel(address string) string {
+	if address == "" {
+		return rootModuleLabel
+	}
+	return fmt.Sprintf("`%s`", address)
+}
+
+// formatGroupedResourceLines renders a module group's resources as list items
+// prefixed with their action
+func formatGroupedResourceLines(g *changeGroup) []string {
+	var lines []string
+	for _, resource := range collapseInstances(g.Create) {
+		lines = append(lines, fmt.Sprintf("- 🟢 %s", formatResourceLabel(resource)))
+	}
+	for _, resource := range collapseInstances(g.Update) {
+		line := fmt.Sprintf("- 🟡 %s", formatResourceLabel(resource))
+		if len(resource.Changes) > 0 {
+			attributes := make([]string, len(resource.Changes))
+			for i, change := range resource.Changes {
+				attributes[i] = change.Attribute
+			}
+			line += " - " + strings.Join(attributes, ", ")
+		}
+		lines = append(lines, line)
+	}
+	for _, resource := range collapseInstances(g.Replace) {
+		line := fmt.Sprintf("- 🔄 %s", formatResourceLabel(resource))
+		if resource.ForceReason != "" {
+			line += " - " + resource.ForceReason
+		}
+		lines = append(lines, line)
+	}
+	for _, resource := range collapseInstances(g.Delete) {
+		line := fmt.Sprintf("- 🔴 %s", formatResourceLabel(resource))
+		if resource.ForceReason != "" {
+			line += " - " + resource.ForceReason
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// writeModuleGroupsSection renders changes grouped by module, replacing the per-action sections
+func writeModuleGroupsSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary) {
+	groups := groupByModuleAddress(plan, summary)
+	if len(groups) == 0 {
+		return
+	}
+
+	md.WriteString("### 📦 Changes by Module\n\n")
+	md.WriteString("| Module | Changes |\n")
+	md.WriteString("|--------|---------|\n")
+	for _, g := range groups {
+		indent := strings.Repeat("↳ ", max(moduleDepth(g.Key)-1, 0))
+		md.WriteString(fmt.Sprintf("| %s%s | %s |\n", indent, formatModuleLabel(g.Key), g.formatCounts()))
+	}
+	md.WriteString("\n")
+
+	for _, g := range groups {
+		md.WriteString(fmt.Sprintf("#### 📦 %s (%d)\n\n", formatModuleLabel(g.Key), g.total()))
+		for _, line := range formatGroupedResourceLines(g) {
+			md.WriteString(line + "\n")
+		}
+		md.WriteString("\n")
+	}
+}
+
+// writeEnvironmentModuleGroupsSection renders changes grouped by module inside a multi-plan environment section
+func writeEnvironmentModuleGroupsSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary) {
+	for _, g := range groupByModuleAddress(plan, summary) {
+		md.WriteString(fmt.Sprintf("**📦 %s:** %s\n", formatModuleLabel(g.Key), g.formatCounts()))
+		for _, line := range formatGroupedResourceLines(g) {
+			md.WriteString(line + "\n")
+		}
+		md.WriteString("\n")
+	}
+}
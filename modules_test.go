@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGroupByModule(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_vpc.main", Type: "aws_vpc", Change: Change{Actions: []string{"create"}}},
+			{
+				Address:       "module.network.aws_subnet.a",
+				ModuleAddress: "module.network",
+				Type:          "aws_subnet",
+				Change:        Change{Actions: []string{"create"}},
+			},
+			{
+				Address:       "module.network.module.nat.aws_nat_gateway.gw",
+				ModuleAddress: "module.network.module.nat",
+				Type:          "aws_nat_gateway",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"connectivity_type": "public"},
+					After:   map[string]interface{}{"connectivity_type": "private"},
+				},
+			},
+			{
+				Address:       "module.network.aws_route_table.old",
+				ModuleAddress: "module.network",
+				Type:          "aws_route_table",
+				Change:        Change{Actions: []string{"delete"}},
+			},
+		},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{GroupBy: groupByModule})
+
+	for _, expected := range []string{
+		"### 📦 Changes by Module",
+		"| (root) | 1 create |",
+		"| `module.network` | 1 create, 1 delete |",
+		"| ↳ `module.network.module.nat` | 1 update |",
+		"#### 📦 `module.network` (2)\n\n- 🟢 `module.network.aws_subnet.a`\n- 🔴 `module.network.aws_route_table.old`",
+		"- 🟡 `module.network.module.nat.aws_nat_gateway.gw` - connectivity_type",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "### 🟢 Resources to be Created") {
+		t.Error("Expected per-action sections to be replaced when grouping by module")
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultNamingAttribute is checked when a naming rule doesn't name an attribute
+const defaultNamingAttribute = "name"
+
+// NamingConfig enforces naming conventions, under "naming" in the -config file
+type NamingConfig struct {
+	// Rules maps resource type globs (e.g. "aws_s3_bucket") to the rule names must follow
+	Rules map[string]NamingRule `json:"rules"`
+	// Fail exits with code 3 when a planned name violates a rule
+	Fail bool `json:"fail"`
+
+	patterns []namingPattern
+}
+
+// NamingRule is a regex the whole value of a planned attribute must match
+type NamingRule struct {
+	Attribute string `json:"attribute"` // default "name"
+	Pattern   string `json:"pattern"`   // e.g. "org-(dev|prod)-.*"
+}
+
+type namingPattern struct {
+	typePattern string
+	attribute   string
+	pattern     string
+	re          *regexp.Regexp
+}
+
+// NamingViolation is a planned name that doesn't follow its naming rule
+type NamingViolation struct {
+	Address   string
+	Attribute string
+	Value     string
+	Pattern   string
+}
+
+func (n *NamingConfig) validate() error {
+	typePatterns := make([]string, 0, len(n.Rules))
+	for typePattern := range n.Rules {
+		if _, err := path.Match(typePattern, ""); err != nil {
+			return fmt.Errorf("invalid naming resource type pattern %q: %w", typePattern, err)
+		}
+		typePatterns = append(typePatterns, typePattern)
+	}
+	sort.Strings(typePatterns)
+
+	n.patterns = nil
+	for _, typePattern := range typePatterns {
+		rule := n.Rules[typePattern]
+		re, err := regexp.Compile(`^(?:` + rule.Pattern + `)$`)
+		if err != nil {
+			return fmt.Errorf("invalid naming pattern %q for %s: %w", rule.Pattern, typePattern, err)
+		}
+		attribute := rule.Attribute
+		if attribute == "" {
+			attribute = defaultNamingAttribute
+		}
+		n.patterns = append(n.patterns, namingPattern{typePattern: typePattern, attribute: attribute, pattern: rule.Pattern, re: re})
+	}
+	return nil
+}
+
+// checkNaming evaluates the naming rules over the planned values of created,
+// updated and replaced resources. Names not known until apply are skipped.
+func checkNaming(plan *TerraformPlan, naming *NamingConfig) []NamingViolation {
+	if naming == nil {
+		return nil
+	}
+
+	var violations []NamingViolation
+	for _, rc := range plan.ResourceChanges {
+		switch planAction(rc.Change.Actions) {
+		case "create", "update", "replace":
+		default:
+			continue
+		}
+		after, ok := rc.Change.After.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, p := range naming.patterns {
+			if ok, _ := path.Match(p.typePattern, rc.Type); !ok {
+				continue
+			}
+			value, ok := after[p.attribute].(string)
+			if !ok || p.re.MatchString(value) {
+				continue
+			}
+			violations = append(violations, NamingViolation{
+				Address:   rc.Address,
+				Attribute: p.attribute,
+				Value:     value,
+				Pattern:   p.pattern,
+			})
+		}
+	}
+	return violations
+}
+
+func formatNamingViolation(violation NamingViolation) string {
+	return fmt.Sprintf("`%s`: %s `%s` doesn't match `%s`", violation.Address, violation.Attribute, violation.Value, violation.Pattern)
+}
+
+// writeNamingSection renders naming convention violations
+func writeNamingSection(md *strings.Builder, plan *TerraformPlan, naming *NamingConfig) {
+	violations := checkNaming(plan, naming)
+	if len(violations) == 0 {
+		return
+	}
+
+	md.WriteString("### 📛 Naming Convention Violations\n\n")
+	for _, violation := range violations {
+		md.WriteString(fmt.Sprintf("- %s\n", formatNamingViolation(violation)))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentNamingSection renders naming convention violations inside a multi-plan environment section
+func writeEnvironmentNamingSection(md *strings.Builder, plan *TerraformPlan, naming *NamingConfig) {
+	violations := checkNaming(plan, naming)
+	if len(violations) == 0 {
+		return
+	}
+
+	md.WriteString("**📛 Naming convention violations:**\n")
+	for _, violation := range violations {
+		md.WriteString(fmt.Sprintf("- %s\n", formatNamingViolation(violation)))
+	}
+	md.WriteString("\n")
+}
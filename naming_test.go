@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckNaming(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"naming": {
+		"rules": {
+			"aws_s3_bucket": {"attribute": "bucket", "pattern": "org-(dev|prod)-.*"},
+			"aws_*": {"pattern": "[a-z0-9-]+"}
+		},
+		"fail": true
+	}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func(address, resourceType string, after map[string]interface{}) ResourceChange {
+		return ResourceChange{Address: address, Type: resourceType, Change: Change{Actions: []string{"create"}, After: after}}
+	}
+	plan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		create("aws_s3_bucket.logs", "aws_s3_bucket", map[string]interface{}{"bucket": "logs-bucket"}),
+		create("aws_s3_bucket.data", "aws_s3_bucket", map[string]interface{}{"bucket": "org-prod-data"}),
+		create("aws_iam_role.app", "aws_iam_role", map[string]interface{}{"name": "App_Role"}),
+		create("aws_sqs_queue.jobs", "aws_sqs_queue", map[string]interface{}{}),
+		{Address: "aws_iam_role.old", Type: "aws_iam_role", Change: Change{Actions: []string{"delete"}, Before: map[string]interface{}{"name": "Old_Role"}}},
+	}}
+
+	violations := checkNaming(plan, config.Naming)
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %+v", violations)
+	}
+	if violations[0].Address != "aws_s3_bucket.logs" || violations[1].Address != "aws_iam_role.app" {
+		t.Errorf("Unexpected violations: %+v", violations)
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{Naming: config.Naming})
+	expected := "### 📛 Naming Convention Violations\n\n" +
+		"- `aws_s3_bucket.logs`: bucket `logs-bucket` doesn't match `org-(dev|prod)-.*`\n" +
+		"- `aws_iam_role.app`: name `App_Role` doesn't match `[a-z0-9-]+`\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected naming section, got:\n%s", result)
+	}
+}
+
+func TestInvalidNamingPattern(t *testing.T) {
+	naming := &NamingConfig{Rules: map[string]NamingRule{"aws_s3_bucket": {Pattern: "org-("}}}
+	if err := naming.validate(); err == nil {
+		t.Error("Expected error for invalid naming pattern")
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OwnershipRule assigns teams to the resources of matching modules or
+// addresses, e.g. module.network to @org/networking
+type OwnershipRule struct {
+	// Patterns are address globs or module addresses; a module address
+	// matches every resource inside it
+	Patterns []string `json:"patterns"`
+	// Teams are mentioned when matching resources change
+	Teams []string `json:"teams"`
+}
+
+func (r OwnershipRule) validate() error {
+	if len(r.Patterns) == 0 || len(r.Teams) == 0 {
+		return fmt.Errorf("ownership rules need patterns and teams")
+	}
+	for _, pattern := range r.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid ownership pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// owns reports whether the rule covers a resource address
+func (r OwnershipRule) owns(address string) bool {
+	for _, pattern := range r.Patterns {
+		if ok, _ := path.Match(pattern, address); ok {
+			return true
+		}
+		if strings.HasPrefix(address, pattern+".") || strings.HasPrefix(address, pattern+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// owningTeams returns the teams owning changed resources of a plan, in rule order
+func owningTeams(plan *TerraformPlan, rules []OwnershipRule) []string {
+	var teams []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		for _, rc := range plan.ResourceChanges {
+			if action := planAction(rc.Change.Actions); action == "no-op" || action == "read" || !rule.owns(rc.Address) {
+				continue
+			}
+			for _, team := range rule.Teams {
+				if !seen[team] {
+					seen[team] = true
+					teams = append(teams, team)
+				}
+			}
+			break
+		}
+	}
+	return teams
+}
+
+// formatOwners renders the owning teams as mentions
+func formatOwners(teams []string) string {
+	mentions := make([]string, len(teams))
+	for i, team := range teams {
+		mentions[i] = "@" + strings.TrimPrefix(team, "@")
+	}
+	return "👥 **Owners:** " + strings.Join(mentions, ", ")
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnershipMentions(t *testing.T) {
+	owners := []OwnershipRule{
+		{Patterns: []string{"module.network"}, Teams: []string{"@org/networking"}},
+		{Patterns: []string{"aws_db_*", "module.data.aws_db_*"}, Teams: []string{"org/dba"}},
+		{Patterns: []string{"module.network.aws_route*"}, Teams: []string{"@org/networking", "@org/sre"}},
+	}
+	prod := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "module.network.aws_vpc.main", Type: "aws_vpc", Change: Change{Actions: []string{"update"}}},
+		{Address: "module.network[\"b\"].aws_route.default", Type: "aws_route", Change: Change{Actions: []string{"create"}}},
+		{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"no-op"}}},
+	}}
+	dev := &TerraformPlan{ResourceChanges: []ResourceChange{
+		{Address: "module.networking.aws_vpc.main", Type: "aws_vpc", Change: Change{Actions: []string{"create"}}},
+		{Address: "module.data.aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+	}}
+
+	if teams := owningTeams(prod, owners); strings.Join(teams, ",") != "@org/networking" {
+		t.Errorf("Unexpected prod owners %v", teams)
+	}
+	if teams := owningTeams(dev, owners); strings.Join(teams, ",") != "org/dba" {
+		t.Errorf("Unexpected dev owners %v", teams)
+	}
+
+	plans := []PlanInfo{{Plan: prod, RelativePath: "prod"}, {Plan: dev, RelativePath: "dev"}}
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{Owners: owners})
+	prodSection := result[strings.Index(result, "#### 📁 `prod`"):strings.Index(result, "#### 📁 `dev`")]
+	devSection := result[strings.Index(result, "#### 📁 `dev`"):]
+	if !strings.Contains(prodSection, "👥 **Owners:** @org/networking\n") {
+		t.Errorf("Expected networking to be mentioned in prod:\n%s", prodSection)
+	}
+	if !strings.Contains(devSection, "👥 **Owners:** @org/dba\n") {
+		t.Errorf("Expected dba to be mentioned in dev:\n%s", devSection)
+	}
+
+	if err := (OwnershipRule{Patterns: []string{"module.x"}}).validate(); err == nil {
+		t.Error("Expected an error for a rule without teams")
+	}
+}
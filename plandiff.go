@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// defaultPlanDiffFile is written by the diff subcommand when no output is given
+const defaultPlanDiffFile = "terraform-plan-diff.md"
+
+// actionEmojis mark actions in the plan-to-plan diff
+var actionEmojis = map[string]string{
+	"create":  "🟢",
+	"update":  "🟡",
+	"replace": "🔄",
+	"delete":  "🔴",
+}
+
+// plannedChange is the action and attribute changes a plan makes to one resource
+type plannedChange struct {
+	action     string
+	attributes map[string]string // Attribute path to rendered change
+}
+
+// PlanDiff describes how a plan differs from an earlier plan of the same workspace
+type PlanDiff struct {
+	Added    []string // Resources only the new plan changes
+	Removed  []string // Resources only the old plan changed
+	Actions  []string // Resources whose action changed, e.g. "`x`: update → replace"
+	Modified []AttributeDiff
+}
+
+// AttributeDiff lists how the planned attribute changes of a resource differ
+type AttributeDiff struct {
+	Address string
+	Added   []string // Rendered attribute changes only in the new plan
+	Removed []string // Attributes no longer changed
+	Changed []string // Rendered attribute changes that now have different values
+}
+
+func (d PlanDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Actions) == 0 && len(d.Modified) == 0
+}
+
+// plannedChanges indexes the changes a plan makes by resource address
+func plannedChanges(plan *TerraformPlan) map[string]plannedChange {
+	changes := make(map[string]plannedChange)
+	for _, rc := range plan.ResourceChanges {
+		action := planAction(rc.Change.Actions)
+		if action == "no-op" || action == "read" {
+			continue
+		}
+
+		planned := plannedChange{action: action, attributes: make(map[string]string)}
+		if action == "update" || action == "replace" {
+			for _, change := range analyzeAttributeChanges(rc.Change) {
+				planned.attributes[change.Attribute] = strings.TrimSpace(formatAttributeChange(change))
+			}
+		}
+		changes[rc.Address] = planned
+	}
+	return changes
+}
+
+// diffPlans compares the changes two plans of the same workspace make
+func diffPlans(oldPlan, newPlan *TerraformPlan) PlanDiff {
+	oldChanges, newChanges := plannedChanges(oldPlan), plannedChanges(newPlan)
+
+	var diff PlanDiff
+	for _, address := range sortedKeys(newChanges) {
+		newChange := newChanges[address]
+		oldChange, ok := oldChanges[address]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, fmt.Sprintf("%s `%s` (%s)", actionEmojis[newChange.action], address, newChange.action))
+		case oldChange.action != newChange.action:
+			diff.Actions = append(diff.Actions, fmt.Sprintf("`%s`: %s → %s", address, oldChange.action, newChange.action))
+		default:
+			if attributes := diffAttributes(address, oldChange, newChange); attributes != nil {
+				diff.Modified = append(diff.Modified, *attributes)
+			}
+		}
+	}
+	for _, address := range sortedKeys(oldChanges) {
+		if _, ok := newChanges[address]; !ok {
+			diff.Removed = append(diff.Removed, fmt.Sprintf("%s `%s` (%s)", actionEmojis[oldChanges[address].action], address, oldChanges[address].action))
+		}
+	}
+	return diff
+}
+
+func diffAttributes(address string, oldChange, newChange plannedChange) *AttributeDiff {
+	diff := AttributeDiff{Address: address}
+	for _, attribute := range sortedKeys(newChange.attributes) {
+		oldRendered, ok := oldChange.attributes[attribute]
+		if !ok {
+			diff.Added = append(diff.Added, newChange.attributes[attribute])
+		} else if oldRendered != newChange.attributes[attribute] {
+			diff.Changed = append(diff.Changed, newChange.attributes[attribute])
+		}
+	}
+	for _, attribute := range sortedKeys(oldChange.attributes) {
+		if _, ok := newChange.attributes[attribute]; !ok {
+			diff.Removed = append(diff.Removed, attribute)
+		}
+	}
+	if len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+		return nil
+	}
+	return &diff
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// generatePlanDiffMarkdown renders the differences between two plan runs
+func generatePlanDiffMarkdown(diff PlanDiff) string {
+	var md strings.Builder
+
+	md.WriteString("## 🔁 Plan Changes Since Previous Run\n\n")
+	if diff.empty() {
+		md.WriteString("✅ **No differences** - the new plan makes the same changes as the previous one\n\n")
+		return md.String()
+	}
+
+	writeList := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		md.WriteString(fmt.Sprintf("### %s\n\n", title))
+		for _, line := range lines {
+			md.WriteString(fmt.Sprintf("- %s\n", line))
+		}
+		md.WriteString("\n")
+	}
+	writeList("➕ Newly Planned Changes", diff.Added)
+	writeList("➖ No Longer Planned", diff.Removed)
+	writeList("🔀 Changed Actions", diff.Actions)
+
+	if len(diff.Modified) > 0 {
+		md.WriteString("### ✏️ Changed Attribute Diffs\n\n")
+		for _, resource := range diff.Modified {
+			md.WriteString(fmt.Sprintf("#### `%s`\n\n", resource.Address))
+			if len(resource.Added) > 0 {
+				md.WriteString("**Newly changed:**\n\n")
+				for _, line := range resource.Added {
+					md.WriteString(line + "\n")
+				}
+				md.WriteString("\n")
+			}
+			if len(resource.Changed) > 0 {
+				md.WriteString("**Now planned as:**\n\n")
+				for _, line := range resource.Changed {
+					md.WriteString(line + "\n")
+				}
+				md.WriteString("\n")
+			}
+			if len(resource.Removed) > 0 {
+				md.WriteString("**No longer changed:**\n\n")
+				for _, attribute := range resource.Removed {
+					md.WriteString(fmt.Sprintf("- **%s**\n", attribute))
+				}
+				md.WriteString("\n")
+			}
+		}
+	}
+	return md.String()
+}
+
+// runDiffCommand implements `tfplan-commenter diff <old-plan.json> <new-plan.json> [output.md]`
+func runDiffCommand(args []string) {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter diff <old-plan.json> <new-plan.json> [output.md]")
+		os.Exit(1)
+	}
+
+	outputFile := defaultPlanDiffFile
+	if len(args) > 2 {
+		outputFile = args[2]
+	}
+
+	oldPlan, err := readTerraformPlan(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading old plan file: %v\n", err)
+		os.Exit(1)
+	}
+	newPlan, err := readTerraformPlan(args[1])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading new plan file: %v\n", err)
+		os.Exit(1)
+	}
+
+	markdown := generatePlanDiffMarkdown(diffPlans(oldPlan, newPlan))
+	if err := os.WriteFile(outputFile, []byte(markdown), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing output file: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Plan diff generated: %s\n", outputFile)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffPlans(t *testing.T) {
+	update := func(address string, before, after map[string]interface{}) ResourceChange {
+		return ResourceChange{Address: address, Change: Change{Actions: []string{"update"}, Before: before, After: after}}
+	}
+	oldPlan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		update("aws_instance.web",
+			map[string]interface{}{"instance_type": "t3.micro", "ami": "ami-1"},
+			map[string]interface{}{"instance_type": "t3.large", "ami": "ami-2"}),
+		update("aws_instance.same", map[string]interface{}{"ami": "ami-1"}, map[string]interface{}{"ami": "ami-2"}),
+		update("aws_db_instance.main", map[string]interface{}{"engine_version": "14"}, map[string]interface{}{"engine_version": "15"}),
+		{Address: "aws_s3_bucket.old", Change: Change{Actions: []string{"delete"}}},
+	}}
+	newPlan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		update("aws_instance.web",
+			map[string]interface{}{"instance_type": "t3.micro", "ami": "ami-1", "monitoring": false},
+			map[string]interface{}{"instance_type": "t3.medium", "ami": "ami-1", "monitoring": true}),
+		update("aws_instance.same", map[string]interface{}{"ami": "ami-1"}, map[string]interface{}{"ami": "ami-2"}),
+		{Address: "aws_db_instance.main", Change: Change{Actions: []string{"delete", "create"}}},
+		{Address: "aws_sqs_queue.jobs", Change: Change{Actions: []string{"create"}}},
+	}}
+
+	result := generatePlanDiffMarkdown(diffPlans(oldPlan, newPlan))
+	for _, expected := range []string{
+		"### ➕ Newly Planned Changes\n\n- 🟢 `aws_sqs_queue.jobs` (create)\n\n",
+		"### ➖ No Longer Planned\n\n- 🔴 `aws_s3_bucket.old` (delete)\n\n",
+		"### 🔀 Changed Actions\n\n- `aws_db_instance.main`: update → replace\n\n",
+		"#### `aws_instance.web`\n\n" +
+			"**Newly changed:**\n\n- **monitoring**: false → true\n\n" +
+			"**Now planned as:**\n\n- **instance_type**: \"t3.micro\" → \"t3.medium\"\n\n" +
+			"**No longer changed:**\n\n- **ami**\n\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in diff, got:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "aws_instance.same") {
+		t.Errorf("Expected unchanged resource to be left out, got:\n%s", result)
+	}
+
+	result = generatePlanDiffMarkdown(diffPlans(oldPlan, oldPlan))
+	if !strings.Contains(result, "✅ **No differences**") {
+		t.Errorf("Expected no differences, got:\n%s", result)
+	}
+}
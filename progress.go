@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// progressLogInterval spaces out progress log lines when output isn't a terminal
+const progressLogInterval = 10 * time.Second
+
+// progressReporter shows how many plans have been parsed, as a live status
+// line on terminals and as periodic log lines in CI logs
+type progressReporter struct {
+	out      io.Writer
+	tty      bool
+	total    int
+	done     int
+	lastLog  time.Time
+	interval time.Duration
+	now      func() time.Time
+	visible  bool // A status line is drawn and must be cleared before other output
+}
+
+func newProgressReporter(total int) *progressReporter {
+	tty := false
+	if info, err := os.Stderr.Stat(); err == nil {
+		tty = info.Mode()&os.ModeCharDevice != 0
+	}
+	return &progressReporter{out: os.Stderr, tty: tty, total: total, interval: progressLogInterval, now: time.Now}
+}
+
+// start reports that a plan is being parsed
+func (p *progressReporter) start(path string) {
+	if p.tty {
+		fmt.Fprintf(p.out, "\r\033[K[%d/%d] Parsing %s", p.done+1, p.total, path)
+		p.visible = true
+		return
+	}
+
+	if now := p.now(); p.lastLog.IsZero() || now.Sub(p.lastLog) >= p.interval {
+		fmt.Fprintf(p.out, "Parsing plans: %d/%d done, current: %s\n", p.done, p.total, path)
+		p.lastLog = now
+	}
+}
+
+// finish records a parsed plan and clears the status line so other output
+// isn't written over it
+func (p *progressReporter) finish() {
+	p.done++
+	p.clear()
+}
+
+func (p *progressReporter) clear() {
+	if p.visible {
+		fmt.Fprint(p.out, "\r\033[K")
+		p.visible = false
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressReporterLogLines(t *testing.T) {
+	var out strings.Builder
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &progressReporter{out: &out, total: 3, interval: 10 * time.Second, now: func() time.Time { return now }}
+
+	for i, path := range []string{"a/tfplan.json", "b/tfplan.json", "c/tfplan.json"} {
+		p.start(path)
+		p.finish()
+		if i == 0 {
+			now = now.Add(15 * time.Second)
+		}
+	}
+
+	expected := "Parsing plans: 0/3 done, current: a/tfplan.json\n" +
+		"Parsing plans: 1/3 done, current: b/tfplan.json\n"
+	if out.String() != expected {
+		t.Errorf("Expected periodic log lines %q, got %q", expected, out.String())
+	}
+}
+
+func TestProgressReporterTerminal(t *testing.T) {
+	var out strings.Builder
+	p := &progressReporter{out: &out, tty: true, total: 2, now: time.Now}
+
+	p.start("a/tfplan.json")
+	p.finish()
+
+	if expected := "\r\033[K[1/2] Parsing a/tfplan.json\r\033[K"; out.String() != expected {
+		t.Errorf("Expected status line cleared after parsing, got %q", out.String())
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+)
+
+// protectiveAttributes maps attributes guarding against accidental destruction
+// to the value that keeps the protection on
+var protectiveAttributes = map[string]bool{
+	"deletion_protection":           true,
+	"deletion_protection_enabled":   true,
+	"enable_deletion_protection":    true,
+	"disable_api_termination":       true,
+	"termination_protection":        true,
+	"enable_termination_protection": true,
+	"force_destroy":                 false,
+	"skip_final_snapshot":           false,
+}
+
+// detectProtectionRemoval flags protective attributes switched off by the plan
+func detectProtectionRemoval(rc ResourceChange) []securityFinding {
+	var findings []securityFinding
+	for _, change := range analyzeAttributeChanges(rc.Change) {
+		protective, ok := protectiveAttributes[lastPathSegment(change.Attribute)]
+		if !ok || change.Before != protective {
+			continue
+		}
+		after, isBool := change.After.(bool)
+		if !isBool || after == protective {
+			continue
+		}
+		findings = append(findings, securityFinding{
+			Address: rc.Address,
+			Title:   "Deletion protection removed",
+			Detail:  fmt.Sprintf("sets %s to %v", change.Attribute, after),
+		})
+	}
+	return findings
+}
+
+// countProtectionRemovals counts the protective attributes switched off across a plan
+func countProtectionRemovals(plan *TerraformPlan) int {
+	count := 0
+	for _, rc := range plan.ResourceChanges {
+		if action := planAction(rc.Change.Actions); action == "update" || action == "replace" {
+			count += len(detectProtectionRemoval(rc))
+		}
+	}
+	return count
+}
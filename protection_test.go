@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectProtectionRemoval(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "aws_db_instance.main",
+				Type:    "aws_db_instance",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"deletion_protection": true, "skip_final_snapshot": false},
+					After:   map[string]interface{}{"deletion_protection": false, "skip_final_snapshot": true},
+				},
+			},
+			{
+				Address: "aws_s3_bucket.logs",
+				Type:    "aws_s3_bucket",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"force_destroy": true},
+					After:   map[string]interface{}{"force_destroy": false},
+				},
+			},
+		},
+	}
+
+	findings := detectProtectionRemoval(plan.ResourceChanges[0])
+	if len(findings) != 2 || findings[0].Detail != "sets deletion_protection to false" || findings[1].Detail != "sets skip_final_snapshot to true" {
+		t.Errorf("Unexpected findings: %+v", findings)
+	}
+	if findings := detectProtectionRemoval(plan.ResourceChanges[1]); len(findings) != 0 {
+		t.Errorf("Expected enabling protection not to be flagged, got %+v", findings)
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "> ⚠️ **Deletion protection removed:** `aws_db_instance.main` sets deletion_protection to false") {
+		t.Errorf("Expected protection warning, got:\n%s", result)
+	}
+	if !strings.Contains(result, "**Risk: HIGH 🔥**") || !strings.Contains(result, "2 deletion protection removals") {
+		t.Errorf("Expected protection removal to raise the risk, got:\n%s", result)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ProviderConfig represents an entry in the configuration's provider_config block
+type ProviderConfig struct {
+	Name              string                 `json:"name"`
+	FullName          string                 `json:"full_name"`
+	Alias             string                 `json:"alias"`
+	VersionConstraint string                 `json:"version_constraint"`
+	Expressions       map[string]interface{} `json:"expressions"`
+}
+
+// LockedProvider is a provider selection recorded in .terraform.lock.hcl
+type LockedProvider struct {
+	Version     string
+	Constraints string
+}
+
+var (
+	lockProviderPattern    = regexp.MustCompile(`^\s*provider\s+"([^"]+)"`)
+	lockVersionPattern     = regexp.MustCompile(`^\s*version\s*=\s*"([^"]*)"`)
+	lockConstraintsPattern = regexp.MustCompile(`^\s*constraints\s*=\s*"([^"]*)"`)
+)
+
+// readLockFile parses the provider selections from a .terraform.lock.hcl file
+func readLockFile(path string) (map[string]LockedProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	locked := make(map[string]LockedProvider)
+	current := ""
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := lockProviderPattern.FindStringSubmatch(line); match != nil {
+			current = match[1]
+			locked[current] = LockedProvider{}
+			continue
+		}
+		if current == "" {
+			continue
+		}
+		entry := locked[current]
+		if match := lockVersionPattern.FindStringSubmatch(line); match != nil {
+			entry.Version = match[1]
+		} else if match := lockConstraintsPattern.FindStringSubmatch(line); match != nil {
+			entry.Constraints = match[1]
+		}
+		locked[current] = entry
+	}
+
+	return locked, scanner.Err()
+}
+
+// requiredProviders merges provider_config entries by provider source address,
+// collecting every distinct version constraint
+func requiredProviders(plan *TerraformPlan) map[string][]string {
+	providers := make(map[string][]string)
+	if plan == nil || plan.Configuration == nil {
+		return providers
+	}
+
+	for _, config := range plan.Configuration.ProviderConfig {
+		name := config.FullName
+		if name == "" {
+			name = config.Name
+		}
+		if strings.HasPrefix(name, "terraform.io/builtin/") {
+			continue
+		}
+		constraints := providers[name]
+		known := false
+		for _, c := range constraints {
+			if c == config.VersionConstraint {
+				known = true
+			}
+		}
+		if config.VersionConstraint != "" && !known {
+			constraints = append(constraints, config.VersionConstraint)
+			sort.Strings(constraints)
+		}
+		providers[name] = constraints
+	}
+
+	return providers
+}
+
+// formatProviderRows describes each required provider, flagging constraints
+// that changed versus the lock file or a previous plan
+func formatProviderRows(plan *TerraformPlan, locked map[string]LockedProvider, previous *TerraformPlan) []string {
+	providers := requiredProviders(plan)
+	previousProviders := requiredProviders(previous)
+
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var rows []string
+	for _, name := range names {
+		constraint := strings.Join(providers[name], ", ")
+
+		lockedVersion := "-"
+		var notes []string
+		if lock, ok := locked[name]; ok {
+			lockedVersion = lock.Version
+			if lock.Constraints != "" && constraint != "" && lock.Constraints != constraint {
+				notes = append(notes, fmt.Sprintf("⚠️ constraint changed from `%s` in lock file", lock.Constraints))
+			}
+		} else if locked != nil {
+			notes = append(notes, "⚠️ not in lock file")
+		}
+
+		if previous != nil {
+			if prev, ok := previousProviders[name]; !ok {
+				notes = append(notes, "🆕 new provider")
+			} else if prevConstraint := strings.Join(prev, ", "); prevConstraint != constraint {
+				notes = append(notes, fmt.Sprintf("⚠️ constraint changed from `%s`", prevConstraint))
+			}
+		}
+
+		if constraint == "" {
+			constraint = "(any)"
+		} else {
+			constraint = fmt.Sprintf("`%s`", constraint)
+		}
+		if len(notes) == 0 {
+			notes = append(notes, "✅ unchanged")
+		}
+
+		rows = append(rows, fmt.Sprintf("| `%s` | %s | %s | %s |", name, constraint, lockedVersion, strings.Join(notes, "; ")))
+	}
+	return rows
+}
+
+// writeProvidersSection renders the plan's required providers
+func writeProvidersSection(md *strings.Builder, plan *TerraformPlan, opts ReportOptions) {
+	var locked map[string]LockedProvider
+	dir := opts.SourceRoot
+	if dir == "" && plan.PlanPath != "" {
+		dir = filepath.Dir(plan.PlanPath)
+	}
+	if dir != "" {
+		if lock, err := readLockFile(filepath.Join(dir, ".terraform.lock.hcl")); err == nil {
+			locked = lock
+		}
+	}
+
+	rows := formatProviderRows(plan, locked, opts.PreviousPlan)
+	if len(rows) == 0 {
+		return
+	}
+
+	md.WriteString("**🔌 Required providers:**\n\n")
+	md.WriteString("| Provider | Constraint | Locked | Status |\n")
+	md.WriteString("|----------|------------|--------|--------|\n")
+	for _, row := range rows {
+		md.WriteString(row + "\n")
+	}
+	md.WriteString("\n")
+}
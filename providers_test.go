@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".terraform.lock.hcl")
+	lock := `provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.79.0"
+  constraints = "~> 5.79.0"
+  hashes = [
+    "h1:abc=",
+  ]
+}
+`
+	if err := os.WriteFile(path, []byte(lock), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	locked, err := readLockFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	aws := locked["registry.terraform.io/hashicorp/aws"]
+	if aws.Version != "5.79.0" || aws.Constraints != "~> 5.79.0" {
+		t.Errorf("Unexpected lock entry: %+v", aws)
+	}
+}
+
+func TestFormatProviderRows(t *testing.T) {
+	plan := &TerraformPlan{
+		Configuration: &Configuration{
+			ProviderConfig: map[string]ProviderConfig{
+				"aws":       {Name: "aws", FullName: "registry.terraform.io/hashicorp/aws", VersionConstraint: "~> 6.0"},
+				"terraform": {Name: "terraform", FullName: "terraform.io/builtin/terraform"},
+			},
+		},
+	}
+	previous := &TerraformPlan{
+		Configuration: &Configuration{
+			ProviderConfig: map[string]ProviderConfig{
+				"aws": {Name: "aws", FullName: "registry.terraform.io/hashicorp/aws", VersionConstraint: "~> 5.79.0"},
+			},
+		},
+	}
+	locked := map[string]LockedProvider{
+		"registry.terraform.io/hashicorp/aws": {Version: "5.79.0", Constraints: "~> 5.79.0"},
+	}
+
+	rows := formatProviderRows(plan, locked, previous)
+	expected := "| `registry.terraform.io/hashicorp/aws` | `~> 6.0` | 5.79.0 | ⚠️ constraint changed from `~> 5.79.0` in lock file; ⚠️ constraint changed from `~> 5.79.0` |"
+	if len(rows) != 1 || rows[0] != expected {
+		t.Errorf("Expected [%s], got %v", expected, rows)
+	}
+}
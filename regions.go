@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// arnPattern matches AWS ARNs and captures their region and account ID
+var arnPattern = regexp.MustCompile(`^arn:aws[a-z-]*:[a-z0-9-]+:([a-z0-9-]*):(\d{12})?:`)
+
+// unknownLocation is shown when a resource's region or account can't be inferred
+const unknownLocation = "unknown"
+
+// resourceLocation is the region and account a resource change applies to
+type resourceLocation struct {
+	Region  string
+	Account string
+}
+
+// locateResources infers the region and account of every resource change from
+// the resource's own ARN and region attributes, then from any ARN in its values,
+// and finally from its provider configuration
+func locateResources(plan *TerraformPlan) map[string]resourceLocation {
+	providers := providerLocations(plan.Configuration)
+
+	providerKeys := make(map[string]string)
+	if plan.Configuration != nil {
+		collectProviderConfigKeys(plan.Configuration.RootModule, "", providerKeys)
+	}
+
+	locations := make(map[string]resourceLocation, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		var location resourceLocation
+		for _, values := range []interface{}{rc.Change.After, rc.Change.Before} {
+			fillLocationFromValues(&location, values)
+		}
+
+		if provider, ok := providers[providerKeys[instanceKeyPattern.ReplaceAllString(rc.Address, "")]]; ok {
+			if location.Region == "" {
+				location.Region = provider.Region
+			}
+			if location.Account == "" {
+				location.Account = provider.Account
+			}
+		}
+
+		if location.Region == "" {
+			location.Region = unknownLocation
+		}
+		if location.Account == "" {
+			location.Account = unknownLocation
+		}
+		locations[rc.Address] = location
+	}
+	return locations
+}
+
+// fillLocationFromValues fills in missing location fields from a resource's
+// top-level arn/region attributes, then from ARNs anywhere in its values
+func fillLocationFromValues(location *resourceLocation, values interface{}) {
+	attributes, ok := values.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if arn, ok := attributes["arn"].(string); ok {
+		fillLocationFromARN(location, arn)
+	}
+	if region, ok := attributes["region"].(string); ok && location.Region == "" {
+		location.Region = region
+	}
+	walkStrings(attributes, func(value string) {
+		fillLocationFromARN(location, value)
+	})
+}
+
+func fillLocationFromARN(location *resourceLocation, arn string) {
+	match := arnPattern.FindStringSubmatch(arn)
+	if match == nil {
+		return
+	}
+	if location.Region == "" && match[1] != "" {
+		location.Region = match[1]
+	}
+	if location.Account == "" && match[2] != "" {
+		location.Account = match[2]
+	}
+}
+
+// walkStrings calls fn for every string in a decoded JSON value, in key order
+func walkStrings(value interface{}, fn func(string)) {
+	switch v := value.(type) {
+	case string:
+		fn(v)
+	case map[string]interface{}:
+		for _, key := range unionKeys(v, nil, nil) {
+			walkStrings(v[key], fn)
+		}
+	case []interface{}:
+		for _, item := range v {
+			walkStrings(item, fn)
+		}
+	}
+}
+
+// providerLocations reads constant region and allowed_account_ids values from
+// each provider configuration
+func providerLocations(config *Configuration) map[string]resourceLocation {
+	locations := make(map[string]resourceLocation)
+	if config == nil {
+		return locations
+	}
+
+	for key, provider := range config.ProviderConfig {
+		var location resourceLocation
+		if region, ok := constantValue(provider.Expressions, "region").(string); ok {
+			location.Region = region
+		}
+		if accounts, ok := constantValue(provider.Expressions, "allowed_account_ids").([]interface{}); ok && len(accounts) == 1 {
+			if account, ok := accounts[0].(string); ok {
+				location.Account = account
+			}
+		}
+		locations[key] = location
+	}
+	return locations
+}
+
+// constantValue returns the constant_value of a configuration expression, if any
+func constantValue(expressions map[string]interface{}, name string) interface{} {
+	expr, ok := expressions[name].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return expr["constant_value"]
+}
+
+// collectProviderConfigKeys maps each configured resource address to the
+// provider configuration it uses
+func collectProviderConfigKeys(module ConfigModule, prefix string, keys map[string]string) {
+	for _, resource := range module.Resources {
+		keys[prefix+resource.Address] = resource.ProviderConfigKey
+	}
+	for name, call := range module.ModuleCalls {
+		if call.Module != nil {
+			collectProviderConfigKeys(*call.Module, prefix+"module."+name+".", keys)
+		}
+	}
+}
+
+// groupByLocation groups a summary's changes by region and account
+func groupByLocation(plan *TerraformPlan, summary ResourceSummary) []*changeGroup {
+	locations := locateResources(plan)
+	return groupChanges(plan, summary, func(rc ResourceChange) string {
+		location := locations[rc.Address]
+		return location.Region + "\x00" + location.Account
+	})
+}
+
+func splitLocationKey(key string) (string, string) {
+	region, account, _ := strings.Cut(key, "\x00")
+	return region, account
+}
+
+// writeRegionSection renders a breakdown of changes by region and account
+func writeRegionSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary) {
+	groups := groupByLocation(plan, summary)
+	if len(groups) == 0 {
+		return
+	}
+
+	md.WriteString("### 🌍 Changes by Region and Account\n\n")
+	md.WriteString("| Region | Account | Changes |\n")
+	md.WriteString("|--------|---------|---------|\n")
+	for _, g := range groups {
+		region, account := splitLocationKey(g.Key)
+		md.WriteString(fmt.Sprintf("| %s | %s | %s |\n", region, account, g.formatCounts()))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentRegionSection renders the region breakdown inside a multi-plan environment section
+func writeEnvironmentRegionSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary) {
+	groups := groupByLocation(plan, summary)
+	if len(groups) == 0 {
+		return
+	}
+
+	entries := make([]string, len(groups))
+	for i, g := range groups {
+		region, account := splitLocationKey(g.Key)
+		entries[i] = fmt.Sprintf("%s / %s: %s", region, account, g.formatCounts())
+	}
+	md.WriteString(fmt.Sprintf("**🌍 By region / account:** %s\n\n", strings.Join(entries, "; ")))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLocateResources(t *testing.T) {
+	plan := &TerraformPlan{
+		Configuration: &Configuration{
+			ProviderConfig: map[string]ProviderConfig{
+				"aws": {Name: "aws", Expressions: map[string]interface{}{
+					"region":              map[string]interface{}{"constant_value": "us-east-1"},
+					"allowed_account_ids": map[string]interface{}{"constant_value": []interface{}{"111111111111"}},
+				}},
+				"aws.west": {Name: "aws", Alias: "west", Expressions: map[string]interface{}{
+					"region": map[string]interface{}{"constant_value": "us-west-2"},
+				}},
+			},
+			RootModule: ConfigModule{
+				Resources: []ConfigResource{
+					{Address: "aws_sqs_queue.east", ProviderConfigKey: "aws"},
+					{Address: "aws_sqs_queue.west", ProviderConfigKey: "aws.west"},
+				},
+			},
+		},
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_sqs_queue.east[0]", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_sqs_queue.west", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+			{
+				Address: "aws_iam_role.app",
+				Type:    "aws_iam_role",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"arn": "arn:aws:iam::222222222222:role/app"},
+					After:   map[string]interface{}{"arn": "arn:aws:iam::222222222222:role/app"},
+				},
+			},
+			{
+				Address: "aws_lambda_function.fn",
+				Type:    "aws_lambda_function",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"arn": "arn:aws:lambda:eu-west-1:333333333333:function:fn"},
+					After:   map[string]interface{}{"arn": "arn:aws:lambda:eu-west-1:333333333333:function:fn"},
+				},
+			},
+		},
+	}
+
+	locations := locateResources(plan)
+	expected := map[string]resourceLocation{
+		"aws_sqs_queue.east[0]":  {"us-east-1", "111111111111"},
+		"aws_sqs_queue.west":     {"us-west-2", unknownLocation},
+		"aws_iam_role.app":       {unknownLocation, "222222222222"},
+		"aws_lambda_function.fn": {"eu-west-1", "333333333333"},
+	}
+	for address, want := range expected {
+		if got := locations[address]; got != want {
+			t.Errorf("%s: expected %+v, got %+v", address, want, got)
+		}
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{ShowRegions: true})
+	if !strings.Contains(result, "| us-east-1 | 111111111111 | 1 create |") {
+		t.Errorf("Expected region breakdown, got:\n%s", result)
+	}
+}
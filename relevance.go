@@ -0,0 +1,128 @@
+package main
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// RelevantAttribute identifies a resource attribute that contributed to the plan
+type RelevantAttribute struct {
+	Resource  string        `json:"resource"`
+	Attribute []interface{} `json:"attribute"`
+}
+
+var instanceKeyPattern = regexp.MustCompile(`\[[^\]]*\]`)
+
+// findChangeDrivers maps each changing resource address to the upstream
+// resource attributes that caused the change. An attribute is a driver when it
+// is listed in relevant_attributes, its resource is itself changing, and the
+// downstream resource's configuration references it.
+func findChangeDrivers(plan *TerraformPlan) map[string][]string {
+	drivers := make(map[string][]string)
+	if plan.Configuration == nil || len(plan.RelevantAttributes) == 0 {
+		return drivers
+	}
+
+	// Upstream resources that are changing, keyed by their configuration address
+	changing := make(map[string]bool)
+	for _, change := range plan.ResourceChanges {
+		if !containsAction(change.Change.Actions, "no-op") && !containsAction(change.Change.Actions, "read") {
+			changing[instanceKeyPattern.ReplaceAllString(change.Address, "")] = true
+		}
+	}
+	for _, change := range plan.ResourceDrift {
+		changing[instanceKeyPattern.ReplaceAllString(change.Address, "")] = true
+	}
+
+	relevant := make(map[string]string)
+	for _, attr := range plan.RelevantAttributes {
+		resource := instanceKeyPattern.ReplaceAllString(attr.Resource, "")
+		if !changing[resource] {
+			continue
+		}
+		reference := resource + "." + formatAttributePath(attr.Attribute)
+		relevant[reference] = reference
+	}
+
+	references := collectConfigReferences(plan.Configuration.RootModule, "")
+
+	for _, change := range plan.ResourceChanges {
+		if containsAction(change.Change.Actions, "no-op") || containsAction(change.Change.Actions, "read") {
+			continue
+		}
+
+		seen := make(map[string]bool)
+		for _, reference := range references[instanceKeyPattern.ReplaceAllString(change.Address, "")] {
+			if driver, ok := relevant[reference]; ok && !seen[driver] {
+				seen[driver] = true
+				drivers[change.Address] = append(drivers[change.Address], driver)
+			}
+		}
+		sort.Strings(drivers[change.Address])
+	}
+
+	return drivers
+}
+
+// collectConfigReferences returns the absolute references made by each resource
+// in the module tree, keyed by the resource's configuration address
+func collectConfigReferences(module ConfigModule, prefix string) map[string][]string {
+	result := make(map[string][]string)
+
+	for _, resource := range module.Resources {
+		var refs []string
+		collectExpressionReferences(resource.Expressions, &refs)
+		address := prefix + resource.Address
+		for _, ref := range refs {
+			result[address] = append(result[address], prefix+ref)
+		}
+	}
+
+	for name, call := range module.ModuleCalls {
+		if call.Module == nil {
+			continue
+		}
+		for address, refs := range collectConfigReferences(*call.Module, prefix+"module."+name+".") {
+			result[address] = append(result[address], refs...)
+		}
+	}
+
+	return result
+}
+
+// collectExpressionReferences walks an expressions tree (including nested
+// blocks) and gathers every "references" entry
+func collectExpressionReferences(expr interface{}, refs *[]string) {
+	switch v := expr.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if key == "references" {
+				if list, ok := value.([]interface{}); ok {
+					for _, item := range list {
+						if ref, ok := item.(string); ok && !isLocalReference(ref) {
+							*refs = append(*refs, ref)
+						}
+					}
+				}
+				continue
+			}
+			collectExpressionReferences(value, refs)
+		}
+	case []interface{}:
+		for _, item := range v {
+			collectExpressionReferences(item, refs)
+		}
+	}
+}
+
+// isLocalReference reports whether a reference points at something other than
+// a resource attribute (variables, locals, iteration values, ...)
+func isLocalReference(ref string) bool {
+	for _, prefix := range []string{"var.", "local.", "each.", "count.", "path.", "self.", "terraform."} {
+		if strings.HasPrefix(ref, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFindChangeDrivers(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "module.net.aws_vpc.main",
+				Change:  Change{Actions: []string{"delete", "create"}},
+			},
+			{
+				Address: "module.net.aws_subnet.private[0]",
+				Change:  Change{Actions: []string{"delete", "create"}},
+			},
+			{
+				Address: "aws_instance.web",
+				Change:  Change{Actions: []string{"update"}},
+			},
+		},
+		RelevantAttributes: []RelevantAttribute{
+			{Resource: "module.net.aws_vpc.main", Attribute: []interface{}{"id"}},
+			{Resource: "aws_security_group.unchanged", Attribute: []interface{}{"id"}},
+		},
+		Configuration: &Configuration{
+			RootModule: ConfigModule{
+				Resources: []ConfigResource{
+					{
+						Address: "aws_instance.web",
+						Expressions: map[string]interface{}{
+							"vpc_security_group_ids": map[string]interface{}{
+								"references": []interface{}{"aws_security_group.unchanged.id", "var.extra"},
+							},
+						},
+					},
+				},
+				ModuleCalls: map[string]ModuleCall{
+					"net": {
+						Module: &ConfigModule{
+							Resources: []ConfigResource{
+								{
+									Address: "aws_subnet.private",
+									Expressions: map[string]interface{}{
+										"vpc_id": map[string]interface{}{
+											"references": []interface{}{"aws_vpc.main.id", "aws_vpc.main"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	drivers := findChangeDrivers(plan)
+	if got := drivers["module.net.aws_subnet.private[0]"]; len(got) != 1 || got[0] != "module.net.aws_vpc.main.id" {
+		t.Errorf("Expected subnet to be driven by the VPC id, got %v", got)
+	}
+	if got := drivers["aws_instance.web"]; len(got) != 0 {
+		t.Errorf("Expected no drivers from unchanged resources, got %v", got)
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(markdown, "**Changed because:** `module.net.aws_vpc.main.id` changed") {
+		t.Error("Expected change driver annotation in markdown")
+	}
+}
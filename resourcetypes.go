@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// groupByResourceType groups a summary's changes by resource type
+func groupByResourceType(plan *TerraformPlan, summary ResourceSummary) []*changeGroup {
+	return groupChanges(plan, summary, func(rc ResourceChange) string {
+		return rc.Type
+	})
+}
+
+// writeResourceTypeSection renders a rollup of changes by resource type. It is
+// skipped when every change is to the same type, as the summary table already
+// says everything the rollup would.
+func writeResourceTypeSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary, linkDocs bool) {
+	groups := groupByResourceType(plan, summary)
+	if len(groups) < 2 {
+		return
+	}
+	var links map[string]string
+	if linkDocs {
+		links = resourceTypeDocs(plan)
+	}
+
+	md.WriteString("### 🧩 Changes by Resource Type\n\n")
+	md.WriteString("| Resource Type | Create | Update | Replace | Delete |\n")
+	md.WriteString("|---------------|--------|--------|---------|--------|\n")
+	for _, g := range groups {
+		md.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", formatResourceType(g.Key, links),
+			formatCount(len(g.Create)), formatCount(len(g.Update)),
+			formatCount(len(g.Replace)), formatCount(len(g.Delete))))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentResourceTypeSection renders the resource type rollup inside a multi-plan environment section
+func writeEnvironmentResourceTypeSection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary, linkDocs bool) {
+	groups := groupByResourceType(plan, summary)
+	if len(groups) < 2 {
+		return
+	}
+	var links map[string]string
+	if linkDocs {
+		links = resourceTypeDocs(plan)
+	}
+
+	entries := make([]string, len(groups))
+	for i, g := range groups {
+		entries[i] = fmt.Sprintf("%s: %s", formatResourceType(g.Key, links), g.formatCounts())
+	}
+	md.WriteString(fmt.Sprintf("**🧩 By resource type:** %s\n\n", strings.Join(entries, "; ")))
+}
+
+// formatCount renders a table count, leaving zero cells blank so non-zero counts stand out
+func formatCount(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResourceTypeSection(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_iam_role.a", Type: "aws_iam_role", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_iam_role.b", Type: "aws_iam_role", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_iam_role.c", Type: "aws_iam_role", Change: Change{Actions: []string{"delete"}}},
+			{Address: "aws_s3_bucket.logs", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete", "create"}}},
+		},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	if !strings.Contains(result, "| `aws_iam_role` | 2 |  |  | 1 |\n| `aws_s3_bucket` |  |  | 1 |  |") {
+		t.Errorf("Expected resource type rollup, got:\n%s", result)
+	}
+	if strings.Index(result, "Changes by Resource Type") > strings.Index(result, "Resources to be Created") {
+		t.Error("Expected rollup above the detailed sections")
+	}
+
+	var md strings.Builder
+	writeEnvironmentResourceTypeSection(&md, plan, analyzePlan(plan), false)
+	if md.String() != "**🧩 By resource type:** `aws_iam_role`: 2 create, 1 delete; `aws_s3_bucket`: 1 replace\n\n" {
+		t.Errorf("Unexpected environment rollup: %q", md.String())
+	}
+
+	single := &TerraformPlan{ResourceChanges: plan.ResourceChanges[:2]}
+	if result := generateMarkdownComment(single, ReportOptions{}); strings.Contains(result, "Changes by Resource Type") {
+		t.Error("Expected rollup to be skipped for a single resource type")
+	}
+}
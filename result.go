@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Gates recorded in the run result
+const (
+	gateFailOn     = "fail_on"
+	gateThresholds = "thresholds"
+	gateNaming     = "naming"
+	gateTagPolicy  = "tag_policy"
+)
+
+// RunResult is the machine-readable outcome of a run written with
+// -result-file, so later pipeline steps can branch without parsing markdown
+type RunResult struct {
+	ExitCode     int                 `json:"exit_code"`
+	ExitReason   string              `json:"exit_reason"` // "success", "changes" or "gate_failed"
+	HasChanges   bool                `json:"has_changes"`
+	Environments []EnvironmentResult `json:"environments"`
+	Gates        []GateResult        `json:"gates"`
+	Outputs      []string            `json:"outputs"`
+}
+
+// EnvironmentResult holds one plan's change counts and risk
+type EnvironmentResult struct {
+	Environment string  `json:"environment"`
+	Create      int     `json:"create"`
+	Update      int     `json:"update"`
+	Replace     int     `json:"replace"`
+	Delete      int     `json:"delete"`
+	RiskScore   float64 `json:"risk_score"`
+	RiskLevel   string  `json:"risk_level"`
+}
+
+// GateResult is the verdict of one gate that was evaluated
+type GateResult struct {
+	Gate       string   `json:"gate"`
+	Passed     bool     `json:"passed"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// environmentResults summarizes the change counts and risk of each plan
+func environmentResults(plans []PlanInfo, opts ReportOptions) []EnvironmentResult {
+	results := make([]EnvironmentResult, 0, len(plans))
+	for _, planInfo := range plans {
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		risk := assessRisk(planInfo.Plan, summary, planInfo.RelativePath, opts.Risk)
+
+		env := planInfo.RelativePath
+		if env == "" {
+			env = planInfo.Plan.PlanPath
+		}
+		results = append(results, EnvironmentResult{
+			Environment: env,
+			Create:      len(summary.Create),
+			Update:      len(summary.Update),
+			Replace:     len(summary.Replace),
+			Delete:      len(summary.Delete),
+			RiskScore:   risk.Score,
+			RiskLevel:   risk.Level,
+		})
+	}
+	return results
+}
+
+// writeRunResult writes the run result as indented JSON
+func writeRunResult(filename string, result RunResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode run result: %w", err)
+	}
+	if err := os.WriteFile(filename, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write run result: %w", err)
+	}
+	return nil
+}
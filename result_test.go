@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteRunResult(t *testing.T) {
+	plans := []PlanInfo{{
+		RelativePath: "prod",
+		Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_s3_bucket.b", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete"}}},
+		}},
+	}}
+
+	environments := environmentResults(plans, ReportOptions{})
+	if len(environments) != 1 || environments[0].Environment != "prod" || environments[0].Create != 1 || environments[0].Delete != 1 {
+		t.Fatalf("Unexpected environment results: %+v", environments)
+	}
+	if environments[0].RiskLevel == "" {
+		t.Error("Expected a risk level")
+	}
+
+	filename := filepath.Join(t.TempDir(), "result.json")
+	result := RunResult{
+		ExitCode:     exitCodeGateFailed,
+		ExitReason:   "gate_failed",
+		HasChanges:   true,
+		Environments: environments,
+		Gates:        []GateResult{{Gate: gateFailOn, Violations: []string{"delete aws_s3_bucket.b"}}},
+		Outputs:      []string{"comment.md"},
+	}
+	if err := writeRunResult(filename, result); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded["exit_code"] != float64(3) || decoded["exit_reason"] != "gate_failed" {
+		t.Errorf("Unexpected exit fields: %s", data)
+	}
+	gates := decoded["gates"].([]interface{})
+	if gate := gates[0].(map[string]interface{}); gate["gate"] != "fail_on" || gate["passed"] != false {
+		t.Errorf("Unexpected gate verdict: %s", data)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+)
+
+// ReviewerRule requests reviews when a plan changes matching resource types
+// or environments, e.g. the DBA team for aws_db_* changes
+type ReviewerRule struct {
+	// ResourceTypes are resource type globs, e.g. "aws_db_*"
+	ResourceTypes []string `json:"resource_types"`
+	// Environments are environment path globs, e.g. "prod*"
+	Environments []string `json:"environments"`
+	// Users are usernames to request
+	Users []string `json:"users"`
+	// Teams are team slugs to request (GitHub and Gitea only)
+	Teams []string `json:"teams"`
+}
+
+func (r ReviewerRule) validate() error {
+	if len(r.ResourceTypes) == 0 && len(r.Environments) == 0 {
+		return fmt.Errorf("reviewer rules need resource_types or environments")
+	}
+	if len(r.Users) == 0 && len(r.Teams) == 0 {
+		return fmt.Errorf("reviewer rules need users or teams")
+	}
+	for _, pattern := range append(append([]string{}, r.ResourceTypes...), r.Environments...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid reviewer pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a changed resource type in an environment
+func (r ReviewerRule) matches(resourceType, environment string) bool {
+	for _, pattern := range r.ResourceTypes {
+		if ok, _ := path.Match(pattern, resourceType); ok {
+			return true
+		}
+	}
+	for _, pattern := range r.Environments {
+		if ok, _ := path.Match(pattern, environment); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedReviewers returns the sorted users and teams of the rules matched
+// by any changed resource across plans
+func requestedReviewers(rules []ReviewerRule, plans []PlanInfo) ([]string, []string) {
+	users := make(map[string]bool)
+	teams := make(map[string]bool)
+	for _, rule := range rules {
+		if !ruleMatchesPlans(rule, plans) {
+			continue
+		}
+		for _, user := range rule.Users {
+			users[user] = true
+		}
+		for _, team := range rule.Teams {
+			teams[team] = true
+		}
+	}
+	return sortedKeys(users), sortedKeys(teams)
+}
+
+func ruleMatchesPlans(rule ReviewerRule, plans []PlanInfo) bool {
+	for _, planInfo := range plans {
+		for _, rc := range planInfo.Plan.ResourceChanges {
+			if action := planAction(rc.Change.Actions); action == "no-op" || action == "read" {
+				continue
+			}
+			if rule.matches(rc.Type, planInfo.RelativePath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requestMergeRequestReviewers adds users to a merge request's reviewers,
+// keeping the existing ones. GitLab has no team reviewers, so teams are not
+// supported here.
+func (c *gitlabClient) requestMergeRequestReviewers(project string, iid int, usernames []string) error {
+	path := fmt.Sprintf("%s/merge_requests/%d", projectPath(project), iid)
+	data, err := c.do(http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	var mergeRequest struct {
+		Reviewers []struct {
+			ID int `json:"id"`
+		} `json:"reviewers"`
+	}
+	if err := json.Unmarshal(data, &mergeRequest); err != nil {
+		return fmt.Errorf("failed to decode GitLab API response: %w", err)
+	}
+
+	ids := make([]int, 0, len(mergeRequest.Reviewers)+len(usernames))
+	existing := make(map[int]bool)
+	for _, reviewer := range mergeRequest.Reviewers {
+		existing[reviewer.ID] = true
+		ids = append(ids, reviewer.ID)
+	}
+	for _, username := range usernames {
+		data, err := c.do(http.MethodGet, "/users?username="+url.QueryEscape(username), nil)
+		if err != nil {
+			return err
+		}
+		var users []struct {
+			ID int `json:"id"`
+		}
+		if err := json.Unmarshal(data, &users); err != nil {
+			return fmt.Errorf("failed to decode GitLab API response: %w", err)
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("GitLab user %q not found", username)
+		}
+		if !existing[users[0].ID] {
+			existing[users[0].ID] = true
+			ids = append(ids, users[0].ID)
+		}
+	}
+
+	_, err = c.do(http.MethodPut, path, map[string][]int{"reviewer_ids": ids})
+	return err
+}
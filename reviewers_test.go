@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestedReviewers(t *testing.T) {
+	rules := []ReviewerRule{
+		{ResourceTypes: []string{"aws_db_*", "aws_rds_*"}, Teams: []string{"dba"}},
+		{Environments: []string{"prod*"}, Users: []string{"oncall", "lead"}, Teams: []string{"sre"}},
+		{ResourceTypes: []string{"aws_iam_*"}, Users: []string{"security"}},
+	}
+	plans := []PlanInfo{
+		{RelativePath: "staging", Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"update"}}},
+			{Address: "aws_iam_role.app", Type: "aws_iam_role", Change: Change{Actions: []string{"no-op"}}},
+		}}},
+		{RelativePath: "production", Plan: &TerraformPlan{ResourceChanges: []ResourceChange{
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		}}},
+	}
+
+	users, teams := requestedReviewers(rules, plans)
+	if strings.Join(users, ",") != "lead,oncall" || strings.Join(teams, ",") != "dba,sre" {
+		t.Errorf("Unexpected reviewers %v %v", users, teams)
+	}
+	if users, teams := requestedReviewers(rules, nil); len(users)+len(teams) != 0 {
+		t.Errorf("Expected no reviewers without plans, got %v %v", users, teams)
+	}
+
+	if err := (ReviewerRule{Users: []string{"a"}}).validate(); err == nil {
+		t.Error("Expected an error for a rule without patterns")
+	}
+	if err := (ReviewerRule{ResourceTypes: []string{"aws_*"}}).validate(); err == nil {
+		t.Error("Expected an error for a rule without reviewers")
+	}
+	if err := (ReviewerRule{ResourceTypes: []string{"["}, Users: []string{"a"}}).validate(); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestRequestMergeRequestReviewers(t *testing.T) {
+	var reviewerIDs []int
+	gitlab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/projects/42/merge_requests/5" && r.Method == http.MethodGet:
+			io.WriteString(w, `{"reviewers": [{"id": 7}]}`)
+		case r.URL.Path == "/projects/42/merge_requests/5" && r.Method == http.MethodPut:
+			var body map[string][]int
+			json.NewDecoder(r.Body).Decode(&body)
+			reviewerIDs = body["reviewer_ids"]
+			io.WriteString(w, `{}`)
+		case r.URL.Path == "/users" && r.URL.Query().Get("username") == "dba":
+			io.WriteString(w, `[{"id": 9}]`)
+		case r.URL.Path == "/users" && r.URL.Query().Get("username") == "lead":
+			io.WriteString(w, `[{"id": 7}]`)
+		case r.URL.Path == "/users":
+			io.WriteString(w, `[]`)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer gitlab.Close()
+	t.Setenv("GITLAB_API_URL", gitlab.URL)
+
+	client := newGitLabClient("secret")
+	if err := client.requestMergeRequestReviewers("42", 5, []string{"dba", "lead"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(reviewerIDs) != 2 || reviewerIDs[0] != 7 || reviewerIDs[1] != 9 {
+		t.Errorf("Expected existing reviewers to be kept, got %v", reviewerIDs)
+	}
+	if err := client.requestMergeRequestReviewers("42", 5, []string{"nobody"}); err == nil {
+		t.Error("Expected an error for an unknown user")
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RiskConfig configures how plans are scored, under "risk" in the -config file.
+// Fields left out of the file keep their defaults.
+type RiskConfig struct {
+	Weights        RiskWeights    `json:"weights"`
+	SensitiveTypes []string       `json:"sensitive_types"` // Resource type globs that hold data or identity
+	IAMTypes       []string       `json:"iam_types"`       // Resource type globs that grant access
+	ProdPattern    string         `json:"prod_pattern"`    // Regex matched against the environment path
+	Thresholds     RiskThresholds `json:"thresholds"`
+}
+
+// RiskWeights are the points each kind of change adds to a risk score
+type RiskWeights struct {
+	Create        float64 `json:"create"`
+	Update        float64 `json:"update"`
+	Replace       float64 `json:"replace"`
+	Delete        float64 `json:"delete"`
+	SensitiveType float64 `json:"sensitive_type"`
+	IAM           float64 `json:"iam"`
+	// ProtectionRemoved is added for each deletion protection flag switched off
+	ProtectionRemoved float64 `json:"protection_removed"`
+	ProdMultiplier    float64 `json:"prod_multiplier"`
+}
+
+// RiskThresholds are the minimum scores for the MEDIUM and HIGH risk levels
+type RiskThresholds struct {
+	Medium float64 `json:"medium"`
+	High   float64 `json:"high"`
+}
+
+// RiskModel is a compiled RiskConfig
+type RiskModel struct {
+	config      RiskConfig
+	prodPattern *regexp.Regexp
+}
+
+// Risk levels
+const (
+	riskNone   = "NONE"
+	riskLow    = "LOW"
+	riskMedium = "MEDIUM"
+	riskHigh   = "HIGH"
+)
+
+var riskEmojis = map[string]string{
+	riskNone:   "✅",
+	riskLow:    "🟢",
+	riskMedium: "🟠",
+	riskHigh:   "🔥",
+}
+
+// riskLevelOrder ranks risk levels from least to most severe
+var riskLevelOrder = map[string]int{
+	riskNone:   0,
+	riskLow:    1,
+	riskMedium: 2,
+	riskHigh:   3,
+}
+
+func defaultRiskConfig() RiskConfig {
+	return RiskConfig{
+		Weights: RiskWeights{
+			Create:         1,
+			Update:         2,
+			Replace:        8,
+			Delete:         10,
+			SensitiveType:  5,
+			IAM:            5,
+			ProdMultiplier: 2,
+
+			ProtectionRemoved: 30,
+		},
+		SensitiveTypes: []string{
+			"aws_db_instance", "aws_rds_cluster*", "aws_dynamodb_table", "aws_s3_bucket",
+			"aws_kms_key", "aws_route53_zone", "aws_efs_file_system", "aws_elasticache_*",
+			"google_sql_database_instance", "google_storage_bucket", "google_kms_*",
+			"azurerm_*sql*", "azurerm_storage_account", "azurerm_key_vault*",
+		},
+		IAMTypes: []string{
+			"aws_iam_*", "aws_organizations_policy*", "aws_kms_grant",
+			"google_*_iam_*", "google_service_account*",
+			"azurerm_role_*", "azuread_*",
+		},
+		ProdPattern: `(?i)(^|[/_.-])(prod|production|prd)([/_.-]|$)`,
+		Thresholds:  RiskThresholds{Medium: 10, High: 30},
+	}
+}
+
+// riskModel compiles the risk configuration
+func (c RiskConfig) riskModel() (*RiskModel, error) {
+	model := &RiskModel{config: c}
+	if c.ProdPattern != "" {
+		re, err := regexp.Compile(c.ProdPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid risk prod_pattern: %w", err)
+		}
+		model.prodPattern = re
+	}
+	for _, patterns := range [][]string{c.SensitiveTypes, c.IAMTypes} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid risk resource type pattern %q: %w", pattern, err)
+			}
+		}
+	}
+	return model, nil
+}
+
+// defaultRiskModel is used when no configuration is given
+func defaultRiskModel() *RiskModel {
+	model, _ := defaultRiskConfig().riskModel()
+	return model
+}
+
+// RiskAssessment is the scored risk of a plan
+type RiskAssessment struct {
+	Score   float64
+	Level   string
+	Factors []string // What contributed to the score, e.g. "2 deletions"
+}
+
+// assessRisk scores a plan's changes. envPath is matched against the
+// production pattern to apply the production multiplier.
+func assessRisk(plan *TerraformPlan, summary ResourceSummary, envPath string, model *RiskModel) RiskAssessment {
+	if model == nil {
+		model = defaultRiskModel()
+	}
+	weights := model.config.Weights
+
+	types := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		types[rc.Address] = rc.Type
+	}
+
+	var score float64
+	var sensitive, iam int
+	for _, group := range []struct {
+		resources []ResourceDetail
+		weight    float64
+	}{
+		{summary.Create, weights.Create},
+		{summary.Update, weights.Update},
+		{summary.Replace, weights.Replace},
+		{summary.Delete, weights.Delete},
+	} {
+		for _, resource := range group.resources {
+			score += group.weight
+			if matchesTypePattern(model.config.SensitiveTypes, types[resource.Address]) {
+				score += weights.SensitiveType
+				sensitive++
+			}
+			if matchesTypePattern(model.config.IAMTypes, types[resource.Address]) {
+				score += weights.IAM
+				iam++
+			}
+		}
+	}
+
+	var factors []string
+	if n := len(summary.Delete); n > 0 {
+		factors = append(factors, pluralize(n, "deletion", "deletions"))
+	}
+	if n := len(summary.Replace); n > 0 {
+		factors = append(factors, pluralize(n, "replacement", "replacements"))
+	}
+	if sensitive > 0 {
+		factors = append(factors, pluralize(sensitive, "sensitive resource change", "sensitive resource changes"))
+	}
+	if iam > 0 {
+		factors = append(factors, pluralize(iam, "IAM change", "IAM changes"))
+	}
+	if removed := countProtectionRemovals(plan); removed > 0 {
+		score += float64(removed) * weights.ProtectionRemoved
+		factors = append(factors, pluralize(removed, "deletion protection removal", "deletion protection removals"))
+	}
+	if score > 0 && model.prodPattern != nil && model.prodPattern.MatchString(envPath) {
+		score *= weights.ProdMultiplier
+		factors = append(factors, "production environment")
+	}
+
+	return RiskAssessment{Score: score, Level: model.level(score), Factors: factors}
+}
+
+func (m *RiskModel) level(score float64) string {
+	switch {
+	case score <= 0:
+		return riskNone
+	case score >= m.config.Thresholds.High:
+		return riskHigh
+	case score >= m.config.Thresholds.Medium:
+		return riskMedium
+	default:
+		return riskLow
+	}
+}
+
+func matchesTypePattern(patterns []string, resourceType string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, resourceType); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
+}
+
+// formatRisk renders an assessment, e.g. "**Risk: HIGH 🔥** (score 42: 2 deletions, production environment)"
+func formatRisk(risk RiskAssessment) string {
+	label := fmt.Sprintf("**Risk: %s %s** (score %.0f", risk.Level, riskEmojis[risk.Level], risk.Score)
+	if len(risk.Factors) > 0 {
+		label += ": " + strings.Join(risk.Factors, ", ")
+	}
+	return label + ")"
+}
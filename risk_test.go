@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAssessRisk(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_db_instance.main", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+			{Address: "aws_iam_role.app", Type: "aws_iam_role", Change: Change{Actions: []string{"update"}}},
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+	summary := analyzeResourceChanges(plan.ResourceChanges)
+
+	// delete 10 + sensitive 5, update 2 + IAM 5, create 1
+	risk := assessRisk(plan, summary, "staging", nil)
+	if risk.Score != 23 || risk.Level != riskMedium {
+		t.Errorf("Expected MEDIUM risk with score 23, got %+v", risk)
+	}
+	if formatted := formatRisk(risk); formatted != "**Risk: MEDIUM 🟠** (score 23: 1 deletion, 1 sensitive resource change, 1 IAM change)" {
+		t.Errorf("Unexpected formatted risk: %s", formatted)
+	}
+
+	prod := assessRisk(plan, summary, "env/prod", nil)
+	if prod.Score != 46 || prod.Level != riskHigh {
+		t.Errorf("Expected production multiplier to raise risk, got %+v", prod)
+	}
+
+	if none := assessRisk(&TerraformPlan{}, ResourceSummary{}, "prod", nil); none.Level != riskNone {
+		t.Errorf("Expected no risk for empty plan, got %+v", none)
+	}
+}
+
+func TestRiskConfigOverrides(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"risk": {"weights": {"create": 50}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Risk.Weights.Delete != 10 || config.Risk.Thresholds.High != 30 {
+		t.Errorf("Expected unspecified risk settings to keep defaults, got %+v", config.Risk)
+	}
+
+	model, err := config.Risk.riskModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+	result := generateMarkdownComment(plan, ReportOptions{Risk: model})
+	if !strings.Contains(result, "**Risk: HIGH 🔥** (score 50)") {
+		t.Errorf("Expected configured weight to be used, got:\n%s", result)
+	}
+}
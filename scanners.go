@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CheckovReport is the JSON output of `checkov -o json` for one framework
+type CheckovReport struct {
+	CheckType string `json:"check_type"`
+	Results   struct {
+		FailedChecks []CheckovCheck `json:"failed_checks"`
+	} `json:"results"`
+}
+
+// CheckovCheck is a single failed Checkov check
+type CheckovCheck struct {
+	CheckID         string `json:"check_id"`
+	CheckName       string `json:"check_name"`
+	Resource        string `json:"resource"`
+	ResourceAddress string `json:"resource_address"`
+	FilePath        string `json:"file_path"`
+	Severity        string `json:"severity"`
+}
+
+// TfsecReport is the JSON output of `tfsec --format json`
+type TfsecReport struct {
+	Results []struct {
+		RuleID      string `json:"rule_id"`
+		LongID      string `json:"long_id"`
+		Description string `json:"description"`
+		Severity    string `json:"severity"`
+		Resource    string `json:"resource"`
+		Location    struct {
+			Filename string `json:"filename"`
+		} `json:"location"`
+	} `json:"results"`
+}
+
+// TrivyReport is the JSON output of `trivy config --format json`
+type TrivyReport struct {
+	Results []struct {
+		Target            string `json:"Target"`
+		Misconfigurations []struct {
+			ID            string `json:"ID"`
+			Title         string `json:"Title"`
+			Message       string `json:"Message"`
+			Severity      string `json:"Severity"`
+			Status        string `json:"Status"`
+			CauseMetadata struct {
+				Resource string `json:"Resource"`
+			} `json:"CauseMetadata"`
+		} `json:"Misconfigurations"`
+	} `json:"Results"`
+}
+
+// readScanResults reads a Checkov, tfsec or Trivy JSON report, detecting the
+// format from its top-level structure
+func readScanResults(filename string) ([]ExternalFinding, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan results: %w", err)
+	}
+
+	// Checkov writes an array when several frameworks were scanned
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		var reports []CheckovReport
+		if err := json.Unmarshal(data, &reports); err != nil {
+			return nil, fmt.Errorf("failed to parse Checkov JSON: %w", err)
+		}
+		return convertCheckovReports(reports), nil
+	}
+
+	// Field names are matched exactly here, as json.Unmarshal ignores case
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse scan results: %w", err)
+	}
+
+	switch {
+	case keys["check_type"] != nil:
+		var report CheckovReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse Checkov JSON: %w", err)
+		}
+		return convertCheckovReports([]CheckovReport{report}), nil
+	case keys["Results"] != nil || keys["SchemaVersion"] != nil:
+		var report TrivyReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse Trivy JSON: %w", err)
+		}
+		return convertTrivyReport(report), nil
+	case keys["results"] != nil:
+		var report TfsecReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			return nil, fmt.Errorf("failed to parse tfsec JSON: %w", err)
+		}
+		return convertTfsecReport(report), nil
+	default:
+		return nil, fmt.Errorf("unrecognized scan results format in %s", filename)
+	}
+}
+
+// severityLevel maps scanner severities to finding levels. Checks without a
+// severity are treated as failures, as the scanner reported them failed.
+func severityLevel(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "LOW", "MEDIUM", "INFO", "UNKNOWN":
+		return findingWarning
+	default:
+		return findingFailure
+	}
+}
+
+// scannedPlanFile returns the reported file when the scanner was run against
+// a plan JSON rather than the .tf sources, which apply to every plan
+func scannedPlanFile(filename string) string {
+	if strings.HasSuffix(filename, ".json") {
+		return filename
+	}
+	return ""
+}
+
+func convertCheckovReports(reports []CheckovReport) []ExternalFinding {
+	var findings []ExternalFinding
+	for _, report := range reports {
+		for _, check := range report.Results.FailedChecks {
+			address := check.ResourceAddress
+			if address == "" {
+				address = check.Resource
+			}
+			findings = append(findings, ExternalFinding{
+				Tool:    "checkov",
+				Level:   severityLevel(check.Severity),
+				RuleID:  check.CheckID,
+				Message: check.CheckName,
+				Address: address,
+				File:    scannedPlanFile(check.FilePath),
+			})
+		}
+	}
+	return findings
+}
+
+func convertTfsecReport(report TfsecReport) []ExternalFinding {
+	var findings []ExternalFinding
+	for _, result := range report.Results {
+		ruleID := result.LongID
+		if ruleID == "" {
+			ruleID = result.RuleID
+		}
+		findings = append(findings, ExternalFinding{
+			Tool:    "tfsec",
+			Level:   severityLevel(result.Severity),
+			RuleID:  ruleID,
+			Message: result.Description,
+			Address: result.Resource,
+			File:    scannedPlanFile(result.Location.Filename),
+		})
+	}
+	return findings
+}
+
+func convertTrivyReport(report TrivyReport) []ExternalFinding {
+	var findings []ExternalFinding
+	for _, result := range report.Results {
+		for _, misconfig := range result.Misconfigurations {
+			if misconfig.Status != "" && misconfig.Status != "FAIL" {
+				continue
+			}
+			message := misconfig.Message
+			if message == "" {
+				message = misconfig.Title
+			}
+			findings = append(findings, ExternalFinding{
+				Tool:    "trivy",
+				Level:   severityLevel(misconfig.Severity),
+				RuleID:  misconfig.ID,
+				Message: message,
+				Address: misconfig.CauseMetadata.Resource,
+				File:    scannedPlanFile(result.Target),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadScanResults(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected []ExternalFinding
+	}{
+		{
+			name: "checkov",
+			data: `{"check_type": "terraform_plan", "results": {"failed_checks": [
+				{"check_id": "CKV_AWS_18", "check_name": "Ensure S3 access logging", "resource": "aws_s3_bucket.logs", "file_path": "/plan.json", "severity": null}
+			]}}`,
+			expected: []ExternalFinding{
+				{Tool: "checkov", Level: findingFailure, RuleID: "CKV_AWS_18", Message: "Ensure S3 access logging", Address: "aws_s3_bucket.logs", File: "/plan.json"},
+			},
+		},
+		{
+			name: "checkov multiple frameworks",
+			data: `[{"check_type": "terraform", "results": {"failed_checks": [
+				{"check_id": "CKV_AWS_8", "check_name": "Encrypt EBS", "resource": "aws_instance.web", "resource_address": "module.app.aws_instance.web", "file_path": "/main.tf", "severity": "LOW"}
+			]}}]`,
+			expected: []ExternalFinding{
+				{Tool: "checkov", Level: findingWarning, RuleID: "CKV_AWS_8", Message: "Encrypt EBS", Address: "module.app.aws_instance.web"},
+			},
+		},
+		{
+			name: "tfsec",
+			data: `{"results": [{"rule_id": "AVD-AWS-0088", "long_id": "aws-s3-enable-bucket-encryption", "description": "Bucket does not have encryption enabled", "severity": "HIGH", "resource": "aws_s3_bucket.logs", "location": {"filename": "/src/main.tf"}}]}`,
+			expected: []ExternalFinding{
+				{Tool: "tfsec", Level: findingFailure, RuleID: "aws-s3-enable-bucket-encryption", Message: "Bucket does not have encryption enabled", Address: "aws_s3_bucket.logs"},
+			},
+		},
+		{
+			name: "trivy",
+			data: `{"SchemaVersion": 2, "Results": [{"Target": "main.tf", "Misconfigurations": [
+				{"ID": "AVD-AWS-0107", "Title": "Ingress open", "Message": "Security group rule allows ingress from public internet.", "Severity": "CRITICAL", "Status": "FAIL", "CauseMetadata": {"Resource": "aws_security_group.web"}},
+				{"ID": "AVD-AWS-0099", "Title": "Description", "Severity": "LOW", "Status": "PASS"}
+			]}]}`,
+			expected: []ExternalFinding{
+				{Tool: "trivy", Level: findingFailure, RuleID: "AVD-AWS-0107", Message: "Security group rule allows ingress from public internet.", Address: "aws_security_group.web"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "scan.json")
+			if err := os.WriteFile(file, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			findings, err := readScanResults(file)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(findings) != len(tt.expected) {
+				t.Fatalf("Expected %+v, got %+v", tt.expected, findings)
+			}
+			for i := range findings {
+				if findings[i] != tt.expected[i] {
+					t.Errorf("Finding %d: expected %+v, got %+v", i, tt.expected[i], findings[i])
+				}
+			}
+		})
+	}
+}
+
+func TestReadScanResultsUnknownFormat(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "scan.json")
+	if err := os.WriteFile(file, []byte(`{"issues": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readScanResults(file); err == nil {
+		t.Error("Expected error for unrecognized format")
+	}
+}
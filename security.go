@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultSecurityTypes are the resource type globs whose changes are pulled
+// into the security-relevant changes section
+var defaultSecurityTypes = []string{
+	"aws_iam_*", "aws_security_group*", "aws_vpc_security_group_*", "aws_network_acl*",
+	"aws_kms_*", "aws_s3_bucket_policy", "aws_s3_bucket_acl", "aws_s3_bucket_public_access_block",
+	"aws_wafv2_*", "aws_networkfirewall_*", "aws_organizations_policy*",
+	"google_compute_firewall*", "google_*_iam_*", "google_kms_*", "google_service_account*",
+	"azurerm_network_security_*", "azurerm_firewall*", "azurerm_role_*", "azurerm_key_vault_access_policy",
+}
+
+// SecurityConfig configures security highlighting, under "security" in the -config file
+type SecurityConfig struct {
+	// Types replaces the default list of security-relevant resource type globs
+	Types []string `json:"types"`
+}
+
+// securityFinding is a specific risky change spotted by a security detector
+type securityFinding struct {
+	Address string
+	Title   string // e.g. "World-open ingress"
+	Detail  string
+}
+
+// securityDetectors inspect a single resource change for risky patterns
+var securityDetectors = []func(rc ResourceChange) []securityFinding{
+	detectWorldOpenIngress,
+	detectPublicExposure,
+	detectProtectionRemoval,
+}
+
+// findSecurityFindings runs every detector over the plan's resource changes
+func findSecurityFindings(plan *TerraformPlan) []securityFinding {
+	var findings []securityFinding
+	for _, rc := range plan.ResourceChanges {
+		action := planAction(rc.Change.Actions)
+		if action == "no-op" || action == "read" || action == "delete" {
+			continue
+		}
+		for _, detect := range securityDetectors {
+			findings = append(findings, detect(rc)...)
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Address < findings[j].Address
+	})
+	return findings
+}
+
+func formatSecurityFinding(finding securityFinding) string {
+	return fmt.Sprintf("> ⚠️ **%s:** `%s` %s", finding.Title, finding.Address, finding.Detail)
+}
+
+// securityRelevantSummary keeps only the changes to security-relevant resource types
+func securityRelevantSummary(plan *TerraformPlan, summary ResourceSummary, types []string) ResourceSummary {
+	if types == nil {
+		types = defaultSecurityTypes
+	}
+
+	relevant := make(map[string]bool)
+	for _, rc := range plan.ResourceChanges {
+		if matchesTypePattern(types, rc.Type) {
+			relevant[rc.Address] = true
+		}
+	}
+
+	filter := func(resources []ResourceDetail) []ResourceDetail {
+		var kept []ResourceDetail
+		for _, resource := range resources {
+			if relevant[resource.Address] {
+				kept = append(kept, resource)
+			}
+		}
+		return kept
+	}
+	return ResourceSummary{
+		Create:  filter(summary.Create),
+		Update:  filter(summary.Update),
+		Replace: filter(summary.Replace),
+		Delete:  filter(summary.Delete),
+	}
+}
+
+// writeSecuritySection renders security-relevant changes regardless of action
+func writeSecuritySection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary, opts ReportOptions) {
+	groups := groupChanges(plan, securityRelevantSummary(plan, summary, opts.SecurityTypes), func(ResourceChange) string {
+		return ""
+	})
+	findings := findSecurityFindings(plan)
+	if len(groups) == 0 && len(findings) == 0 {
+		return
+	}
+
+	md.WriteString("### ⚠️ Security-relevant Changes\n\n")
+	if len(findings) > 0 {
+		// Findings are separated by an empty quoted line so each renders as its own paragraph
+		quoted := make([]string, len(findings))
+		for i, finding := range findings {
+			quoted[i] = formatSecurityFinding(finding)
+		}
+		md.WriteString(strings.Join(quoted, "\n>\n") + "\n\n")
+	}
+	if len(groups) == 0 {
+		return
+	}
+	for _, line := range formatGroupedResourceLines(groups[0]) {
+		md.WriteString(line + "\n")
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentSecuritySection renders security-relevant changes inside a multi-plan environment section
+func writeEnvironmentSecuritySection(md *strings.Builder, plan *TerraformPlan, summary ResourceSummary, opts ReportOptions) {
+	groups := groupChanges(plan, securityRelevantSummary(plan, summary, opts.SecurityTypes), func(ResourceChange) string {
+		return ""
+	})
+	findings := findSecurityFindings(plan)
+	if len(groups) == 0 && len(findings) == 0 {
+		return
+	}
+
+	md.WriteString("**⚠️ Security-relevant changes:**\n")
+	for _, finding := range findings {
+		md.WriteString(fmt.Sprintf("- ⚠️ **%s:** `%s` %s\n", finding.Title, finding.Address, finding.Detail))
+	}
+	if len(groups) == 0 {
+		md.WriteString("\n")
+		return
+	}
+	for _, line := range formatGroupedResourceLines(groups[0]) {
+		md.WriteString(line + "\n")
+	}
+	md.WriteString("\n")
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSecuritySection(t *testing.T) {
+	plan := &TerraformPlan{
+		TerraformVersion: "1.9.8",
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_iam_role.app", Type: "aws_iam_role", Change: Change{Actions: []string{"delete"}}},
+			{
+				Address: "aws_security_group.web",
+				Type:    "aws_security_group",
+				Change: Change{
+					Actions: []string{"update"},
+					Before:  map[string]interface{}{"description": "old"},
+					After:   map[string]interface{}{"description": "new"},
+				},
+			},
+			{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{})
+	expected := "### ⚠️ Security-relevant Changes\n\n" +
+		"- 🟡 `aws_security_group.web` - description\n" +
+		"- 🔴 `aws_iam_role.app` - Resource marked for deletion\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected security section, got:\n%s", result)
+	}
+
+	result = generateMarkdownComment(plan, ReportOptions{SecurityTypes: []string{"aws_sqs_*"}})
+	if !strings.Contains(result, "### ⚠️ Security-relevant Changes\n\n- 🟢 `aws_sqs_queue.q`\n\n") {
+		t.Errorf("Expected configured security types to be used, got:\n%s", result)
+	}
+}
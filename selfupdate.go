@@ -0,0 +1,259 @@
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Release layout published by GoReleaser (see .goreleaser.yml)
+const (
+	releaseRepository = "akomic/go-tfplan-commenter"
+	binaryName        = "tfplan-commenter"
+	checksumsAsset    = "checksums.txt"
+	signatureSuffix   = ".sig"
+)
+
+// GitHubRelease is the subset of the GitHub release API used by self-update
+type GitHubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// assetURL returns the download URL of a named release asset
+func (r GitHubRelease) assetURL(name string) string {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// releaseAssetNames returns the candidate assets for a platform: the
+// GoReleaser archive first, then the plain binary built by `make release`
+func releaseAssetNames(goos, goarch string) []string {
+	arch := goarch
+	if goarch == "amd64" {
+		arch = "x86_64"
+	}
+	return []string{
+		fmt.Sprintf("%s_%s%s_%s.tar.gz", binaryName, strings.ToUpper(goos[:1]), goos[1:], arch),
+		fmt.Sprintf("%s-%s-%s", binaryName, goos, goarch),
+	}
+}
+
+// isCurrentVersion reports whether a release tag is the running version
+func isCurrentVersion(tag string) bool {
+	return strings.TrimPrefix(tag, "v") == strings.TrimPrefix(Version, "v")
+}
+
+// releaseChecksum looks up an asset's SHA-256 in a sha256sum-style checksums file
+func releaseChecksum(checksums []byte, name string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("%s has no checksum for %s", checksumsAsset, name)
+}
+
+// extractBinary returns the tfplan-commenter executable from a release archive
+func extractBinary(archive []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid release archive: %w", err)
+	}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("release archive has no %s binary", binaryName)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid release archive: %w", err)
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == binaryName {
+			return io.ReadAll(reader)
+		}
+	}
+}
+
+// fetchRelease looks up the latest release, or the release with the given tag
+func fetchRelease(client *githubClient, tag string) (*GitHubRelease, error) {
+	path := "/repos/" + releaseRepository + "/releases/latest"
+	if tag != "" {
+		path = "/repos/" + releaseRepository + "/releases/tags/" + tag
+	}
+	var release GitHubRelease
+	if err := client.do(http.MethodGet, path, nil, &release); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// downloadVerifiedBinary downloads the release binary for this platform and
+// verifies it against the release checksums, whose signature is checked
+// first. Without a public key it refuses unless skipSignature is set: checksums
+// alone come from the same place as the binary and prove nothing.
+func downloadVerifiedBinary(client *githubClient, release *GitHubRelease, key crypto.PublicKey, skipSignature bool) ([]byte, error) {
+	if key == nil && !skipSignature {
+		return nil, fmt.Errorf("no public key to verify %s%s; pass -key, or -insecure-skip-signature to trust the checksums alone", checksumsAsset, signatureSuffix)
+	}
+	checksumsURL := release.assetURL(checksumsAsset)
+	if checksumsURL == "" {
+		return nil, fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, checksumsAsset)
+	}
+	checksums, err := client.download(checksumsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if key != nil {
+		signatureURL := release.assetURL(checksumsAsset + signatureSuffix)
+		if signatureURL == "" {
+			return nil, fmt.Errorf("release %s has no %s%s", release.TagName, checksumsAsset, signatureSuffix)
+		}
+		signature, err := client.download(signatureURL)
+		if err != nil {
+			return nil, err
+		}
+		// cosign sign-blob writes the signature base64-encoded
+		if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature))); err == nil {
+			signature = decoded
+		}
+		verified, err := verifyMessage(key, checksums, signature)
+		if err != nil {
+			return nil, err
+		}
+		if !verified {
+			return nil, fmt.Errorf("%s signature does not match the public key", checksumsAsset)
+		}
+	}
+
+	for _, name := range releaseAssetNames(runtime.GOOS, runtime.GOARCH) {
+		url := release.assetURL(name)
+		if url == "" {
+			continue
+		}
+		expected, err := releaseChecksum(checksums, name)
+		if err != nil {
+			return nil, err
+		}
+		data, err := client.download(url)
+		if err != nil {
+			return nil, err
+		}
+		digest := sha256.Sum256(data)
+		if hex.EncodeToString(digest[:]) != expected {
+			return nil, fmt.Errorf("checksum mismatch for %s", name)
+		}
+		if strings.HasSuffix(name, ".tar.gz") {
+			return extractBinary(data)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+}
+
+// replaceBinary atomically swaps the executable at path for the new binary
+func replaceBinary(path string, binary []byte) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+binaryName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file next to %s: %w", path, err)
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(binary); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write new binary: %w", err)
+	}
+	if err := os.Chmod(temp.Name(), 0755); err != nil {
+		return fmt.Errorf("failed to make new binary executable: %w", err)
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+// runSelfUpdateCommand implements `tfplan-commenter self-update [-check] [-version tag] [-key file | -insecure-skip-signature]`
+func runSelfUpdateCommand(args []string) {
+	flags := flag.NewFlagSet("self-update", flag.ExitOnError)
+	checkOnly := flags.Bool("check", false, "Only report whether an update is available")
+	targetVersion := flags.String("version", "", "Release tag to install (default: latest)")
+	keyFile := flags.String("key", "", "PEM public key to verify the checksums file signature (checksums.txt.sig)")
+	insecure := flags.Bool("insecure-skip-signature", false, "Install without verifying the checksums file signature (anyone who can publish a release can replace the binary)")
+	apiURL := flags.String("api-url", defaultGitHubAPIURL, "GitHub API URL hosting the releases")
+	flags.Parse(args)
+
+	client := newGitHubClientAt(*apiURL, os.Getenv("GITHUB_TOKEN")) // The token is optional; it raises the API rate limit
+	client.http.Timeout = 5 * time.Minute
+	release, err := fetchRelease(client, *targetVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error checking releases: %v\n", err)
+		os.Exit(1)
+	}
+	if isCurrentVersion(release.TagName) {
+		fmt.Printf("tfplan-commenter %s is up to date\n", Version)
+		return
+	}
+	if *checkOnly {
+		fmt.Printf("Update available: %s → %s\n", Version, release.TagName)
+		return
+	}
+
+	var key crypto.PublicKey
+	if *keyFile != "" {
+		if key, err = loadVerificationKey(*keyFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	binary, err := downloadVerifiedBinary(client, release, key, *insecure)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error downloading %s: %v\n", release.TagName, err)
+		os.Exit(1)
+	}
+
+	executable, err := os.Executable()
+	if err == nil {
+		executable, err = filepath.EvalSymlinks(executable)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error locating the running binary: %v\n", err)
+		os.Exit(1)
+	}
+	if err := replaceBinary(executable, binary); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			fmt.Fprintf(os.Stderr, "Error: %v (re-run with permission to write %s)\n", err, filepath.Dir(executable))
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("Updated %s from %s to %s\n", executable, Version, release.TagName)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func testReleaseArchive(t *testing.T, binary []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	archive.WriteHeader(&tar.Header{Name: "README.md", Mode: 0644, Size: 2, Typeflag: tar.TypeReg})
+	archive.Write([]byte("hi"))
+	archive.WriteHeader(&tar.Header{Name: binaryName, Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg})
+	archive.Write(binary)
+	archive.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestReleaseAssetNames(t *testing.T) {
+	names := releaseAssetNames("darwin", "amd64")
+	if names[0] != "tfplan-commenter_Darwin_x86_64.tar.gz" || names[1] != "tfplan-commenter-darwin-amd64" {
+		t.Errorf("Unexpected asset names %v", names)
+	}
+	if names := releaseAssetNames("darwin", "arm64"); names[0] != "tfplan-commenter_Darwin_arm64.tar.gz" {
+		t.Errorf("Unexpected asset names %v", names)
+	}
+}
+
+func TestSelfUpdateDownload(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	archive := testReleaseArchive(t, binary)
+	assetName := releaseAssetNames(runtime.GOOS, runtime.GOARCH)[0]
+	digest := sha256.Sum256(archive)
+	checksums := []byte(hex.EncodeToString(digest[:]) + "  " + assetName + "\n")
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	checksumsDigest := sha256.Sum256(checksums)
+	sig, _ := ecdsa.SignASN1(rand.Reader, key, checksumsDigest[:])
+
+	assets := map[string][]byte{
+		assetName:                        archive,
+		checksumsAsset:                   checksums,
+		checksumsAsset + signatureSuffix: []byte(base64.StdEncoding.EncodeToString(sig)),
+	}
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/repos/"+releaseRepository+"/releases/latest" {
+			release := map[string]interface{}{"tag_name": "v9.9.9"}
+			var list []map[string]string
+			for name := range assets {
+				list = append(list, map[string]string{"name": name, "browser_download_url": ts.URL + "/download/" + name})
+			}
+			release["assets"] = list
+			json.NewEncoder(w).Encode(release)
+			return
+		}
+		if data, ok := assets[strings.TrimPrefix(r.URL.Path, "/download/")]; ok {
+			w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	client := newGitHubClientAt(ts.URL, "")
+	client.http = ts.Client()
+	release, err := fetchRelease(client, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isCurrentVersion(release.TagName) {
+		t.Fatal("Expected a newer release than the test build")
+	}
+
+	got, err := downloadVerifiedBinary(client, release, key.Public(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, binary) {
+		t.Errorf("Unexpected binary %q", got)
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := downloadVerifiedBinary(client, release, otherKey.Public(), false); err == nil {
+		t.Error("Expected a signature from another key to be rejected")
+	}
+	if _, err := downloadVerifiedBinary(client, release, nil, false); err == nil || !strings.Contains(err.Error(), "-insecure-skip-signature") {
+		t.Errorf("Expected installing without a key to be refused, got %v", err)
+	}
+	if got, err := downloadVerifiedBinary(client, release, nil, true); err != nil || !bytes.Equal(got, binary) {
+		t.Errorf("Expected -insecure-skip-signature to fall back to checksums, got %q, %v", got, err)
+	}
+
+	assets[assetName] = testReleaseArchive(t, []byte("tampered"))
+	if _, err := downloadVerifiedBinary(client, release, nil, true); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("Expected a checksum mismatch, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), binaryName)
+	os.WriteFile(path, []byte("old"), 0755)
+	if err := replaceBinary(path, binary); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, binary) {
+		t.Errorf("Expected the binary to be replaced, got %q", data)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0755 {
+		t.Errorf("Expected an executable binary, got %v", info.Mode())
+	}
+}
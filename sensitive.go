@@ -0,0 +1,64 @@
+package main
+
+// sensitiveValue stands in for a value Terraform marked as sensitive, so the
+// real value never reaches the rendered report
+type sensitiveValue struct{}
+
+func (sensitiveValue) String() string {
+	return "(sensitive)"
+}
+
+// isSensitive reports whether a before_sensitive/after_sensitive marker flags
+// the value, or any part of it, as sensitive
+func isSensitive(marker interface{}) bool {
+	return containsTrueMarker(marker)
+}
+
+// isUnknown reports whether an after_unknown marker flags the value, or any
+// part of it, as known only after apply
+func isUnknown(marker interface{}) bool {
+	return containsTrueMarker(marker)
+}
+
+// containsTrueMarker walks a marker structure, which mirrors the shape of the
+// value it describes, looking for any true leaf
+func containsTrueMarker(marker interface{}) bool {
+	switch v := marker.(type) {
+	case bool:
+		return v
+	case map[string]interface{}:
+		for _, nested := range v {
+			if containsTrueMarker(nested) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, nested := range v {
+			if containsTrueMarker(nested) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// maskSensitive replaces an attribute of an object with sensitiveValue when
+// the object's sensitivity markers flag it
+func maskSensitive(val interface{}, markers interface{}, key string) interface{} {
+	return maskValue(val, childMarker(markers, key))
+}
+
+// maskValue replaces a value with sensitiveValue when its marker flags it
+func maskValue(val interface{}, marker interface{}) interface{} {
+	if val != nil && isSensitive(marker) {
+		return sensitiveValue{}
+	}
+	return val
+}
+
+// unknownValue stands in for a value that Terraform will only know after apply
+type unknownValue struct{}
+
+func (unknownValue) String() string {
+	return "(known after apply)"
+}
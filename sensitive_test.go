@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSensitive(t *testing.T) {
+	tests := []struct {
+		marker   interface{}
+		expected bool
+	}{
+		{nil, false},
+		{false, false},
+		{true, true},
+		{map[string]interface{}{}, false},
+		{map[string]interface{}{"password": true}, true},
+		{[]interface{}{false, map[string]interface{}{"token": true}}, true},
+	}
+
+	for _, test := range tests {
+		if result := isSensitive(test.marker); result != test.expected {
+			t.Errorf("isSensitive(%v) = %v, expected %v", test.marker, result, test.expected)
+		}
+	}
+}
+
+func TestSensitiveAttributesMasked(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{
+				Address: "aws_db_instance.main",
+				Change: Change{
+					Actions:         []string{"update"},
+					Before:          map[string]interface{}{"password": "hunter2", "instance_class": "db.t3.micro"},
+					After:           map[string]interface{}{"password": "correct-horse", "instance_class": "db.t3.large"},
+					BeforeSensitive: map[string]interface{}{"password": true},
+					AfterSensitive:  map[string]interface{}{"password": true},
+				},
+			},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if strings.Contains(markdown, "hunter2") || strings.Contains(markdown, "correct-horse") {
+		t.Error("Expected sensitive values not to appear in markdown")
+	}
+	if !strings.Contains(markdown, "- **password**: (sensitive) → (sensitive)") {
+		t.Error("Expected masked sensitive attribute change")
+	}
+	if !strings.Contains(markdown, "- **instance_class**: \"db.t3.micro\" → \"db.t3.large\"") {
+		t.Error("Expected non-sensitive attribute change to be rendered")
+	}
+}
+
+func TestKnownAfterApplyAttributes(t *testing.T) {
+	change := Change{
+		Actions:      []string{"update"},
+		Before:       map[string]interface{}{"arn": "arn:old", "endpoint": "old.example.com", "port": float64(5432)},
+		After:        map[string]interface{}{"arn": "arn:old", "port": float64(5432)},
+		AfterUnknown: map[string]interface{}{"endpoint": true},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	if len(changes) != 1 {
+		t.Fatalf("Expected 1 attribute change, got %+v", changes)
+	}
+	if changes[0].Attribute != "endpoint" || changes[0].IsRemoved {
+		t.Errorf("Expected endpoint change rather than removal, got %+v", changes[0])
+	}
+	if formatAttributeValue(changes[0].After) != "(known after apply)" {
+		t.Errorf("Expected (known after apply), got %s", formatAttributeValue(changes[0].After))
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// maxRequestBytes bounds the size of plans accepted by the server
+const maxRequestBytes = 256 << 20
+
+// formatMarkdown is the report format rendered by the server
+const formatMarkdown = "markdown"
+
+// server renders and publishes reports over HTTP
+type server struct {
+	opts   ReportOptions
+	github *githubClient // nil when publishing isn't configured
+	gitlab *gitlabClient // nil when GitLab webhooks aren't configured
+	secret string        // Shared secret request bodies must be signed with, if set
+}
+
+// PublishRequest is the body of POST /publish
+type PublishRequest struct {
+	Repository  string          `json:"repository"`   // e.g. "org/infra"
+	PullRequest int             `json:"pull_request"` // Pull request number
+	Plan        json.RawMessage `json:"plan"`         // Plan JSON as rendered by POST /render
+	Commit      string          `json:"commit"`       // Commit the plan was made for (default: the pull request's head)
+}
+
+// MultiPlanRequest is the body of POST /render-multi
+type MultiPlanRequest struct {
+	Plans []struct {
+		Path string          `json:"path"` // Environment path, e.g. "env1/dev"
+		Plan json.RawMessage `json:"plan"`
+	} `json:"plans"`
+}
+
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("POST /render", s.requireSignature(s.handleRender, false))
+	mux.HandleFunc("POST /render-multi", s.requireSignature(s.handleRenderMulti, false))
+	mux.HandleFunc("POST /publish", s.requireSignature(s.handlePublish, false))
+	mux.HandleFunc("POST /approval", s.requireSignature(s.handleApproval, false))
+	mux.HandleFunc("POST /webhook/github", s.requireSignature(s.handleGitHubWebhook, false))
+	mux.HandleFunc("POST /webhook/gitlab", s.requireSignature(s.handleGitLabWebhook, true))
+	mux.HandleFunc("POST "+grpcServicePath+"{method}", s.handleGRPC)
+	return mux
+}
+
+// requestOptions applies per-request query parameters to the server's report options
+func (s *server) requestOptions(r *http.Request) (ReportOptions, error) {
+	return s.queryOptions(r.URL.Query())
+}
+
+// queryOptions applies format, sort, group_by, max_detail and style values to
+// the server's report options
+func (s *server) queryOptions(query url.Values) (ReportOptions, error) {
+	opts := s.opts
+
+	if format := query.Get("format"); format != "" && format != formatMarkdown {
+		return opts, fmt.Errorf("unsupported format %q (supported: %s)", format, formatMarkdown)
+	}
+	if sort := query.Get("sort"); sort != "" {
+		if !isValidSortKey(sort) {
+			return opts, fmt.Errorf("invalid sort %q: supported values are: %s", sort, formatSortKeys())
+		}
+		opts.Sort = sort
+	}
+	if groupBy := query.Get("group_by"); groupBy != "" {
+		if groupBy != groupByModule {
+			return opts, fmt.Errorf("invalid group_by %q: supported values are: %s", groupBy, groupByModule)
+		}
+		opts.GroupBy = groupBy
+	}
+	if maxDetail := query.Get("max_detail"); maxDetail != "" {
+		value, err := strconv.Atoi(maxDetail)
+		if err != nil || value < 0 {
+			return opts, fmt.Errorf("invalid max_detail %q", maxDetail)
+		}
+		opts.MaxDetail = value
+	}
+	if style := query.Get("style"); style != "" {
+		if !isValidStyle(style) {
+			return opts, fmt.Errorf("invalid style %q: supported values are: %s", style, formatStyles())
+		}
+		applyStyle(&opts, style)
+	}
+	return opts, nil
+}
+
+func (s *server) handleRender(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+		return
+	}
+	plan, err := parseTerraformPlan(data, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	io.WriteString(w, generateMarkdownComment(plan, opts))
+}
+
+func (s *server) handleRenderMulti(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request MultiPlanRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid render request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if len(request.Plans) == 0 {
+		http.Error(w, "plans are required", http.StatusBadRequest)
+		return
+	}
+
+	var plans []PlanInfo
+	for _, file := range request.Plans {
+		plan, err := parseTerraformPlan(file.Plan, file.Path)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("%s: %v", file.Path, err), http.StatusUnprocessableEntity)
+			return
+		}
+		plans = append(plans, PlanInfo{Plan: plan, RelativePath: file.Path})
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	io.WriteString(w, generateMultiPlanMarkdownComment(plans, opts))
+}
+
+func (s *server) handlePublish(w http.ResponseWriter, r *http.Request) {
+	if s.github == nil {
+		http.Error(w, "publishing is not configured (set GITHUB_TOKEN on the server)", http.StatusServiceUnavailable)
+		return
+	}
+	opts, err := s.requestOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var request PublishRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid publish request: %v", err), http.StatusBadRequest)
+		return
+	}
+	if request.Repository == "" || request.PullRequest <= 0 || len(request.Plan) == 0 {
+		http.Error(w, "repository, pull_request and plan are required", http.StatusBadRequest)
+		return
+	}
+	plan, err := parseTerraformPlan(request.Plan, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	plans := []PlanInfo{{Plan: plan}}
+	url, err := s.publishPlanComment(request.Repository, request.PullRequest, request.Commit, "", plans, generateMarkdownComment(plan, opts))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"comment_url": url})
+}
+
+// publishPlanComment posts a plan made for commit (the pull request's head
+// when empty) on a pull request with the plan comment markers and, when
+// approvals or destructive change sign-off are configured, sets the head
+// commit's statuses for the new plan. Oversized reports are truncated after
+// the markers, pointing at runURL for the full report.
+func (s *server) publishPlanComment(repository string, number int, commit, runURL string, plans []PlanInfo, body string) (string, error) {
+	if commit == "" {
+		head, err := s.github.pullRequestHead(repository, number)
+		if err != nil {
+			return "", err
+		}
+		commit = head
+	}
+	markers := planCommentMarker + "\n" + headMarker(commit) + "\n"
+	if destructive := destructiveChanges(plans, s.opts); destructive > 0 {
+		markers += destructiveMarker(destructive) + "\n"
+	}
+	url, err := s.upsertPlanComment(repository, number, commit, fitComment(markers+body, runURL))
+	if err != nil {
+		return "", err
+	}
+	if users, teams := requestedReviewers(s.opts.Reviewers, plans); len(users) > 0 || len(teams) > 0 {
+		if err := s.github.requestReviewers(repository, number, users, teams); err != nil {
+			return url, fmt.Errorf("failed to request reviewers: %w", err)
+		}
+	}
+	if s.opts.Approval != nil {
+		if _, err := s.refreshApproval(repository, number); err != nil {
+			return url, err
+		}
+	}
+	if s.opts.Acknowledgement != nil {
+		if _, err := s.refreshAcknowledgement(repository, number); err != nil {
+			return url, err
+		}
+	}
+	return url, nil
+}
+
+// upsertPlanComment updates the latest plan comment when it already holds
+// this plan of the same commit, so redelivered webhooks and re-run workflows
+// don't stack up copies of it. A plan that differs gets a new comment, since
+// approvals and acknowledgements given after the old one mustn't carry over.
+func (s *server) upsertPlanComment(repository string, number int, commit, body string) (string, error) {
+	login, err := s.github.authenticatedLogin()
+	if err != nil {
+		return "", err
+	}
+	comments, err := s.github.listComments(repository, number)
+	if err != nil {
+		return "", err
+	}
+	if latest := latestPlanComment(comments, login); latest != nil && planCommit(latest.Body) == commit && latest.Body == body {
+		return s.github.updateComment(repository, latest.ID, body)
+	}
+	return s.github.createComment(repository, number, body)
+}
+
+// clientCATLSConfig requires clients to present a certificate signed by one
+// of the CAs in caFile (mutual TLS)
+func clientCATLSConfig(caFile string) (*tls.Config, error) {
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", caFile)
+	}
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// runServeCommand implements `tfplan-commenter serve [-addr :8080] [-config file] [-tls-cert file -tls-key file]`
+func runServeCommand(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	configFile := flags.String("config", "", "JSON configuration file applied to every report")
+	certFile := flags.String("tls-cert", "", "TLS certificate file (serves HTTPS)")
+	keyFile := flags.String("tls-key", "", "TLS private key file")
+	clientCAFile := flags.String("tls-client-ca", "", "CA bundle clients must present certificates from (mutual TLS)")
+	insecure := flags.Bool("insecure-no-signature", false, "Serve publishing endpoints without "+webhookSecretEnv+" (anyone reaching the server can use its forge tokens)")
+	flags.Parse(args)
+
+	if (*certFile == "") != (*keyFile == "") {
+		fmt.Fprintln(os.Stderr, "Error: -tls-cert and -tls-key must be used together")
+		os.Exit(1)
+	}
+	if *clientCAFile != "" && *certFile == "" {
+		fmt.Fprintln(os.Stderr, "Error: -tls-client-ca requires -tls-cert and -tls-key")
+		os.Exit(1)
+	}
+
+	s := &server{
+		opts:   ReportOptions{Sort: sortByAddress},
+		github: newGitHubClient(os.Getenv("GITHUB_TOKEN")),
+		gitlab: newGitLabClient(os.Getenv("GITLAB_TOKEN")),
+		secret: os.Getenv(webhookSecretEnv),
+	}
+	if err := s.checkSignatureConfig(*insecure); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if s.secret == "" && (s.github != nil || s.gitlab != nil) {
+		fmt.Fprintf(os.Stderr, "Warning: %s is not set; requests are not authenticated\n", webhookSecretEnv)
+	}
+	if *configFile != "" {
+		config, err := loadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+		if err := config.applyTo(&s.opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// gRPC clients speak HTTP/2, negotiated over TLS or with prior knowledge
+	// on plain connections
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           s.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		Protocols:         protocols,
+	}
+	if *clientCAFile != "" {
+		tlsConfig, err := clientCATLSConfig(*clientCAFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		httpServer.TLSConfig = tlsConfig
+	}
+
+	fmt.Printf("Listening on %s\n", *addr)
+	var err error
+	if *certFile != "" {
+		err = httpServer.ListenAndServeTLS(*certFile, *keyFile)
+	} else {
+		err = httpServer.ListenAndServe()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Server error: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+const serverTestPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "aws_instance.web", "type": "aws_instance", "name": "web",
+     "change": {"actions": ["create"], "before": null, "after": {"ami": "ami-123"}}}
+  ]
+}`
+
+func TestServerRender(t *testing.T) {
+	s := &server{opts: ReportOptions{Sort: sortByAddress}}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	response, err := http.Post(ts.URL+"/render?format=markdown", "application/json", strings.NewReader(serverTestPlan))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.StatusCode, body)
+	}
+	if !strings.Contains(string(body), "aws_instance.web") {
+		t.Errorf("Expected the resource in the rendered report, got:\n%s", body)
+	}
+
+	for query, status := range map[string]int{
+		"?format=html":  http.StatusBadRequest,
+		"?sort=bogus":   http.StatusBadRequest,
+		"?max_detail=x": http.StatusBadRequest,
+		"?style=bogus":  http.StatusBadRequest,
+	} {
+		response, err := http.Post(ts.URL+"/render"+query, "application/json", strings.NewReader(serverTestPlan))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != status {
+			t.Errorf("Expected %d for %s, got %d", status, query, response.StatusCode)
+		}
+	}
+
+	response, err = http.Post(ts.URL+"/render", "application/json", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for an invalid plan, got %d", response.StatusCode)
+	}
+}
+
+func TestServerPublish(t *testing.T) {
+	var posted, updated string
+	existing := `[]`
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		var comment map[string]string
+		switch {
+		case r.URL.Path == "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+		case r.URL.Path == "/repos/org/infra/issues/7/comments" && r.Method == http.MethodGet:
+			if r.URL.Query().Get("page") != "1" {
+				io.WriteString(w, `[]`)
+				return
+			}
+			io.WriteString(w, existing)
+		case r.URL.Path == "/repos/org/infra/issues/7/comments" && r.Method == http.MethodPost:
+			json.NewDecoder(r.Body).Decode(&comment)
+			posted = comment["body"]
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7#issuecomment-1"}`)
+		case r.URL.Path == "/repos/org/infra/issues/comments/1" && r.Method == http.MethodPatch:
+			json.NewDecoder(r.Body).Decode(&comment)
+			updated = comment["body"]
+			io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7#issuecomment-1"}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusNotFound)
+		}
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+
+	s := &server{opts: ReportOptions{Sort: sortByAddress}, github: newGitHubClient("secret")}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	request := `{"repository": "org/infra", "pull_request": 7, "commit": "abc123", "plan": ` + serverTestPlan + `}`
+	response, err := http.Post(ts.URL+"/publish", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]string
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.StatusCode)
+	}
+	if result["comment_url"] != "https://github.com/org/infra/pull/7#issuecomment-1" {
+		t.Errorf("Unexpected comment URL %q", result["comment_url"])
+	}
+	if !strings.Contains(posted, "aws_instance.web") || !strings.Contains(posted, headMarker("abc123")) {
+		t.Errorf("Expected the report to be posted for the commit, got:\n%s", posted)
+	}
+
+	// Publishing the same commit again updates the plan comment in place
+	comment, _ := json.Marshal([]map[string]interface{}{{"id": 1, "body": posted, "user": map[string]string{"login": "tfplan-bot"}}})
+	existing, posted = string(comment), ""
+	response, err = http.Post(ts.URL+"/publish", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK || posted != "" || !strings.Contains(updated, headMarker("abc123")) {
+		t.Errorf("Expected the existing plan comment to be updated, got %d, posted %q", response.StatusCode, posted)
+	}
+
+	// A different plan, or a plan for a new commit, gets a new comment so
+	// earlier sign-offs don't carry over
+	for _, changed := range []string{strings.Replace(request, `"create"`, `"delete"`, 1), strings.Replace(request, "abc123", "def456", 1)} {
+		posted = ""
+		response, err = http.Post(ts.URL+"/publish", "application/json", strings.NewReader(changed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK || posted == "" {
+			t.Errorf("Expected a new comment for a changed plan, got %d", response.StatusCode)
+		}
+	}
+
+	unconfigured := httptest.NewServer((&server{}).handler())
+	defer unconfigured.Close()
+	response, err = http.Post(unconfigured.URL+"/publish", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 without a token, got %d", response.StatusCode)
+	}
+}
+
+func TestServerPublishTruncatesLargePlans(t *testing.T) {
+	var posted string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+			return
+		case r.Method == http.MethodGet:
+			io.WriteString(w, `[]`)
+			return
+		}
+		var comment map[string]string
+		json.NewDecoder(r.Body).Decode(&comment)
+		posted = comment["body"]
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7#issuecomment-1"}`)
+	}))
+	defer github.Close()
+	t.Setenv("GITHUB_API_URL", github.URL)
+
+	var changes []string
+	for i := 0; i < 1000; i++ {
+		changes = append(changes, fmt.Sprintf(`{"address": "aws_instance.web%d", "type": "aws_instance", "name": "web%d",
+			"change": {"actions": ["delete"], "before": {"user_data": %q}, "after": null}}`, i, i, strings.Repeat("x", 100)))
+	}
+	plan := `{"format_version": "1.2", "resource_changes": [` + strings.Join(changes, ",") + `]}`
+
+	s := &server{opts: ReportOptions{Sort: sortByAddress}, github: newGitHubClient("secret")}
+	ts := httptest.NewServer(s.handler())
+	defer ts.Close()
+
+	request := `{"repository": "org/infra", "pull_request": 7, "commit": "abc123", "plan": ` + plan + `}`
+	response, err := http.Post(ts.URL+"/publish", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", response.StatusCode)
+	}
+	if length := utf8.RuneCountInString(posted); length > githubCommentLimit {
+		t.Errorf("Expected the comment to fit the limit, got %d characters", length)
+	}
+	markers := planCommentMarker + "\n" + headMarker("abc123") + "\n" + destructiveMarker(1000) + "\n"
+	if !strings.HasPrefix(posted, markers) {
+		t.Errorf("Expected the markers to survive truncation, got:\n%.300s", posted)
+	}
+	if !strings.Contains(posted, "Report truncated") {
+		t.Error("Expected a truncation notice")
+	}
+}
+
+func TestServerRenderMulti(t *testing.T) {
+	ts := httptest.NewServer((&server{opts: ReportOptions{Sort: sortByAddress}}).handler())
+	defer ts.Close()
+
+	request := `{"plans": [{"path": "env1/dev", "plan": ` + serverTestPlan + `}, {"path": "env1/prod", "plan": ` + serverTestPlan + `}]}`
+	response, err := http.Post(ts.URL+"/render-multi", "application/json", strings.NewReader(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", response.StatusCode, body)
+	}
+	for _, expected := range []string{"Multi-Environment", "env1/dev", "env1/prod"} {
+		if !strings.Contains(string(body), expected) {
+			t.Errorf("Expected %q in the rendered report, got:\n%s", expected, body)
+		}
+	}
+
+	response, err = http.Post(ts.URL+"/render-multi", "application/json", strings.NewReader(`{"plans": []}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 without plans, got %d", response.StatusCode)
+	}
+}
+
+func TestClientCATLSConfig(t *testing.T) {
+	ts := httptest.NewTLSServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := os.WriteFile(caFile, certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := clientCATLSConfig(caFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ClientAuth != tls.RequireAndVerifyClientCert {
+		t.Errorf("Expected client certificates to be required, got %v", config.ClientAuth)
+	}
+
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := clientCATLSConfig(caFile); err == nil {
+		t.Error("Expected an error for a CA file without certificates")
+	}
+}
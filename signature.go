@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// webhookSecretEnv names the environment variable holding the shared secret
+// used to sign and verify request payloads
+const webhookSecretEnv = "TFPLAN_COMMENTER_WEBHOOK_SECRET"
+
+// signatureHeader carries the payload signature, as GitHub sends it
+const signatureHeader = "X-Hub-Signature-256"
+
+// gitlabTokenHeader carries GitLab's webhook secret token; GitLab sends the
+// secret itself rather than an HMAC
+const gitlabTokenHeader = "X-Gitlab-Token"
+
+// signPayload returns the X-Hub-Signature-256 value for a payload: "sha256="
+// followed by the hex HMAC-SHA256 of the body keyed with the secret
+func signPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifySignature reports whether signature is the payload's valid
+// X-Hub-Signature-256 value, comparing in constant time
+func verifySignature(secret string, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signPayload(secret, payload)))
+}
+
+// checkSignatureConfig refuses to serve unsigned requests with a forge token,
+// since anyone reaching the server could then comment and set commit statuses
+// with it, unless insecure explicitly opts out
+func (s *server) checkSignatureConfig(insecure bool) error {
+	if s.secret != "" || (s.github == nil && s.gitlab == nil) {
+		return nil
+	}
+	if !insecure {
+		return fmt.Errorf("GITHUB_TOKEN or GITLAB_TOKEN is set without %s; set the secret or pass -insecure-no-signature", webhookSecretEnv)
+	}
+	return nil
+}
+
+// requireSignature rejects requests whose body isn't signed with the server's
+// secret. With allowGitLabToken, GitLab's X-Gitlab-Token is accepted instead.
+// Requests pass through unchecked when no secret is configured, which
+// checkSignatureConfig only allows without forge tokens or when opted out.
+func (s *server) requireSignature(next http.HandlerFunc, allowGitLabToken bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.secret == "" {
+			next(w, r)
+			return
+		}
+
+		if allowGitLabToken && r.Header.Get(signatureHeader) == "" {
+			token := r.Header.Get(gitlabTokenHeader)
+			if subtle.ConstantTimeCompare([]byte(token), []byte(s.secret)) != 1 {
+				http.Error(w, "invalid or missing "+gitlabTokenHeader, http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if !verifySignature(s.secret, body, r.Header.Get(signatureHeader)) {
+			http.Error(w, "invalid or missing "+signatureHeader, http.StatusUnauthorized)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	}
+}
+
+// runSignCommand implements `tfplan-commenter sign <file>`, printing the
+// X-Hub-Signature-256 value for a payload so scripts can sign requests to serve
+func runSignCommand(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter sign <file|->")
+		os.Exit(1)
+	}
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		fmt.Fprintf(os.Stderr, "Error: %s is not set\n", webhookSecretEnv)
+		os.Exit(1)
+	}
+
+	var payload []byte
+	var err error
+	if args[0] == "-" {
+		payload, err = io.ReadAll(os.Stdin)
+	} else {
+		payload, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading payload: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(signPayload(secret, payload))
+}
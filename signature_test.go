@@ -0,0 +1,87 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSignPayload(t *testing.T) {
+	// Example from GitHub's webhook validation documentation
+	signature := signPayload("It's a Secret to Everybody", []byte("Hello, World!"))
+	expected := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if signature != expected {
+		t.Errorf("Expected %s, got %s", expected, signature)
+	}
+
+	if !verifySignature("It's a Secret to Everybody", []byte("Hello, World!"), expected) {
+		t.Error("Expected the signature to verify")
+	}
+	for _, invalid := range []string{"", strings.TrimPrefix(expected, "sha256="), "sha256=00", signPayload("other", []byte("Hello, World!"))} {
+		if verifySignature("It's a Secret to Everybody", []byte("Hello, World!"), invalid) {
+			t.Errorf("Expected %q not to verify", invalid)
+		}
+	}
+}
+
+func TestServerRequiresSignature(t *testing.T) {
+	ts := httptest.NewServer((&server{opts: ReportOptions{Sort: sortByAddress}, secret: "secret"}).handler())
+	defer ts.Close()
+
+	post := func(path string, headers map[string]string) int {
+		request, _ := http.NewRequest(http.MethodPost, ts.URL+path, strings.NewReader(serverTestPlan))
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	if status := post("/render", nil); status != http.StatusUnauthorized {
+		t.Errorf("Expected unsigned requests to be rejected, got %d", status)
+	}
+	if status := post("/render", map[string]string{signatureHeader: signPayload("wrong", []byte(serverTestPlan))}); status != http.StatusUnauthorized {
+		t.Errorf("Expected requests signed with another secret to be rejected, got %d", status)
+	}
+	if status := post("/render", map[string]string{signatureHeader: signPayload("secret", []byte(serverTestPlan))}); status != http.StatusOK {
+		t.Errorf("Expected signed requests to be rendered, got %d", status)
+	}
+
+	// GitLab webhooks authenticate with the secret token instead
+	if status := post("/webhook/gitlab", map[string]string{gitlabTokenHeader: "wrong"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected an invalid GitLab token to be rejected, got %d", status)
+	}
+	if status := post("/webhook/gitlab", map[string]string{gitlabTokenHeader: "secret"}); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected a valid GitLab token to reach the handler, got %d", status)
+	}
+	if status := post("/render", map[string]string{gitlabTokenHeader: "secret"}); status != http.StatusUnauthorized {
+		t.Errorf("Expected the GitLab token to be refused outside the GitLab webhook, got %d", status)
+	}
+}
+
+func TestCheckSignatureConfig(t *testing.T) {
+	if err := (&server{}).checkSignatureConfig(false); err != nil {
+		t.Errorf("Expected a render-only server without a secret to start, got %v", err)
+	}
+
+	withToken := &server{github: newGitHubClient("token")}
+	if err := withToken.checkSignatureConfig(false); err == nil {
+		t.Error("Expected a forge token without a secret to be refused")
+	}
+	if err := withToken.checkSignatureConfig(true); err != nil {
+		t.Errorf("Expected -insecure-no-signature to allow starting, got %v", err)
+	}
+
+	withToken.secret = "secret"
+	if err := withToken.checkSignatureConfig(false); err != nil {
+		t.Errorf("Expected a forge token with a secret to start, got %v", err)
+	}
+	if err := (&server{gitlab: newGitLabClient("token")}).checkSignatureConfig(false); err == nil {
+		t.Error("Expected a GitLab token without a secret to be refused")
+	}
+}
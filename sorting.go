@@ -0,0 +1,88 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// Supported -sort keys
+const (
+	sortByAddress = "address"
+	sortByType    = "type"
+	sortByAction  = "action"
+	sortByImpact  = "impact"
+)
+
+var sortKeys = []string{sortByAddress, sortByType, sortByAction, sortByImpact}
+
+func isValidSortKey(key string) bool {
+	for _, k := range sortKeys {
+		if key == k {
+			return true
+		}
+	}
+	return false
+}
+
+// sectionOrder returns the order of the create/update/replace/delete detail
+// sections. Sorting by action or impact puts the destructive sections first.
+func sectionOrder(sortBy string, reverse bool) []string {
+	order := []string{"create", "update", "replace", "delete"}
+	if sortBy == sortByAction || sortBy == sortByImpact {
+		order = []string{"delete", "replace", "update", "create"}
+	}
+	if reverse {
+		for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+			order[i], order[j] = order[j], order[i]
+		}
+	}
+	return order
+}
+
+// sortSummary orders the resources within each action by the -sort key:
+// address (default), resource type, or impact (most attribute changes first)
+func sortSummary(summary *ResourceSummary, plan *TerraformPlan, sortBy string, reverse bool) {
+	types := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		types[rc.Address] = rc.Type
+	}
+
+	less := func(a, b ResourceDetail) bool {
+		switch sortBy {
+		case sortByType:
+			if types[a.Address] != types[b.Address] {
+				return types[a.Address] < types[b.Address]
+			}
+		case sortByImpact:
+			if impactA, impactB := resourceImpact(a), resourceImpact(b); impactA != impactB {
+				return impactA > impactB
+			}
+		}
+		return a.Address < b.Address
+	}
+
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		sort.SliceStable(details, func(i, j int) bool {
+			if reverse {
+				return less(details[j], details[i])
+			}
+			return less(details[i], details[j])
+		})
+	}
+}
+
+// resourceImpact estimates how much a resource change matters to reviewers:
+// the number of non-tag attribute changes, plus one when the change carries a
+// replacement or deletion reason
+func resourceImpact(resource ResourceDetail) int {
+	_, changes := splitTagChanges(resource.Changes)
+	impact := len(changes)
+	if resource.ForceReason != "" {
+		impact++
+	}
+	return impact
+}
+
+func formatSortKeys() string {
+	return strings.Join(sortKeys, "|")
+}
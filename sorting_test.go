@@ -0,0 +1,75 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSectionOrder(t *testing.T) {
+	if order := sectionOrder(sortByAddress, false); !reflect.DeepEqual(order, []string{"create", "update", "replace", "delete"}) {
+		t.Errorf("Unexpected default order: %v", order)
+	}
+	if order := sectionOrder(sortByAction, false); !reflect.DeepEqual(order, []string{"delete", "replace", "update", "create"}) {
+		t.Errorf("Unexpected action order: %v", order)
+	}
+	if order := sectionOrder(sortByAction, true); !reflect.DeepEqual(order, []string{"create", "update", "replace", "delete"}) {
+		t.Errorf("Unexpected reversed action order: %v", order)
+	}
+}
+
+func TestSortSummary(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_vpc.a", Type: "aws_vpc"},
+			{Address: "aws_iam_role.b", Type: "aws_iam_role"},
+			{Address: "aws_s3_bucket.c", Type: "aws_s3_bucket"},
+		},
+	}
+	newSummary := func() ResourceSummary {
+		return ResourceSummary{Update: []ResourceDetail{
+			{Address: "aws_iam_role.b", Changes: []AttributeChange{{Attribute: "name"}}},
+			{Address: "aws_s3_bucket.c", Changes: []AttributeChange{{Attribute: "acl"}, {Attribute: "policy"}}},
+			{Address: "aws_vpc.a"},
+		}}
+	}
+	addresses := func(details []ResourceDetail) []string {
+		var result []string
+		for _, d := range details {
+			result = append(result, d.Address)
+		}
+		return result
+	}
+
+	tests := []struct {
+		sortBy   string
+		reverse  bool
+		expected []string
+	}{
+		{sortByAddress, false, []string{"aws_iam_role.b", "aws_s3_bucket.c", "aws_vpc.a"}},
+		{sortByAddress, true, []string{"aws_vpc.a", "aws_s3_bucket.c", "aws_iam_role.b"}},
+		{sortByType, false, []string{"aws_iam_role.b", "aws_s3_bucket.c", "aws_vpc.a"}},
+		{sortByImpact, false, []string{"aws_s3_bucket.c", "aws_iam_role.b", "aws_vpc.a"}},
+	}
+	for _, tt := range tests {
+		summary := newSummary()
+		sortSummary(&summary, plan, tt.sortBy, tt.reverse)
+		if got := addresses(summary.Update); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("sort %s (reverse=%v): expected %v, got %v", tt.sortBy, tt.reverse, tt.expected, got)
+		}
+	}
+}
+
+func TestSortByActionPutsDeletesFirst(t *testing.T) {
+	plan := &TerraformPlan{
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_vpc.new", Type: "aws_vpc", Change: Change{Actions: []string{"create"}}},
+			{Address: "aws_db_instance.old", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+		},
+	}
+
+	result := generateMarkdownComment(plan, ReportOptions{Sort: sortByAction})
+	if strings.Index(result, "Resources to be Deleted") > strings.Index(result, "Resources to be Created") {
+		t.Errorf("Expected deletes before creates, got:\n%s", result)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SourceLocation is where a resource is declared in the Terraform configuration
+type SourceLocation struct {
+	File         string // Path to the .tf file, empty when the module is not local
+	Line         int
+	ModuleSource string // Source of the containing module call, if any
+}
+
+var resourceBlockPattern = regexp.MustCompile(`^\s*(resource|data)\s+"([^"]+)"\s+"([^"]+)"`)
+
+// sourceLocator resolves resource addresses to their declaring .tf files by
+// following module_calls from the configuration block and scanning module directories
+type sourceLocator struct {
+	rootDir string
+	config  *Configuration
+	cache   map[string]map[string]SourceLocation
+}
+
+func newSourceLocator(rootDir string, config *Configuration) *sourceLocator {
+	return &sourceLocator{
+		rootDir: rootDir,
+		config:  config,
+		cache:   make(map[string]map[string]SourceLocation),
+	}
+}
+
+// locate returns the source location of a resource address, or false if it can't be resolved
+func (l *sourceLocator) locate(address string) (SourceLocation, bool) {
+	if l.config == nil {
+		return SourceLocation{}, false
+	}
+
+	parts := strings.Split(instanceKeyPattern.ReplaceAllString(address, ""), ".")
+	module := l.config.RootModule
+	dir := l.rootDir
+	moduleSource := ""
+
+	for len(parts) > 2 && parts[0] == "module" {
+		call, ok := module.ModuleCalls[parts[1]]
+		if !ok {
+			return SourceLocation{}, false
+		}
+		moduleSource = call.Source
+		if isLocalModuleSource(call.Source) && dir != "" {
+			dir = filepath.Join(dir, call.Source)
+		} else {
+			dir = ""
+		}
+		if call.Module != nil {
+			module = *call.Module
+		}
+		parts = parts[2:]
+	}
+
+	key := strings.Join(parts, ".")
+	if dir == "" {
+		if moduleSource == "" {
+			return SourceLocation{}, false
+		}
+		return SourceLocation{ModuleSource: moduleSource}, true
+	}
+
+	location, ok := l.scan(dir)[key]
+	if !ok {
+		if moduleSource == "" {
+			return SourceLocation{}, false
+		}
+		return SourceLocation{ModuleSource: moduleSource}, true
+	}
+	location.ModuleSource = moduleSource
+	return location, true
+}
+
+// scan indexes the resource and data blocks declared in a module directory
+func (l *sourceLocator) scan(dir string) map[string]SourceLocation {
+	if index, ok := l.cache[dir]; ok {
+		return index
+	}
+
+	index := make(map[string]SourceLocation)
+	l.cache[dir] = index
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return index
+	}
+
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		line := 0
+		for scanner.Scan() {
+			line++
+			match := resourceBlockPattern.FindStringSubmatch(scanner.Text())
+			if match == nil {
+				continue
+			}
+			key := match[2] + "." + match[3]
+			if match[1] == "data" {
+				key = "data." + key
+			}
+			index[key] = SourceLocation{File: file, Line: line}
+		}
+		f.Close()
+	}
+
+	return index
+}
+
+func isLocalModuleSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// formatSourceLink renders a source location as a markdown link. Files are
+// linked relative to the working directory, or under baseURL when set.
+func formatSourceLink(location SourceLocation, baseURL string) string {
+	if location.File == "" {
+		if isRegistryModuleSource(location.ModuleSource) {
+			return fmt.Sprintf("[module `%s`](https://registry.terraform.io/modules/%s)",
+				location.ModuleSource, location.ModuleSource)
+		}
+		return fmt.Sprintf("module `%s`", location.ModuleSource)
+	}
+
+	path := relativeSourcePath(location.File)
+	target := path
+	if baseURL != "" {
+		target = strings.TrimSuffix(baseURL, "/") + "/" + strings.TrimPrefix(path, "./")
+	}
+
+	return fmt.Sprintf("[%s:%d](%s#L%d)", filepath.Base(path), location.Line, target, location.Line)
+}
+
+// relativeSourcePath returns a source file path relative to the working
+// directory when possible, with forward slashes
+func relativeSourcePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// isRegistryModuleSource reports whether a module source is a public registry
+// address such as hashicorp/consul/aws
+func isRegistryModuleSource(source string) bool {
+	if strings.Contains(source, "::") || strings.Contains(source, "://") || isLocalModuleSource(source) {
+		return false
+	}
+	return len(strings.Split(source, "/")) == 3
+}
+
+// annotateSourceLinks attaches source links to every resource in the summary
+func annotateSourceLinks(summary *ResourceSummary, plan *TerraformPlan, opts ReportOptions) {
+	locator := newSourceLocator(sourceRootDir(plan, opts), plan.Configuration)
+	for _, details := range [][]ResourceDetail{summary.Create, summary.Update, summary.Replace, summary.Delete} {
+		for i := range details {
+			if location, ok := locator.locate(details[i].Address); ok {
+				details[i].SourceLink = formatSourceLink(location, opts.SourceURL)
+			}
+		}
+	}
+}
+
+// sourceRootDir returns the root module directory of a plan: -source-root,
+// or the plan file's directory
+func sourceRootDir(plan *TerraformPlan, opts ReportOptions) string {
+	if opts.SourceRoot == "" && plan.PlanPath != "" {
+		return filepath.Dir(plan.PlanPath)
+	}
+	return opts.SourceRoot
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceLocator(t *testing.T) {
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "modules", "network")
+	if err := os.MkdirAll(moduleDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "main.tf"), []byte("module \"network\" {\n  source = \"./modules/network\"\n}\n\nresource \"aws_s3_bucket\" \"logs\" {\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(moduleDir, "vpc.tf"), []byte("# VPC\nresource \"aws_vpc\" \"main\" {\n  cidr_block = \"10.0.0.0/16\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &Configuration{
+		RootModule: ConfigModule{
+			ModuleCalls: map[string]ModuleCall{
+				"network": {Source: "./modules/network", Module: &ConfigModule{}},
+				"consul":  {Source: "hashicorp/consul/aws", Module: &ConfigModule{}},
+			},
+		},
+	}
+	locator := newSourceLocator(root, config)
+
+	location, ok := locator.locate("module.network.aws_vpc.main")
+	if !ok || location.File != filepath.Join(moduleDir, "vpc.tf") || location.Line != 2 {
+		t.Errorf("Unexpected location for module resource: %+v", location)
+	}
+
+	location, ok = locator.locate("aws_s3_bucket.logs")
+	if !ok || location.Line != 5 {
+		t.Errorf("Unexpected location for root resource: %+v", location)
+	}
+
+	location, ok = locator.locate("module.consul.aws_instance.server[0]")
+	if !ok || location.File != "" || location.ModuleSource != "hashicorp/consul/aws" {
+		t.Errorf("Expected registry module source for remote module, got %+v", location)
+	}
+	link := formatSourceLink(location, "")
+	if !strings.Contains(link, "https://registry.terraform.io/modules/hashicorp/consul/aws") {
+		t.Errorf("Expected registry link, got %s", link)
+	}
+
+	link = formatSourceLink(SourceLocation{File: "stacks/prod/main.tf", Line: 12}, "https://github.com/org/repo/blob/main/")
+	if link != "[main.tf:12](https://github.com/org/repo/blob/main/stacks/prod/main.tf#L12)" {
+		t.Errorf("Unexpected VCS link: %s", link)
+	}
+
+	if _, ok := locator.locate("aws_iam_role.missing"); ok {
+		t.Error("Expected undeclared resource not to resolve")
+	}
+}
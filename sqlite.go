@@ -0,0 +1,691 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// The SQLite history store reads and writes the SQLite 3 file format
+// directly (https://www.sqlite.org/fileformat.html) so the module needs no
+// database driver. The database holds a single history table:
+//
+//	CREATE TABLE history(time TEXT, environment TEXT, creates INTEGER, ...)
+//
+// Appending rewrites the whole file, which keeps the writer to a few
+// b-tree pages' worth of code at the cost of speed on very large histories.
+
+// sqliteHeader starts every SQLite database file
+const sqliteHeader = "SQLite format 3\x00"
+
+// sqlitePageSize is the page size of databases written by the history store
+const sqlitePageSize = 4096
+
+// sqliteVersionNumber is recorded as the version of SQLite that last wrote
+// the file
+const sqliteVersionNumber = 3040001
+
+// sqliteHistoryTable is the table history records are stored in
+const sqliteHistoryTable = "history"
+
+// sqliteHistorySchema creates the history table, one column per
+// HistoryRecord field
+const sqliteHistorySchema = "CREATE TABLE history(time TEXT NOT NULL, environment TEXT NOT NULL, " +
+	"creates INTEGER NOT NULL, updates INTEGER NOT NULL, replaces INTEGER NOT NULL, deletes INTEGER NOT NULL, " +
+	"risk_score REAL NOT NULL, risk_level TEXT NOT NULL)"
+
+// sqliteHistoryColumns lists the history table's columns in schema order
+var sqliteHistoryColumns = []string{"time", "environment", "creates", "updates", "replaces", "deletes", "risk_score", "risk_level"}
+
+// B-tree page types
+const (
+	sqliteInteriorTable = 0x05
+	sqliteLeafTable     = 0x0d
+)
+
+// isSQLiteHistory reports whether a history file is an SQLite database: an
+// existing file with the SQLite header, or a new one named *.sqlite,
+// *.sqlite3 or *.db
+func isSQLiteHistory(filename string) bool {
+	file, err := os.Open(filename)
+	if err != nil {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".sqlite", ".sqlite3", ".db":
+			return true
+		}
+		return false
+	}
+	defer file.Close()
+	header := make([]byte, len(sqliteHeader))
+	n, _ := io.ReadFull(file, header)
+	return n == len(header) && string(header) == sqliteHeader
+}
+
+// appendSQLiteHistory adds records to an SQLite history database, creating
+// it if needed
+func appendSQLiteHistory(filename string, records []HistoryRecord) error {
+	var existing []HistoryRecord
+	changes := uint32(0)
+	if data, err := os.ReadFile(filename); err == nil {
+		database, err := openSQLite(data)
+		if err != nil {
+			return err
+		}
+		if err := database.checkHistoryOnly(); err != nil {
+			return err
+		}
+		if existing, err = database.historyRecords(); err != nil {
+			return err
+		}
+		changes = binary.BigEndian.Uint32(data[24:])
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read history database: %w", err)
+	}
+
+	data, err := encodeSQLiteHistory(append(existing, records...), changes+1)
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(filename), ".history-*.sqlite")
+	if err != nil {
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	if err := os.Chmod(temp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	if err := os.Rename(temp.Name(), filename); err != nil {
+		return fmt.Errorf("failed to write history database: %w", err)
+	}
+	return nil
+}
+
+// readSQLiteHistory loads every record from an SQLite history database
+func readSQLiteHistory(filename string) ([]HistoryRecord, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	database, err := openSQLite(data)
+	if err != nil {
+		return nil, err
+	}
+	return database.historyRecords()
+}
+
+// encodeSQLiteHistory returns a database file holding the records
+func encodeSQLiteHistory(records []HistoryRecord, changes uint32) ([]byte, error) {
+	// Page 1 holds the schema; overflow pages and the history table's b-tree
+	// follow
+	pages := [][]byte{nil}
+	cells := make([][]byte, 0, len(records))
+	for i, record := range records {
+		payload := sqliteRecord(record.Time.UTC().Format(time.RFC3339Nano), record.Environment,
+			int64(record.Create), int64(record.Update), int64(record.Replace), int64(record.Delete),
+			record.RiskScore, record.RiskLevel)
+		local := sqliteLocalPayload(sqlitePageSize, len(payload))
+		cell := appendSQLiteVarint(nil, uint64(len(payload)))
+		cell = appendSQLiteVarint(cell, uint64(i+1))
+		cell = append(cell, payload[:local]...)
+		if overflow := payload[local:]; len(overflow) > 0 {
+			cell = binary.BigEndian.AppendUint32(cell, uint32(len(pages)+1))
+			for len(overflow) > 0 {
+				page := make([]byte, sqlitePageSize)
+				n := copy(page[4:], overflow)
+				if overflow = overflow[n:]; len(overflow) > 0 {
+					binary.BigEndian.PutUint32(page, uint32(len(pages)+2))
+				}
+				pages = append(pages, page)
+			}
+		}
+		cells = append(cells, cell)
+	}
+
+	type child struct {
+		page   uint32
+		maxKey int64
+	}
+	var level []child
+	for start := 0; start == 0 || start < len(cells); {
+		end, used := start, 8
+		for end < len(cells) && used+len(cells[end])+2 <= sqlitePageSize {
+			used += len(cells[end]) + 2
+			end++
+		}
+		pages = append(pages, sqlitePage(sqliteLeafTable, cells[start:end], 0, 0))
+		level = append(level, child{page: uint32(len(pages)), maxKey: int64(end)})
+		start = end
+		if end == len(cells) {
+			break
+		}
+	}
+	// Interior cells are at most 13 bytes plus a 2 byte pointer; children are
+	// spread evenly so no interior page is left with a single child
+	const maxChildren = (sqlitePageSize - 12) / 15
+	for len(level) > 1 {
+		groups := (len(level) + maxChildren - 1) / maxChildren
+		var parents []child
+		for g, start := 0, 0; g < groups; g++ {
+			end := start + len(level)/groups
+			if g < len(level)%groups {
+				end++
+			}
+			var cells [][]byte
+			for _, c := range level[start : end-1] {
+				cell := binary.BigEndian.AppendUint32(nil, c.page)
+				cells = append(cells, appendSQLiteVarint(cell, uint64(c.maxKey)))
+			}
+			pages = append(pages, sqlitePage(sqliteInteriorTable, cells, level[end-1].page, 0))
+			parents = append(parents, child{page: uint32(len(pages)), maxKey: level[end-1].maxKey})
+			start = end
+		}
+		level = parents
+	}
+
+	schema := sqliteRecord("table", sqliteHistoryTable, sqliteHistoryTable, int64(level[0].page), sqliteHistorySchema)
+	pages[0] = sqlitePage(sqliteLeafTable, [][]byte{sqliteLeafCell(1, schema)}, 0, 100)
+
+	header := pages[0][:100]
+	copy(header, sqliteHeader)
+	binary.BigEndian.PutUint16(header[16:], sqlitePageSize)
+	header[18], header[19] = 1, 1 // Rollback journal, not WAL
+	header[21], header[22], header[23] = 64, 32, 32
+	binary.BigEndian.PutUint32(header[24:], changes)
+	binary.BigEndian.PutUint32(header[28:], uint32(len(pages)))
+	binary.BigEndian.PutUint32(header[40:], 1) // Schema cookie
+	binary.BigEndian.PutUint32(header[44:], 4) // Schema format
+	binary.BigEndian.PutUint32(header[56:], 1) // UTF-8
+	binary.BigEndian.PutUint32(header[92:], changes)
+	binary.BigEndian.PutUint32(header[96:], sqliteVersionNumber)
+
+	data := make([]byte, 0, len(pages)*sqlitePageSize)
+	for _, page := range pages {
+		data = append(data, page...)
+	}
+	return data, nil
+}
+
+// sqlitePage lays out a table b-tree page: the header at offset, the cell
+// pointer array after it and the cells packed at the end of the page
+func sqlitePage(pageType byte, cells [][]byte, rightChild uint32, offset int) []byte {
+	page := make([]byte, sqlitePageSize)
+	headerSize := 8
+	if pageType == sqliteInteriorTable {
+		headerSize = 12
+		binary.BigEndian.PutUint32(page[offset+8:], rightChild)
+	}
+	page[offset] = pageType
+	binary.BigEndian.PutUint16(page[offset+3:], uint16(len(cells)))
+
+	content := sqlitePageSize
+	for i, cell := range cells {
+		content -= len(cell)
+		copy(page[content:], cell)
+		binary.BigEndian.PutUint16(page[offset+headerSize+2*i:], uint16(content))
+	}
+	binary.BigEndian.PutUint16(page[offset+5:], uint16(content))
+	return page
+}
+
+// sqliteLocalPayload returns how much of a table leaf cell's payload is stored
+// on the b-tree page itself, the rest going to overflow pages
+func sqliteLocalPayload(usable, size int) int {
+	maxLocal := usable - 35
+	if size <= maxLocal {
+		return size
+	}
+	minLocal := (usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	return local
+}
+
+// sqliteLeafCell returns a table leaf cell holding a row that fits on the page
+func sqliteLeafCell(rowid int64, payload []byte) []byte {
+	cell := appendSQLiteVarint(nil, uint64(len(payload)))
+	cell = appendSQLiteVarint(cell, uint64(rowid))
+	return append(cell, payload...)
+}
+
+// sqliteRecord encodes values (int64, float64 or string) in the record format
+func sqliteRecord(values ...interface{}) []byte {
+	var types, body []byte
+	for _, value := range values {
+		switch v := value.(type) {
+		case int64:
+			switch {
+			case v == 0:
+				types = appendSQLiteVarint(types, 8)
+			case v == 1:
+				types = appendSQLiteVarint(types, 9)
+			case v >= math.MinInt8 && v <= math.MaxInt8:
+				types = appendSQLiteVarint(types, 1)
+				body = append(body, byte(v))
+			case v >= math.MinInt16 && v <= math.MaxInt16:
+				types = appendSQLiteVarint(types, 2)
+				body = binary.BigEndian.AppendUint16(body, uint16(v))
+			case v >= math.MinInt32 && v <= math.MaxInt32:
+				types = appendSQLiteVarint(types, 4)
+				body = binary.BigEndian.AppendUint32(body, uint32(v))
+			default:
+				types = appendSQLiteVarint(types, 6)
+				body = binary.BigEndian.AppendUint64(body, uint64(v))
+			}
+		case float64:
+			types = appendSQLiteVarint(types, 7)
+			body = binary.BigEndian.AppendUint64(body, math.Float64bits(v))
+		case string:
+			types = appendSQLiteVarint(types, uint64(13+2*len(v)))
+			body = append(body, v...)
+		}
+	}
+	// The header size includes its own varint
+	size := len(types) + 1
+	for size != len(types)+len(appendSQLiteVarint(nil, uint64(size))) {
+		size = len(types) + len(appendSQLiteVarint(nil, uint64(size)))
+	}
+	record := appendSQLiteVarint(nil, uint64(size))
+	record = append(record, types...)
+	return append(record, body...)
+}
+
+// appendSQLiteVarint appends a big-endian SQLite varint: 7 bits per byte
+// with the high bit set on all but the last, and 8 bits in a ninth byte
+func appendSQLiteVarint(data []byte, v uint64) []byte {
+	if v > 1<<56-1 {
+		for shift := 57; shift >= 8; shift -= 7 {
+			data = append(data, byte(v>>shift)|0x80)
+		}
+		return append(data, byte(v))
+	}
+	var groups [8]byte
+	n := 0
+	for {
+		groups[n] = byte(v & 0x7f)
+		n++
+		v >>= 7
+		if v == 0 {
+			break
+		}
+	}
+	for i := n - 1; i > 0; i-- {
+		data = append(data, groups[i]|0x80)
+	}
+	return append(data, groups[0])
+}
+
+// sqliteVarint decodes a varint, returning its length or 0 if data is short
+func sqliteVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 8; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	if len(data) < 9 {
+		return 0, 0
+	}
+	return v<<8 | uint64(data[8]), 9
+}
+
+// sqliteDatabase reads the tables of a database file
+type sqliteDatabase struct {
+	data     []byte
+	pageSize int
+	usable   int
+}
+
+// openSQLite checks the header of a database file
+func openSQLite(data []byte) (*sqliteDatabase, error) {
+	if len(data) < 100 || string(data[:len(sqliteHeader)]) != sqliteHeader {
+		return nil, fmt.Errorf("history file is not an SQLite database")
+	}
+	pageSize := int(binary.BigEndian.Uint16(data[16:]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if data[18] == 2 || data[19] == 2 {
+		return nil, fmt.Errorf("history database uses write-ahead logging; run PRAGMA journal_mode=DELETE on it first")
+	}
+	if encoding := binary.BigEndian.Uint32(data[56:]); encoding > 1 {
+		return nil, fmt.Errorf("history database is UTF-16 encoded; only UTF-8 databases are supported")
+	}
+	if pageSize < 512 || len(data)%pageSize != 0 {
+		return nil, fmt.Errorf("history database is truncated or corrupt")
+	}
+	return &sqliteDatabase{data: data, pageSize: pageSize, usable: pageSize - int(data[20])}, nil
+}
+
+// sqliteSchemaEntry is a row of the sqlite_schema table
+type sqliteSchemaEntry struct {
+	kind, name string
+	rootPage   int64
+	sql        string
+}
+
+func (d *sqliteDatabase) schema() ([]sqliteSchemaEntry, error) {
+	var entries []sqliteSchemaEntry
+	err := d.tableRows(1, func(_ int64, values []interface{}) error {
+		if len(values) < 5 {
+			return fmt.Errorf("history database has an invalid schema")
+		}
+		kind, _ := values[0].(string)
+		name, _ := values[1].(string)
+		rootPage, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		entries = append(entries, sqliteSchemaEntry{kind: kind, name: name, rootPage: rootPage, sql: sql})
+		return nil
+	})
+	return entries, err
+}
+
+// checkHistoryOnly refuses databases holding anything besides the history
+// table, which rewriting the file on append would drop
+func (d *sqliteDatabase) checkHistoryOnly() error {
+	entries, err := d.schema()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.kind != "table" || !strings.EqualFold(entry.name, sqliteHistoryTable) {
+			return fmt.Errorf("history database has %s %q besides the %s table; use a database for the history alone", entry.kind, entry.name, sqliteHistoryTable)
+		}
+	}
+	return nil
+}
+
+// historyRecords reads the history table, matching columns by name
+func (d *sqliteDatabase) historyRecords() ([]HistoryRecord, error) {
+	entries, err := d.schema()
+	if err != nil {
+		return nil, err
+	}
+	var table *sqliteSchemaEntry
+	for i, entry := range entries {
+		if entry.kind == "table" && strings.EqualFold(entry.name, sqliteHistoryTable) {
+			table = &entries[i]
+		}
+	}
+	if table == nil {
+		return nil, nil
+	}
+
+	positions := make(map[string]int)
+	for i, column := range sqliteTableColumns(table.sql) {
+		positions[column] = i
+	}
+	for _, column := range sqliteHistoryColumns {
+		if _, ok := positions[column]; !ok {
+			return nil, fmt.Errorf("history table has no %s column", column)
+		}
+	}
+
+	var records []HistoryRecord
+	err = d.tableRows(table.rootPage, func(rowid int64, values []interface{}) error {
+		value := func(column string) interface{} {
+			if i := positions[column]; i < len(values) {
+				return values[i]
+			}
+			return nil
+		}
+		text, _ := value("time").(string)
+		when, err := time.Parse(time.RFC3339Nano, text)
+		if err != nil {
+			return fmt.Errorf("invalid time in history row %d: %w", rowid, err)
+		}
+		environment, _ := value("environment").(string)
+		level, _ := value("risk_level").(string)
+		records = append(records, HistoryRecord{
+			Time:        when,
+			Environment: environment,
+			Create:      int(sqliteNumber(value("creates"))),
+			Update:      int(sqliteNumber(value("updates"))),
+			Replace:     int(sqliteNumber(value("replaces"))),
+			Delete:      int(sqliteNumber(value("deletes"))),
+			RiskScore:   sqliteNumber(value("risk_score")),
+			RiskLevel:   level,
+		})
+		return nil
+	})
+	return records, err
+}
+
+// sqliteNumber converts an INTEGER or REAL value; SQLite stores whole REAL
+// values as integers
+func sqliteNumber(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// sqliteTableColumns returns the lower-cased column names of a CREATE TABLE
+// statement
+func sqliteTableColumns(sql string) []string {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end < start {
+		return nil
+	}
+	var definitions []string
+	depth, from := 0, start+1
+	for i := start + 1; i < end; i++ {
+		switch sql[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				definitions = append(definitions, sql[from:i])
+				from = i + 1
+			}
+		}
+	}
+	definitions = append(definitions, sql[from:end])
+
+	var columns []string
+	for _, definition := range definitions {
+		fields := strings.Fields(definition)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(strings.Trim(fields[0], "\"`[]'"))
+		switch name {
+		case "constraint", "primary", "unique", "check", "foreign":
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// page returns a page by its 1-based number
+func (d *sqliteDatabase) page(number int64) ([]byte, error) {
+	if number < 1 || number*int64(d.pageSize) > int64(len(d.data)) {
+		return nil, fmt.Errorf("history database is corrupt: page %d out of range", number)
+	}
+	return d.data[(number-1)*int64(d.pageSize) : number*int64(d.pageSize)], nil
+}
+
+// tableRows calls row for each row of the table b-tree rooted at root, in
+// rowid order
+func (d *sqliteDatabase) tableRows(root int64, row func(rowid int64, values []interface{}) error) error {
+	return d.walkTable(root, 0, row)
+}
+
+func (d *sqliteDatabase) walkTable(number int64, depth int, row func(int64, []interface{}) error) error {
+	corrupt := fmt.Errorf("history database is corrupt: invalid page %d", number)
+	if depth > 20 {
+		return corrupt
+	}
+	page, err := d.page(number)
+	if err != nil {
+		return err
+	}
+	offset := 0
+	if number == 1 {
+		offset = 100
+	}
+	cellCount := int(binary.BigEndian.Uint16(page[offset+3:]))
+
+	switch page[offset] {
+	case sqliteInteriorTable:
+		pointers := offset + 12
+		if pointers+2*cellCount > len(page) {
+			return corrupt
+		}
+		for i := 0; i < cellCount; i++ {
+			cell := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+			if cell+4 > len(page) {
+				return corrupt
+			}
+			if err := d.walkTable(int64(binary.BigEndian.Uint32(page[cell:])), depth+1, row); err != nil {
+				return err
+			}
+		}
+		return d.walkTable(int64(binary.BigEndian.Uint32(page[offset+8:])), depth+1, row)
+	case sqliteLeafTable:
+		pointers := offset + 8
+		if pointers+2*cellCount > len(page) {
+			return corrupt
+		}
+		for i := 0; i < cellCount; i++ {
+			cell := page[binary.BigEndian.Uint16(page[pointers+2*i:]):]
+			size, n := sqliteVarint(cell)
+			if n == 0 {
+				return corrupt
+			}
+			rowid, m := sqliteVarint(cell[n:])
+			if m == 0 {
+				return corrupt
+			}
+			payload, err := d.payload(cell[n+m:], int(size))
+			if err != nil {
+				return err
+			}
+			values, err := decodeSQLiteRecord(payload)
+			if err != nil {
+				return err
+			}
+			if err := row(int64(rowid), values); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return corrupt
+}
+
+// payload returns a cell's payload, following overflow pages for payloads
+// too large to be stored on the b-tree page
+func (d *sqliteDatabase) payload(cell []byte, size int) ([]byte, error) {
+	corrupt := fmt.Errorf("history database is corrupt: invalid cell payload")
+	local := sqliteLocalPayload(d.usable, size)
+	if local == size {
+		if size > len(cell) {
+			return nil, corrupt
+		}
+		return cell[:size], nil
+	}
+	if local+4 > len(cell) {
+		return nil, corrupt
+	}
+	payload := append([]byte(nil), cell[:local]...)
+	next := int64(binary.BigEndian.Uint32(cell[local:]))
+	for len(payload) < size {
+		page, err := d.page(next)
+		if err != nil {
+			return nil, err
+		}
+		chunk := min(size-len(payload), d.usable-4)
+		payload = append(payload, page[4:4+chunk]...)
+		next = int64(binary.BigEndian.Uint32(page))
+	}
+	return payload, nil
+}
+
+// decodeSQLiteRecord decodes a record into int64, float64, string, []byte
+// and nil values
+func decodeSQLiteRecord(record []byte) ([]interface{}, error) {
+	corrupt := fmt.Errorf("history database is corrupt: invalid record")
+	headerSize, n := sqliteVarint(record)
+	if n == 0 || headerSize > uint64(len(record)) {
+		return nil, corrupt
+	}
+	header, body := record[n:headerSize], record[headerSize:]
+
+	var values []interface{}
+	for len(header) > 0 {
+		serialType, n := sqliteVarint(header)
+		if n == 0 {
+			return nil, corrupt
+		}
+		header = header[n:]
+
+		var size int
+		switch {
+		case serialType >= 1 && serialType <= 4:
+			size = int(serialType)
+		case serialType == 5:
+			size = 6
+		case serialType == 6 || serialType == 7:
+			size = 8
+		case serialType >= 12:
+			size = int((serialType - 12) / 2)
+		}
+		if size > len(body) {
+			return nil, corrupt
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values = append(values, nil)
+		case serialType <= 6:
+			var v int64
+			for i, b := range data {
+				if i == 0 {
+					v = int64(int8(b))
+				} else {
+					v = v<<8 | int64(b)
+				}
+			}
+			values = append(values, v)
+		case serialType == 7:
+			values = append(values, math.Float64frombits(binary.BigEndian.Uint64(data)))
+		case serialType == 8:
+			values = append(values, int64(0))
+		case serialType == 9:
+			values = append(values, int64(1))
+		case serialType >= 12 && serialType%2 == 0:
+			values = append(values, data)
+		case serialType >= 13:
+			values = append(values, string(data))
+		default:
+			return nil, corrupt
+		}
+	}
+	return values, nil
+}
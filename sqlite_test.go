@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSQLiteHistoryLargeDatabase(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "history.db")
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Enough rows for interior pages, and one row spilling onto overflow pages
+	var records []HistoryRecord
+	for i := 0; i < 20000; i++ {
+		records = append(records, HistoryRecord{
+			Time:        start.Add(time.Duration(i) * time.Hour),
+			Environment: fmt.Sprintf("env%d/prod", i%13),
+			Create:      i,
+			Delete:      -i,
+			Replace:     i * 100000,
+			RiskScore:   float64(i) / 4,
+			RiskLevel:   "medium",
+		})
+	}
+	records[7].Environment = strings.Repeat("long/", 3000)
+	if err := appendHistory(file, records[:10000]); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendHistory(file, records[10000:]); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), sqliteHeader) {
+		t.Fatal("Expected an SQLite database file")
+	}
+
+	read, err := readHistory(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != len(records) {
+		t.Fatalf("Expected %d records, got %d", len(records), len(read))
+	}
+	for i := range records {
+		if !read[i].Time.Equal(records[i].Time) || read[i].Environment != records[i].Environment ||
+			read[i].Create != records[i].Create || read[i].Delete != records[i].Delete ||
+			read[i].Replace != records[i].Replace || read[i].RiskScore != records[i].RiskScore ||
+			read[i].RiskLevel != records[i].RiskLevel {
+			t.Fatalf("Record %d: expected %+v, got %+v", i, records[i], read[i])
+		}
+	}
+}
+
+func TestSQLiteHistoryDetection(t *testing.T) {
+	dir := t.TempDir()
+	for name, expected := range map[string]bool{
+		"history.sqlite":  true,
+		"history.SQLITE3": true,
+		"history.db":      true,
+		"history.jsonl":   false,
+	} {
+		if got := isSQLiteHistory(filepath.Join(dir, name)); got != expected {
+			t.Errorf("isSQLiteHistory(%s) = %v, expected %v", name, got, expected)
+		}
+	}
+
+	database := filepath.Join(dir, "history")
+	if err := appendSQLiteHistory(database, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !isSQLiteHistory(database) {
+		t.Error("Expected an existing database to be detected by its header")
+	}
+	if records, err := readHistory(database); err != nil || len(records) != 0 {
+		t.Errorf("Expected an empty history, got %v, %v", records, err)
+	}
+
+	if err := os.WriteFile(database, []byte(sqliteHeader+"pages"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readHistory(database); err == nil {
+		t.Error("Expected a truncated database to be refused")
+	}
+}
+
+func TestSQLiteVarint(t *testing.T) {
+	for _, value := range []uint64{0, 127, 128, 16383, 16384, 1<<56 - 1, 1 << 56, 1<<64 - 1} {
+		encoded := appendSQLiteVarint(nil, value)
+		decoded, n := sqliteVarint(encoded)
+		if decoded != value || n != len(encoded) || n > 9 {
+			t.Errorf("Varint %d: encoded %x, decoded %d (%d bytes)", value, encoded, decoded, n)
+		}
+	}
+}
+
+func TestSQLiteTableColumns(t *testing.T) {
+	columns := sqliteTableColumns(`CREATE TABLE "history" (id INTEGER PRIMARY KEY, [time] TEXT, risk_score REAL DEFAULT (0.0), CHECK (id > 0))`)
+	if strings.Join(columns, ",") != "id,time,risk_score" {
+		t.Errorf("Unexpected columns %v", columns)
+	}
+}
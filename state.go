@@ -0,0 +1,267 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// StateValues represents the "values" representation used by prior_state and planned_values
+type StateValues struct {
+	RootModule StateModule `json:"root_module"`
+}
+
+// StateModule represents a module within a values representation
+type StateModule struct {
+	Address      string          `json:"address"`
+	Resources    []StateResource `json:"resources"`
+	ChildModules []StateModule   `json:"child_modules"`
+}
+
+// StateResource represents a single resource instance within a values representation
+type StateResource struct {
+	Address         string                 `json:"address"`
+	Mode            string                 `json:"mode"`
+	Type            string                 `json:"type"`
+	Name            string                 `json:"name"`
+	ProviderName    string                 `json:"provider_name"`
+	Values          map[string]interface{} `json:"values"`
+	SensitiveValues map[string]interface{} `json:"sensitive_values"`
+}
+
+// PriorState represents the plan's "prior_state" block
+type PriorState struct {
+	Values *StateValues `json:"values"`
+}
+
+// allResources returns every resource in the module tree
+func (m StateModule) allResources() []StateResource {
+	resources := append([]StateResource{}, m.Resources...)
+	for _, child := range m.ChildModules {
+		resources = append(resources, child.allResources()...)
+	}
+	return resources
+}
+
+// shortProviderName turns registry.terraform.io/hashicorp/aws into aws
+func shortProviderName(providerName string) string {
+	if idx := strings.LastIndex(providerName, "/"); idx >= 0 {
+		return providerName[idx+1:]
+	}
+	return providerName
+}
+
+// planAction classifies a resource change's actions the same way the report does
+func planAction(actions []string) string {
+	switch {
+	case containsAction(actions, "create") && containsAction(actions, "delete"):
+		return "replace"
+	case containsAction(actions, "create"):
+		return "create"
+	case containsAction(actions, "update"):
+		return "update"
+	case containsAction(actions, "delete"):
+		return "delete"
+	case containsAction(actions, "read"):
+		return "read"
+	default:
+		return "no-op"
+	}
+}
+
+// formatPriorStateRows describes the managed resources per provider and type
+// before the change, alongside the planned changes to each type
+func formatPriorStateRows(plan *TerraformPlan) []string {
+	type typeStats struct {
+		provider string
+		managed  int
+		actions  map[string]int
+	}
+
+	stats := make(map[string]*typeStats)
+	get := func(resourceType, provider string) *typeStats {
+		if stats[resourceType] == nil {
+			stats[resourceType] = &typeStats{provider: shortProviderName(provider), actions: make(map[string]int)}
+		}
+		return stats[resourceType]
+	}
+
+	if plan.PriorState != nil && plan.PriorState.Values != nil {
+		for _, resource := range plan.PriorState.Values.RootModule.allResources() {
+			if resource.Mode != "managed" {
+				continue
+			}
+			get(resource.Type, resource.ProviderName).managed++
+		}
+	}
+
+	for _, change := range plan.ResourceChanges {
+		if change.Mode == "data" {
+			continue
+		}
+		action := planAction(change.Change.Actions)
+		if action == "no-op" || action == "read" {
+			continue
+		}
+		get(change.Type, change.ProviderName).actions[action]++
+	}
+
+	types := make([]string, 0, len(stats))
+	for resourceType := range stats {
+		types = append(types, resourceType)
+	}
+	sort.Strings(types)
+
+	var rows []string
+	for _, resourceType := range types {
+		s := stats[resourceType]
+
+		var planned []string
+		for _, action := range []string{"create", "update", "replace", "delete"} {
+			count := s.actions[action]
+			if count == 0 {
+				continue
+			}
+			if action == "create" {
+				planned = append(planned, fmt.Sprintf("%d create", count))
+			} else {
+				planned = append(planned, fmt.Sprintf("%d of %d %s", count, s.managed, action))
+			}
+		}
+		if len(planned) == 0 {
+			planned = append(planned, "-")
+		}
+
+		rows = append(rows, fmt.Sprintf("| %s | `%s` | %d | %s |",
+			s.provider, resourceType, s.managed, strings.Join(planned, ", ")))
+	}
+	return rows
+}
+
+// writePriorStateSection renders a table of managed resources before the change
+func writePriorStateSection(md *strings.Builder, plan *TerraformPlan) {
+	rows := formatPriorStateRows(plan)
+	if len(rows) == 0 {
+		return
+	}
+
+	md.WriteString("**🗂️ Managed resources before this change:**\n\n")
+	md.WriteString("| Provider | Resource Type | Managed | Planned Changes |\n")
+	md.WriteString("|----------|---------------|---------|-----------------|\n")
+	for _, row := range rows {
+		md.WriteString(row + "\n")
+	}
+	md.WriteString("\n")
+}
+
+// keyPlannedAttributes are the attributes most useful for sanity checking a new resource
+var keyPlannedAttributes = []string{
+	"name", "bucket", "instance_type", "instance_class", "node_type", "machine_type",
+	"ami", "engine", "engine_version", "cidr_block", "allocated_storage", "storage_type",
+	"size", "runtime", "memory_size", "availability_zone", "region", "location",
+}
+
+// plannedValuesByAddress indexes the planned values of every resource by
+// address, with sensitive values masked
+func plannedValuesByAddress(plan *TerraformPlan) map[string]map[string]interface{} {
+	values := make(map[string]map[string]interface{})
+	if plan.PlannedValues == nil {
+		return values
+	}
+	for _, resource := range plan.PlannedValues.RootModule.allResources() {
+		masked := make(map[string]interface{}, len(resource.Values))
+		for key, val := range resource.Values {
+			masked[key] = maskSensitive(val, resource.SensitiveValues, key)
+		}
+		values[resource.Address] = masked
+	}
+	return values
+}
+
+// resourceTypesByAddress indexes the type of every changed resource by address
+func resourceTypesByAddress(plan *TerraformPlan) map[string]string {
+	types := make(map[string]string, len(plan.ResourceChanges))
+	for _, rc := range plan.ResourceChanges {
+		types[rc.Address] = rc.Type
+	}
+	return types
+}
+
+// formatKeyAttributes describes the key attributes present in a resource's values
+func formatKeyAttributes(values map[string]interface{}) []string {
+	var attrs []string
+	for _, key := range keyPlannedAttributes {
+		val, ok := values[key]
+		if !ok || val == nil {
+			continue
+		}
+		attrs = append(attrs, fmt.Sprintf("%s: %s", key, formatAttributeValue(val)))
+	}
+	return attrs
+}
+
+// Supported -detail levels
+const (
+	detailSummary = "summary"
+	detailFull    = "full"
+)
+
+// plannedAttributeLines describes the planned attributes rendered under a
+// created resource: the key attributes with -show-planned, and with -detail
+// full the type's planned_attributes allowlist, or every known top-level
+// attribute when no allowlist matches the type
+func plannedAttributeLines(resourceType string, values map[string]interface{}, opts ReportOptions) []string {
+	if opts.Detail != detailFull {
+		if opts.ShowPlanned {
+			return formatKeyAttributes(values)
+		}
+		return nil
+	}
+
+	keys := allowedPlannedAttributes(resourceType, opts.PlannedAttributes)
+	if keys == nil {
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+	}
+
+	var attrs []string
+	for _, key := range keys {
+		val, ok := values[key]
+		if !ok || val == nil {
+			continue
+		}
+		attrs = append(attrs, fmt.Sprintf("%s: %s", key, formatAttributeValue(val)))
+	}
+	return attrs
+}
+
+// allowedPlannedAttributes returns the attributes allowlisted for a resource
+// type by every matching planned_attributes glob, or nil when none matches
+func allowedPlannedAttributes(resourceType string, allowlist map[string][]string) []string {
+	patterns := make([]string, 0, len(allowlist))
+	for pattern := range allowlist {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var keys []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, resourceType); !ok {
+			continue
+		}
+		if keys == nil {
+			keys = []string{}
+		}
+		for _, key := range allowlist[pattern] {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
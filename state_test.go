@@ -0,0 +1,132 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatPriorStateRows(t *testing.T) {
+	plan := &TerraformPlan{
+		PriorState: &PriorState{
+			Values: &StateValues{
+				RootModule: StateModule{
+					Resources: []StateResource{
+						{Address: "data.aws_caller_identity.current", Mode: "data", Type: "aws_caller_identity"},
+					},
+					ChildModules: []StateModule{
+						{
+							Resources: []StateResource{
+								{Address: "module.db.aws_db_instance.a", Mode: "managed", Type: "aws_db_instance", ProviderName: "registry.terraform.io/hashicorp/aws"},
+								{Address: "module.db.aws_db_instance.b", Mode: "managed", Type: "aws_db_instance", ProviderName: "registry.terraform.io/hashicorp/aws"},
+								{Address: "module.db.aws_db_instance.c", Mode: "managed", Type: "aws_db_instance", ProviderName: "registry.terraform.io/hashicorp/aws"},
+								{Address: "module.db.aws_db_instance.d", Mode: "managed", Type: "aws_db_instance", ProviderName: "registry.terraform.io/hashicorp/aws"},
+							},
+						},
+					},
+				},
+			},
+		},
+		ResourceChanges: []ResourceChange{
+			{Address: "module.db.aws_db_instance.a", Mode: "managed", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+			{Address: "module.db.aws_db_instance.b", Mode: "managed", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+			{Address: "module.db.aws_db_instance.c", Mode: "managed", Type: "aws_db_instance", Change: Change{Actions: []string{"delete"}}},
+			{Address: "random_id.suffix", Mode: "managed", Type: "random_id", ProviderName: "registry.terraform.io/hashicorp/random", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+
+	expected := []string{
+		"| aws | `aws_db_instance` | 4 | 3 of 4 delete |",
+		"| random | `random_id` | 0 | 1 create |",
+	}
+
+	rows := formatPriorStateRows(plan)
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(expected), len(rows), rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("Row %d: expected %s, got %s", i, expected[i], rows[i])
+		}
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{ShowPriorState: true})
+	if !strings.Contains(markdown, "Managed resources before this change") {
+		t.Error("Expected prior state section when ShowPriorState is set")
+	}
+}
+
+func TestShowPlannedAttributes(t *testing.T) {
+	plan := &TerraformPlan{
+		PlannedValues: &StateValues{
+			RootModule: StateModule{
+				Resources: []StateResource{
+					{
+						Address: "aws_instance.web",
+						Values: map[string]interface{}{
+							"ami":           "ami-123",
+							"instance_type": "t3.large",
+							"tags":          map[string]interface{}{"Name": "web"},
+						},
+					},
+				},
+			},
+		},
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_instance.web", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{})
+	if strings.Contains(markdown, "instance_type") {
+		t.Error("Expected planned attributes to be hidden by default")
+	}
+
+	markdown = generateMarkdownComment(plan, ReportOptions{ShowPlanned: true})
+	if !strings.Contains(markdown, "- `aws_instance.web`\n  - instance_type: \"t3.large\"\n  - ami: \"ami-123\"\n") {
+		t.Errorf("Expected key planned attributes under created resource, got:\n%s", markdown)
+	}
+}
+
+func TestFullDetailPlannedAttributes(t *testing.T) {
+	plan := &TerraformPlan{
+		PlannedValues: &StateValues{
+			RootModule: StateModule{
+				Resources: []StateResource{
+					{
+						Address: "aws_instance.web",
+						Type:    "aws_instance",
+						Values: map[string]interface{}{
+							"ami":           "ami-123",
+							"instance_type": "t3.large",
+							"monitoring":    true,
+							"user_data":     nil,
+						},
+					},
+				},
+			},
+		},
+		ResourceChanges: []ResourceChange{
+			{Address: "aws_instance.web", Type: "aws_instance", Change: Change{Actions: []string{"create"}}},
+		},
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{Detail: detailFull})
+	if !strings.Contains(markdown, "- `aws_instance.web`\n  - ami: \"ami-123\"\n  - instance_type: \"t3.large\"\n  - monitoring: true\n") {
+		t.Errorf("Expected every known planned attribute, got:\n%s", markdown)
+	}
+	if strings.Contains(markdown, "user_data") {
+		t.Error("Expected null attributes to be omitted")
+	}
+
+	opts := ReportOptions{Detail: detailFull, PlannedAttributes: map[string][]string{"aws_*": {"instance_type"}}}
+	markdown = generateMarkdownComment(plan, opts)
+	if !strings.Contains(markdown, "  - instance_type: \"t3.large\"\n") || strings.Contains(markdown, "ami-123") {
+		t.Errorf("Expected only allowlisted attributes, got:\n%s", markdown)
+	}
+
+	plans := []PlanInfo{{Plan: plan, RelativePath: "prod"}}
+	markdown = generateMultiPlanMarkdownComment(plans, opts)
+	if !strings.Contains(markdown, "- `aws_instance.web`\n  - instance_type: \"t3.large\"\n") {
+		t.Errorf("Expected allowlisted attributes in environment details, got:\n%s", markdown)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxStatsDPacket keeps datagrams under typical network MTUs
+const maxStatsDPacket = 1432
+
+// repositoryEnvVars name the repository in common CI systems, in order of preference
+var repositoryEnvVars = []string{"GITHUB_REPOSITORY", "CI_PROJECT_PATH", "BITBUCKET_REPO_FULL_NAME", "BUILDKITE_PIPELINE_SLUG"}
+
+// ciRepository returns the repository the run belongs to, when running in CI
+func ciRepository() string {
+	for _, name := range repositoryEnvVars {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// formatStatsDLines renders metric families as DogStatsD gauges, e.g.
+// tfplan.resource_changes:3|g|#action:create,environment:prod
+func formatStatsDLines(families []metricFamily, repository string) []string {
+	var lines []string
+	for _, family := range families {
+		name := strings.Replace(family.name, "tfplan_", "tfplan.", 1)
+		for _, sample := range family.samples {
+			tags := make(map[string]string, len(sample.labels)+1)
+			for key, value := range sample.labels {
+				tags[key] = value
+			}
+			if repository != "" {
+				tags["repository"] = repository
+			}
+			lines = append(lines, fmt.Sprintf("%s:%g|g%s", name, sample.value, formatStatsDTags(tags)))
+		}
+	}
+	return lines
+}
+
+func formatStatsDTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Commas and pipes separate tags and fields, so they can't appear in values
+	sanitizer := strings.NewReplacer(",", "_", "|", "_", "\n", " ")
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + ":" + sanitizer.Replace(tags[key])
+	}
+	return "|#" + strings.Join(pairs, ",")
+}
+
+// sendStatsD sends metrics to a StatsD or DogStatsD agent over UDP, batching
+// lines into as few datagrams as fit
+func sendStatsD(address string, families []metricFamily, repository string) error {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to StatsD agent: %w", err)
+	}
+	defer conn.Close()
+
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+
+	for _, line := range formatStatsDLines(families, repository) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > maxStatsDPacket {
+			if err := flush(); err != nil {
+				return fmt.Errorf("failed to send metrics: %w", err)
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteString("\n")
+		}
+		packet.WriteString(line)
+	}
+	if err := flush(); err != nil {
+		return fmt.Errorf("failed to send metrics: %w", err)
+	}
+	return nil
+}
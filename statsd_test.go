@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSendStatsD(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	families := []metricFamily{
+		{name: "tfplan_resource_changes", samples: []metricSample{
+			{labels: map[string]string{"environment": "prod,eu", "action": "delete"}, value: 2},
+		}},
+		{name: "tfplan_parse_duration_seconds", samples: []metricSample{{value: 0.25}}},
+	}
+	if err := sendStatsD(listener.LocalAddr().String(), families, "org/infra"); err != nil {
+		t.Fatal(err)
+	}
+
+	buffer := make([]byte, maxStatsDPacket)
+	listener.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := listener.ReadFrom(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "tfplan.resource_changes:2|g|#action:delete,environment:prod_eu,repository:org/infra\n" +
+		"tfplan.parse_duration_seconds:0.25|g|#repository:org/infra"
+	if got := string(buffer[:n]); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+
+	lines := formatStatsDLines(families, "")
+	if !strings.HasSuffix(lines[1], "|g") {
+		t.Errorf("Expected untagged gauge without repository, got %q", lines[1])
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Built-in report styles selectable with -style
+const (
+	styleDefault   = "default"
+	styleCompact   = "compact"
+	styleDetailed  = "detailed"
+	styleChangelog = "changelog"
+)
+
+var reportStyles = []string{styleDefault, styleCompact, styleDetailed, styleChangelog}
+
+func isValidStyle(style string) bool {
+	for _, s := range reportStyles {
+		if style == s {
+			return true
+		}
+	}
+	return false
+}
+
+func formatStyles() string {
+	return strings.Join(reportStyles, "|")
+}
+
+// applyStyle turns on the options a built-in style implies. Options enabled
+// by their own flags stay enabled, so a style can be extended but not trimmed.
+func applyStyle(opts *ReportOptions, style string) {
+	opts.Style = style
+	switch style {
+	case styleCompact:
+		opts.SummaryOnly = true
+	case styleDetailed:
+		opts.Detail = detailFull
+		opts.ShowReads = true
+		opts.ShowNoOp = true
+		opts.ShowPriorState = true
+		opts.ShowProviders = true
+		opts.ShowRegions = true
+		opts.LinkDocs = true
+	}
+}
+
+// generateChangelogMarkdown renders the changelog style: one bullet per
+// changed resource under Added / Changed / Replaced / Removed / Moved
+// headings, per environment in multi-plan reports
+func generateChangelogMarkdown(plans []PlanInfo, opts ReportOptions) string {
+	return "## 📋 Terraform Plan Changelog\n\n" + changelogSections(plans, opts)
+}
+
+// changelogSections renders the Keep a Changelog style sections of plans,
+// under an environment heading each when there are several
+func changelogSections(plans []PlanInfo, opts ReportOptions) string {
+	var md strings.Builder
+
+	for _, planInfo := range plans {
+		heading := "###"
+		if len(plans) > 1 || planInfo.RelativePath != "" {
+			md.WriteString(fmt.Sprintf("### %s\n\n", formatEnvironment(planInfo.RelativePath, opts.EnvironmentNames)))
+			heading = "####"
+		}
+
+		summary := analyzePlan(planInfo.Plan)
+		applyIgnoreRules(&summary, planInfo.Plan, opts.IgnoreRules)
+		applyBaselineAttributes(&summary, planInfo.RelativePath, opts.Baseline)
+		sortSummary(&summary, planInfo.Plan, opts.Sort, opts.SortReverse)
+
+		entries := []struct {
+			title     string
+			resources []ResourceDetail
+		}{
+			{"Added", summary.Create},
+			{"Changed", summary.Update},
+			{"Replaced", summary.Replace},
+			{"Removed", summary.Delete},
+			{"Moved", summary.Move},
+		}
+
+		empty := true
+		for _, entry := range entries {
+			if len(entry.resources) == 0 {
+				continue
+			}
+			empty = false
+			md.WriteString(fmt.Sprintf("%s %s\n\n", heading, entry.title))
+			for _, resource := range entry.resources {
+				md.WriteString(formatChangelogEntry(resource) + "\n")
+			}
+			md.WriteString("\n")
+		}
+		if empty {
+			md.WriteString("No changes.\n\n")
+		}
+	}
+
+	return md.String()
+}
+
+// formatChangelogEntry renders one changelog bullet
+func formatChangelogEntry(resource ResourceDetail) string {
+	if resource.PreviousAddress != "" {
+		return fmt.Sprintf("- `%s` → `%s`", resource.PreviousAddress, resource.Address)
+	}
+
+	line := fmt.Sprintf("- `%s`", resource.Address)
+	if len(resource.Changes) > 0 {
+		attributes := make([]string, len(resource.Changes))
+		for i, change := range resource.Changes {
+			attributes[i] = change.Attribute
+		}
+		line += " (" + strings.Join(attributes, ", ") + ")"
+	}
+	if resource.ForceReason != "" {
+		line += " - " + resource.ForceReason
+	}
+	return line
+}
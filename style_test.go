@@ -0,0 +1,60 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyStyle(t *testing.T) {
+	var opts ReportOptions
+	applyStyle(&opts, styleCompact)
+	if !opts.SummaryOnly {
+		t.Error("Expected compact style to render summaries only")
+	}
+
+	opts = ReportOptions{Detail: detailSummary}
+	applyStyle(&opts, styleDetailed)
+	if opts.Detail != detailFull || !opts.ShowProviders || !opts.ShowReads {
+		t.Errorf("Expected detailed style to enable optional sections, got %+v", opts)
+	}
+
+	opts = ReportOptions{ShowReads: true}
+	applyStyle(&opts, styleDefault)
+	if !opts.ShowReads || opts.Style != styleDefault {
+		t.Error("Expected the default style to keep flag-enabled options")
+	}
+}
+
+func TestChangelogStyle(t *testing.T) {
+	plan := &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: []ResourceChange{
+		{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}},
+		{
+			Address: "aws_instance.web",
+			Type:    "aws_instance",
+			Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"instance_type": "t3.small"},
+				After:   map[string]interface{}{"instance_type": "t3.large"},
+			},
+		},
+		{Address: "aws_s3_bucket.old", Type: "aws_s3_bucket", Change: Change{Actions: []string{"delete"}}},
+	}}
+
+	result := generateMarkdownComment(plan, ReportOptions{Style: styleChangelog})
+	expected := "## 📋 Terraform Plan Changelog\n\n" +
+		"### Added\n\n- `aws_sqs_queue.q`\n\n" +
+		"### Changed\n\n- `aws_instance.web` (instance_type)\n\n" +
+		"### Removed\n\n- `aws_s3_bucket.old` - Resource marked for deletion\n\n"
+	if result != expected {
+		t.Errorf("Unexpected changelog:\n%s", result)
+	}
+
+	plans := []PlanInfo{{Plan: plan, RelativePath: "prod"}, {Plan: &TerraformPlan{}, RelativePath: "dev"}}
+	result = generateMultiPlanMarkdownComment(plans, ReportOptions{Style: styleChangelog})
+	if !strings.Contains(result, "### `prod`\n\n#### Added\n\n- `aws_sqs_queue.q`\n") {
+		t.Errorf("Expected changelog entries per environment, got:\n%s", result)
+	}
+	if !strings.Contains(result, "### `dev`\n\nNo changes.\n") {
+		t.Errorf("Expected unchanged environments to be noted, got:\n%s", result)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// plannedTagAttributes hold resource tags or labels, in order of preference. AWS
+// tags_all includes provider default_tags, so it's checked before tags.
+var plannedTagAttributes = []string{"tags_all", "tags", "labels"}
+
+// TagPolicy lists tags resources must carry, under "tag_policy" in the -config file
+type TagPolicy struct {
+	// Required tags for every taggable resource, e.g. ["cost-center", "owner"]
+	Required []string `json:"required"`
+	// Types maps resource type globs to additional required tags
+	Types map[string][]string `json:"types"`
+	// Fail exits with code 3 when a resource is missing required tags
+	Fail bool `json:"fail"`
+}
+
+// TagViolation is a created or updated resource missing required tags
+type TagViolation struct {
+	Address string
+	Missing []string
+}
+
+func (p *TagPolicy) validate() error {
+	for pattern := range p.Types {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tag policy resource type pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// requiredFor returns the tags required for a resource type
+func (p *TagPolicy) requiredFor(resourceType string) []string {
+	required := append([]string{}, p.Required...)
+	for pattern, tags := range p.Types {
+		if ok, _ := path.Match(pattern, resourceType); ok {
+			required = append(required, tags...)
+		}
+	}
+	sort.Strings(required)
+
+	// Drop duplicates from overlapping patterns
+	unique := required[:0]
+	for i, tag := range required {
+		if i == 0 || tag != required[i-1] {
+			unique = append(unique, tag)
+		}
+	}
+	return unique
+}
+
+// checkTagPolicy lists created, updated and replaced resources missing
+// required tags. Resources without a tags or labels attribute aren't taggable
+// and are skipped, as are tags not known until apply.
+func checkTagPolicy(plan *TerraformPlan, policy *TagPolicy) []TagViolation {
+	if policy == nil {
+		return nil
+	}
+
+	var violations []TagViolation
+	for _, rc := range plan.ResourceChanges {
+		if rc.Mode == "data" {
+			continue
+		}
+		switch planAction(rc.Change.Actions) {
+		case "create", "update", "replace":
+		default:
+			continue
+		}
+		after, ok := rc.Change.After.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		attribute, taggable := tagAttribute(after, rc.Change.AfterUnknown)
+		if !taggable {
+			continue
+		}
+		tags, _ := after[attribute].(map[string]interface{})
+		unknown := childMarker(rc.Change.AfterUnknown, attribute)
+
+		var missing []string
+		for _, tag := range policy.requiredFor(rc.Type) {
+			if value, _ := tags[tag].(string); value == "" && !isUnknown(childMarker(unknown, tag)) {
+				missing = append(missing, tag)
+			}
+		}
+		if len(missing) > 0 {
+			violations = append(violations, TagViolation{Address: rc.Address, Missing: missing})
+		}
+	}
+	return violations
+}
+
+// tagAttribute returns the attribute holding a resource's planned tags, and
+// whether the resource is taggable with tags known at plan time
+func tagAttribute(after map[string]interface{}, afterUnknown interface{}) (string, bool) {
+	for _, attribute := range plannedTagAttributes {
+		if _, ok := after[attribute]; ok {
+			return attribute, true
+		}
+		if childMarker(afterUnknown, attribute) == true {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+func formatTagViolation(violation TagViolation) string {
+	missing := make([]string, len(violation.Missing))
+	for i, tag := range violation.Missing {
+		missing[i] = fmt.Sprintf("`%s`", tag)
+	}
+	return fmt.Sprintf("`%s` - missing %s", violation.Address, strings.Join(missing, ", "))
+}
+
+// writeTagPolicySection renders resources missing required tags
+func writeTagPolicySection(md *strings.Builder, plan *TerraformPlan, policy *TagPolicy) {
+	violations := checkTagPolicy(plan, policy)
+	if len(violations) == 0 {
+		return
+	}
+
+	md.WriteString("### 🔖 Missing Required Tags\n\n")
+	for _, violation := range violations {
+		md.WriteString(fmt.Sprintf("- %s\n", formatTagViolation(violation)))
+	}
+	md.WriteString("\n")
+}
+
+// writeEnvironmentTagPolicySection renders resources missing required tags inside a multi-plan environment section
+func writeEnvironmentTagPolicySection(md *strings.Builder, plan *TerraformPlan, policy *TagPolicy) {
+	violations := checkTagPolicy(plan, policy)
+	if len(violations) == 0 {
+		return
+	}
+
+	md.WriteString("**🔖 Missing required tags:**\n")
+	for _, violation := range violations {
+		md.WriteString(fmt.Sprintf("- %s\n", formatTagViolation(violation)))
+	}
+	md.WriteString("\n")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckTagPolicy(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"tag_policy": {
+		"required": ["owner", "environment"],
+		"types": {"aws_db_*": ["cost-center"]}
+	}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	change := func(address, resourceType string, after map[string]interface{}, afterUnknown interface{}) ResourceChange {
+		return ResourceChange{
+			Address: address,
+			Type:    resourceType,
+			Change:  Change{Actions: []string{"create"}, After: after, AfterUnknown: afterUnknown},
+		}
+	}
+	plan := &TerraformPlan{ResourceChanges: []ResourceChange{
+		// Provider default_tags show up in tags_all
+		change("aws_s3_bucket.logs", "aws_s3_bucket", map[string]interface{}{
+			"tags":     map[string]interface{}{"owner": "sre"},
+			"tags_all": map[string]interface{}{"owner": "sre", "environment": "prod"},
+		}, nil),
+		change("aws_db_instance.main", "aws_db_instance", map[string]interface{}{
+			"tags": map[string]interface{}{"owner": "", "environment": "prod"},
+		}, nil),
+		change("google_storage_bucket.data", "google_storage_bucket", map[string]interface{}{
+			"labels": nil,
+		}, nil),
+		// Tags computed during apply can't be checked
+		change("aws_instance.web", "aws_instance", map[string]interface{}{
+			"tags": map[string]interface{}{"environment": "prod"},
+		}, map[string]interface{}{"tags": map[string]interface{}{"owner": true}}),
+		change("aws_route.default", "aws_route", map[string]interface{}{"route_table_id": "rtb-1"}, nil),
+	}}
+
+	result := generateMarkdownComment(plan, ReportOptions{TagPolicy: config.TagPolicy})
+	expected := "### 🔖 Missing Required Tags\n\n" +
+		"- `aws_db_instance.main` - missing `cost-center`, `owner`\n" +
+		"- `google_storage_bucket.data` - missing `environment`, `owner`\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected tag compliance section, got:\n%s", result)
+	}
+}
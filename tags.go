@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// tagAttributes are the top-level attributes that hold resource tags or labels
+var tagAttributes = map[string]bool{
+	"tags":   true,
+	"labels": true,
+}
+
+// TagChange is a single added, removed or changed tag
+type TagChange struct {
+	Attribute string // tags or labels
+	Key       string
+	Before    interface{}
+	After     interface{}
+	IsNew     bool
+	IsRemoved bool
+}
+
+// attributeRoot returns the top-level attribute of an attribute path
+func attributeRoot(path string) string {
+	if i := strings.IndexAny(path, ".["); i >= 0 {
+		return path[:i]
+	}
+	return path
+}
+
+// tagKey extracts the tag key from a path such as tags.Owner or tags["kubernetes.io/role"]
+func tagKey(path string) string {
+	rest := strings.TrimPrefix(path, attributeRoot(path))
+	if strings.HasPrefix(rest, "[\"") && strings.HasSuffix(rest, "\"]") {
+		return rest[2 : len(rest)-2]
+	}
+	return strings.TrimPrefix(rest, ".")
+}
+
+// splitTagChanges separates tag changes from other attribute changes. A tag map
+// that appears or disappears as a whole is expanded into individual tags.
+func splitTagChanges(changes []AttributeChange) ([]TagChange, []AttributeChange) {
+	var tags []TagChange
+	var other []AttributeChange
+
+	for _, change := range changes {
+		root := attributeRoot(change.Attribute)
+		if !tagAttributes[root] || change.Note != "" {
+			other = append(other, change)
+			continue
+		}
+
+		if change.Attribute != root {
+			tags = append(tags, TagChange{
+				Attribute: root,
+				Key:       tagKey(change.Attribute),
+				Before:    change.Before,
+				After:     change.After,
+				IsNew:     change.IsNew,
+				IsRemoved: change.IsRemoved,
+			})
+			continue
+		}
+
+		// The whole map changed, e.g. from null to a set of tags
+		before, beforeOk := change.Before.(map[string]interface{})
+		after, afterOk := change.After.(map[string]interface{})
+		if (change.Before != nil && !beforeOk) || (change.After != nil && !afterOk) {
+			other = append(other, change)
+			continue
+		}
+		for _, key := range unionKeys(before, after, nil) {
+			beforeVal, inBefore := before[key]
+			afterVal, inAfter := after[key]
+			if inBefore && inAfter && deepEqual(beforeVal, afterVal) {
+				continue
+			}
+			tags = append(tags, TagChange{
+				Attribute: root,
+				Key:       key,
+				Before:    beforeVal,
+				After:     afterVal,
+				IsNew:     !inBefore,
+				IsRemoved: !inAfter,
+			})
+		}
+	}
+
+	return tags, other
+}
+
+// splitTagOnlyResources separates resources whose only changes are to tags
+func splitTagOnlyResources(resources []ResourceDetail) ([]ResourceDetail, []ResourceDetail) {
+	var tagOnly, rest []ResourceDetail
+	for _, resource := range resources {
+		tags, other := splitTagChanges(resource.Changes)
+		if len(tags) > 0 && len(other) == 0 {
+			tagOnly = append(tagOnly, resource)
+		} else {
+			rest = append(rest, resource)
+		}
+	}
+	return tagOnly, rest
+}
+
+// formatTagTable renders tag changes as a compact markdown table
+func formatTagTable(tags []TagChange) string {
+	var table strings.Builder
+	table.WriteString("| Tag | Before | After |\n")
+	table.WriteString("|-----|--------|-------|\n")
+	for _, tag := range tags {
+		before, after := formatAttributeValue(tag.Before), formatAttributeValue(tag.After)
+		if tag.IsNew {
+			before = "*(added)*"
+		}
+		if tag.IsRemoved {
+			after = "*(removed)*"
+		}
+		key := tag.Key
+		if tag.Attribute != "tags" {
+			key = fmt.Sprintf("%s.%s", tag.Attribute, tag.Key)
+		}
+		table.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n",
+			key, strings.ReplaceAll(before, "|", "\\|"), strings.ReplaceAll(after, "|", "\\|")))
+	}
+	return table.String()
+}
+
+// tagChangeGroup is a set of resources sharing an identical tag diff
+type tagChangeGroup struct {
+	Table     string
+	Resources []ResourceDetail
+}
+
+// groupTagOnlyResources groups resources by identical tag diffs so bulk tag
+// rollouts render as one table instead of one per resource
+func groupTagOnlyResources(resources []ResourceDetail) []tagChangeGroup {
+	var groups []tagChangeGroup
+	index := make(map[string]int)
+	for _, resource := range resources {
+		tags, _ := splitTagChanges(resource.Changes)
+		table := formatTagTable(tags)
+		if i, ok := index[table]; ok {
+			groups[i].Resources = append(groups[i].Resources, resource)
+			continue
+		}
+		index[table] = len(groups)
+		groups = append(groups, tagChangeGroup{Table: table, Resources: []ResourceDetail{resource}})
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(groups[i].Resources) > len(groups[j].Resources)
+	})
+	return groups
+}
+
+// writeTagOnlySection renders resources whose only changes are tag changes
+func writeTagOnlySection(md *strings.Builder, resources []ResourceDetail) {
+	if len(resources) == 0 {
+		return
+	}
+
+	md.WriteString("### 🏷️ Tag-only Changes\n\n")
+	md.WriteString(fmt.Sprintf("**Tag-only changes: %d resource(s)**\n\n", len(resources)))
+	for _, group := range groupTagOnlyResources(resources) {
+		md.WriteString(group.Table)
+		md.WriteString("\n")
+		md.WriteString(fmt.Sprintf("<details>\n<summary>Applies to %d resource(s)</summary>\n\n", len(group.Resources)))
+		for _, resource := range group.Resources {
+			md.WriteString(fmt.Sprintf("- %s\n", formatResourceLabel(resource)))
+		}
+		md.WriteString("\n</details>\n\n")
+	}
+}
+
+// writeEnvironmentTagOnlySection renders the tag-only rollup inside a multi-plan environment section
+func writeEnvironmentTagOnlySection(md *strings.Builder, resources []ResourceDetail) {
+	if len(resources) == 0 {
+		return
+	}
+
+	md.WriteString(fmt.Sprintf("**🏷️ Tag-only changes:** %d resource(s) - %s\n\n",
+		len(resources), formatResourceList(resources, 3)))
+}
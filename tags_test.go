@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitTagChanges(t *testing.T) {
+	changes := []AttributeChange{
+		{Attribute: "instance_type", Before: "t3.micro", After: "t3.small"},
+		{Attribute: "tags.Owner", After: "sre", IsNew: true},
+		{Attribute: `tags["kubernetes.io/role"]`, Before: "old", After: "new"},
+		{Attribute: "labels", Before: nil, After: map[string]interface{}{"env": "prod"}},
+	}
+
+	tags, other := splitTagChanges(changes)
+	if len(other) != 1 || other[0].Attribute != "instance_type" {
+		t.Errorf("Expected instance_type to remain, got %+v", other)
+	}
+	if len(tags) != 3 {
+		t.Fatalf("Expected 3 tag changes, got %+v", tags)
+	}
+	if tags[0].Key != "Owner" || !tags[0].IsNew {
+		t.Errorf("Unexpected tag change: %+v", tags[0])
+	}
+	if tags[1].Key != "kubernetes.io/role" {
+		t.Errorf("Expected quoted key to be unwrapped, got %+v", tags[1])
+	}
+	if tags[2].Attribute != "labels" || tags[2].Key != "env" || !tags[2].IsNew {
+		t.Errorf("Expected labels map to be expanded, got %+v", tags[2])
+	}
+}
+
+func TestTagOnlyChangesGrouped(t *testing.T) {
+	var changes []ResourceChange
+	for _, name := range []string{"a", "b", "c"} {
+		changes = append(changes, ResourceChange{
+			Address: "aws_s3_bucket." + name,
+			Type:    "aws_s3_bucket",
+			Change: Change{
+				Actions: []string{"update"},
+				Before:  map[string]interface{}{"tags": map[string]interface{}{"CostCenter": "100"}},
+				After:   map[string]interface{}{"tags": map[string]interface{}{"CostCenter": "200"}},
+			},
+		})
+	}
+	changes = append(changes, ResourceChange{
+		Address: "aws_instance.web",
+		Type:    "aws_instance",
+		Change: Change{
+			Actions: []string{"update"},
+			Before:  map[string]interface{}{"instance_type": "t3.micro", "tags": map[string]interface{}{}},
+			After:   map[string]interface{}{"instance_type": "t3.small", "tags": map[string]interface{}{"Owner": "sre"}},
+		},
+	})
+
+	result := generateMarkdownComment(&TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: changes}, ReportOptions{})
+
+	if !strings.Contains(result, "**Tag-only changes: 3 resource(s)**") {
+		t.Errorf("Expected tag-only rollup, got:\n%s", result)
+	}
+	if strings.Count(result, "| `CostCenter` | \"100\" | \"200\" |") != 1 {
+		t.Errorf("Expected a single shared tag table, got:\n%s", result)
+	}
+	if strings.Contains(result, "#### `aws_s3_bucket.a`") {
+		t.Error("Expected tag-only resources to be left out of the update details")
+	}
+	if !strings.Contains(result, "**Tags:**\n\n| Tag | Before | After |\n|-----|--------|-------|\n| `Owner` | *(added)* | \"sre\" |") {
+		t.Errorf("Expected tag table for mixed update, got:\n%s", result)
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// base64Attributes lists attributes that commonly carry base64-encoded scripts
+var base64Attributes = map[string]bool{
+	"user_data":        true,
+	"user_data_base64": true,
+	"custom_data":      true,
+}
+
+// isBase64Attribute reports whether the last segment of an attribute path
+// names a base64-encoded attribute
+func isBase64Attribute(path string) bool {
+	return base64Attributes[lastPathSegment(path)]
+}
+
+// lastPathSegment returns the final attribute name of a path such as settings[0].tier
+func lastPathSegment(path string) string {
+	if i := strings.LastIndexAny(path, ".]"); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// decodeBase64Text decodes base64 content, transparently gunzipping it as
+// cloud-init allows, and returns it only when the result is readable text
+func decodeBase64Text(value string) (string, bool) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil || len(data) == 0 {
+		return "", false
+	}
+
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return "", false
+		}
+		data, err = io.ReadAll(reader)
+		if err != nil {
+			return "", false
+		}
+	}
+
+	if !utf8.Valid(data) || bytes.IndexByte(data, 0) >= 0 {
+		return "", false
+	}
+	return string(data), true
+}
+
+// longStringThreshold is the length above which string changes are rendered as a diff block
+const longStringThreshold = 80
+
+// diffContextLines is the number of unchanged lines shown around each change
+const diffContextLines = 3
+
+// isLongStringChange reports whether a change is between strings that are
+// multi-line or too long to read comfortably inline
+func isLongStringChange(before, after interface{}) bool {
+	b, ok := before.(string)
+	if !ok {
+		return false
+	}
+	a, ok := after.(string)
+	if !ok {
+		return false
+	}
+	return strings.Contains(b, "\n") || strings.Contains(a, "\n") ||
+		len(b) > longStringThreshold || len(a) > longStringThreshold
+}
+
+// diffOp is a single line in an edit script
+type diffOp struct {
+	kind byte // ' ', '-' or '+'
+	text string
+}
+
+// maxDiffLines bounds the quadratic line alignment; longer texts are shown
+// whole as removed and added
+const maxDiffLines = 1000
+
+// diffLines computes a line-level edit script using the longest common subsequence
+func diffLines(before, after []string) []diffOp {
+	if len(before) > maxDiffLines || len(after) > maxDiffLines {
+		ops := make([]diffOp, 0, len(before)+len(after))
+		for _, line := range before {
+			ops = append(ops, diffOp{'-', line})
+		}
+		for _, line := range after {
+			ops = append(ops, diffOp{'+', line})
+		}
+		return ops
+	}
+
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			ops = append(ops, diffOp{' ', before[i]})
+			i++
+			j++
+		case i < len(before) && (j == len(after) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, diffOp{'-', before[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', after[j]})
+			j++
+		}
+	}
+	return ops
+}
+
+// unifiedDiff renders the difference between two texts as unified diff hunks
+func unifiedDiff(before, after string) string {
+	beforeLines := splitLines(before)
+	afterLines := splitLines(after)
+
+	// Single long lines are wrapped so the diff shows where they differ
+	if len(beforeLines) == 1 && len(afterLines) == 1 {
+		beforeLines = wrapLine(beforeLines[0], longStringThreshold)
+		afterLines = wrapLine(afterLines[0], longStringThreshold)
+	}
+
+	ops := diffLines(beforeLines, afterLines)
+
+	var out strings.Builder
+	for start := 0; start < len(ops); {
+		// Find the next change
+		first := start
+		for first < len(ops) && ops[first].kind == ' ' {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Extend the hunk while changes are within 2*context lines of each other
+		hunkStart := first - diffContextLines
+		if hunkStart < start {
+			hunkStart = start
+		}
+		end := first
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			gap := end
+			for gap < len(ops) && ops[gap].kind == ' ' {
+				gap++
+			}
+			if gap == len(ops) || gap-end > 2*diffContextLines {
+				break
+			}
+			end = gap
+		}
+		hunkEnd := end + diffContextLines
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		// Line numbers for the hunk header
+		beforeStart, afterStart := 1, 1
+		for _, op := range ops[:hunkStart] {
+			if op.kind != '+' {
+				beforeStart++
+			}
+			if op.kind != '-' {
+				afterStart++
+			}
+		}
+		beforeCount, afterCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				beforeCount++
+			}
+			if op.kind != '-' {
+				afterCount++
+			}
+		}
+
+		out.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", beforeStart, beforeCount, afterStart, afterCount))
+		for _, op := range ops[hunkStart:hunkEnd] {
+			out.WriteString(fmt.Sprintf("%c %s\n", op.kind, op.text))
+		}
+
+		start = hunkEnd
+	}
+
+	return out.String()
+}
+
+func splitLines(text string) []string {
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// wrapLine splits a long line into chunks of at most width runes
+func wrapLine(line string, width int) []string {
+	runes := []rune(line)
+	var lines []string
+	for len(runes) > width {
+		lines = append(lines, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(lines, string(runes))
+}
+
+// formatDiffBlock renders a unified diff as a fenced code block indented to
+// sit inside a markdown list item
+func formatDiffBlock(diff string) string {
+	fence := "```"
+	if strings.Contains(diff, "```") {
+		fence = "~~~"
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("  %sdiff\n", fence))
+	for _, line := range splitLines(diff) {
+		out.WriteString("  " + line + "\n")
+	}
+	out.WriteString(fmt.Sprintf("  %s\n", fence))
+	return out.String()
+}
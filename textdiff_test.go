@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	before := "#!/bin/bash\nset -e\napt-get update\napt-get install -y nginx\nsystemctl start nginx\n"
+	after := "#!/bin/bash\nset -e\napt-get update\napt-get install -y nginx curl\nsystemctl start nginx\n"
+
+	expected := "@@ -1,5 +1,5 @@\n" +
+		"  #!/bin/bash\n" +
+		"  set -e\n" +
+		"  apt-get update\n" +
+		"- apt-get install -y nginx\n" +
+		"+ apt-get install -y nginx curl\n" +
+		"  systemctl start nginx\n"
+
+	if diff := unifiedDiff(before, after); diff != expected {
+		t.Errorf("Unexpected diff:\n%s\nexpected:\n%s", diff, expected)
+	}
+}
+
+func TestUnifiedDiffSeparateHunks(t *testing.T) {
+	var beforeLines, afterLines []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1)
+		beforeLines = append(beforeLines, line)
+		afterLines = append(afterLines, line)
+	}
+	afterLines[1] = "changed-top"
+	afterLines[18] = "changed-bottom"
+
+	diff := unifiedDiff(strings.Join(beforeLines, "\n"), strings.Join(afterLines, "\n"))
+	if strings.Count(diff, "@@ -") != 2 {
+		t.Errorf("Expected 2 hunks, got:\n%s", diff)
+	}
+	if !strings.Contains(diff, "@@ -16,5 +16,5 @@") {
+		t.Errorf("Expected second hunk header with context, got:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiffLargeTexts(t *testing.T) {
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = strings.Repeat("y", i%50)
+	}
+	before := strings.Join(lines, "\n")
+	after := before + "\nappended"
+
+	diff := unifiedDiff(before, after)
+	expected := "@@ -1,20000 +1,20001 @@\n"
+	if !strings.HasPrefix(diff, expected) {
+		t.Errorf("Expected the whole value as one hunk, got:\n%.200s", diff)
+	}
+	if strings.Count(diff, "\n- ") != 20000 || !strings.HasSuffix(diff, "+ appended\n") {
+		t.Error("Expected every line of both values to be shown")
+	}
+}
+
+func TestLongStringChangeRendering(t *testing.T) {
+	change := AttributeChange{
+		Attribute: "user_data",
+		Before:    "line one\nline two\n",
+		After:     "line one\nline 2\n",
+	}
+
+	rendered := formatAttributeChange(change)
+	if !strings.Contains(rendered, "  ```diff\n") || !strings.Contains(rendered, "  - line two\n  + line 2\n") {
+		t.Errorf("Expected fenced diff block, got:\n%s", rendered)
+	}
+
+	short := AttributeChange{Attribute: "name", Before: "a", After: "b"}
+	if rendered := formatAttributeChange(short); rendered != "- **name**: \"a\" → \"b\"\n" {
+		t.Errorf("Expected short strings to stay inline, got %q", rendered)
+	}
+}
+
+func TestBase64UserDataDiff(t *testing.T) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte("#!/bin/bash\necho v2\n"))
+	writer.Close()
+
+	change := Change{
+		Actions: []string{"update"},
+		Before:  map[string]interface{}{"user_data": base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\necho v1\n"))},
+		After:   map[string]interface{}{"user_data": base64.StdEncoding.EncodeToString(compressed.Bytes())},
+	}
+
+	changes := analyzeAttributeChanges(change)
+	if len(changes) != 1 || !changes[0].Decoded {
+		t.Fatalf("Expected one decoded change, got %+v", changes)
+	}
+
+	rendered := formatAttributeChange(changes[0])
+	if !strings.Contains(rendered, "*(decoded from base64)*") || !strings.Contains(rendered, "  - echo v1\n  + echo v2\n") {
+		t.Errorf("Expected decoded script diff, got:\n%s", rendered)
+	}
+
+	if _, ok := decodeBase64Text("not base64!"); ok {
+		t.Error("Expected invalid base64 to be rejected")
+	}
+}
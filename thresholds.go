@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Threshold breach handling
+const (
+	thresholdFail = "fail"
+	thresholdWarn = "warn"
+)
+
+// ThresholdConfig limits how many changes a plan may make, under "thresholds"
+// in the -config file
+type ThresholdConfig struct {
+	Default ChangeLimits `json:"default"`
+	// Environments overrides limits for environment paths matching a glob, e.g. "prod/*"
+	Environments map[string]ChangeLimits `json:"environments"`
+	// OnExceed is "fail" (default) to exit non-zero or "warn" to only add a banner
+	OnExceed string `json:"on_exceed"`
+}
+
+// ChangeLimits are the maximum change counts allowed; nil means unlimited
+type ChangeLimits struct {
+	MaxCreates      *int `json:"max_creates"`
+	MaxUpdates      *int `json:"max_updates"`
+	MaxReplaces     *int `json:"max_replaces"`
+	MaxDeletes      *int `json:"max_deletes"`
+	MaxTotalChanges *int `json:"max_total_changes"`
+}
+
+func (t *ThresholdConfig) validate() error {
+	if t.OnExceed != "" && t.OnExceed != thresholdFail && t.OnExceed != thresholdWarn {
+		return fmt.Errorf("invalid thresholds on_exceed %q (supported: %s, %s)", t.OnExceed, thresholdFail, thresholdWarn)
+	}
+	for pattern := range t.Environments {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid thresholds environment pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// failsOnExceed reports whether breaching a threshold should fail the run
+func (t *ThresholdConfig) failsOnExceed() bool {
+	return t != nil && t.OnExceed != thresholdWarn
+}
+
+// limitsFor returns the limits for an environment: the defaults, overridden
+// field by field by every matching environment pattern in sorted order
+func (t *ThresholdConfig) limitsFor(env string) ChangeLimits {
+	limits := t.Default
+
+	patterns := make([]string, 0, len(t.Environments))
+	for pattern := range t.Environments {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, env); !ok {
+			continue
+		}
+		override := t.Environments[pattern]
+		for _, field := range []struct{ dst, src **int }{
+			{&limits.MaxCreates, &override.MaxCreates},
+			{&limits.MaxUpdates, &override.MaxUpdates},
+			{&limits.MaxReplaces, &override.MaxReplaces},
+			{&limits.MaxDeletes, &override.MaxDeletes},
+			{&limits.MaxTotalChanges, &override.MaxTotalChanges},
+		} {
+			if *field.src != nil {
+				*field.dst = *field.src
+			}
+		}
+	}
+	return limits
+}
+
+// checkThresholds returns the limits a plan's changes exceed, e.g. "600 deletions (max 0)"
+func checkThresholds(plan *TerraformPlan, env string, thresholds *ThresholdConfig) []string {
+	if thresholds == nil {
+		return nil
+	}
+
+	summary := analyzeResourceChanges(plan.ResourceChanges)
+	limits := thresholds.limitsFor(env)
+	total := len(summary.Create) + len(summary.Update) + len(summary.Replace) + len(summary.Delete)
+
+	var breaches []string
+	for _, check := range []struct {
+		count int
+		limit *int
+		noun  string
+	}{
+		{len(summary.Create), limits.MaxCreates, "creations"},
+		{len(summary.Update), limits.MaxUpdates, "updates"},
+		{len(summary.Replace), limits.MaxReplaces, "replacements"},
+		{len(summary.Delete), limits.MaxDeletes, "deletions"},
+		{total, limits.MaxTotalChanges, "total changes"},
+	} {
+		if check.limit != nil && check.count > *check.limit {
+			breaches = append(breaches, fmt.Sprintf("%d %s (max %d)", check.count, check.noun, *check.limit))
+		}
+	}
+	return breaches
+}
+
+// writeThresholdBanner renders a prominent warning when change thresholds are exceeded
+func writeThresholdBanner(md *strings.Builder, breaches []string) {
+	if len(breaches) == 0 {
+		return
+	}
+	md.WriteString(fmt.Sprintf("> 🚨 **Change thresholds exceeded:** %s\n\n", strings.Join(breaches, ", ")))
+}
+
+// formatEnvironmentSuffix renders " in <env>" for multi-plan messages
+func formatEnvironmentSuffix(env string) string {
+	if env == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in %s", env)
+}
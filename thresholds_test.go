@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckThresholds(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"thresholds": {
+		"default": {"max_deletes": 5, "max_total_changes": 200},
+		"environments": {"prod/*": {"max_deletes": 0}}
+	}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfig(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var changes []ResourceChange
+	for i := 0; i < 3; i++ {
+		changes = append(changes, ResourceChange{
+			Address: fmt.Sprintf("aws_instance.web[%d]", i),
+			Change:  Change{Actions: []string{"delete"}},
+		})
+	}
+	plan := &TerraformPlan{ResourceChanges: changes}
+
+	if breaches := checkThresholds(plan, "dev/us-east-1", config.Thresholds); len(breaches) != 0 {
+		t.Errorf("Expected dev to be within limits, got %v", breaches)
+	}
+	breaches := checkThresholds(plan, "prod/us-east-1", config.Thresholds)
+	if !reflect.DeepEqual(breaches, []string{"3 deletions (max 0)"}) {
+		t.Errorf("Unexpected prod breaches: %v", breaches)
+	}
+	if !config.Thresholds.failsOnExceed() {
+		t.Error("Expected thresholds to fail by default")
+	}
+
+	result := generateMultiPlanMarkdownComment([]PlanInfo{{Plan: plan, RelativePath: "prod/us-east-1"}}, ReportOptions{Thresholds: config.Thresholds})
+	if !strings.Contains(result, "> 🚨 **1 environment(s) exceed change thresholds**") ||
+		!strings.Contains(result, "> 🚨 **Change thresholds exceeded:** 3 deletions (max 0)") {
+		t.Errorf("Expected threshold banners, got:\n%s", result)
+	}
+}
+
+func TestInvalidThresholdAction(t *testing.T) {
+	thresholds := &ThresholdConfig{OnExceed: "explode"}
+	if err := thresholds.validate(); err == nil {
+		t.Error("Expected error for invalid on_exceed")
+	}
+}
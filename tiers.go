@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// otherTier collects environments that match no configured tier
+const otherTier = "Other"
+
+// TierConfig groups environments into a tier such as production or staging
+type TierConfig struct {
+	Name         string   `json:"name"`
+	Environments []string `json:"environments"` // Environment globs, e.g. "*prod*"
+	Collapsed    bool     `json:"collapsed"`    // Render the tier's environments in a collapsed section
+}
+
+// validateTiers checks tier names and environment patterns
+func validateTiers(tiers []TierConfig) error {
+	for _, tier := range tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("tiers require a name")
+		}
+		for _, pattern := range tier.Environments {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid environment pattern %q in tier %s: %w", pattern, tier.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// tierGroup is a tier and the environments assigned to it
+type tierGroup struct {
+	Name      string
+	Collapsed bool
+	Plans     []PlanInfo
+}
+
+// groupByTier assigns each environment to the first tier matching it, keeping
+// tiers in configured order. Unmatched environments form a final "Other" tier.
+func groupByTier(plans []PlanInfo, tiers []TierConfig) []tierGroup {
+	groups := make([]tierGroup, len(tiers)+1)
+	for i, tier := range tiers {
+		groups[i] = tierGroup{Name: tier.Name, Collapsed: tier.Collapsed}
+	}
+	groups[len(tiers)].Name = otherTier
+
+	for _, planInfo := range plans {
+		i := len(tiers)
+		for j, tier := range tiers {
+			if tier.matches(planInfo.RelativePath) {
+				i = j
+				break
+			}
+		}
+		groups[i].Plans = append(groups[i].Plans, planInfo)
+	}
+
+	var nonEmpty []tierGroup
+	for _, group := range groups {
+		if len(group.Plans) > 0 {
+			nonEmpty = append(nonEmpty, group)
+		}
+	}
+	return nonEmpty
+}
+
+func (t TierConfig) matches(env string) bool {
+	for _, pattern := range t.Environments {
+		if matchesEnvironment(pattern, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// tierChanges counts a tier's resource changes by action
+func tierChanges(group tierGroup) (create, update, replace, remove int) {
+	for _, planInfo := range group.Plans {
+		summary := analyzeResourceChanges(planInfo.Plan.ResourceChanges)
+		create += len(summary.Create)
+		update += len(summary.Update)
+		replace += len(summary.Replace)
+		remove += len(summary.Delete)
+	}
+	return create, update, replace, remove
+}
+
+// writeTierSummarySection renders subtotals per tier
+func writeTierSummarySection(md *strings.Builder, groups []tierGroup) {
+	md.WriteString("### 🧱 Tier Summary\n\n")
+	md.WriteString("| Tier | Environments | 🟢 Create | 🟡 Update | 🔄 Replace | 🔴 Delete |\n")
+	md.WriteString("|------|--------------|-----------|-----------|------------|-----------|\n")
+	for _, group := range groups {
+		create, update, replace, remove := tierChanges(group)
+		md.WriteString(fmt.Sprintf("| **%s** | %d | %d | %d | %d | %d |\n",
+			group.Name, len(group.Plans), create, update, replace, remove))
+	}
+	md.WriteString("\n")
+}
+
+// writeTieredEnvironmentDetails renders environment sections under their
+// tier, collapsing tiers configured as collapsed
+func writeTieredEnvironmentDetails(md *strings.Builder, groups []tierGroup, opts ReportOptions) {
+	for _, group := range groups {
+		md.WriteString(fmt.Sprintf("### 🧱 %s\n\n", group.Name))
+		if group.Collapsed {
+			create, update, replace, remove := tierChanges(group)
+			md.WriteString(fmt.Sprintf("<details>\n<summary>%d environment(s) with %d change(s)</summary>\n\n",
+				len(group.Plans), create+update+replace+remove))
+		}
+		for _, planInfo := range group.Plans {
+			writeEnvironmentDetails(md, planInfo, opts)
+		}
+		if group.Collapsed {
+			md.WriteString("</details>\n\n")
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTieredReport(t *testing.T) {
+	plan := func(actions ...string) *TerraformPlan {
+		p := &TerraformPlan{}
+		for i, action := range actions {
+			p.ResourceChanges = append(p.ResourceChanges, ResourceChange{
+				Address: "aws_s3_bucket.b" + string(rune('a'+i)),
+				Change:  Change{Actions: []string{action}},
+			})
+		}
+		return p
+	}
+	plans := []PlanInfo{
+		{Plan: plan("create"), RelativePath: "eu/dev"},
+		{Plan: plan("delete", "update"), RelativePath: "eu/prod"},
+		{Plan: plan("create"), RelativePath: "sandbox"},
+		{Plan: plan("create", "create"), RelativePath: "us/dev"},
+	}
+	tiers := []TierConfig{
+		{Name: "Production", Environments: []string{"prod"}},
+		{Name: "Development", Environments: []string{"dev"}, Collapsed: true},
+	}
+
+	groups := groupByTier(plans, tiers)
+	var names []string
+	for _, group := range groups {
+		names = append(names, group.Name)
+	}
+	if strings.Join(names, ",") != "Production,Development,Other" {
+		t.Fatalf("Unexpected tiers %v", names)
+	}
+	if len(groups[1].Plans) != 2 {
+		t.Errorf("Expected both dev environments in Development, got %d", len(groups[1].Plans))
+	}
+
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{Tiers: tiers})
+	for _, expected := range []string{
+		"| **Production** | 1 | 0 | 1 | 0 | 1 |\n",
+		"| **Development** | 2 | 3 | 0 | 0 | 0 |\n",
+		"### 🧱 Development\n\n<details>\n<summary>2 environment(s) with 3 change(s)</summary>",
+		"### 🧱 Other\n\n#### 📁 `sandbox`",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "Environment Details") {
+		t.Error("Expected tiers to replace the flat environment list")
+	}
+	if strings.Index(result, "### 🧱 Production") > strings.Index(result, "### 🧱 Development") {
+		t.Error("Expected tiers in configured order")
+	}
+
+	if err := validateTiers([]TierConfig{{Environments: []string{"prod"}}}); err == nil {
+		t.Error("Expected an error for a tier without a name")
+	}
+	if err := validateTiers([]TierConfig{{Name: "x", Environments: []string{"["}}}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultServiceName identifies the tool in exported traces unless OTEL_SERVICE_NAME is set
+const defaultServiceName = "tfplan-commenter"
+
+// tracer records the spans of one run for export over OTLP/HTTP. A nil
+// tracer records nothing, so phases can be instrumented unconditionally.
+type tracer struct {
+	traceID string
+	spans   []*span
+}
+
+// span is one timed phase of a run
+type span struct {
+	tracer     *tracer
+	id         string
+	parentID   string
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes map[string]interface{}
+}
+
+func newTracer() *tracer {
+	return &tracer{traceID: randomHex(16)}
+}
+
+func randomHex(n int) string {
+	id := make([]byte, n)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// start begins a span, nested under parent when one is given
+func (t *tracer) start(name string, parent *span) *span {
+	if t == nil {
+		return nil
+	}
+
+	s := &span{tracer: t, id: randomHex(8), name: name, start: time.Now(), attributes: make(map[string]interface{})}
+	if parent != nil {
+		s.parentID = parent.id
+	}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+// setAttribute annotates the span, e.g. with the number of plans processed
+func (s *span) setAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.attributes[key] = value
+}
+
+func (s *span) finish() {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+}
+
+// otlpTracesEndpoint returns the OTLP/HTTP traces URL from the flag or the
+// standard OTEL_EXPORTER_OTLP_* environment variables
+func otlpTracesEndpoint(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		return strings.TrimSuffix(endpoint, "/") + "/v1/traces"
+	}
+	return ""
+}
+
+// otlpHeaders parses OTEL_EXPORTER_OTLP_HEADERS, e.g. "api-key=secret,team=platform"
+func otlpHeaders(value string) map[string]string {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(key) != "" {
+			headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+	return headers
+}
+
+// otlpAttribute converts a span attribute to its OTLP JSON representation
+func otlpAttribute(key string, value interface{}) map[string]interface{} {
+	var encoded map[string]interface{}
+	switch v := value.(type) {
+	case int:
+		encoded = map[string]interface{}{"intValue": strconv.Itoa(v)}
+	case bool:
+		encoded = map[string]interface{}{"boolValue": v}
+	case float64:
+		encoded = map[string]interface{}{"doubleValue": v}
+	default:
+		encoded = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+	return map[string]interface{}{"key": key, "value": encoded}
+}
+
+// otlpPayload encodes the recorded spans as an OTLP ExportTraceServiceRequest in JSON
+func (t *tracer) otlpPayload(serviceName string) ([]byte, error) {
+	spans := make([]map[string]interface{}, 0, len(t.spans))
+	for _, s := range t.spans {
+		end := s.end
+		if end.IsZero() {
+			end = time.Now()
+		}
+
+		attributes := make([]map[string]interface{}, 0, len(s.attributes))
+		for _, key := range sortedKeys(s.attributes) {
+			attributes = append(attributes, otlpAttribute(key, s.attributes[key]))
+		}
+
+		encoded := map[string]interface{}{
+			"traceId":           t.traceID,
+			"spanId":            s.id,
+			"name":              s.name,
+			"kind":              1, // SPAN_KIND_INTERNAL
+			"startTimeUnixNano": strconv.FormatInt(s.start.UnixNano(), 10),
+			"endTimeUnixNano":   strconv.FormatInt(end.UnixNano(), 10),
+			"attributes":        attributes,
+		}
+		if s.parentID != "" {
+			encoded["parentSpanId"] = s.parentID
+		}
+		spans = append(spans, encoded)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []interface{}{
+					otlpAttribute("service.name", serviceName),
+					otlpAttribute("service.version", Version),
+				},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": defaultServiceName, "version": Version},
+				"spans": spans,
+			}},
+		}},
+	})
+}
+
+// export sends the recorded spans to an OTLP/HTTP collector
+func (t *tracer) export(endpoint string) error {
+	if t == nil || endpoint == "" {
+		return nil
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	payload, err := t.otlpPayload(serviceName)
+	if err != nil {
+		return fmt.Errorf("failed to encode traces: %w", err)
+	}
+
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("invalid OTLP endpoint: %w", err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range otlpHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")) {
+		request.Header.Set(key, value)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to export traces: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		return fmt.Errorf("OTLP collector returned %s", response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracerExport(t *testing.T) {
+	var received map[string]interface{}
+	var apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		apiKey = r.Header.Get("api-key")
+	}))
+	defer server.Close()
+
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "api-key=secret")
+	t.Setenv("OTEL_SERVICE_NAME", "")
+
+	tr := newTracer()
+	run := tr.start("tfplan-commenter", nil)
+	parse := tr.start("parse", run)
+	parse.setAttribute("plans.with_changes", 3)
+	parse.finish()
+	run.finish()
+
+	if err := tr.export(server.URL + "/v1/traces"); err != nil {
+		t.Fatal(err)
+	}
+	if apiKey != "secret" {
+		t.Errorf("Expected OTLP headers to be sent, got %q", apiKey)
+	}
+
+	resourceSpans := received["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %+v", spans)
+	}
+	root, child := spans[0].(map[string]interface{}), spans[1].(map[string]interface{})
+	if child["parentSpanId"] != root["spanId"] || child["traceId"] != root["traceId"] {
+		t.Errorf("Expected parse span nested under the run span, got %+v and %+v", root, child)
+	}
+	attribute := child["attributes"].([]interface{})[0].(map[string]interface{})
+	if attribute["key"] != "plans.with_changes" || attribute["value"].(map[string]interface{})["intValue"] != "3" {
+		t.Errorf("Unexpected span attribute %+v", attribute)
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tr *tracer
+	s := tr.start("parse", nil)
+	s.setAttribute("plans", 1)
+	s.finish()
+	if err := tr.export("http://127.0.0.1:1"); err != nil {
+		t.Errorf("Expected nil tracer to be a no-op, got %v", err)
+	}
+}
+
+func TestOTLPTracesEndpoint(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+	if got := otlpTracesEndpoint(""); got != "http://collector:4318/v1/traces" {
+		t.Errorf("Unexpected endpoint %q", got)
+	}
+	if got := otlpTracesEndpoint("http://other/v1/traces"); got != "http://other/v1/traces" {
+		t.Errorf("Expected flag to take precedence, got %q", got)
+	}
+}
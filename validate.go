@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// validPlanActions are the actions Terraform writes to resource_changes
+var validPlanActions = map[string]bool{
+	"no-op":  true,
+	"create": true,
+	"read":   true,
+	"update": true,
+	"delete": true,
+	"forget": true,
+}
+
+// PlanValidation is the result of checking one plan file
+type PlanValidation struct {
+	Path     string
+	Problems []string // Structural problems that make the plan unusable
+	Warnings []string
+}
+
+// validatePlanFile checks a plan file against the structure the report relies on
+func validatePlanFile(filename string) PlanValidation {
+	result := PlanValidation{Path: filename}
+	problem := func(format string, args ...interface{}) {
+		result.Problems = append(result.Problems, fmt.Sprintf(format, args...))
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		problem("failed to read file: %v", err)
+		return result
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		problem("file is empty")
+		return result
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		var syntaxErr *json.SyntaxError
+		switch {
+		case err.Error() == "unexpected end of JSON input":
+			problem("truncated JSON: document ends after %d bytes", len(data))
+		case errors.As(err, &syntaxErr):
+			// The offset is just past the offending character
+			line, column := offsetPosition(data, syntaxErr.Offset-1)
+			problem("invalid JSON at line %d, column %d: %v", line, column, err)
+		default:
+			problem("invalid JSON: %v", err)
+		}
+		return result
+	}
+
+	fields, ok := document.(map[string]interface{})
+	if !ok {
+		problem("expected a JSON object at the top level")
+		return result
+	}
+
+	if isCloudFormationChangeSet(data) {
+		if _, err := parseCloudFormationChangeSet(data); err != nil {
+			problem("invalid CloudFormation change set: %v", err)
+		}
+		return result
+	}
+
+	var plan TerraformPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		problem("unexpected plan structure: %v", err)
+		return result
+	}
+
+	warnings, err := validateFormatVersion(&plan)
+	if err != nil {
+		problem("%v", err)
+	}
+	result.Warnings = append(result.Warnings, warnings...)
+
+	_, hasChanges := fields["resource_changes"]
+	_, hasPlannedValues := fields["planned_values"]
+	_, hasStateValues := fields["values"]
+	switch {
+	case !hasChanges && !hasPlannedValues && hasStateValues:
+		problem("this is state, not a plan (run `terraform show -json <planfile>` with a saved plan file)")
+	case !hasChanges && !hasPlannedValues:
+		problem("missing resource_changes and planned_values (expected output of `terraform show -json <planfile>`)")
+	case !hasChanges:
+		result.Warnings = append(result.Warnings, "no resource_changes; the plan changes no resources")
+	}
+
+	for i, rc := range plan.ResourceChanges {
+		label := rc.Address
+		if label == "" {
+			label = fmt.Sprintf("resource_changes[%d]", i)
+			problem("%s: missing address", label)
+		}
+		if rc.Type == "" {
+			problem("%s: missing type", label)
+		}
+		if len(rc.Change.Actions) == 0 {
+			problem("%s: missing change.actions", label)
+		}
+		for _, action := range rc.Change.Actions {
+			if !validPlanActions[action] {
+				problem("%s: unsupported action %q", label, action)
+			}
+		}
+	}
+
+	return result
+}
+
+// offsetPosition converts a byte offset into a 1-based line and column
+func offsetPosition(data []byte, offset int64) (int, int) {
+	offset = min(max(offset, 0), int64(len(data)))
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// runValidateCommand implements `tfplan-commenter validate <path>`, checking a
+// plan file or every tfplan.json under a directory
+func runValidateCommand(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tfplan-commenter validate <path>")
+		os.Exit(1)
+	}
+
+	var files []string
+	err := filepath.Walk(args[0], func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == args[0] && !info.IsDir() {
+			files = append(files, path)
+		} else if !info.IsDir() && info.Name() == planFileName {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error accessing input path: %v\n", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "No tfplan.json files found in directory: %s\n", args[0])
+		os.Exit(1)
+	}
+
+	invalid := 0
+	for _, file := range files {
+		result := validatePlanFile(file)
+		if len(result.Problems) > 0 {
+			invalid++
+			fmt.Printf("❌ %s\n", file)
+		} else {
+			fmt.Printf("✅ %s\n", file)
+		}
+		for _, problem := range result.Problems {
+			fmt.Printf("   error: %s\n", problem)
+		}
+		for _, warning := range result.Warnings {
+			fmt.Printf("   warning: %s\n", warning)
+		}
+	}
+
+	if invalid > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d plan file(s) are invalid\n", invalid, len(files))
+		os.Exit(1)
+	}
+}
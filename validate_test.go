@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidatePlanFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		problems []string
+		warnings []string
+	}{
+		{
+			name:    "valid",
+			content: `{"format_version": "1.2", "resource_changes": [{"address": "aws_instance.web", "type": "aws_instance", "change": {"actions": ["create"]}}]}`,
+		},
+		{
+			name:     "no changes",
+			content:  `{"format_version": "1.2", "planned_values": {}}`,
+			warnings: []string{"no resource_changes"},
+		},
+		{
+			name:     "empty",
+			content:  "  \n",
+			problems: []string{"file is empty"},
+		},
+		{
+			name:     "truncated",
+			content:  `{"format_version": "1.2", "resource_changes": [`,
+			problems: []string{"truncated JSON: document ends after 47 bytes"},
+		},
+		{
+			name:     "syntax error",
+			content:  "{\n  \"format_version\": \"1.2\",,\n}",
+			problems: []string{"invalid JSON at line 2, column 27"},
+		},
+		{
+			name:     "state",
+			content:  `{"format_version": "1.0", "values": {}}`,
+			problems: []string{"this is state, not a plan"},
+		},
+		{
+			name:     "missing format version and changes",
+			content:  `{"foo": "bar"}`,
+			problems: []string{"missing resource_changes and planned_values"},
+			warnings: []string{"Plan has no format_version"},
+		},
+		{
+			name:     "bad resource change",
+			content:  `{"format_version": "1.2", "resource_changes": [{"type": "aws_instance", "change": {"actions": ["explode"]}}]}`,
+			problems: []string{"resource_changes[0]: missing address", `resource_changes[0]: unsupported action "explode"`},
+		},
+		{
+			name:     "newer format",
+			content:  `{"format_version": "2.0", "resource_changes": []}`,
+			warnings: []string{"Plan format version 2.0 is newer than supported"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "tfplan.json")
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			result := validatePlanFile(file)
+			checkMessages(t, "problems", result.Problems, tt.problems)
+			checkMessages(t, "warnings", result.Warnings, tt.warnings)
+		})
+	}
+}
+
+// checkMessages verifies each message starts with the expected prefix
+func checkMessages(t *testing.T, kind string, got, expected []string) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %s %q, got %q", kind, expected, got)
+	}
+	for i := range expected {
+		if !strings.HasPrefix(got[i], expected[i]) {
+			t.Errorf("Expected %s %d to start with %q, got %q", kind, i, expected[i], got[i])
+		}
+	}
+}
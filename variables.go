@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultRedactPattern matches variable names that commonly hold secrets
+const defaultRedactPattern = `(?i)(password|secret|token|private_key|api_key|credential)`
+
+// PlanVariable is an input variable value recorded in the plan
+type PlanVariable struct {
+	Value interface{} `json:"value"`
+}
+
+// formatVariableRows describes each input variable as a markdown table row,
+// redacting values declared sensitive or whose name matches redactPattern
+// (defaultRedactPattern when nil)
+func formatVariableRows(plan *TerraformPlan, redactPattern *regexp.Regexp) []string {
+	if redactPattern == nil {
+		redactPattern = regexp.MustCompile(defaultRedactPattern)
+	}
+
+	names := make([]string, 0, len(plan.Variables))
+	for name := range plan.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sensitive map[string]ConfigVariable
+	if plan.Configuration != nil {
+		sensitive = plan.Configuration.RootModule.Variables
+	}
+
+	var rows []string
+	for _, name := range names {
+		value := formatAttributeValue(plan.Variables[name].Value)
+		if sensitive[name].Sensitive || redactPattern.MatchString(name) {
+			value = "(sensitive)"
+		}
+		rows = append(rows, fmt.Sprintf("| `%s` | %s |", name, strings.ReplaceAll(value, "|", "\\|")))
+	}
+	return rows
+}
+
+// writeVariablesSection renders the plan's input variables in a collapsed table
+func writeVariablesSection(md *strings.Builder, plan *TerraformPlan, redactPattern *regexp.Regexp) {
+	rows := formatVariableRows(plan, redactPattern)
+	if len(rows) == 0 {
+		return
+	}
+
+	md.WriteString("<details>\n")
+	md.WriteString(fmt.Sprintf("<summary>🔧 Input variables (%d)</summary>\n\n", len(rows)))
+	md.WriteString("| Variable | Value |\n")
+	md.WriteString("|----------|-------|\n")
+	for _, row := range rows {
+		md.WriteString(row + "\n")
+	}
+	md.WriteString("\n</details>\n\n")
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFormatVariableRows(t *testing.T) {
+	plan := &TerraformPlan{
+		Variables: map[string]PlanVariable{
+			"region":      {Value: "us-east-1"},
+			"db_password": {Value: "hunter2"},
+			"api_secret":  {Value: "abc"},
+			"replicas":    {Value: float64(3)},
+		},
+		Configuration: &Configuration{
+			RootModule: ConfigModule{
+				Variables: map[string]ConfigVariable{
+					"api_secret": {Sensitive: true},
+				},
+			},
+		},
+	}
+
+	expected := []string{
+		"| `api_secret` | (sensitive) |",
+		"| `db_password` | (sensitive) |",
+		"| `region` | \"us-east-1\" |",
+		"| `replicas` | 3 |",
+	}
+
+	rows := formatVariableRows(plan, regexp.MustCompile(defaultRedactPattern))
+	if len(rows) != len(expected) {
+		t.Fatalf("Expected %d rows, got %d: %v", len(expected), len(rows), rows)
+	}
+	for i := range expected {
+		if rows[i] != expected[i] {
+			t.Errorf("Row %d: expected %s, got %s", i, expected[i], rows[i])
+		}
+	}
+
+	markdown := generateMarkdownComment(plan, ReportOptions{ShowVariables: true})
+	if !strings.Contains(markdown, "Input variables (4)") {
+		t.Error("Expected variables section when ShowVariables is set")
+	}
+	if strings.Contains(markdown, "hunter2") {
+		t.Error("Expected sensitive variable values to be redacted")
+	}
+}
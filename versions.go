@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// terraformVersions returns the distinct Terraform versions the plans were
+// created with, in first-seen order. CloudFormation change sets are skipped.
+func terraformVersions(plans []PlanInfo) []string {
+	var versions []string
+	seen := make(map[string]bool)
+	for _, planInfo := range plans {
+		if planInfo.Plan.Source == sourceCloudFormation || seen[planInfo.Plan.TerraformVersion] {
+			continue
+		}
+		seen[planInfo.Plan.TerraformVersion] = true
+		versions = append(versions, planInfo.Plan.TerraformVersion)
+	}
+	return versions
+}
+
+// writeVersionMismatchSection warns when environments were planned with
+// different Terraform versions, listing the version of each environment
+func writeVersionMismatchSection(md *strings.Builder, plans []PlanInfo, opts ReportOptions) {
+	versions := terraformVersions(plans)
+	if len(versions) < 2 {
+		return
+	}
+
+	md.WriteString("### ⚠️ Terraform Version Mismatch\n\n")
+	md.WriteString(fmt.Sprintf("Environments were planned with %d different Terraform versions (%s). "+
+		"Align them before applying, since state written by a newer version cannot be read by an older one.\n\n",
+		len(versions), strings.Join(versions, ", ")))
+	md.WriteString("| Environment | Terraform Version |\n")
+	md.WriteString("|-------------|-------------------|\n")
+	for _, planInfo := range plans {
+		if planInfo.Plan.Source == sourceCloudFormation {
+			continue
+		}
+		md.WriteString(fmt.Sprintf("| %s | %s |\n",
+			formatEnvironment(planInfo.RelativePath, opts.EnvironmentNames), planInfo.Plan.TerraformVersion))
+	}
+	md.WriteString("\n")
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersionMismatchSection(t *testing.T) {
+	change := []ResourceChange{{Address: "aws_sqs_queue.q", Type: "aws_sqs_queue", Change: Change{Actions: []string{"create"}}}}
+	plans := []PlanInfo{
+		{Plan: &TerraformPlan{TerraformVersion: "1.9.8", ResourceChanges: change}, RelativePath: "prod"},
+		{Plan: &TerraformPlan{TerraformVersion: "1.10.2"}, RelativePath: "staging"},
+		{Plan: &TerraformPlan{TerraformVersion: "1.9.8"}, RelativePath: "dev"},
+	}
+
+	result := generateMultiPlanMarkdownComment(plans, ReportOptions{EnvironmentNames: map[string]string{"prod": "Production"}})
+	expected := "### ⚠️ Terraform Version Mismatch\n\n" +
+		"Environments were planned with 2 different Terraform versions (1.9.8, 1.10.2)."
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected a version mismatch warning, got:\n%s", result)
+	}
+	if !strings.Contains(result, "| Production | 1.9.8 |\n| `staging` | 1.10.2 |\n| `dev` | 1.9.8 |\n") {
+		t.Errorf("Expected a version per environment, got:\n%s", result)
+	}
+
+	plans[1].Plan.TerraformVersion = "1.9.8"
+	if result := generateMultiPlanMarkdownComment(plans, ReportOptions{}); strings.Contains(result, "Version Mismatch") {
+		t.Errorf("Expected no warning for a single version, got:\n%s", result)
+	}
+}
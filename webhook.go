@@ -0,0 +1,264 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+)
+
+// plansFromArchive reads every tfplan.json in a zip archive. Environments are
+// named after the file's directory, prefixed with prefix when set.
+func plansFromArchive(data []byte, prefix string) ([]PlanInfo, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open artifact archive: %w", err)
+	}
+
+	var plans []PlanInfo
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || path.Base(file.Name) != planFileName {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", file.Name, err)
+		}
+		content, err := io.ReadAll(io.LimitReader(reader, maxRequestBytes))
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+		}
+
+		plan, err := parseTerraformPlan(content, file.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Failed to read plan file %s: %v\n", file.Name, err)
+			continue
+		}
+		if hasNoChanges(plan) {
+			continue
+		}
+
+		relPath := path.Dir(file.Name)
+		if prefix != "" {
+			relPath = path.Join(prefix, relPath)
+		}
+		if relPath == "." {
+			relPath = "root"
+		}
+		plans = append(plans, PlanInfo{Plan: plan, RelativePath: relPath})
+	}
+	return plans, nil
+}
+
+// renderPlans renders a single plan on its own and several as a multi-environment report
+func renderPlans(plans []PlanInfo, opts ReportOptions) string {
+	if len(plans) == 1 {
+		return generateMarkdownComment(plans[0].Plan, opts)
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].RelativePath < plans[j].RelativePath
+	})
+	return generateMultiPlanMarkdownComment(plans, opts)
+}
+
+// writeWebhookResult reports the outcome of a webhook delivery; skipped
+// deliveries are acknowledged so the sender doesn't retry them
+func writeWebhookResult(w http.ResponseWriter, status int, result map[string]string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(result)
+}
+
+// GitHubWorkflowRunEvent is the subset of the workflow_run webhook payload used
+type GitHubWorkflowRunEvent struct {
+	Action      string `json:"action"`
+	WorkflowRun struct {
+		ID           int64  `json:"id"`
+		HeadSHA      string `json:"head_sha"`
+		HTMLURL      string `json:"html_url"`
+		Conclusion   string `json:"conclusion"`
+		PullRequests []struct {
+			Number int `json:"number"`
+		} `json:"pull_requests"`
+	} `json:"workflow_run"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (s *server) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.github == nil {
+		http.Error(w, "GitHub webhooks are not configured (set GITHUB_TOKEN on the server)", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		writeWebhookResult(w, http.StatusOK, map[string]string{"status": "pong"})
+		return
+	case "workflow_run":
+	case "issue_comment":
+		s.handleGitHubIssueComment(w, r)
+		return
+	default:
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("event %q", event)})
+		return
+	}
+
+	var event GitHubWorkflowRunEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&event); err != nil {
+		http.Error(w, fmt.Sprintf("invalid workflow_run payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if event.Action != "completed" {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "workflow run not completed"})
+		return
+	}
+	if len(event.WorkflowRun.PullRequests) == 0 {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "workflow run has no pull request"})
+		return
+	}
+
+	repository := event.Repository.FullName
+	artifacts, err := s.github.listArtifacts(repository, event.WorkflowRun.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var plans []PlanInfo
+	for _, artifact := range artifacts {
+		if artifact.Expired {
+			continue
+		}
+		data, err := s.github.download(artifact.ArchiveDownloadURL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		prefix := ""
+		if len(artifacts) > 1 {
+			prefix = artifact.Name
+		}
+		found, err := plansFromArchive(data, prefix)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("artifact %s: %v", artifact.Name, err), http.StatusUnprocessableEntity)
+			return
+		}
+		plans = append(plans, found...)
+	}
+	if len(plans) == 0 {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "no plans with changes in artifacts"})
+		return
+	}
+
+	number := event.WorkflowRun.PullRequests[0].Number
+	url, err := s.publishPlanComment(repository, number, event.WorkflowRun.HeadSHA, event.WorkflowRun.HTMLURL, plans, renderPlans(plans, s.opts))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeWebhookResult(w, http.StatusOK, map[string]string{"status": "published", "comment_url": url})
+}
+
+// GitLabPipelineEvent is the subset of the pipeline webhook payload used
+type GitLabPipelineEvent struct {
+	ObjectKind       string `json:"object_kind"`
+	ObjectAttributes struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	} `json:"object_attributes"`
+	MergeRequest *struct {
+		IID int `json:"iid"`
+	} `json:"merge_request"`
+	Project struct {
+		ID     int    `json:"id"`
+		WebURL string `json:"web_url"`
+	} `json:"project"`
+	Builds []struct {
+		ID            int    `json:"id"`
+		Name          string `json:"name"`
+		ArtifactsFile struct {
+			Filename string `json:"filename"`
+		} `json:"artifacts_file"`
+	} `json:"builds"`
+}
+
+// completedPipelineStatuses are the pipeline statuses that can carry plan artifacts
+var completedPipelineStatuses = map[string]bool{"success": true, "failed": true}
+
+func (s *server) handleGitLabWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.gitlab == nil {
+		http.Error(w, "GitLab webhooks are not configured (set GITLAB_TOKEN on the server)", http.StatusServiceUnavailable)
+		return
+	}
+
+	var event GitLabPipelineEvent
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&event); err != nil {
+		http.Error(w, fmt.Sprintf("invalid pipeline payload: %v", err), http.StatusBadRequest)
+		return
+	}
+	if event.ObjectKind != "pipeline" {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": fmt.Sprintf("event %q", event.ObjectKind)})
+		return
+	}
+	if !completedPipelineStatuses[event.ObjectAttributes.Status] {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "pipeline not completed"})
+		return
+	}
+	if event.MergeRequest == nil {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "pipeline has no merge request"})
+		return
+	}
+
+	builds := event.Builds[:0]
+	for _, build := range event.Builds {
+		if build.ArtifactsFile.Filename != "" {
+			builds = append(builds, build)
+		}
+	}
+
+	project := strconv.Itoa(event.Project.ID)
+	var plans []PlanInfo
+	for _, build := range builds {
+		data, err := s.gitlab.downloadJobArtifacts(project, build.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		prefix := ""
+		if len(builds) > 1 {
+			prefix = build.Name
+		}
+		found, err := plansFromArchive(data, prefix)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("job %s: %v", build.Name, err), http.StatusUnprocessableEntity)
+			return
+		}
+		plans = append(plans, found...)
+	}
+	if len(plans) == 0 {
+		writeWebhookResult(w, http.StatusAccepted, map[string]string{"status": "ignored", "reason": "no plans with changes in artifacts"})
+		return
+	}
+	noteID, err := s.gitlab.createMergeRequestNote(project, event.MergeRequest.IID, renderPlans(plans, s.opts))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	url := fmt.Sprintf("%s/-/merge_requests/%d#note_%d", event.Project.WebURL, event.MergeRequest.IID, noteID)
+	if users, _ := requestedReviewers(s.opts.Reviewers, plans); len(users) > 0 {
+		if err := s.gitlab.requestMergeRequestReviewers(project, event.MergeRequest.IID, users); err != nil {
+			http.Error(w, fmt.Sprintf("failed to request reviewers: %v", err), http.StatusBadGateway)
+			return
+		}
+	}
+	writeWebhookResult(w, http.StatusOK, map[string]string{"status": "published", "comment_url": url})
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testArchive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(writer, content)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestPlansFromArchive(t *testing.T) {
+	data := testArchive(t, map[string]string{
+		"dev/tfplan.json":  serverTestPlan,
+		"prod/tfplan.json": `{"format_version": "1.2", "resource_changes": []}`,
+		"tfplan.json":      serverTestPlan,
+		"dev/other.json":   serverTestPlan,
+	})
+
+	plans, err := plansFromArchive(data, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, plan := range plans {
+		paths = append(paths, plan.RelativePath)
+	}
+	if strings.Join(paths, ",") != "dev,root" && strings.Join(paths, ",") != "root,dev" {
+		t.Errorf("Expected the dev and root plans with changes, got %v", paths)
+	}
+
+	plans, err = plansFromArchive(data, "plan-job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, plan := range plans {
+		if !strings.HasPrefix(plan.RelativePath, "plan-job") {
+			t.Errorf("Expected the prefix on %q", plan.RelativePath)
+		}
+	}
+
+	if _, err := plansFromArchive([]byte("not a zip"), ""); err == nil {
+		t.Error("Expected an error for an invalid archive")
+	}
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	archive := testArchive(t, map[string]string{"tfplan.json": serverTestPlan})
+	var posted string
+	var api *httptest.Server
+	api = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/infra/actions/runs/42/artifacts":
+			if r.URL.Query().Get("page") == "1" {
+				expired := strings.Repeat(`{"name": "old", "expired": true},`, 100)
+				io.WriteString(w, `{"total_count": 101, "artifacts": [`+strings.TrimSuffix(expired, ",")+`]}`)
+				return
+			}
+			io.WriteString(w, `{"total_count": 101, "artifacts": [{"name": "plans", "archive_download_url": "`+api.URL+`/download/1", "expired": false}]}`)
+		case "/download/1":
+			w.Write(archive)
+		case "/user":
+			io.WriteString(w, `{"login": "tfplan-bot"}`)
+		case "/repos/org/infra/issues/7/comments":
+			if r.Method == http.MethodGet {
+				io.WriteString(w, `[]`)
+				return
+			}
+			var comment map[string]string
+			json.NewDecoder(r.Body).Decode(&comment)
+			posted = comment["body"]
+			io.WriteString(w, `{"html_url": "https://github.com/org/infra/pull/7#issuecomment-1"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	t.Setenv("GITHUB_API_URL", api.URL)
+
+	ts := httptest.NewServer((&server{opts: ReportOptions{Sort: sortByAddress}, github: newGitHubClient("secret")}).handler())
+	defer ts.Close()
+
+	deliver := func(event, payload string) (int, map[string]string) {
+		request, _ := http.NewRequest(http.MethodPost, ts.URL+"/webhook/github", strings.NewReader(payload))
+		request.Header.Set("X-GitHub-Event", event)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer response.Body.Close()
+		var result map[string]string
+		json.NewDecoder(response.Body).Decode(&result)
+		return response.StatusCode, result
+	}
+
+	payload := `{"action": "completed", "workflow_run": {"id": 42, "head_sha": "abc123", "pull_requests": [{"number": 7}]}, "repository": {"full_name": "org/infra"}}`
+	status, result := deliver("workflow_run", payload)
+	if status != http.StatusOK || result["status"] != "published" {
+		t.Fatalf("Expected the report to be published, got %d %v", status, result)
+	}
+	if !strings.Contains(posted, "aws_instance.web") || !strings.Contains(posted, headMarker("abc123")) {
+		t.Errorf("Expected the plan from the second page of artifacts for the run's commit in the posted comment, got:\n%s", posted)
+	}
+
+	if status, _ := deliver("push", "{}"); status != http.StatusAccepted {
+		t.Errorf("Expected other events to be acknowledged and ignored, got %d", status)
+	}
+	if status, result := deliver("workflow_run", `{"action": "completed", "workflow_run": {"id": 42}}`); status != http.StatusAccepted {
+		t.Errorf("Expected runs without a pull request to be ignored, got %d %v", status, result)
+	}
+}
+
+func TestGitLabWebhook(t *testing.T) {
+	archive := testArchive(t, map[string]string{"env/dev/tfplan.json": serverTestPlan})
+	var posted string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/projects/5/jobs/11/artifacts":
+			w.Write(archive)
+		case "/projects/5/merge_requests/3/notes":
+			var note map[string]string
+			json.NewDecoder(r.Body).Decode(&note)
+			posted = note["body"]
+			io.WriteString(w, `{"id": 99}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+	t.Setenv("GITLAB_API_URL", api.URL)
+
+	ts := httptest.NewServer((&server{opts: ReportOptions{Sort: sortByAddress}, gitlab: newGitLabClient("secret")}).handler())
+	defer ts.Close()
+
+	payload := `{"object_kind": "pipeline", "object_attributes": {"id": 1, "status": "success"},
+		"merge_request": {"iid": 3}, "project": {"id": 5, "web_url": "https://gitlab.com/org/infra"},
+		"builds": [{"id": 11, "name": "plan", "artifacts_file": {"filename": "artifacts.zip"}}, {"id": 12, "name": "lint", "artifacts_file": {"filename": null}}]}`
+	response, err := http.Post(ts.URL+"/webhook/gitlab", "application/json", strings.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]string
+	json.NewDecoder(response.Body).Decode(&result)
+	response.Body.Close()
+	if result["comment_url"] != "https://gitlab.com/org/infra/-/merge_requests/3#note_99" {
+		t.Errorf("Unexpected result %d %v", response.StatusCode, result)
+	}
+	if !strings.Contains(posted, "aws_instance.web") {
+		t.Errorf("Expected the plan in the posted note, got:\n%s", posted)
+	}
+
+	running := strings.Replace(payload, `"success"`, `"running"`, 1)
+	response, err = http.Post(ts.URL+"/webhook/gitlab", "application/json", strings.NewReader(running))
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected running pipelines to be ignored, got %d", response.StatusCode)
+	}
+}